@@ -13,6 +13,7 @@ import (
 	"github.com/alphabill-org/alphabill/partition"
 	"github.com/alphabill-org/alphabill/state"
 	"github.com/alphabill-org/alphabill/txsystem"
+	txtypes "github.com/alphabill-org/alphabill/txsystem/types"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -34,6 +35,8 @@ type (
 		PartitionTypeIDString() string
 		DefaultPartitionParams(flags *ShardConfGenerateFlags) map[string]string
 		NewGenesisState(pdr *types.PartitionDescriptionRecord) (*state.State, error)
+		UnitDataConstructor(pdr *types.PartitionDescriptionRecord) state.UnitDataConstructor
+		TxDecoders(pdr *types.PartitionDescriptionRecord) (txtypes.TxExecutors, error)
 		CreateTxSystem(flags *ShardNodeRunFlags, nodeConf *partition.NodeConf) (txsystem.TransactionSystem, error)
 	}
 )
@@ -66,7 +69,15 @@ func (a *AlphabillApp) AddSubcommands(opts []interface{}) {
 	a.baseCmd.AddCommand(newTrustBaseCmd(a.baseConfig))
 	a.baseCmd.AddCommand(newShardNodeCmd(a.baseConfig, convertOptsToRunnable(opts)))
 	a.baseCmd.AddCommand(newShardConfCmd(a.baseConfig))
+	a.baseCmd.AddCommand(newStateCmd(a.baseConfig))
+	a.baseCmd.AddCommand(newBlocksCmd(a.baseConfig))
+	a.baseCmd.AddCommand(newProofCmd(a.baseConfig))
+	a.baseCmd.AddCommand(newDecodeCmd(a.baseConfig))
+	a.baseCmd.AddCommand(newDevnetCmd(a.baseConfig))
+	a.baseCmd.AddCommand(newLoadgenCmd(a.baseConfig))
 	a.baseCmd.AddCommand(newNodeIDCmd(a.baseConfig))
+	a.baseCmd.AddCommand(newKeysCmd(a.baseConfig))
+	a.baseCmd.AddCommand(newRemoteSignerCmd(a.baseConfig))
 }
 
 func (a *AlphabillApp) RegisterPartition(partition Partition) error {
@@ -0,0 +1,412 @@
+package cmd
+
+import (
+	gocrypto "crypto"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alphabill-org/alphabill-go-base/cbor"
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill-go-base/util"
+
+	"github.com/alphabill-org/alphabill/keyvaluedb"
+	"github.com/alphabill-org/alphabill/keyvaluedb/boltdb"
+	"github.com/alphabill-org/alphabill/partition"
+	"github.com/alphabill-org/alphabill/state"
+)
+
+type (
+	blocksFlags struct {
+		*baseFlags
+		BlockStoreFile string
+	}
+
+	blocksPrintFlags struct {
+		blocksFlags
+		Round uint64
+	}
+
+	blocksExportFlags struct {
+		blocksFlags
+		FromRound  uint64
+		ToRound    uint64
+		OutputFile string
+	}
+
+	blocksImportFlags struct {
+		blocksFlags
+		shardConfFlags
+		trustBaseFlags
+		InputFile string
+	}
+
+	// blocksFileHeader is the header of the block export file. The header is
+	// followed by BlockCount CBOR encoded blocks and the CRC32 checksum of the
+	// file (the same layout as the state file).
+	blocksFileHeader struct {
+		_           struct{} `cbor:",toarray"`
+		Version     types.ABVersion
+		PartitionID types.PartitionID
+		ShardID     types.ShardID
+		BlockCount  uint64
+	}
+
+	// blockInfo is the output of the "blocks print" command
+	blockInfo struct {
+		Header             *types.Header             `json:"header"`
+		UnicityCertificate *types.UnicityCertificate `json:"unicityCertificate"`
+		Transactions       []*transactionRecordInfo  `json:"transactions"`
+	}
+
+	transactionRecordInfo struct {
+		TransactionOrder *types.TransactionOrder `json:"transactionOrder"`
+		// Attributes and AuthProof of the transaction order decoded by the
+		// transaction handler of the partition (see "decode" command)
+		Attributes     any                   `json:"attributes,omitempty"`
+		AuthProof      any                   `json:"authProof,omitempty"`
+		ServerMetadata *types.ServerMetadata `json:"serverMetadata,omitempty"`
+	}
+)
+
+func newBlocksCmd(baseFlags *baseFlags) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "blocks",
+		Short: "Tools to work with the block database of a shard node",
+	}
+	cmd.AddCommand(blocksListCmd(baseFlags))
+	cmd.AddCommand(blocksPrintCmd(baseFlags))
+	cmd.AddCommand(blocksExportCmd(baseFlags))
+	cmd.AddCommand(blocksImportCmd(baseFlags))
+	return cmd
+}
+
+func (f *blocksFlags) addBlockStoreFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&f.BlockStoreFile, "block-db", "", "",
+		fmt.Sprintf("path to the block database (default %s)", filepath.Join("$AB_HOME", blockStoreFileName)))
+}
+
+// openBlockStore opens the block database, unlike the node the commands must close it when done
+func (f *blocksFlags) openBlockStore() (*boltdb.BoltDB, error) {
+	path := f.PathWithDefault(f.BlockStoreFile, blockStoreFileName)
+	db, err := boltdb.New(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init %q: %w", path, err)
+	}
+	return db, nil
+}
+
+func blocksListCmd(baseFlags *baseFlags) *cobra.Command {
+	flags := &blocksFlags{baseFlags: baseFlags}
+	var cmd = &cobra.Command{
+		Use:   "list",
+		Short: "Lists the rounds of the blocks in the block database",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return blocksList(cmd.OutOrStdout(), flags)
+		},
+	}
+	flags.addBlockStoreFlags(cmd)
+	return cmd
+}
+
+func blocksPrintCmd(baseFlags *baseFlags) *cobra.Command {
+	flags := &blocksPrintFlags{blocksFlags: blocksFlags{baseFlags: baseFlags}}
+	var cmd = &cobra.Command{
+		Use:   "print",
+		Short: "Prints a block as JSON",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return blocksPrint(cmd.OutOrStdout(), flags)
+		},
+	}
+	flags.addBlockStoreFlags(cmd)
+	cmd.Flags().Uint64VarP(&flags.Round, "round", "r", 0, "round number of the block")
+	_ = cmd.MarkFlagRequired("round")
+	return cmd
+}
+
+func blocksExportCmd(baseFlags *baseFlags) *cobra.Command {
+	flags := &blocksExportFlags{blocksFlags: blocksFlags{baseFlags: baseFlags}}
+	var cmd = &cobra.Command{
+		Use:   "export",
+		Short: "Exports a range of blocks to a file",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return blocksExport(cmd.OutOrStdout(), flags)
+		},
+	}
+	flags.addBlockStoreFlags(cmd)
+	cmd.Flags().Uint64Var(&flags.FromRound, "from", 1, "round number of the first block to export")
+	cmd.Flags().Uint64Var(&flags.ToRound, "to", 0, "round number of the last block to export (default 0, ie up to the latest block)")
+	cmd.Flags().StringVarP(&flags.OutputFile, "output", "o", "", "path to the export file")
+	_ = cmd.MarkFlagRequired("output")
+	return cmd
+}
+
+func blocksImportCmd(baseFlags *baseFlags) *cobra.Command {
+	flags := &blocksImportFlags{blocksFlags: blocksFlags{baseFlags: baseFlags}}
+	var cmd = &cobra.Command{
+		Use:   "import",
+		Short: "Imports blocks from an export file into the block database",
+		Long: `Imports blocks from an export file into the block database. Unicity certificates
+of the blocks are verified against the trust base and the blocks must form a chain
+which extends the latest block in the block database.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return blocksImport(cmd.OutOrStdout(), flags)
+		},
+	}
+	flags.addBlockStoreFlags(cmd)
+	flags.addShardConfFlags(cmd)
+	flags.addTrustBaseFlags(cmd)
+	cmd.Flags().StringVarP(&flags.InputFile, "input", "i", "", "path to the export file")
+	_ = cmd.MarkFlagRequired("input")
+	return cmd
+}
+
+func blocksList(out io.Writer, flags *blocksFlags) (err error) {
+	blockStore, err := flags.openBlockStore()
+	if err != nil {
+		return err
+	}
+	defer func() { err = errors.Join(err, blockStore.Close()) }()
+	dbIt := blockStore.First()
+	defer func() { err = errors.Join(err, dbIt.Close()) }()
+	for ; dbIt.Valid(); dbIt.Next() {
+		var b types.Block
+		if err := dbIt.Value(&b); err != nil {
+			return fmt.Errorf("failed to read block: %w", err)
+		}
+		if len(dbIt.Key()) != 8 {
+			fmt.Fprintf(out, "pending proposal: %d transactions\n", len(b.Transactions))
+			continue
+		}
+		fmt.Fprintf(out, "%d: %d transactions\n", util.BytesToUint64(dbIt.Key()), len(b.Transactions))
+	}
+	return nil
+}
+
+func blocksPrint(out io.Writer, flags *blocksPrintFlags) (err error) {
+	blockStore, err := flags.openBlockStore()
+	if err != nil {
+		return err
+	}
+	defer func() { err = errors.Join(err, blockStore.Close()) }()
+	var b types.Block
+	found, err := blockStore.Read(util.Uint64ToBytes(flags.Round), &b)
+	if err != nil {
+		return fmt.Errorf("failed to read block %d: %w", flags.Round, err)
+	}
+	if !found {
+		return fmt.Errorf("block %d not found", flags.Round)
+	}
+
+	info := &blockInfo{Header: b.Header}
+	if b.UnicityCertificate != nil {
+		info.UnicityCertificate = &types.UnicityCertificate{}
+		if err := cbor.Unmarshal(b.UnicityCertificate, info.UnicityCertificate); err != nil {
+			return fmt.Errorf("failed to decode unicity certificate: %w", err)
+		}
+	}
+	for i, txr := range b.Transactions {
+		txo, err := txr.GetTransactionOrderV1()
+		if err != nil {
+			return fmt.Errorf("failed to decode transaction %d: %w", i, err)
+		}
+		info.Transactions = append(info.Transactions, &transactionRecordInfo{TransactionOrder: txo, ServerMetadata: txr.ServerMetadata})
+	}
+	return writeJSON(out, info)
+}
+
+func blocksExport(out io.Writer, flags *blocksExportFlags) (err error) {
+	blockStore, err := flags.openBlockStore()
+	if err != nil {
+		return err
+	}
+	defer func() { err = errors.Join(err, blockStore.Close()) }()
+	blocks, err := readBlocks(blockStore, flags.FromRound, flags.ToRound)
+	if err != nil {
+		return err
+	}
+	if len(blocks) == 0 {
+		return fmt.Errorf("no blocks found in the range %d..%d", flags.FromRound, flags.ToRound)
+	}
+
+	f, err := os.Create(filepath.Clean(flags.OutputFile))
+	if err != nil {
+		return fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer func() { err = errors.Join(err, f.Close()) }()
+	if err := writeBlocks(f, blocks); err != nil {
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+	fmt.Fprintf(out, "exported %d blocks\n", len(blocks))
+	return nil
+}
+
+func blocksImport(out io.Writer, flags *blocksImportFlags) (err error) {
+	shardConf, err := flags.loadShardConf(flags.baseFlags)
+	if err != nil {
+		return err
+	}
+	trustBase, err := flags.loadTrustBase(flags.baseFlags)
+	if err != nil {
+		return err
+	}
+	hashAlgorithm := gocrypto.SHA256
+	ucValidator, err := partition.NewDefaultUnicityCertificateValidator(shardConf.PartitionID, shardConf.ShardID, trustBase, hashAlgorithm)
+	if err != nil {
+		return fmt.Errorf("failed to create unicity certificate validator: %w", err)
+	}
+	// blocks must be certified with the shard conf given by the flags
+	shardConfs := partition.NewEpochShardConfs(shardConf, nil)
+
+	f, err := os.Open(filepath.Clean(flags.InputFile))
+	if err != nil {
+		return fmt.Errorf("failed to open export file: %w", err)
+	}
+	defer f.Close()
+	header, blocks, err := readBlocksFile(f)
+	if err != nil {
+		return fmt.Errorf("failed to read export file: %w", err)
+	}
+	if header.PartitionID != shardConf.PartitionID || header.ShardID.Key() != shardConf.ShardID.Key() {
+		return fmt.Errorf("blocks of shard %s_%s can't be imported into shard %s_%s",
+			header.PartitionID, header.ShardID.String(), shardConf.PartitionID, shardConf.ShardID.String())
+	}
+
+	blockStore, err := flags.openBlockStore()
+	if err != nil {
+		return err
+	}
+	defer func() { err = errors.Join(err, blockStore.Close()) }()
+	prevUC, err := latestBlockUC(blockStore)
+	if err != nil {
+		return err
+	}
+
+	dbTx, err := blockStore.StartTx()
+	if err != nil {
+		return fmt.Errorf("failed to start database transaction: %w", err)
+	}
+	imported := 0
+	for _, b := range blocks {
+		round, err := b.GetRoundNumber()
+		if err != nil {
+			return errors.Join(fmt.Errorf("failed to read round number of the block: %w", err), dbTx.Rollback())
+		}
+		if round <= prevUC.GetRoundNumber() {
+			// already in the block store
+			continue
+		}
+		uc, err := partition.VerifyCertifiedBlock(b, prevUC, ucValidator, shardConfs, hashAlgorithm)
+		if err != nil {
+			return errors.Join(fmt.Errorf("invalid block of round %d: %w", round, err), dbTx.Rollback())
+		}
+		if err := dbTx.Write(util.Uint64ToBytes(uc.GetRoundNumber()), b); err != nil {
+			return errors.Join(fmt.Errorf("failed to write block %d: %w", uc.GetRoundNumber(), err), dbTx.Rollback())
+		}
+		prevUC = uc
+		imported++
+	}
+	if err := dbTx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit imported blocks: %w", err)
+	}
+	fmt.Fprintf(out, "imported %d blocks, latest round %d\n", imported, prevUC.GetRoundNumber())
+	return nil
+}
+
+// readBlocks returns the blocks of the rounds "from".."to" (to == 0 means up to the latest block)
+func readBlocks(blockStore keyvaluedb.KeyValueDB, from, to uint64) (blocks []*types.Block, err error) {
+	dbIt := blockStore.Find(util.Uint64ToBytes(from))
+	defer func() { err = errors.Join(err, dbIt.Close()) }()
+	for ; dbIt.Valid(); dbIt.Next() {
+		if len(dbIt.Key()) != 8 {
+			continue
+		}
+		round := util.BytesToUint64(dbIt.Key())
+		if to != 0 && round > to {
+			break
+		}
+		b := &types.Block{}
+		if err := dbIt.Value(b); err != nil {
+			return nil, fmt.Errorf("failed to read block %d: %w", round, err)
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks, nil
+}
+
+// latestBlockUC returns the UC of the latest block in the block store, nil if the store is empty
+func latestBlockUC(blockStore keyvaluedb.KeyValueDB) (_ *types.UnicityCertificate, err error) {
+	dbIt := blockStore.Last()
+	defer func() { err = errors.Join(err, dbIt.Close()) }()
+	if !dbIt.Valid() || len(dbIt.Key()) != 8 {
+		return nil, nil
+	}
+	var b types.Block
+	if err := dbIt.Value(&b); err != nil {
+		return nil, fmt.Errorf("failed to read latest block: %w", err)
+	}
+	uc := &types.UnicityCertificate{}
+	if err := cbor.Unmarshal(b.UnicityCertificate, uc); err != nil {
+		return nil, fmt.Errorf("failed to decode unicity certificate of the latest block: %w", err)
+	}
+	return uc, nil
+}
+
+func writeBlocks(w io.Writer, blocks []*types.Block) error {
+	crc32Writer := state.NewCRC32Writer(w)
+	encoder, err := cbor.GetEncoder(crc32Writer)
+	if err != nil {
+		return fmt.Errorf("unable to get encoder: %w", err)
+	}
+	header := &blocksFileHeader{
+		Version:     1,
+		PartitionID: blocks[0].Header.PartitionID,
+		ShardID:     blocks[0].Header.ShardID,
+		BlockCount:  uint64(len(blocks)),
+	}
+	if err := encoder.Encode(header); err != nil {
+		return fmt.Errorf("unable to write header: %w", err)
+	}
+	for _, b := range blocks {
+		if err := encoder.Encode(b); err != nil {
+			return fmt.Errorf("unable to write block: %w", err)
+		}
+	}
+	if err := encoder.Encode(util.Uint32ToBytes(crc32Writer.Sum())); err != nil {
+		return fmt.Errorf("unable to write checksum: %w", err)
+	}
+	return nil
+}
+
+func readBlocksFile(r io.Reader) (*blocksFileHeader, []*types.Block, error) {
+	crc32Reader := state.NewCRC32Reader(r, state.CBORChecksumLength)
+	decoder := cbor.GetDecoder(crc32Reader)
+
+	header := &blocksFileHeader{}
+	if err := decoder.Decode(header); err != nil {
+		return nil, nil, fmt.Errorf("unable to decode header: %w", err)
+	}
+	if header.Version != 1 {
+		return nil, nil, fmt.Errorf("unsupported version %d", header.Version)
+	}
+	var blocks []*types.Block
+	for i := range header.BlockCount {
+		b := &types.Block{}
+		if err := decoder.Decode(b); err != nil {
+			return nil, nil, fmt.Errorf("unable to decode block %d: %w", i, err)
+		}
+		blocks = append(blocks, b)
+	}
+	var checksum []byte
+	if err := decoder.Decode(&checksum); err != nil {
+		return nil, nil, fmt.Errorf("unable to decode checksum: %w", err)
+	}
+	if util.BytesToUint32(checksum) != crc32Reader.Sum() {
+		return nil, nil, fmt.Errorf("checksum mismatch")
+	}
+	return header, blocks, nil
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	gocrypto "crypto"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	abcrypto "github.com/alphabill-org/alphabill-go-base/crypto"
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill-go-base/util"
+	testcertificates "github.com/alphabill-org/alphabill/internal/testutils/certificates"
+	testobserve "github.com/alphabill-org/alphabill/internal/testutils/observability"
+	testsig "github.com/alphabill-org/alphabill/internal/testutils/sig"
+	"github.com/alphabill-org/alphabill/internal/testutils/trustbase"
+	"github.com/alphabill-org/alphabill/keyvaluedb/boltdb"
+	testtransaction "github.com/alphabill-org/alphabill/txsystem/testutils/transaction"
+)
+
+func Test_Blocks(t *testing.T) {
+	signer, verifier := testsig.CreateSignerAndVerifier(t)
+	homeDir := writeShardConf(t, defaultMoneyShardConf)
+	require.NoError(t, util.WriteJsonFile(filepath.Join(homeDir, trustBaseFileName), trustbase.NewTrustBase(t, verifier)))
+
+	blocks := createTestBlocks(t, signer, defaultMoneyShardConf, 3)
+	srcDB := filepath.Join(homeDir, "src.db")
+	writeTestBlocks(t, srcDB, blocks...)
+
+	run := func(t *testing.T, args ...string) (string, error) {
+		out := &bytes.Buffer{}
+		cmd := New(testobserve.NewFactory(t))
+		cmd.baseCmd.SetOut(out)
+		cmd.baseCmd.SetArgs(append(args, "--home", homeDir))
+		err := cmd.Execute(context.Background())
+		return out.String(), err
+	}
+
+	t.Run("list", func(t *testing.T) {
+		out, err := run(t, "blocks", "list", "--block-db", srcDB)
+		require.NoError(t, err)
+		require.Equal(t, "1: 1 transactions\n2: 1 transactions\n3: 1 transactions\n", out)
+	})
+
+	t.Run("print", func(t *testing.T) {
+		out, err := run(t, "blocks", "print", "--block-db", srcDB, "--round", "2")
+		require.NoError(t, err)
+		var info blockInfo
+		require.NoError(t, json.Unmarshal([]byte(out), &info), out)
+		require.EqualValues(t, 2, info.UnicityCertificate.GetRoundNumber())
+		require.Len(t, info.Transactions, 1)
+		require.Equal(t, defaultMoneyShardConf.PartitionID, info.Transactions[0].TransactionOrder.PartitionID)
+
+		_, err = run(t, "blocks", "print", "--block-db", srcDB, "--round", "4")
+		require.EqualError(t, err, "block 4 not found")
+	})
+
+	t.Run("export and import", func(t *testing.T) {
+		exportFile := filepath.Join(t.TempDir(), "blocks.cbor")
+		out, err := run(t, "blocks", "export", "--block-db", srcDB, "--from", "2", "--output", exportFile)
+		require.NoError(t, err)
+		require.Equal(t, "exported 2 blocks\n", out)
+
+		// import into an empty block store
+		dstDB := filepath.Join(t.TempDir(), "dst.db")
+		out, err = run(t, "blocks", "import", "--block-db", dstDB, "--input", exportFile)
+		require.NoError(t, err)
+		require.Equal(t, "imported 2 blocks, latest round 3\n", out)
+		out, err = run(t, "blocks", "list", "--block-db", dstDB)
+		require.NoError(t, err)
+		require.Equal(t, "2: 1 transactions\n3: 1 transactions\n", out)
+
+		// import into a block store which must be extended by the blocks
+		dstDB = filepath.Join(t.TempDir(), "dst.db")
+		writeTestBlocks(t, dstDB, blocks[0])
+		out, err = run(t, "blocks", "import", "--block-db", dstDB, "--input", exportFile)
+		require.NoError(t, err)
+		require.Equal(t, "imported 2 blocks, latest round 3\n", out)
+
+		// import into a block store which already contains some of the blocks
+		dstDB = filepath.Join(t.TempDir(), "dst.db")
+		writeTestBlocks(t, dstDB, blocks[0], blocks[1])
+		out, err = run(t, "blocks", "import", "--block-db", dstDB, "--input", exportFile)
+		require.NoError(t, err)
+		require.Equal(t, "imported 1 blocks, latest round 3\n", out)
+		out, err = run(t, "blocks", "list", "--block-db", dstDB)
+		require.NoError(t, err)
+		require.Equal(t, "1: 1 transactions\n2: 1 transactions\n3: 1 transactions\n", out)
+
+		// importing the same blocks again is no-op
+		out, err = run(t, "blocks", "import", "--block-db", dstDB, "--input", exportFile)
+		require.NoError(t, err)
+		require.Equal(t, "imported 0 blocks, latest round 3\n", out)
+	})
+
+	t.Run("import verifies the chain", func(t *testing.T) {
+		exportFile := filepath.Join(t.TempDir(), "blocks.cbor")
+		_, err := run(t, "blocks", "export", "--block-db", srcDB, "--from", "3", "--output", exportFile)
+		require.NoError(t, err)
+
+		// round 2 is missing
+		dstDB := filepath.Join(t.TempDir(), "dst.db")
+		writeTestBlocks(t, dstDB, blocks[0])
+		_, err = run(t, "blocks", "import", "--block-db", dstDB, "--input", exportFile)
+		require.ErrorContains(t, err, "invalid block of round 3: missing blocks after round 1")
+
+		// blocks not certified by the trust base
+		_, otherVerifier := testsig.CreateSignerAndVerifier(t)
+		otherTrustBase := filepath.Join(t.TempDir(), trustBaseFileName)
+		require.NoError(t, util.WriteJsonFile(otherTrustBase, trustbase.NewTrustBase(t, otherVerifier)))
+		_, err = run(t, "blocks", "import", "--block-db", filepath.Join(t.TempDir(), "dst.db"), "--input", exportFile, "--trust-base", otherTrustBase)
+		require.ErrorContains(t, err, "invalid block of round 3: invalid unicity certificate")
+	})
+}
+
+func createTestBlocks(t *testing.T, signer abcrypto.Signer, shardConf *types.PartitionDescriptionRecord, count int) []*types.Block {
+	var blocks []*types.Block
+	var prevIR *types.InputRecord
+	for round := uint64(1); round <= uint64(count); round++ {
+		ir := &types.InputRecord{
+			Version:      1,
+			RoundNumber:  round,
+			Hash:         []byte{byte(round)},
+			SummaryValue: []byte{1},
+			Timestamp:    types.NewTimestamp(),
+		}
+		b := &types.Block{
+			Header: &types.Header{
+				Version:     1,
+				PartitionID: shardConf.PartitionID,
+				ProposerID:  "test",
+			},
+			Transactions: []*types.TransactionRecord{testtransaction.NewTransactionRecord(t, testtransaction.WithPartition(shardConf))},
+		}
+		if prevIR != nil {
+			ir.PreviousHash = prevIR.Hash
+			b.Header.PreviousBlockHash = prevIR.BlockHash
+		}
+		var err error
+		b.UnicityCertificate, err = (&types.UnicityCertificate{Version: 1, InputRecord: ir}).MarshalCBOR()
+		require.NoError(t, err)
+		ir, err = b.CalculateBlockHash(gocrypto.SHA256)
+		require.NoError(t, err)
+		uc := testcertificates.CreateUnicityCertificate(t, signer, ir, shardConf, round, make([]byte, 32), make([]byte, 32))
+		b.UnicityCertificate, err = uc.MarshalCBOR()
+		require.NoError(t, err)
+		blocks = append(blocks, b)
+		prevIR = ir
+	}
+	return blocks
+}
+
+func writeTestBlocks(t *testing.T, path string, blocks ...*types.Block) {
+	db, err := boltdb.New(path)
+	require.NoError(t, err)
+	for _, b := range blocks {
+		uc := &types.UnicityCertificate{}
+		require.NoError(t, uc.UnmarshalCBOR(b.UnicityCertificate))
+		require.NoError(t, db.Write(util.Uint64ToBytes(uc.GetRoundNumber()), b))
+	}
+	require.NoError(t, db.Close())
+}
@@ -181,6 +181,16 @@ func (r *baseFlags) PathWithDefault(path string, defaultFileName string) string
 	return filepath.Join(r.HomeDir, defaultFileName)
 }
 
+/*
+withHomeDir returns a copy of the flags with the home directory replaced, used to
+run commands of several nodes in the same process.
+*/
+func (r *baseFlags) withHomeDir(homeDir string) *baseFlags {
+	flags := *r
+	flags.HomeDir = homeDir
+	return &flags
+}
+
 func (r *baseFlags) loadConf(path string, defaultFileName string, conf any) error {
 	path = r.PathWithDefault(path, defaultFileName)
 	if _, err := util.ReadJsonFile(path, &conf); err != nil {
@@ -199,6 +209,18 @@ func (f *baseFlags) initStore(path string, defaultFileName string) (keyvaluedb.K
 	return db, nil
 }
 
+/*
+openStore opens the existing database, unlike initStore it fails when the database
+file doesn't exist (the error wraps fs.ErrNotExist).
+*/
+func (f *baseFlags) openStore(path string, defaultFileName string) (keyvaluedb.KeyValueDB, error) {
+	path = f.PathWithDefault(path, defaultFileName)
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("failed to open %q: %w", path, err)
+	}
+	return f.initStore(path, defaultFileName)
+}
+
 func envKey(key string) string {
 	return strings.ToUpper(envPrefix + "_" + key)
 }
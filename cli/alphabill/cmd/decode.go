@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alphabill-org/alphabill-go-base/cbor"
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill-go-base/util"
+
+	txtypes "github.com/alphabill-org/alphabill/txsystem/types"
+)
+
+const (
+	decodeTypeAuto     = "auto"
+	decodeTypeBlock    = "block"
+	decodeTypeTxRecord = "tx-record"
+	decodeTypeTxOrder  = "tx-order"
+	decodeTypeUC       = "uc"
+)
+
+type (
+	decodeFlags struct {
+		*baseFlags
+		shardConfFlags
+		Type          string
+		PartitionType string
+	}
+
+	// decodeResult is the output of the "decode" command
+	decodeResult struct {
+		Type  string `json:"type"`
+		Value any    `json:"value"`
+	}
+
+	// decoder decodes the input into the type, returns false when the input
+	// is not of the type
+	decoder func(data []byte) (any, bool, error)
+)
+
+func newDecodeCmd(baseFlags *baseFlags) *cobra.Command {
+	flags := &decodeFlags{baseFlags: baseFlags}
+	var cmd = &cobra.Command{
+		Use:   "decode <hex or file>",
+		Short: "Decodes CBOR encoded transaction order, transaction record, block or unicity certificate",
+		Long: `Decodes CBOR encoded transaction order, transaction record, block or unicity certificate
+and prints it as JSON. The input is either a hex string or a path to a file containing the
+value (binary or hex encoded). The type of the value is detected automatically unless set
+with the --type flag.
+
+The attributes and auth proofs of the transactions are decoded when the partition type is
+known, ie the partition is described by the shard configuration or the --partition-type
+flag is used.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return decode(cmd.OutOrStdout(), args[0], flags)
+		},
+	}
+	flags.addShardConfFlags(cmd)
+	cmd.Flags().StringVar(&flags.Type, "type", decodeTypeAuto,
+		fmt.Sprintf("type of the value, one of: %s", strings.Join([]string{decodeTypeAuto, decodeTypeBlock, decodeTypeTxRecord, decodeTypeTxOrder, decodeTypeUC}, ", ")))
+	cmd.Flags().StringVar(&flags.PartitionType, "partition-type", "", "partition type of the transactions (money, tokens, orchestration), used when there is no shard conf")
+	return cmd
+}
+
+func decode(out io.Writer, input string, flags *decodeFlags) error {
+	data, err := readCBORInput(input)
+	if err != nil {
+		return err
+	}
+	decoders := map[string]decoder{
+		decodeTypeBlock:    flags.decodeBlock,
+		decodeTypeTxRecord: flags.decodeTxRecord,
+		decodeTypeTxOrder:  flags.decodeTxOrder,
+		decodeTypeUC:       decodeUnicityCertificate,
+	}
+	typeNames := []string{flags.Type}
+	if flags.Type == decodeTypeAuto {
+		// the order matters, more specific types first
+		typeNames = []string{decodeTypeBlock, decodeTypeTxRecord, decodeTypeTxOrder, decodeTypeUC}
+	}
+	for _, typ := range typeNames {
+		dec, ok := decoders[typ]
+		if !ok {
+			return fmt.Errorf("unknown type %q", typ)
+		}
+		v, ok, err := dec(data)
+		if err != nil {
+			return fmt.Errorf("failed to decode %s: %w", typ, err)
+		}
+		if ok {
+			return writeJSON(out, &decodeResult{Type: typ, Value: v})
+		}
+	}
+	if flags.Type != decodeTypeAuto {
+		return fmt.Errorf("input is not a valid %s", flags.Type)
+	}
+	return errors.New("unknown input, expected CBOR encoded transaction order, transaction record, block or unicity certificate")
+}
+
+func (f *decodeFlags) decodeBlock(data []byte) (any, bool, error) {
+	b := &types.Block{}
+	if err := cbor.Unmarshal(data, b); err != nil || b.Header == nil {
+		return nil, false, nil
+	}
+	info := &blockInfo{Header: b.Header}
+	if len(b.UnicityCertificate) > 0 {
+		info.UnicityCertificate = &types.UnicityCertificate{}
+		if err := cbor.Unmarshal(b.UnicityCertificate, info.UnicityCertificate); err != nil {
+			return nil, false, fmt.Errorf("failed to decode unicity certificate: %w", err)
+		}
+	}
+	for i, txr := range b.Transactions {
+		txInfo, err := f.txRecordInfo(txr)
+		if err != nil {
+			return nil, false, fmt.Errorf("transaction %d: %w", i, err)
+		}
+		info.Transactions = append(info.Transactions, txInfo)
+	}
+	return info, true, nil
+}
+
+func (f *decodeFlags) decodeTxRecord(data []byte) (any, bool, error) {
+	txr := &types.TransactionRecord{}
+	if err := cbor.Unmarshal(data, txr); err != nil || len(txr.TransactionOrder) == 0 || txr.ServerMetadata == nil {
+		return nil, false, nil
+	}
+	info, err := f.txRecordInfo(txr)
+	if err != nil {
+		return nil, false, err
+	}
+	return info, true, nil
+}
+
+func (f *decodeFlags) decodeTxOrder(data []byte) (any, bool, error) {
+	txo := &types.TransactionOrder{}
+	if err := cbor.Unmarshal(data, txo); err != nil || len(txo.UnitID) == 0 {
+		return nil, false, nil
+	}
+	info, err := f.txOrderInfo(txo)
+	if err != nil {
+		return nil, false, err
+	}
+	return info, true, nil
+}
+
+func decodeUnicityCertificate(data []byte) (any, bool, error) {
+	uc := &types.UnicityCertificate{}
+	if err := cbor.Unmarshal(data, uc); err != nil || uc.InputRecord == nil || uc.UnicitySeal == nil {
+		return nil, false, nil
+	}
+	return uc, true, nil
+}
+
+func (f *decodeFlags) txRecordInfo(txr *types.TransactionRecord) (*transactionRecordInfo, error) {
+	txo, err := txr.GetTransactionOrderV1()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode transaction order: %w", err)
+	}
+	info, err := f.txOrderInfo(txo)
+	if err != nil {
+		return nil, err
+	}
+	info.ServerMetadata = txr.ServerMetadata
+	return info, nil
+}
+
+/*
+txOrderInfo decodes the attributes and auth proof of the transaction order using
+the transaction handlers of the partition. When the partition type is not known
+only the transaction order is returned.
+*/
+func (f *decodeFlags) txOrderInfo(txo *types.TransactionOrder) (*transactionRecordInfo, error) {
+	info := &transactionRecordInfo{TransactionOrder: txo}
+	txDecoders, err := f.txDecoders(txo.PartitionID)
+	if err != nil || txDecoders == nil {
+		return info, err
+	}
+	if info.Attributes, info.AuthProof, err = txDecoders.DecodeTx(txo); err != nil {
+		return nil, fmt.Errorf("failed to decode transaction of type %d: %w", txo.Type, err)
+	}
+	return info, nil
+}
+
+/*
+txDecoders returns the transaction handlers of the partition, nil when the
+partition type is not known.
+*/
+func (f *decodeFlags) txDecoders(partitionID types.PartitionID) (txtypes.TxExecutors, error) {
+	var shardConf *types.PartitionDescriptionRecord
+	if util.FileExists(f.shardConfPath(f.baseFlags)) {
+		pdr, err := f.loadShardConf(f.baseFlags)
+		if err != nil {
+			return nil, err
+		}
+		if pdr.PartitionID == partitionID {
+			shardConf = pdr
+		}
+	}
+	if shardConf == nil && f.PartitionType != "" {
+		for _, p := range f.partitions {
+			if p.PartitionTypeIDString() == f.PartitionType {
+				shardConf = &types.PartitionDescriptionRecord{PartitionTypeID: p.PartitionTypeID(), PartitionID: partitionID}
+				break
+			}
+		}
+		if shardConf == nil {
+			return nil, fmt.Errorf("unsupported partition type %q", f.PartitionType)
+		}
+	}
+	if shardConf == nil {
+		return nil, nil
+	}
+	p, ok := f.partitions[shardConf.PartitionTypeID]
+	if !ok {
+		return nil, fmt.Errorf("unsupported partition type %d", shardConf.PartitionTypeID)
+	}
+	return p.TxDecoders(shardConf)
+}
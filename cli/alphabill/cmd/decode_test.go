@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/cbor"
+	"github.com/alphabill-org/alphabill-go-base/predicates/templates"
+	moneysdk "github.com/alphabill-org/alphabill-go-base/txsystem/money"
+	testobserve "github.com/alphabill-org/alphabill/internal/testutils/observability"
+	testsig "github.com/alphabill-org/alphabill/internal/testutils/sig"
+	testtransaction "github.com/alphabill-org/alphabill/txsystem/testutils/transaction"
+)
+
+func Test_Decode(t *testing.T) {
+	homeDir := writeShardConf(t, defaultMoneyShardConf)
+	txo := testtransaction.NewTransactionOrder(t,
+		testtransaction.WithPartition(defaultMoneyShardConf),
+		testtransaction.WithTransactionType(moneysdk.TransactionTypeTransfer),
+		testtransaction.WithAttributes(moneysdk.TransferAttributes{NewOwnerPredicate: templates.AlwaysTrueBytes(), TargetValue: 100, Counter: 1}),
+		testtransaction.WithAuthProof(moneysdk.TransferAuthProof{OwnerProof: []byte{1, 2, 3}}),
+	)
+	txoBytes, err := cbor.Marshal(txo)
+	require.NoError(t, err)
+
+	type decodedTx struct {
+		TransactionOrder json.RawMessage `json:"transactionOrder"`
+		Attributes       json.RawMessage `json:"attributes"`
+		AuthProof        json.RawMessage `json:"authProof"`
+		ServerMetadata   json.RawMessage `json:"serverMetadata"`
+	}
+	run := func(t *testing.T, home string, args ...string) (string, error) {
+		out := &bytes.Buffer{}
+		cmd := New(testobserve.NewFactory(t))
+		cmd.baseCmd.SetOut(out)
+		cmd.baseCmd.SetArgs(append(append([]string{"decode"}, args...), "--home", home))
+		err := cmd.Execute(context.Background())
+		return out.String(), err
+	}
+	decodeTx := func(t *testing.T, home string, args ...string) (string, *decodedTx) {
+		out, err := run(t, home, args...)
+		require.NoError(t, err)
+		result := struct {
+			Type  string     `json:"type"`
+			Value *decodedTx `json:"value"`
+		}{}
+		require.NoError(t, json.Unmarshal([]byte(out), &result), out)
+		return result.Type, result.Value
+	}
+
+	t.Run("transaction order", func(t *testing.T) {
+		typ, tx := decodeTx(t, homeDir, fmt.Sprintf("0x%x", txoBytes))
+		require.Equal(t, decodeTypeTxOrder, typ)
+		require.NotEmpty(t, tx.TransactionOrder)
+		require.NotEmpty(t, tx.Attributes)
+		require.NotEmpty(t, tx.AuthProof)
+		require.Empty(t, tx.ServerMetadata)
+	})
+
+	t.Run("partition type not known", func(t *testing.T) {
+		typ, tx := decodeTx(t, t.TempDir(), fmt.Sprintf("%x", txoBytes))
+		require.Equal(t, decodeTypeTxOrder, typ)
+		require.NotEmpty(t, tx.TransactionOrder)
+		require.Empty(t, tx.Attributes)
+
+		// partition type set by flag
+		_, tx = decodeTx(t, t.TempDir(), fmt.Sprintf("%x", txoBytes), "--partition-type", "money")
+		require.NotEmpty(t, tx.Attributes)
+	})
+
+	t.Run("transaction record", func(t *testing.T) {
+		txr := testtransaction.NewTransactionRecord(t, testtransaction.WithPartition(defaultMoneyShardConf))
+		txrBytes, err := cbor.Marshal(txr)
+		require.NoError(t, err)
+		typ, tx := decodeTx(t, t.TempDir(), fmt.Sprintf("%x", txrBytes))
+		require.Equal(t, decodeTypeTxRecord, typ)
+		require.NotEmpty(t, tx.TransactionOrder)
+		require.NotEmpty(t, tx.ServerMetadata)
+	})
+
+	t.Run("block and unicity certificate", func(t *testing.T) {
+		signer, _ := testsig.CreateSignerAndVerifier(t)
+		b := createTestBlocks(t, signer, defaultMoneyShardConf, 1)[0]
+		blockBytes, err := cbor.Marshal(b)
+		require.NoError(t, err)
+		out, err := run(t, t.TempDir(), fmt.Sprintf("%x", blockBytes))
+		require.NoError(t, err)
+		require.Contains(t, out, `"type": "block"`)
+
+		out, err = run(t, t.TempDir(), fmt.Sprintf("%x", []byte(b.UnicityCertificate)))
+		require.NoError(t, err)
+		require.Contains(t, out, `"type": "uc"`)
+
+		_, err = run(t, t.TempDir(), fmt.Sprintf("%x", []byte(b.UnicityCertificate)), "--type", decodeTypeTxOrder)
+		require.EqualError(t, err, "input is not a valid tx-order")
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		_, err := run(t, t.TempDir(), "0x0102")
+		require.ErrorContains(t, err, "unknown input")
+	})
+}
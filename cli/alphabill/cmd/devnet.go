@@ -0,0 +1,399 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill-go-base/util"
+)
+
+const (
+	devnetConfFileName  = "devnet.json"
+	devnetStartFileName = "start.sh"
+	devnetStopFileName  = "stop.sh"
+	devnetPidFileName   = "devnet.pid"
+
+	// root round in which the shard configurations of the devnet are activated
+	devnetEpochStart = 10
+	// offset of the p2p ports of the shard nodes from the base port
+	devnetShardPortOffset = 100
+	// offset of the RPC ports from the p2p ports
+	devnetRPCPortOffset = 1000
+)
+
+type (
+	devnetGenerateFlags struct {
+		*baseFlags
+		RootNodes int
+		Shards    []string // shard specs in the form "<partition type>:<node count>[:<partition id>]"
+		NetworkID uint16
+		BasePort  int
+	}
+
+	devnetRunFlags struct {
+		*baseFlags
+	}
+
+	// devnetConf describes the generated devnet, paths are relative to the devnet home
+	devnetConf struct {
+		NetworkID     types.NetworkID `json:"networkId"`
+		TrustBaseFile string          `json:"trustBaseFile"`
+		RootNodes     []*devnetNode   `json:"rootNodes"`
+		Shards        []*devnetShard  `json:"shards"`
+	}
+
+	devnetShard struct {
+		PartitionID     types.PartitionID     `json:"partitionId"`
+		PartitionTypeID types.PartitionTypeID `json:"partitionTypeId"`
+		ShardConfFile   string                `json:"shardConfFile"`
+		Nodes           []*devnetNode         `json:"nodes"`
+	}
+
+	devnetNode struct {
+		HomeDir    string `json:"homeDir"`
+		NodeID     string `json:"nodeId"`
+		Address    string `json:"address"`
+		RPCAddress string `json:"rpcAddress"`
+	}
+
+	devnetShardSpec struct {
+		partition   Partition
+		nodeCount   int
+		partitionID types.PartitionID
+	}
+)
+
+func newDevnetCmd(baseFlags *baseFlags) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "devnet",
+		Short: "Tools to set up and run a local development network",
+	}
+	cmd.AddCommand(devnetGenerateCmd(baseFlags))
+	cmd.AddCommand(devnetRunCmd(baseFlags))
+	return cmd
+}
+
+func devnetGenerateCmd(baseFlags *baseFlags) *cobra.Command {
+	flags := &devnetGenerateFlags{baseFlags: baseFlags}
+	var cmd = &cobra.Command{
+		Use:   "generate",
+		Short: "Generates keys, shard confs, genesis states and a signed trust base of a local network",
+		Long: `Generates keys, shard confs, genesis states and a signed trust base of a local network
+into the home directory. The network can be started with the generated start script or
+with the "devnet run" command.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return devnetGenerate(cmd.Context(), cmd.OutOrStdout(), flags)
+		},
+	}
+	cmd.Flags().IntVar(&flags.RootNodes, "root-nodes", 3, "number of root nodes")
+	cmd.Flags().StringSliceVar(&flags.Shards, "shard", []string{"money:3", "tokens:3", "orchestration:3"},
+		`shards of the network in the form "<partition type>:<node count>[:<partition id>]", partition ids are assigned sequentially when not set`)
+	cmd.Flags().Uint16Var(&flags.NetworkID, "network-id", uint16(types.NetworkLocal), "network identifier")
+	cmd.Flags().IntVar(&flags.BasePort, "base-port", 26662, "first p2p port of the nodes, RPC ports are offset by 1000")
+	return cmd
+}
+
+func devnetRunCmd(baseFlags *baseFlags) *cobra.Command {
+	flags := &devnetRunFlags{baseFlags: baseFlags}
+	var cmd = &cobra.Command{
+		Use:   "run",
+		Short: "Runs all the nodes of a generated local network in one process",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return devnetRun(cmd.Context(), flags)
+		},
+	}
+	return cmd
+}
+
+func devnetGenerate(ctx context.Context, out io.Writer, flags *devnetGenerateFlags) error {
+	if util.FileExists(filepath.Join(flags.HomeDir, devnetConfFileName)) {
+		return fmt.Errorf("devnet already exists in %q", flags.HomeDir)
+	}
+	if flags.RootNodes < 1 {
+		return errors.New("at least one root node is required")
+	}
+	specs, err := flags.parseShardSpecs()
+	if err != nil {
+		return err
+	}
+
+	conf := &devnetConf{NetworkID: types.NetworkID(flags.NetworkID), TrustBaseFile: trustBaseFileName}
+	port := flags.BasePort
+	var rootNodeInfoFiles []string
+	for i := range flags.RootNodes {
+		node, err := flags.initNode(ctx, fmt.Sprintf("root%d", i+1), port)
+		if err != nil {
+			return fmt.Errorf("failed to init root node: %w", err)
+		}
+		conf.RootNodes = append(conf.RootNodes, node)
+		rootNodeInfoFiles = append(rootNodeInfoFiles, filepath.Join(flags.HomeDir, node.HomeDir, nodeInfoFileName))
+		port++
+	}
+	tbFlags := &trustBaseGenerateFlags{baseFlags: flags.baseFlags, NetworkID: flags.NetworkID, NodeInfoFiles: rootNodeInfoFiles}
+	if err := trustBaseGenerate(tbFlags); err != nil {
+		return err
+	}
+	for _, node := range conf.RootNodes {
+		signFlags := &trustBaseSignFlags{baseFlags: flags.nodeBaseFlags(node), trustBaseFlags: trustBaseFlags{TrustBaseFile: filepath.Join(flags.HomeDir, trustBaseFileName)}}
+		if err := trustBaseSign(signFlags); err != nil {
+			return fmt.Errorf("root node %s failed to sign trust base: %w", node.HomeDir, err)
+		}
+	}
+
+	port = flags.BasePort + devnetShardPortOffset
+	for _, spec := range specs {
+		shard := &devnetShard{
+			PartitionID:     spec.partitionID,
+			PartitionTypeID: spec.partition.PartitionTypeID(),
+			ShardConfFile:   fmt.Sprintf("shard-conf-%d_0.json", spec.partitionID),
+		}
+		var nodeInfoFiles []string
+		for i := range spec.nodeCount {
+			node, err := flags.initNode(ctx, filepath.Join(fmt.Sprintf("%s-%d", spec.partition.PartitionTypeIDString(), spec.partitionID), fmt.Sprintf("node%d", i+1)), port)
+			if err != nil {
+				return fmt.Errorf("failed to init shard node: %w", err)
+			}
+			shard.Nodes = append(shard.Nodes, node)
+			nodeInfoFiles = append(nodeInfoFiles, filepath.Join(flags.HomeDir, node.HomeDir, nodeInfoFileName))
+			port++
+		}
+		confFlags := &ShardConfGenerateFlags{
+			baseFlags:       flags.baseFlags,
+			NetworkID:       flags.NetworkID,
+			PartitionID:     uint32(spec.partitionID),
+			PartitionTypeID: uint32(spec.partition.PartitionTypeID()),
+			ShardID:         "0x80",
+			EpochStart:      devnetEpochStart,
+			NodeInfoFiles:   nodeInfoFiles,
+		}
+		if err := shardConfGenerate(confFlags); err != nil {
+			return fmt.Errorf("failed to generate shard conf of partition %d: %w", spec.partitionID, err)
+		}
+		for _, node := range shard.Nodes {
+			genesisFlags := &shardConfGenesisFlags{
+				baseFlags:      flags.nodeBaseFlags(node),
+				shardConfFlags: shardConfFlags{ShardConfFile: filepath.Join(flags.HomeDir, shard.ShardConfFile)},
+			}
+			if err := shardConfGenesis(genesisFlags); err != nil {
+				return fmt.Errorf("failed to generate genesis state of node %s: %w", node.HomeDir, err)
+			}
+		}
+		conf.Shards = append(conf.Shards, shard)
+	}
+
+	if err := util.WriteJsonFile(filepath.Join(flags.HomeDir, devnetConfFileName), conf); err != nil {
+		return fmt.Errorf("failed to write devnet configuration: %w", err)
+	}
+	if err := writeDevnetScripts(flags.HomeDir, conf); err != nil {
+		return fmt.Errorf("failed to write start scripts: %w", err)
+	}
+	fmt.Fprintf(out, "generated devnet of %d root nodes and %d shards in %s\n", len(conf.RootNodes), len(conf.Shards), flags.HomeDir)
+	return nil
+}
+
+/*
+initNode generates the keys and the node info of a node into the "homeDir"
+(relative to the devnet home).
+*/
+func (f *devnetGenerateFlags) initNode(ctx context.Context, homeDir string, port int) (*devnetNode, error) {
+	node := &devnetNode{
+		HomeDir:    homeDir,
+		Address:    fmt.Sprintf("/ip4/127.0.0.1/tcp/%d", port),
+		RPCAddress: fmt.Sprintf("localhost:%d", port+devnetRPCPortOffset),
+	}
+	initFlags := &shardNodeInitFlags{baseFlags: f.nodeBaseFlags(node), keyConfFlags: keyConfFlags{Generate: true}}
+	if err := shardNodeInit(ctx, initFlags); err != nil {
+		return nil, err
+	}
+	keyConf, err := initFlags.loadKeyConf(initFlags.baseFlags, false)
+	if err != nil {
+		return nil, err
+	}
+	nodeID, err := keyConf.NodeID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get node identifier: %w", err)
+	}
+	node.NodeID = nodeID.String()
+	return node, nil
+}
+
+func (f *devnetGenerateFlags) parseShardSpecs() ([]*devnetShardSpec, error) {
+	var specs []*devnetShardSpec
+	usedIDs := map[types.PartitionID]bool{}
+	for _, s := range f.Shards {
+		parts := strings.Split(s, ":")
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil, fmt.Errorf("invalid shard %q, expected <partition type>:<node count>[:<partition id>]", s)
+		}
+		spec := &devnetShardSpec{}
+		for _, p := range f.partitions {
+			if p.PartitionTypeIDString() == parts[0] {
+				spec.partition = p
+			}
+		}
+		if spec.partition == nil {
+			return nil, fmt.Errorf("invalid shard %q: unsupported partition type %q", s, parts[0])
+		}
+		nodeCount, err := strconv.Atoi(parts[1])
+		if err != nil || nodeCount < 1 {
+			return nil, fmt.Errorf("invalid shard %q: invalid node count %q", s, parts[1])
+		}
+		spec.nodeCount = nodeCount
+		if len(parts) == 3 {
+			id, err := strconv.ParseUint(parts[2], 10, 32)
+			if err != nil || id == 0 {
+				return nil, fmt.Errorf("invalid shard %q: invalid partition id %q", s, parts[2])
+			}
+			spec.partitionID = types.PartitionID(id)
+			if usedIDs[spec.partitionID] {
+				return nil, fmt.Errorf("invalid shard %q: partition id %d is already used", s, id)
+			}
+			usedIDs[spec.partitionID] = true
+		}
+		specs = append(specs, spec)
+	}
+	// assign the partition ids which were not set
+	nextID := types.PartitionID(1)
+	for _, spec := range specs {
+		if spec.partitionID != 0 {
+			continue
+		}
+		for usedIDs[nextID] {
+			nextID++
+		}
+		spec.partitionID = nextID
+		usedIDs[nextID] = true
+	}
+	return specs, nil
+}
+
+func (f *devnetGenerateFlags) nodeBaseFlags(node *devnetNode) *baseFlags {
+	return f.withHomeDir(filepath.Join(f.HomeDir, node.HomeDir))
+}
+
+/*
+rootNodeArgs returns the "root-node run" command line arguments of the node,
+paths are relative to the "devnetHome".
+*/
+func (c *devnetConf) rootNodeArgs(devnetHome string, node *devnetNode) []string {
+	args := []string{
+		"--address", node.Address,
+		"--rpc-server-address", node.RPCAddress,
+		"--trust-base", filepath.Join(devnetHome, c.TrustBaseFile),
+	}
+	if node != c.RootNodes[0] {
+		args = append(args, "--bootnodes", c.bootNode())
+	}
+	for _, shard := range c.Shards {
+		args = append(args, "--shard-conf", filepath.Join(devnetHome, shard.ShardConfFile))
+	}
+	return args
+}
+
+/*
+shardNodeArgs returns the "shard-node run" command line arguments of the node,
+paths are relative to the "devnetHome".
+*/
+func (c *devnetConf) shardNodeArgs(devnetHome string, shard *devnetShard, node *devnetNode) []string {
+	return []string{
+		"--address", node.Address,
+		"--rpc-server-address", node.RPCAddress,
+		"--bootnodes", c.bootNode(),
+		"--trust-base", filepath.Join(devnetHome, c.TrustBaseFile),
+		"--shard-conf", filepath.Join(devnetHome, shard.ShardConfFile),
+		"--with-get-units",
+	}
+}
+
+// bootNode returns the address of the first root node which is used as the bootstrap node
+func (c *devnetConf) bootNode() string {
+	return fmt.Sprintf("%s/p2p/%s", c.RootNodes[0].Address, c.RootNodes[0].NodeID)
+}
+
+func writeDevnetScripts(devnetHome string, conf *devnetConf) error {
+	start := &strings.Builder{}
+	start.WriteString(`#!/bin/sh
+# Starts the devnet nodes in the background, the output of the nodes is written
+# to the debug.log file in the home directory of the node. Set AB to the path of
+# the alphabill binary when it is not in the PATH.
+set -e
+AB=${AB:-alphabill}
+# relative path of the binary must be resolved before changing the directory
+case "$AB" in
+*/*) AB="$(cd "$(dirname "$AB")" && pwd)/$(basename "$AB")" ;;
+esac
+cd "$(dirname "$0")"
+`)
+	writeCmd := func(cmd string, homeDir string, args []string) {
+		fmt.Fprintf(start, "\n\"$AB\" %s --home %s %s >> %s 2>&1 &\necho $! >> %s\n",
+			cmd, homeDir, strings.Join(args, " "), filepath.Join(homeDir, "debug.log"), devnetPidFileName)
+	}
+	for _, node := range conf.RootNodes {
+		writeCmd("root-node run", node.HomeDir, conf.rootNodeArgs("", node))
+	}
+	for _, shard := range conf.Shards {
+		for _, node := range shard.Nodes {
+			writeCmd("shard-node run", node.HomeDir, conf.shardNodeArgs("", shard, node))
+		}
+	}
+	if err := os.WriteFile(filepath.Join(devnetHome, devnetStartFileName), []byte(start.String()), 0700); err != nil { // -rwx------
+		return err
+	}
+
+	stop := fmt.Sprintf(`#!/bin/sh
+# Stops the devnet nodes started by the start script.
+cd "$(dirname "$0")"
+[ -f %[1]s ] || exit 0
+kill $(cat %[1]s) 2>/dev/null || true
+rm %[1]s
+`, devnetPidFileName)
+	return os.WriteFile(filepath.Join(devnetHome, devnetStopFileName), []byte(stop), 0700) // -rwx------
+}
+
+/*
+devnetRun starts all the nodes of the devnet in the current process, the nodes
+are stopped when the context is cancelled or any of the nodes fails.
+*/
+func devnetRun(ctx context.Context, flags *devnetRunFlags) error {
+	conf, err := util.ReadJsonFile(filepath.Join(flags.HomeDir, devnetConfFileName), &devnetConf{})
+	if err != nil {
+		return fmt.Errorf("failed to load devnet configuration: %w", err)
+	}
+	if len(conf.RootNodes) == 0 {
+		return errors.New("devnet has no root nodes")
+	}
+
+	// the nodes are run using the same commands as the standalone nodes so
+	// that the defaults of the command line flags apply
+	g, ctx := errgroup.WithContext(ctx)
+	run := func(node *devnetNode, cmd *cobra.Command, args []string) {
+		cmd.SetArgs(args)
+		cmd.SilenceUsage = true
+		g.Go(func() error {
+			if err := cmd.ExecuteContext(ctx); err != nil {
+				return fmt.Errorf("node %s: %w", node.HomeDir, err)
+			}
+			return nil
+		})
+	}
+	for _, node := range conf.RootNodes {
+		run(node, rootNodeRunCmd(flags.withHomeDir(filepath.Join(flags.HomeDir, node.HomeDir))), conf.rootNodeArgs(flags.HomeDir, node))
+	}
+	for _, shard := range conf.Shards {
+		for _, node := range shard.Nodes {
+			run(node, shardNodeRunCmd(flags.withHomeDir(filepath.Join(flags.HomeDir, node.HomeDir)), nil), conf.shardNodeArgs(flags.HomeDir, shard, node))
+		}
+	}
+	flags.observe.Logger().InfoContext(ctx, fmt.Sprintf("devnet of %d root nodes and %d shards started", len(conf.RootNodes), len(conf.Shards)))
+	return g.Wait()
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill-go-base/util"
+	"github.com/alphabill-org/alphabill/internal/testutils/net"
+	"github.com/alphabill-org/alphabill/internal/testutils/observability"
+)
+
+func Test_Devnet(t *testing.T) {
+	homeDir := filepath.Join(t.TempDir(), "devnet")
+	basePort := net.GetFreeRandomPort(t)
+	obsF := observability.NewFactory(t)
+
+	out := &bytes.Buffer{}
+	cmd := New(obsF)
+	cmd.baseCmd.SetOut(out)
+	cmd.baseCmd.SetArgs([]string{"devnet", "generate", "--home", homeDir,
+		"--root-nodes", "2", "--shard", "money:1,tokens:1:5", "--base-port", fmt.Sprint(basePort)})
+	require.NoError(t, cmd.Execute(context.Background()))
+	require.Equal(t, fmt.Sprintf("generated devnet of 2 root nodes and 2 shards in %s\n", homeDir), out.String())
+
+	conf, err := util.ReadJsonFile(filepath.Join(homeDir, devnetConfFileName), &devnetConf{})
+	require.NoError(t, err)
+	require.Len(t, conf.RootNodes, 2)
+	require.Len(t, conf.Shards, 2)
+	require.EqualValues(t, 1, conf.Shards[0].PartitionID)
+	require.EqualValues(t, 5, conf.Shards[1].PartitionID)
+
+	trustBase, err := util.ReadJsonFile(filepath.Join(homeDir, trustBaseFileName), &types.RootTrustBaseV1{})
+	require.NoError(t, err)
+	require.Len(t, trustBase.Signatures, 2)
+	for _, shard := range conf.Shards {
+		shardConf, err := util.ReadJsonFile(filepath.Join(homeDir, shard.ShardConfFile), &types.PartitionDescriptionRecord{})
+		require.NoError(t, err)
+		require.Equal(t, shard.PartitionTypeID, shardConf.PartitionTypeID)
+		require.Len(t, shardConf.Validators, 1)
+		require.FileExists(t, filepath.Join(homeDir, shard.Nodes[0].HomeDir, StateFileName))
+	}
+
+	script, err := os.ReadFile(filepath.Join(homeDir, devnetStartFileName))
+	require.NoError(t, err)
+	require.Equal(t, 2, strings.Count(string(script), "root-node run"))
+	require.Equal(t, 2, strings.Count(string(script), "shard-node run"))
+	require.Contains(t, string(script), "--bootnodes "+conf.bootNode())
+
+	// generating again into the same directory must fail
+	cmd = New(obsF)
+	cmd.baseCmd.SetArgs([]string{"devnet", "generate", "--home", homeDir})
+	require.ErrorContains(t, cmd.Execute(context.Background()), "devnet already exists")
+
+	// run the devnet until the root node RPC responds
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		cmd := New(obsF)
+		cmd.baseCmd.SetArgs([]string{"devnet", "run", "--home", homeDir})
+		done <- cmd.Execute(ctx)
+	}()
+	require.Eventually(t, func() bool {
+		rsp, err := http.Get(fmt.Sprintf("http://%s/api/v1/roundInfo", conf.RootNodes[0].RPCAddress))
+		if err != nil {
+			return false
+		}
+		defer rsp.Body.Close()
+		return rsp.StatusCode == http.StatusOK
+	}, 10*time.Second, 100*time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, context.Canceled)
+	case <-time.After(10 * time.Second):
+		t.Fatal("devnet didn't stop")
+	}
+}
+
+func Test_devnetGenerateFlags_parseShardSpecs(t *testing.T) {
+	flags := &devnetGenerateFlags{baseFlags: New(observability.NewFactory(t)).baseConfig}
+
+	flags.Shards = []string{"money:3", "tokens:2:1", "orchestration:1"}
+	specs, err := flags.parseShardSpecs()
+	require.NoError(t, err)
+	require.Len(t, specs, 3)
+	require.Equal(t, "money", specs[0].partition.PartitionTypeIDString())
+	require.EqualValues(t, 2, specs[0].partitionID)
+	require.EqualValues(t, 3, specs[0].nodeCount)
+	require.EqualValues(t, 1, specs[1].partitionID)
+	require.EqualValues(t, 3, specs[2].partitionID)
+
+	flags.Shards = []string{"money"}
+	_, err = flags.parseShardSpecs()
+	require.ErrorContains(t, err, `invalid shard "money"`)
+
+	flags.Shards = []string{"foo:1"}
+	_, err = flags.parseShardSpecs()
+	require.ErrorContains(t, err, `unsupported partition type "foo"`)
+
+	flags.Shards = []string{"money:0"}
+	_, err = flags.parseShardSpecs()
+	require.ErrorContains(t, err, `invalid node count "0"`)
+
+	flags.Shards = []string{"money:1:2", "tokens:1:2"}
+	_, err = flags.parseShardSpecs()
+	require.ErrorContains(t, err, "partition id 2 is already used")
+}
+
+func Test_DevnetStartScript(t *testing.T) {
+	// the script is started from another directory using the relative path of the binary
+	workDir := t.TempDir()
+	devnetHome := filepath.Join(workDir, "devnet")
+	conf := &devnetConf{
+		TrustBaseFile: "trust-base.json",
+		RootNodes:     []*devnetNode{{HomeDir: "root1", NodeID: "16Uiu2HAm", Address: "/ip4/127.0.0.1/tcp/26662"}},
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(devnetHome, "root1"), 0700))
+	require.NoError(t, writeDevnetScripts(devnetHome, conf))
+	require.NoError(t, os.MkdirAll(filepath.Join(workDir, "build"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, "build", "alphabill"), []byte("#!/bin/sh\necho \"$@\" >> ab.out\n"), 0700))
+
+	cmd := exec.Command("sh", filepath.Join("devnet", devnetStartFileName))
+	cmd.Dir = workDir
+	cmd.Env = append(os.Environ(), "AB="+filepath.Join("build", "alphabill"))
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	require.Eventually(t, func() bool {
+		b, err := os.ReadFile(filepath.Join(devnetHome, "ab.out"))
+		return err == nil && strings.Contains(string(b), "root-node run --home root1")
+	}, 5*time.Second, 50*time.Millisecond)
+}
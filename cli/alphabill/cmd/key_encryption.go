@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/term"
+
+	"github.com/alphabill-org/alphabill-go-base/types/hex"
+	"github.com/alphabill-org/alphabill/partition"
+)
+
+const (
+	keyEncryptionVersion = 1
+	keyEncryptionKDF     = "scrypt"
+	keyEncryptionCipher  = "aes-256-gcm"
+
+	// environment variable of the key file passphrase
+	envKeyPassphrase = "AB_KEY_PASSPHRASE"
+)
+
+// scrypt parameters of the newly encrypted key files, the parameters are stored in the file
+var keyEncryptionScrypt = scryptParams{N: 1 << 17, R: 8, P: 1}
+
+type (
+	// encryptedKeyConf is the key configuration file encrypted with a passphrase
+	encryptedKeyConf struct {
+		Version    int          `json:"version"`
+		KDF        scryptParams `json:"kdf"`
+		Cipher     string       `json:"cipher"`
+		Nonce      hex.Bytes    `json:"nonce"`
+		Ciphertext hex.Bytes    `json:"ciphertext"`
+	}
+
+	scryptParams struct {
+		Name string    `json:"name"`
+		N    int       `json:"n"`
+		R    int       `json:"r"`
+		P    int       `json:"p"`
+		Salt hex.Bytes `json:"salt"`
+	}
+)
+
+/*
+encryptKeyConf encrypts the key configuration with AES-GCM, the encryption key is
+derived from the passphrase with scrypt.
+*/
+func encryptKeyConf(keyConf *partition.KeyConf, passphrase []byte) (*encryptedKeyConf, error) {
+	plaintext, err := json.Marshal(keyConf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode keys: %w", err)
+	}
+	kdf := keyEncryptionScrypt
+	kdf.Name = keyEncryptionKDF
+	kdf.Salt = make([]byte, 32)
+	if _, err := rand.Read(kdf.Salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	aead, err := kdf.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return &encryptedKeyConf{
+		Version:    keyEncryptionVersion,
+		KDF:        kdf,
+		Cipher:     keyEncryptionCipher,
+		Nonce:      nonce,
+		Ciphertext: aead.Seal(nil, nonce, plaintext, nil),
+	}, nil
+}
+
+func (e *encryptedKeyConf) decrypt(passphrase []byte) (*partition.KeyConf, error) {
+	if e.Version != keyEncryptionVersion {
+		return nil, fmt.Errorf("unsupported key file version %d", e.Version)
+	}
+	if e.KDF.Name != keyEncryptionKDF || e.Cipher != keyEncryptionCipher {
+		return nil, fmt.Errorf("unsupported key file encryption %s/%s", e.KDF.Name, e.Cipher)
+	}
+	aead, err := e.KDF.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if len(e.Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce length %d", len(e.Nonce))
+	}
+	plaintext, err := aead.Open(nil, e.Nonce, e.Ciphertext, nil)
+	if err != nil {
+		return nil, errors.New("failed to decrypt keys: invalid passphrase or corrupted key file")
+	}
+	keyConf := &partition.KeyConf{}
+	if err := json.Unmarshal(plaintext, keyConf); err != nil {
+		return nil, fmt.Errorf("failed to decode keys: %w", err)
+	}
+	return keyConf, nil
+}
+
+func (p scryptParams) aead(passphrase []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(passphrase, p.Salt, p.N, p.R, p.P, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive encryption key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+/*
+readKeyConfFile reads plaintext or encrypted key configuration, the passphrase of
+the encrypted file is requested from "passphrase".
+*/
+func readKeyConfFile(path string, passphrase func(confirm bool) ([]byte, error)) (*partition.KeyConf, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := decodeEncryptedKeyConf(data)
+	if err != nil {
+		return nil, err
+	}
+	if encrypted == nil {
+		keyConf := &partition.KeyConf{}
+		if err := json.Unmarshal(data, keyConf); err != nil {
+			return nil, err
+		}
+		return keyConf, nil
+	}
+	pass, err := passphrase(false)
+	if err != nil {
+		return nil, err
+	}
+	return encrypted.decrypt(pass)
+}
+
+// decodeEncryptedKeyConf returns nil when the data is a plaintext key configuration.
+func decodeEncryptedKeyConf(data []byte) (*encryptedKeyConf, error) {
+	encrypted := &encryptedKeyConf{}
+	if err := json.Unmarshal(data, encrypted); err != nil {
+		return nil, err
+	}
+	if encrypted.Ciphertext == nil {
+		return nil, nil
+	}
+	return encrypted, nil
+}
+
+// writeKeyConfFile writes the key configuration, readable by the owner only.
+func writeKeyConfFile(path string, keyConf any) error {
+	data, err := json.MarshalIndent(keyConf, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600) // -rw-------
+}
+
+// checkKeyFilePermissions returns error when the key file is accessible by other users.
+func checkKeyFilePermissions(path string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if perm := fi.Mode().Perm(); perm&0o007 != 0 {
+		return fmt.Errorf("key file %q is accessible by other users (mode %04o), restrict the permissions with \"chmod 600\"", path, perm)
+	}
+	return nil
+}
+
+/*
+passphrase returns the passphrase of the key file from the passphrase file, from
+the environment variable or prompts it when the standard input is a terminal.
+When "confirm" is true the prompted passphrase must be entered twice.
+*/
+func (c *keyConfFlags) passphrase(confirm bool) ([]byte, error) {
+	if c.PassphraseFile != "" {
+		data, err := os.ReadFile(filepath.Clean(c.PassphraseFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read passphrase file: %w", err)
+		}
+		return nonEmptyPassphrase([]byte(strings.TrimRight(string(data), "\r\n")))
+	}
+	if pass, ok := os.LookupEnv(envKeyPassphrase); ok {
+		return nonEmptyPassphrase([]byte(pass))
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, fmt.Errorf("key file passphrase required, use --key-passphrase-file or %s", envKeyPassphrase)
+	}
+	pass, err := promptPassphrase("Enter key file passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	if confirm {
+		again, err := promptPassphrase("Repeat key file passphrase: ")
+		if err != nil {
+			return nil, err
+		}
+		if string(pass) != string(again) {
+			return nil, errors.New("passphrases do not match")
+		}
+	}
+	return nonEmptyPassphrase(pass)
+}
+
+// encryptionRequested returns true when the generated keys must be encrypted.
+func (c *keyConfFlags) encryptionRequested() bool {
+	_, envSet := os.LookupEnv(envKeyPassphrase)
+	return c.Encrypt || c.PassphraseFile != "" || envSet
+}
+
+func promptPassphrase(prompt string) ([]byte, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	defer fmt.Fprintln(os.Stderr)
+	pass, err := term.ReadPassword(int(os.Stdin.Fd()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	return pass, nil
+}
+
+func nonEmptyPassphrase(pass []byte) ([]byte, error) {
+	if len(pass) == 0 {
+		return nil, errors.New("key file passphrase is empty")
+	}
+	return pass, nil
+}
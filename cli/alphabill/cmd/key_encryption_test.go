@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	testobserve "github.com/alphabill-org/alphabill/internal/testutils/observability"
+)
+
+// useFastScrypt lowers the scrypt cost for the duration of the test
+func useFastScrypt(t *testing.T) {
+	params := keyEncryptionScrypt
+	keyEncryptionScrypt.N = 1 << 10
+	t.Cleanup(func() { keyEncryptionScrypt = params })
+}
+
+func TestEncryptKeyConf(t *testing.T) {
+	useFastScrypt(t)
+	keyConf, err := generateKeys()
+	require.NoError(t, err)
+
+	encrypted, err := encryptKeyConf(keyConf, []byte("secret"))
+	require.NoError(t, err)
+	require.Equal(t, keyEncryptionKDF, encrypted.KDF.Name)
+	require.Equal(t, 1<<10, encrypted.KDF.N)
+	require.NotContains(t, string(encrypted.Ciphertext), string(keyConf.SigKey.PrivateKey))
+
+	decrypted, err := encrypted.decrypt([]byte("secret"))
+	require.NoError(t, err)
+	require.Equal(t, keyConf, decrypted)
+
+	_, err = encrypted.decrypt([]byte("wrong"))
+	require.EqualError(t, err, "failed to decrypt keys: invalid passphrase or corrupted key file")
+
+	encrypted.Ciphertext[0] ^= 1
+	_, err = encrypted.decrypt([]byte("secret"))
+	require.EqualError(t, err, "failed to decrypt keys: invalid passphrase or corrupted key file")
+}
+
+func TestKeyConfFlags_encryptedKeys(t *testing.T) {
+	useFastScrypt(t)
+	dir := t.TempDir()
+	passphraseFile := filepath.Join(dir, "passphrase")
+	require.NoError(t, os.WriteFile(passphraseFile, []byte("secret\n"), 0600))
+
+	flags := &keyConfFlags{KeyConfFile: filepath.Join(dir, keyConfFileName), PassphraseFile: passphraseFile}
+	keyConf, err := flags.loadKeyConf(&baseFlags{}, true)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(flags.KeyConfFile)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"ciphertext"`)
+	require.NotContains(t, string(data), "privateKey")
+
+	t.Run("passphrase from file", func(t *testing.T) {
+		loaded, err := flags.loadKeyConf(&baseFlags{}, false)
+		require.NoError(t, err)
+		require.Equal(t, keyConf, loaded)
+	})
+
+	t.Run("passphrase from environment", func(t *testing.T) {
+		t.Setenv(envKeyPassphrase, "secret")
+		loaded, err := (&keyConfFlags{KeyConfFile: flags.KeyConfFile}).loadKeyConf(&baseFlags{}, false)
+		require.NoError(t, err)
+		require.Equal(t, keyConf, loaded)
+	})
+
+	t.Run("wrong passphrase", func(t *testing.T) {
+		t.Setenv(envKeyPassphrase, "wrong")
+		_, err := (&keyConfFlags{KeyConfFile: flags.KeyConfFile}).loadKeyConf(&baseFlags{}, false)
+		require.ErrorContains(t, err, "invalid passphrase")
+	})
+
+	t.Run("empty passphrase", func(t *testing.T) {
+		t.Setenv(envKeyPassphrase, "")
+		_, err := (&keyConfFlags{KeyConfFile: flags.KeyConfFile}).loadKeyConf(&baseFlags{}, false)
+		require.ErrorContains(t, err, "key file passphrase is empty")
+	})
+}
+
+func TestKeyConfFlags_worldReadable(t *testing.T) {
+	keyConfFile := filepath.Join(t.TempDir(), keyConfFileName)
+	flags := &keyConfFlags{KeyConfFile: keyConfFile}
+	_, err := flags.loadKeyConf(&baseFlags{}, true)
+	require.NoError(t, err)
+
+	fi, err := os.Stat(keyConfFile)
+	require.NoError(t, err)
+	require.EqualValues(t, 0600, fi.Mode().Perm())
+
+	require.NoError(t, os.Chmod(keyConfFile, 0644))
+	_, err = flags.loadKeyConf(&baseFlags{}, false)
+	require.ErrorContains(t, err, "is accessible by other users (mode 0644)")
+}
+
+func TestKeysEncrypt(t *testing.T) {
+	useFastScrypt(t)
+	dir := t.TempDir()
+	keyConfFile := filepath.Join(dir, keyConfFileName)
+	keyConf, err := (&keyConfFlags{KeyConfFile: keyConfFile}).loadKeyConf(&baseFlags{}, true)
+	require.NoError(t, err)
+	t.Setenv(envKeyPassphrase, "secret")
+
+	run := func() (string, error) {
+		out := &bytes.Buffer{}
+		cmd := New(testobserve.NewFactory(t))
+		cmd.baseCmd.SetOut(out)
+		cmd.baseCmd.SetArgs([]string{"keys", "encrypt", "--key-conf", keyConfFile})
+		err := cmd.Execute(context.Background())
+		return out.String(), err
+	}
+
+	out, err := run()
+	require.NoError(t, err)
+	require.Equal(t, "encrypted key configuration "+keyConfFile+"\n", out)
+
+	loaded, err := (&keyConfFlags{KeyConfFile: keyConfFile}).loadKeyConf(&baseFlags{}, false)
+	require.NoError(t, err)
+	require.Equal(t, keyConf, loaded)
+
+	_, err = run()
+	require.ErrorContains(t, err, "is already encrypted")
+}
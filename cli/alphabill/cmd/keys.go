@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+type keysEncryptFlags struct {
+	*baseFlags
+	keyConfFlags
+}
+
+func newKeysCmd(baseFlags *baseFlags) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "keys",
+		Short: "Tools to work with node key configuration files",
+	}
+	cmd.AddCommand(keysEncryptCmd(baseFlags))
+	return cmd
+}
+
+func keysEncryptCmd(baseFlags *baseFlags) *cobra.Command {
+	flags := &keysEncryptFlags{baseFlags: baseFlags}
+	var cmd = &cobra.Command{
+		Use:   "encrypt",
+		Short: "Encrypts a plaintext key configuration file with a passphrase",
+		Long: fmt.Sprintf(`Encrypts a plaintext key configuration file with a passphrase.
+
+The passphrase is read from the --key-passphrase-file, from the %s environment
+variable or prompted. The file is replaced with the encrypted key configuration
+which is readable by the owner only.`, envKeyPassphrase),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return keysEncrypt(cmd.OutOrStdout(), flags)
+		},
+	}
+	flags.addKeyConfFlags(cmd, false)
+	return cmd
+}
+
+func keysEncrypt(out io.Writer, flags *keysEncryptFlags) error {
+	keyConfPath := flags.PathWithDefault(flags.KeyConfFile, keyConfFileName)
+	data, err := os.ReadFile(filepath.Clean(keyConfPath))
+	if err != nil {
+		return fmt.Errorf("failed to read key configuration: %w", err)
+	}
+	encrypted, err := decodeEncryptedKeyConf(data)
+	if err != nil {
+		return fmt.Errorf("failed to decode key configuration: %w", err)
+	}
+	if encrypted != nil {
+		return fmt.Errorf("key configuration %q is already encrypted", keyConfPath)
+	}
+	keyConf, err := readKeyConfFile(keyConfPath, func(bool) ([]byte, error) {
+		return nil, errors.New("unexpected encrypted key configuration")
+	})
+	if err != nil {
+		return fmt.Errorf("failed to decode key configuration: %w", err)
+	}
+	if _, err := keyConf.NodeID(); err != nil {
+		return fmt.Errorf("invalid key configuration: %w", err)
+	}
+
+	passphrase, err := flags.passphrase(true)
+	if err != nil {
+		return err
+	}
+	encrypted, err = encryptKeyConf(keyConf, passphrase)
+	if err != nil {
+		return err
+	}
+	// write to a temporary file first so the keys are not lost when writing fails
+	tmpPath := keyConfPath + ".tmp"
+	if err := writeKeyConfFile(tmpPath, encrypted); err != nil {
+		return fmt.Errorf("failed to write encrypted key configuration: %w", err)
+	}
+	if err := os.Rename(tmpPath, keyConfPath); err != nil {
+		return fmt.Errorf("failed to replace key configuration: %w", err)
+	}
+	fmt.Fprintf(out, "encrypted key configuration %s\n", keyConfPath)
+	return nil
+}
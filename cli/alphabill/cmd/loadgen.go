@@ -0,0 +1,491 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+
+	abcrypto "github.com/alphabill-org/alphabill-go-base/crypto"
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill-go-base/types/hex"
+)
+
+const (
+	loadgenOpTransfer    = "transfer"
+	loadgenOpSplit       = "split"
+	loadgenOpNFTMint     = "nft-mint"
+	loadgenOpNFTTransfer = "nft-transfer"
+)
+
+type (
+	loadgenFlags struct {
+		*baseFlags
+		MoneyRPCAddresses  []string
+		TokensRPCAddresses []string
+		MoneyShardConf     string
+		TokensShardConf    string
+		Key                string
+		BillID             string
+		Duration           time.Duration
+		TxCount            int
+		Workers            int
+		Rate               float64
+		Mix                string
+		FeeCredit          uint64
+		WorkerBillValue    uint64
+		Timeout            time.Duration
+	}
+
+	// loadgenMix is the weighted list of operations the workers pick from
+	loadgenMix struct {
+		ops     []string
+		weights []int
+		total   int
+	}
+
+	loadgen struct {
+		flags  *loadgenFlags
+		out    io.Writer
+		owner  *loadgenOwner
+		money  *loadgenClient
+		tokens *loadgenClient
+		mix    *loadgenMix
+		stats  *loadgenStats
+
+		nftTypeID types.UnitID
+		issued    atomic.Int64
+		limiter   <-chan time.Time
+	}
+
+	// loadgenWorker owns a bill and optionally an NFT, so the workers never
+	// compete for the same units.
+	loadgenWorker struct {
+		g           *loadgen
+		rnd         *rand.Rand
+		billID      types.UnitID
+		billCounter uint64
+		nftID       types.UnitID
+		nftCounter  uint64
+	}
+
+	loadgenStats struct {
+		mu        sync.Mutex
+		start     time.Time
+		end       time.Time
+		submitted int
+		confirmed map[string]int
+		latencies []time.Duration
+		failures  map[string]int
+	}
+)
+
+func newLoadgenCmd(baseFlags *baseFlags) *cobra.Command {
+	flags := &loadgenFlags{baseFlags: baseFlags}
+	var cmd = &cobra.Command{
+		Use:   "loadgen",
+		Short: "Generates transaction load on money and tokens shards and reports throughput and latency",
+		Long: `Generates transaction load on money and tokens shards and reports throughput and latency.
+
+The fee credit and the bills of the workers are created from the genesis bill (or the
+bill given with --bill-id), which must be owned by --key or by an "always true" predicate
+when the key is not set. Transactions are submitted round-robin to the given nodes with
+state_sendTransaction and the finality is tracked with state_getTransactionProof.
+
+The mix is a comma separated list of "<operation>:<weight>", supported operations are
+transfer and split (money), nft-mint and nft-transfer (tokens).`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runLoadgen(cmd.Context(), cmd.OutOrStdout(), flags)
+		},
+	}
+	cmd.Flags().StringSliceVar(&flags.MoneyRPCAddresses, "money-rpc-address", nil, "RPC addresses of the money shard nodes")
+	cmd.Flags().StringSliceVar(&flags.TokensRPCAddresses, "tokens-rpc-address", nil, "RPC addresses of the tokens shard nodes, required for token operations")
+	cmd.Flags().StringVar(&flags.MoneyShardConf, "money-shard-conf", "", "path to the shard conf of the money shard")
+	cmd.Flags().StringVar(&flags.TokensShardConf, "tokens-shard-conf", "", "path to the shard conf of the tokens shard, required for token operations")
+	cmd.Flags().StringVar(&flags.Key, "key", "", `hex encoded secp256k1 private key of the bill owner, "always true" predicate is used when not set`)
+	cmd.Flags().StringVar(&flags.BillID, "bill-id", "", "hex encoded ID of the bill to fund the load from (default is the genesis bill)")
+	cmd.Flags().DurationVar(&flags.Duration, "duration", time.Minute, "how long to generate the load")
+	cmd.Flags().IntVar(&flags.TxCount, "tx-count", 0, "stop after submitting this many transactions, unlimited when 0")
+	cmd.Flags().IntVar(&flags.Workers, "workers", 10, "number of concurrent workers, each worker waits for the finality of its transaction before sending the next one")
+	cmd.Flags().Float64Var(&flags.Rate, "rate", 0, "maximum number of transactions submitted per second, unlimited when 0")
+	cmd.Flags().StringVar(&flags.Mix, "mix", "transfer:1,split:1", "weighted mix of the operations")
+	cmd.Flags().Uint64Var(&flags.FeeCredit, "fee-credit", 100_000_000, "amount of fee credit to add to each partition")
+	cmd.Flags().Uint64Var(&flags.WorkerBillValue, "worker-bill-value", 1_000_000, "value of the bill of each worker")
+	cmd.Flags().DurationVar(&flags.Timeout, "timeout", 30*time.Second, "how long to wait for the finality of a transaction")
+	_ = cmd.MarkFlagRequired("money-rpc-address")
+	_ = cmd.MarkFlagRequired("money-shard-conf")
+	return cmd
+}
+
+func runLoadgen(ctx context.Context, out io.Writer, flags *loadgenFlags) error {
+	if flags.Workers < 1 {
+		return errors.New("number of workers must be at least 1")
+	}
+	mix, err := parseLoadgenMix(flags.Mix)
+	if err != nil {
+		return fmt.Errorf("invalid mix: %w", err)
+	}
+	if mix.hasTokenOps() && (len(flags.TokensRPCAddresses) == 0 || flags.TokensShardConf == "") {
+		return errors.New("token operations require --tokens-rpc-address and --tokens-shard-conf")
+	}
+	owner, err := newLoadgenOwner(flags.Key)
+	if err != nil {
+		return err
+	}
+
+	g := &loadgen{flags: flags, out: out, owner: owner, mix: mix, stats: newLoadgenStats()}
+	if g.money, err = dialLoadgenClient(ctx, flags.MoneyRPCAddresses, flags.MoneyShardConf); err != nil {
+		return fmt.Errorf("money shard: %w", err)
+	}
+	defer g.money.close()
+	if mix.hasTokenOps() {
+		if g.tokens, err = dialLoadgenClient(ctx, flags.TokensRPCAddresses, flags.TokensShardConf); err != nil {
+			return fmt.Errorf("tokens shard: %w", err)
+		}
+		defer g.tokens.close()
+	}
+
+	workers, err := g.setup(ctx)
+	if err != nil {
+		return fmt.Errorf("setup failed: %w", err)
+	}
+	g.run(ctx, workers)
+	g.stats.report(out)
+	return nil
+}
+
+/*
+setup creates the fee credit records, splits a bill for every worker and defines
+the NFT type when the mix contains token operations.
+*/
+func (g *loadgen) setup(ctx context.Context) ([]*loadgenWorker, error) {
+	billID := types.UnitID(moneyPartitionInitialBillID)
+	if g.flags.BillID != "" {
+		var err error
+		if billID, err = hex.Decode([]byte(g.flags.BillID)); err != nil {
+			return nil, fmt.Errorf("invalid bill ID: %w", err)
+		}
+	}
+	bill, err := g.money.getBill(ctx, billID)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(g.out, "funding bill %s, value %d\n", billID, bill.Value)
+
+	for _, c := range []*loadgenClient{g.money, g.tokens} {
+		if c == nil {
+			continue
+		}
+		if c.feeless {
+			fmt.Fprintf(g.out, "partition %s is in feeless mode\n", c.shardConf.PartitionID)
+			continue
+		}
+		if c.permissioned {
+			return nil, fmt.Errorf("partition %s is in permissioned mode, fee credit must be set by the admin", c.shardConf.PartitionID)
+		}
+		if bill.Counter, err = g.addFeeCredit(ctx, c, billID, bill.Counter); err != nil {
+			return nil, fmt.Errorf("adding fee credit to partition %s: %w", c.shardConf.PartitionID, err)
+		}
+		fmt.Fprintf(g.out, "added %d fee credit to partition %s, fee credit record %s\n", g.flags.FeeCredit, c.shardConf.PartitionID, c.fcrID)
+	}
+
+	billIDs, err := g.splitBill(ctx, billID, bill.Counter)
+	if err != nil {
+		return nil, fmt.Errorf("creating worker bills: %w", err)
+	}
+	fmt.Fprintf(g.out, "created %d worker bills of value %d\n", len(billIDs), g.flags.WorkerBillValue)
+
+	if g.mix.hasTokenOps() {
+		if g.nftTypeID, err = g.defineNFTType(ctx); err != nil {
+			return nil, fmt.Errorf("defining NFT type: %w", err)
+		}
+		fmt.Fprintf(g.out, "defined NFT type %s\n", g.nftTypeID)
+	}
+
+	workers := make([]*loadgenWorker, len(billIDs))
+	for i, id := range billIDs {
+		workers[i] = &loadgenWorker{g: g, rnd: rand.New(rand.NewPCG(uint64(time.Now().UnixNano()), uint64(i))), billID: id}
+	}
+	return workers, nil
+}
+
+// run starts the workers and waits until the duration has passed or the tx count has been reached.
+func (g *loadgen) run(ctx context.Context, workers []*loadgenWorker) {
+	runCtx, cancel := context.WithTimeout(ctx, g.flags.Duration)
+	defer cancel()
+	if g.flags.Rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / g.flags.Rate))
+		defer ticker.Stop()
+		g.limiter = ticker.C
+	}
+
+	fmt.Fprintf(g.out, "running %d workers for %s\n", len(workers), g.flags.Duration)
+	g.stats.begin()
+	var eg errgroup.Group
+	for _, w := range workers {
+		eg.Go(func() error {
+			w.run(ctx, runCtx)
+			return nil
+		})
+	}
+	_ = eg.Wait()
+	g.stats.finish()
+}
+
+/*
+next blocks until the next transaction is allowed to be sent, returns false when
+the load generation must stop.
+*/
+func (g *loadgen) next(ctx context.Context) bool {
+	if g.flags.TxCount > 0 && g.issued.Add(1) > int64(g.flags.TxCount) {
+		return false
+	}
+	if g.limiter == nil {
+		return ctx.Err() == nil
+	}
+	select {
+	case <-g.limiter:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+/*
+run sends transactions until "runCtx" is done, "ctx" is used to wait for the
+finality of the last transaction after the load generation has ended.
+*/
+func (w *loadgenWorker) run(ctx, runCtx context.Context) {
+	for w.g.next(runCtx) {
+		op := w.g.mix.pick(w.rnd)
+		if op == loadgenOpNFTTransfer && w.nftID == nil {
+			op = loadgenOpNFTMint
+		}
+		start := time.Now()
+		err := w.execute(ctx, op)
+		w.g.stats.record(op, time.Since(start), err)
+		if err != nil && ctx.Err() == nil {
+			// the counters might be out of sync after failure
+			if err := w.sync(ctx); err != nil {
+				w.g.stats.record(op, 0, fmt.Errorf("sync failed: %w", err))
+				return
+			}
+		}
+	}
+}
+
+func (w *loadgenWorker) execute(ctx context.Context, op string) error {
+	switch op {
+	case loadgenOpTransfer:
+		if err := w.g.transferBill(ctx, w.billID, w.billCounter); err != nil {
+			return err
+		}
+		w.billCounter++
+	case loadgenOpSplit:
+		if err := w.g.splitOne(ctx, w.billID, w.billCounter); err != nil {
+			return err
+		}
+		w.billCounter++
+	case loadgenOpNFTMint:
+		id, err := w.g.mintNFT(ctx)
+		if err != nil {
+			return err
+		}
+		w.nftID, w.nftCounter = id, 0
+	case loadgenOpNFTTransfer:
+		if err := w.g.transferNFT(ctx, w.nftID, w.nftCounter); err != nil {
+			return err
+		}
+		w.nftCounter++
+	default:
+		return fmt.Errorf("unknown operation %q", op)
+	}
+	return nil
+}
+
+// sync reloads the counters of the units owned by the worker.
+func (w *loadgenWorker) sync(ctx context.Context) error {
+	bill, err := w.g.money.getBill(ctx, w.billID)
+	if err != nil {
+		return err
+	}
+	w.billCounter = bill.Counter
+	if w.nftID != nil {
+		nft, err := w.g.tokens.getNFT(ctx, w.nftID)
+		if err != nil {
+			return err
+		}
+		w.nftCounter = nft.Counter
+	}
+	return nil
+}
+
+/*
+parseLoadgenMix parses the mix in the form "<operation>:<weight>,...", the weight
+is optional and defaults to 1.
+*/
+func parseLoadgenMix(s string) (*loadgenMix, error) {
+	mix := &loadgenMix{}
+	for _, item := range strings.Split(s, ",") {
+		op, weightStr, found := strings.Cut(strings.TrimSpace(item), ":")
+		weight := 1
+		if found {
+			var err error
+			if weight, err = strconv.Atoi(weightStr); err != nil || weight < 0 {
+				return nil, fmt.Errorf("invalid weight %q of operation %q", weightStr, op)
+			}
+		}
+		switch op {
+		case loadgenOpTransfer, loadgenOpSplit, loadgenOpNFTMint, loadgenOpNFTTransfer:
+		default:
+			return nil, fmt.Errorf("unknown operation %q", op)
+		}
+		if slices.Contains(mix.ops, op) {
+			return nil, fmt.Errorf("duplicate operation %q", op)
+		}
+		if weight == 0 {
+			continue
+		}
+		mix.ops = append(mix.ops, op)
+		mix.weights = append(mix.weights, weight)
+		mix.total += weight
+	}
+	if mix.total == 0 {
+		return nil, errors.New("no operations")
+	}
+	return mix, nil
+}
+
+func (m *loadgenMix) pick(rnd *rand.Rand) string {
+	n := rnd.IntN(m.total)
+	for i, w := range m.weights {
+		if n < w {
+			return m.ops[i]
+		}
+		n -= w
+	}
+	return m.ops[len(m.ops)-1]
+}
+
+func (m *loadgenMix) hasTokenOps() bool {
+	return slices.Contains(m.ops, loadgenOpNFTMint) || slices.Contains(m.ops, loadgenOpNFTTransfer)
+}
+
+func newLoadgenStats() *loadgenStats {
+	return &loadgenStats{
+		confirmed: make(map[string]int),
+		failures:  make(map[string]int),
+	}
+}
+
+func (s *loadgenStats) begin() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.start = time.Now()
+}
+
+func (s *loadgenStats) finish() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.end = time.Now()
+}
+
+func (s *loadgenStats) record(op string, latency time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.submitted++
+	if err != nil {
+		s.failures[err.Error()]++
+		return
+	}
+	s.confirmed[op]++
+	s.latencies = append(s.latencies, latency)
+}
+
+func (s *loadgenStats) report(out io.Writer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elapsed := s.end.Sub(s.start)
+	confirmed := len(s.latencies)
+	fmt.Fprintf(out, "\ntransactions: %d submitted, %d confirmed, %d failed\n", s.submitted, confirmed, s.submitted-confirmed)
+	for _, op := range slices.Sorted(maps.Keys(s.confirmed)) {
+		fmt.Fprintf(out, "  %s: %d\n", op, s.confirmed[op])
+	}
+	if elapsed > 0 {
+		fmt.Fprintf(out, "throughput: %.2f tx/s over %s\n", float64(confirmed)/elapsed.Seconds(), elapsed.Round(time.Millisecond))
+	}
+	if confirmed > 0 {
+		latencies := slices.Clone(s.latencies)
+		slices.Sort(latencies)
+		fmt.Fprintf(out, "latency: min %s, p50 %s, p90 %s, p99 %s, max %s\n",
+			latencies[0].Round(time.Millisecond),
+			percentile(latencies, 50).Round(time.Millisecond),
+			percentile(latencies, 90).Round(time.Millisecond),
+			percentile(latencies, 99).Round(time.Millisecond),
+			latencies[len(latencies)-1].Round(time.Millisecond))
+	}
+	if len(s.failures) > 0 {
+		fmt.Fprintln(out, "failures:")
+		reasons := slices.Collect(maps.Keys(s.failures))
+		sort.Slice(reasons, func(i, j int) bool {
+			if s.failures[reasons[i]] != s.failures[reasons[j]] {
+				return s.failures[reasons[i]] > s.failures[reasons[j]]
+			}
+			return reasons[i] < reasons[j]
+		})
+		for _, reason := range reasons {
+			fmt.Fprintf(out, "  %d x %s\n", s.failures[reason], reason)
+		}
+	}
+}
+
+// percentile returns the p-th percentile of the sorted durations using the nearest-rank method.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
+
+// loadgenOwner creates the owner predicates and proofs of the units created by the load generator.
+type loadgenOwner struct {
+	signer abcrypto.Signer
+	pubKey []byte
+}
+
+func newLoadgenOwner(key string) (*loadgenOwner, error) {
+	if key == "" {
+		return &loadgenOwner{}, nil
+	}
+	privKey, err := hex.Decode([]byte(key))
+	if err != nil {
+		return nil, fmt.Errorf("invalid key: %w", err)
+	}
+	signer, err := abcrypto.NewInMemorySecp256K1SignerFromKey(privKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key: %w", err)
+	}
+	verifier, err := signer.Verifier()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create verifier: %w", err)
+	}
+	pubKey, err := verifier.MarshalPublicKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal public key: %w", err)
+	}
+	return &loadgenOwner{signer: signer, pubKey: pubKey}, nil
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/rand/v2"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	testobserve "github.com/alphabill-org/alphabill/internal/testutils/observability"
+)
+
+func Test_parseLoadgenMix(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		mix, err := parseLoadgenMix("transfer:3, split, nft-mint:0,nft-transfer:2")
+		require.NoError(t, err)
+		require.Equal(t, []string{loadgenOpTransfer, loadgenOpSplit, loadgenOpNFTTransfer}, mix.ops)
+		require.Equal(t, []int{3, 1, 2}, mix.weights)
+		require.Equal(t, 6, mix.total)
+		require.True(t, mix.hasTokenOps())
+
+		mix, err = parseLoadgenMix("transfer")
+		require.NoError(t, err)
+		require.False(t, mix.hasTokenOps())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for mix, errMsg := range map[string]string{
+			"":                      `unknown operation ""`,
+			"burn:1":                `unknown operation "burn"`,
+			"transfer:x":            `invalid weight "x" of operation "transfer"`,
+			"transfer:-1":           `invalid weight "-1" of operation "transfer"`,
+			"transfer:1,transfer":   `duplicate operation "transfer"`,
+			"transfer:0,nft-mint:0": "no operations",
+		} {
+			_, err := parseLoadgenMix(mix)
+			require.EqualError(t, err, errMsg, "mix %q", mix)
+		}
+	})
+
+	t.Run("pick", func(t *testing.T) {
+		mix, err := parseLoadgenMix("transfer:3,split:1")
+		require.NoError(t, err)
+		rnd := rand.New(rand.NewPCG(1, 2))
+		counts := map[string]int{}
+		for range 4000 {
+			counts[mix.pick(rnd)]++
+		}
+		require.Len(t, counts, 2)
+		require.InDelta(t, 3000, counts[loadgenOpTransfer], 200)
+		require.InDelta(t, 1000, counts[loadgenOpSplit], 200)
+	})
+}
+
+func Test_percentile(t *testing.T) {
+	require.Zero(t, percentile(nil, 50))
+
+	var sorted []time.Duration
+	for i := range 100 {
+		sorted = append(sorted, time.Duration(i+1)*time.Millisecond)
+	}
+	require.Equal(t, time.Millisecond, percentile(sorted, 0))
+	require.Equal(t, 50*time.Millisecond, percentile(sorted, 50))
+	require.Equal(t, 99*time.Millisecond, percentile(sorted, 99))
+	require.Equal(t, 100*time.Millisecond, percentile(sorted, 100))
+	require.Equal(t, 5*time.Millisecond, percentile([]time.Duration{5 * time.Millisecond}, 90))
+}
+
+func Test_loadgenStats_report(t *testing.T) {
+	stats := newLoadgenStats()
+	stats.start = time.Unix(0, 0)
+	stats.end = time.Unix(2, 0)
+	stats.record(loadgenOpTransfer, 100*time.Millisecond, nil)
+	stats.record(loadgenOpTransfer, 300*time.Millisecond, nil)
+	stats.record(loadgenOpSplit, 200*time.Millisecond, nil)
+	stats.record(loadgenOpSplit, 0, errors.New("timeout waiting for the transaction proof"))
+	stats.record(loadgenOpTransfer, 0, errors.New("send: invalid counter"))
+	stats.record(loadgenOpTransfer, 0, errors.New("send: invalid counter"))
+
+	out := &bytes.Buffer{}
+	stats.report(out)
+	require.Equal(t, `
+transactions: 6 submitted, 3 confirmed, 3 failed
+  split: 1
+  transfer: 2
+throughput: 1.50 tx/s over 2s
+latency: min 100ms, p50 200ms, p90 300ms, p99 300ms, max 300ms
+failures:
+  2 x send: invalid counter
+  1 x timeout waiting for the transaction proof
+`, out.String())
+}
+
+func Test_loadgenCmd_invalidFlags(t *testing.T) {
+	homeDir := writeShardConf(t, defaultMoneyShardConf)
+	run := func(args ...string) error {
+		cmd := New(testobserve.NewFactory(t))
+		cmd.baseCmd.SetOut(&bytes.Buffer{})
+		cmd.baseCmd.SetArgs(append([]string{"loadgen", "--home", homeDir, "--money-rpc-address", "localhost:1", "--money-shard-conf", filepath.Join(homeDir, shardConfFileName)}, args...))
+		return cmd.Execute(context.Background())
+	}
+
+	require.EqualError(t, run("--workers", "0"), "number of workers must be at least 1")
+	require.EqualError(t, run("--mix", "transfer:0"), "invalid mix: no operations")
+	require.EqualError(t, run("--mix", "nft-mint"), "token operations require --tokens-rpc-address and --tokens-shard-conf")
+	require.ErrorContains(t, run("--key", "zz"), "invalid key")
+}
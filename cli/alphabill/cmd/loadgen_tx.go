@@ -0,0 +1,454 @@
+package cmd
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethrpc "github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/alphabill-org/alphabill-go-base/cbor"
+	"github.com/alphabill-org/alphabill-go-base/predicates/templates"
+	fcsdk "github.com/alphabill-org/alphabill-go-base/txsystem/fc"
+	"github.com/alphabill-org/alphabill-go-base/txsystem/money"
+	tokenssdk "github.com/alphabill-org/alphabill-go-base/txsystem/tokens"
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill-go-base/types/hex"
+	"github.com/alphabill-org/alphabill-go-base/util"
+
+	"github.com/alphabill-org/alphabill/partition"
+	"github.com/alphabill-org/alphabill/rpc"
+)
+
+const (
+	// max fee of the ordinary transactions
+	loadgenMaxFee = 10
+	// transaction timeout in rounds from the current round
+	loadgenTxTimeout = 20
+	// how many rounds the target partition has to execute the addFC transaction
+	loadgenFeeCreditTimeout = 100
+	// interval of polling the transaction proof
+	loadgenPollInterval = 100 * time.Millisecond
+	// how long the round number used for the transaction timeouts is cached
+	loadgenRoundCacheTTL = time.Second
+)
+
+// loadgenClient sends transactions to the nodes of one partition.
+type loadgenClient struct {
+	shardConf    *types.PartitionDescriptionRecord
+	nodes        []*ethrpc.Client
+	next         atomic.Uint64
+	feeless      bool
+	permissioned bool
+	fcrID        types.UnitID // fee credit record of the load generator, nil in feeless mode
+
+	mu          sync.Mutex
+	round       uint64
+	roundLoaded time.Time
+}
+
+/*
+dialLoadgenClient connects to the nodes and verifies that they belong to the
+partition of the shard conf.
+*/
+func dialLoadgenClient(ctx context.Context, addrs []string, shardConfFile string) (*loadgenClient, error) {
+	shardConf, err := util.ReadJsonFile(shardConfFile, &types.PartitionDescriptionRecord{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shard conf: %w", err)
+	}
+	c := &loadgenClient{shardConf: shardConf}
+	for _, addr := range addrs {
+		node, err := ethrpc.DialContext(ctx, buildRpcUrl(addr))
+		if err != nil {
+			c.close()
+			return nil, fmt.Errorf("failed to dial %s: %w", addr, err)
+		}
+		c.nodes = append(c.nodes, node)
+
+		var info rpc.NodeInfoResponse
+		if err := node.CallContext(ctx, &info, "admin_getNodeInfo"); err != nil {
+			c.close()
+			return nil, fmt.Errorf("failed to get node info from %s: %w", addr, err)
+		}
+		if info.NetworkID != shardConf.NetworkID || info.PartitionID != shardConf.PartitionID {
+			c.close()
+			return nil, fmt.Errorf("node %s belongs to network %d partition %s, expected network %d partition %s",
+				addr, info.NetworkID, info.PartitionID, shardConf.NetworkID, shardConf.PartitionID)
+		}
+		c.feeless = info.FeelessMode
+		c.permissioned = info.PermissionedMode
+	}
+	return c, nil
+}
+
+func (c *loadgenClient) close() {
+	for _, node := range c.nodes {
+		node.Close()
+	}
+}
+
+// node returns the next node in round-robin order.
+func (c *loadgenClient) node() *ethrpc.Client {
+	return c.nodes[c.next.Add(1)%uint64(len(c.nodes))]
+}
+
+func (c *loadgenClient) roundNumber(ctx context.Context) (uint64, error) {
+	var info partition.RoundInfo
+	if err := c.node().CallContext(ctx, &info, "state_getRoundInfo"); err != nil {
+		return 0, fmt.Errorf("failed to get round info: %w", err)
+	}
+	return info.RoundNumber, nil
+}
+
+// cachedRoundNumber returns the round number for the transaction timeouts, the workers share the cached value.
+func (c *loadgenClient) cachedRoundNumber(ctx context.Context) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time.Since(c.roundLoaded) < loadgenRoundCacheTTL {
+		return c.round, nil
+	}
+	round, err := c.roundNumber(ctx)
+	if err != nil {
+		return 0, err
+	}
+	c.round, c.roundLoaded = round, time.Now()
+	return round, nil
+}
+
+func (c *loadgenClient) getUnit(ctx context.Context, unitID types.UnitID, data any) error {
+	var unit *rpc.Unit[json.RawMessage]
+	if err := c.node().CallContext(ctx, &unit, "state_getUnit", hex.Bytes(unitID), false); err != nil {
+		return fmt.Errorf("failed to get unit %s: %w", unitID, err)
+	}
+	if unit == nil {
+		return fmt.Errorf("unit %s not found", unitID)
+	}
+	if err := json.Unmarshal(unit.Data, data); err != nil {
+		return fmt.Errorf("failed to decode unit %s data: %w", unitID, err)
+	}
+	return nil
+}
+
+func (c *loadgenClient) getBill(ctx context.Context, unitID types.UnitID) (*money.BillData, error) {
+	bill := &money.BillData{}
+	return bill, c.getUnit(ctx, unitID, bill)
+}
+
+func (c *loadgenClient) getNFT(ctx context.Context, unitID types.UnitID) (*tokenssdk.NonFungibleTokenData, error) {
+	nft := &tokenssdk.NonFungibleTokenData{}
+	return nft, c.getUnit(ctx, unitID, nft)
+}
+
+// newTx creates an unsigned transaction, fee credit record is set unless it is a fee credit transaction.
+func (c *loadgenClient) newTx(ctx context.Context, unitID types.UnitID, txType uint16, attr any, feeTx bool) (*types.TransactionOrder, error) {
+	round, err := c.cachedRoundNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tx := &types.TransactionOrder{
+		Version: 1,
+		Payload: types.Payload{
+			NetworkID:      c.shardConf.NetworkID,
+			PartitionID:    c.shardConf.PartitionID,
+			UnitID:         unitID,
+			Type:           txType,
+			ClientMetadata: &types.ClientMetadata{Timeout: round + loadgenTxTimeout, MaxTransactionFee: loadgenMaxFee},
+		},
+	}
+	if !feeTx {
+		tx.ClientMetadata.FeeCreditRecordID = c.fcrID
+	}
+	if err := tx.SetAttributes(attr); err != nil {
+		return nil, fmt.Errorf("failed to encode attributes: %w", err)
+	}
+	return tx, nil
+}
+
+/*
+submit sends the transaction and waits until its proof is available or the
+timeout expires. Returns error when the transaction was not executed successfully.
+*/
+func (c *loadgenClient) submit(ctx context.Context, tx *types.TransactionOrder, timeout time.Duration) (*types.TxRecordProof, error) {
+	txBytes, err := cbor.Marshal(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode transaction: %w", err)
+	}
+	txHash, err := tx.Hash(crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate transaction hash: %w", err)
+	}
+	var res hex.Bytes
+	if err := c.node().CallContext(ctx, &res, "state_sendTransaction", hexutil.Encode(txBytes)); err != nil {
+		return nil, fmt.Errorf("send: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(loadgenPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, errors.New("timeout waiting for the transaction proof")
+		case <-ticker.C:
+		}
+		var proofRes *rpc.TransactionRecordAndProof
+		if err := c.node().CallContext(ctx, &proofRes, "state_getTransactionProof", hex.Bytes(txHash)); err != nil {
+			if ctx.Err() != nil {
+				continue
+			}
+			return nil, fmt.Errorf("get proof: %w", err)
+		}
+		if proofRes == nil {
+			continue
+		}
+		proof := &types.TxRecordProof{}
+		if err := cbor.Unmarshal(proofRes.TxRecordProof, proof); err != nil {
+			return nil, fmt.Errorf("failed to decode transaction proof: %w", err)
+		}
+		if sm := proof.TxRecord.ServerMetadata; sm.SuccessIndicator != types.TxStatusSuccessful {
+			return nil, fmt.Errorf("transaction failed: %w", sm.ErrDetail())
+		}
+		return proof, nil
+	}
+}
+
+func (o *loadgenOwner) predicate() []byte {
+	if o.signer == nil {
+		return templates.AlwaysTrueBytes()
+	}
+	return templates.NewP2pkh256BytesFromKey(o.pubKey)
+}
+
+// proof signs the bytes returned by "sigBytes", empty argument is returned for the "always true" predicate.
+func (o *loadgenOwner) proof(sigBytes func() ([]byte, error)) ([]byte, error) {
+	if o.signer == nil {
+		return templates.EmptyArgument(), nil
+	}
+	b, err := sigBytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signature bytes: %w", err)
+	}
+	sig, err := o.signer.SignBytes(b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	return templates.NewP2pkh256SignatureBytes(sig, o.pubKey), nil
+}
+
+// sign sets the auth proof created by "authProof" from the owner proof and the fee proof of ordinary transactions.
+func (g *loadgen) sign(tx *types.TransactionOrder, authProof func(ownerProof []byte) any) error {
+	ownerProof, err := g.owner.proof(tx.AuthProofSigBytes)
+	if err != nil {
+		return err
+	}
+	if err := tx.SetAuthProof(authProof(ownerProof)); err != nil {
+		return fmt.Errorf("failed to set auth proof: %w", err)
+	}
+	if tx.FeeCreditRecordID() != nil {
+		if tx.FeeProof, err = g.owner.proof(tx.FeeProofSigBytes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+addFeeCredit transfers fee credit from the bill to the partition of "c" and adds
+it to the fee credit record of the load generator. Returns the new counter of the bill.
+*/
+func (g *loadgen) addFeeCredit(ctx context.Context, c *loadgenClient, billID types.UnitID, counter uint64) (uint64, error) {
+	round, err := c.roundNumber(ctx)
+	if err != nil {
+		return 0, err
+	}
+	latestAdditionTime := round + loadgenFeeCreditTimeout
+	fcrUnitType := uint32(money.FeeCreditRecordUnitType)
+	if c.shardConf.PartitionTypeID == tokenssdk.PartitionTypeID {
+		fcrUnitType = uint32(tokenssdk.FeeCreditRecordUnitType)
+	}
+	fcrID, err := c.shardConf.ComposeUnitID(types.ShardID{}, fcrUnitType, fcsdk.PrndSh(g.owner.predicate(), latestAdditionTime))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create fee credit record ID: %w", err)
+	}
+
+	transferTx, err := g.money.newTx(ctx, billID, fcsdk.TransactionTypeTransferFeeCredit, &fcsdk.TransferFeeCreditAttributes{
+		Amount:             g.flags.FeeCredit,
+		TargetPartitionID:  c.shardConf.PartitionID,
+		TargetRecordID:     fcrID,
+		LatestAdditionTime: latestAdditionTime,
+		Counter:            counter,
+	}, true)
+	if err != nil {
+		return 0, err
+	}
+	if err := g.sign(transferTx, func(p []byte) any { return &fcsdk.TransferFeeCreditAuthProof{OwnerProof: p} }); err != nil {
+		return 0, err
+	}
+	transferProof, err := g.money.submit(ctx, transferTx, g.flags.Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("transferFC: %w", err)
+	}
+
+	addTx, err := c.newTx(ctx, fcrID, fcsdk.TransactionTypeAddFeeCredit, &fcsdk.AddFeeCreditAttributes{
+		FeeCreditOwnerPredicate: g.owner.predicate(),
+		FeeCreditTransferProof:  transferProof,
+	}, true)
+	if err != nil {
+		return 0, err
+	}
+	if err := g.sign(addTx, func(p []byte) any { return &fcsdk.AddFeeCreditAuthProof{OwnerProof: p} }); err != nil {
+		return 0, err
+	}
+	if _, err := c.submit(ctx, addTx, g.flags.Timeout); err != nil {
+		return 0, fmt.Errorf("addFC: %w", err)
+	}
+	c.fcrID = fcrID
+	return counter + 1, nil
+}
+
+// splitBill splits a bill for every worker from the bill, returns the IDs of the new bills.
+func (g *loadgen) splitBill(ctx context.Context, billID types.UnitID, counter uint64) ([]types.UnitID, error) {
+	attr := &money.SplitAttributes{Counter: counter}
+	for range g.flags.Workers {
+		attr.TargetUnits = append(attr.TargetUnits, &money.TargetUnit{Amount: g.flags.WorkerBillValue, OwnerPredicate: g.owner.predicate()})
+	}
+	tx, err := g.money.newTx(ctx, billID, money.TransactionTypeSplit, attr, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.sign(tx, func(p []byte) any { return &money.SplitAuthProof{OwnerProof: p} }); err != nil {
+		return nil, err
+	}
+	proof, err := g.money.submit(ctx, tx, g.flags.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	// the first target unit is the split bill, followed by the new bills
+	targetUnits := proof.TxRecord.ServerMetadata.TargetUnits
+	if len(targetUnits) < g.flags.Workers+1 {
+		return nil, fmt.Errorf("expected %d target units, got %d", g.flags.Workers+1, len(targetUnits))
+	}
+	return targetUnits[1 : g.flags.Workers+1], nil
+}
+
+// transferBill transfers the bill to its current owner.
+func (g *loadgen) transferBill(ctx context.Context, billID types.UnitID, counter uint64) error {
+	tx, err := g.money.newTx(ctx, billID, money.TransactionTypeTransfer, &money.TransferAttributes{
+		NewOwnerPredicate: g.owner.predicate(),
+		TargetValue:       g.flags.WorkerBillValue,
+		Counter:           counter,
+	}, false)
+	if err != nil {
+		return err
+	}
+	if err := g.sign(tx, func(p []byte) any { return &money.TransferAuthProof{OwnerProof: p} }); err != nil {
+		return err
+	}
+	_, err = g.money.submit(ctx, tx, g.flags.Timeout)
+	return err
+}
+
+// splitOne splits a bill of value 1 from the bill.
+func (g *loadgen) splitOne(ctx context.Context, billID types.UnitID, counter uint64) error {
+	tx, err := g.money.newTx(ctx, billID, money.TransactionTypeSplit, &money.SplitAttributes{
+		TargetUnits: []*money.TargetUnit{{Amount: 1, OwnerPredicate: g.owner.predicate()}},
+		Counter:     counter,
+	}, false)
+	if err != nil {
+		return err
+	}
+	if err := g.sign(tx, func(p []byte) any { return &money.SplitAuthProof{OwnerProof: p} }); err != nil {
+		return err
+	}
+	_, err = g.money.submit(ctx, tx, g.flags.Timeout)
+	return err
+}
+
+// defineNFTType defines an NFT type with "always true" predicates so anyone can mint and transfer the tokens.
+func (g *loadgen) defineNFTType(ctx context.Context) (types.UnitID, error) {
+	tx, err := g.tokens.newTx(ctx, nil, tokenssdk.TransactionTypeDefineNFT, &tokenssdk.DefineNonFungibleTokenAttributes{
+		Symbol:                   "LOADGEN",
+		Name:                     "load generator tokens",
+		SubTypeCreationPredicate: templates.AlwaysTrueBytes(),
+		TokenMintingPredicate:    templates.AlwaysTrueBytes(),
+		TokenTypeOwnerPredicate:  templates.AlwaysTrueBytes(),
+		DataUpdatePredicate:      templates.AlwaysTrueBytes(),
+	}, false)
+	if err != nil {
+		return nil, err
+	}
+	if tx.UnitID, err = g.tokens.shardConf.ComposeUnitID(types.ShardID{}, tokenssdk.NonFungibleTokenTypeUnitType, tokenssdk.PrndSh(tx)); err != nil {
+		return nil, fmt.Errorf("failed to create NFT type ID: %w", err)
+	}
+	if err := g.sign(tx, func([]byte) any { return &tokenssdk.DefineNonFungibleTokenAuthProof{} }); err != nil {
+		return nil, err
+	}
+	if _, err := g.tokens.submit(ctx, tx, g.flags.Timeout); err != nil {
+		return nil, err
+	}
+	return tx.UnitID, nil
+}
+
+// mintNFT mints a new token of the load generator NFT type, returns the ID of the token.
+func (g *loadgen) mintNFT(ctx context.Context) (types.UnitID, error) {
+	tx, err := g.tokens.newTx(ctx, nil, tokenssdk.TransactionTypeMintNFT, &tokenssdk.MintNonFungibleTokenAttributes{
+		OwnerPredicate:      g.owner.predicate(),
+		TypeID:              g.nftTypeID,
+		Name:                "loadgen",
+		Data:                []byte{1},
+		DataUpdatePredicate: templates.AlwaysTrueBytes(),
+	}, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := tokenssdk.GenerateUnitID(tx, g.tokens.shardConf); err != nil {
+		return nil, fmt.Errorf("failed to generate token ID: %w", err)
+	}
+	if err := g.sign(tx, func([]byte) any {
+		return &tokenssdk.MintNonFungibleTokenAuthProof{TokenMintingProof: templates.EmptyArgument()}
+	}); err != nil {
+		return nil, err
+	}
+	if _, err := g.tokens.submit(ctx, tx, g.flags.Timeout); err != nil {
+		return nil, err
+	}
+	return tx.UnitID, nil
+}
+
+// transferNFT transfers the token to its current owner.
+func (g *loadgen) transferNFT(ctx context.Context, nftID types.UnitID, counter uint64) error {
+	tx, err := g.tokens.newTx(ctx, nftID, tokenssdk.TransactionTypeTransferNFT, &tokenssdk.TransferNonFungibleTokenAttributes{
+		NewOwnerPredicate: g.owner.predicate(),
+		Counter:           counter,
+		TypeID:            g.nftTypeID,
+	}, false)
+	if err != nil {
+		return err
+	}
+	if err := g.sign(tx, func(p []byte) any {
+		return &tokenssdk.TransferNonFungibleTokenAuthProof{OwnerProof: p, TokenTypeOwnerProofs: [][]byte{templates.EmptyArgument()}}
+	}); err != nil {
+		return err
+	}
+	_, err = g.tokens.submit(ctx, tx, g.flags.Timeout)
+	return err
+}
+
+func buildRpcUrl(url string) string {
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		url = "http://" + url
+	}
+	url = strings.TrimSuffix(url, "/")
+	if !strings.HasSuffix(url, "/rpc") {
+		url = url + "/rpc"
+	}
+	return url
+}
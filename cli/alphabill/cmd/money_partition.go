@@ -12,6 +12,7 @@ import (
 	"github.com/alphabill-org/alphabill/state"
 	"github.com/alphabill-org/alphabill/txsystem"
 	"github.com/alphabill-org/alphabill/txsystem/money"
+	txtypes "github.com/alphabill-org/alphabill/txsystem/types"
 )
 
 type (
@@ -52,11 +53,19 @@ func (p *MoneyPartition) NewGenesisState(pdr *types.PartitionDescriptionRecord)
 	return newMoneyGenesisState(pdr)
 }
 
+func (p *MoneyPartition) UnitDataConstructor(pdr *types.PartitionDescriptionRecord) state.UnitDataConstructor {
+	return func(ui types.UnitID) (types.UnitData, error) {
+		return moneysdk.NewUnitData(ui, pdr)
+	}
+}
+
+func (p *MoneyPartition) TxDecoders(pdr *types.PartitionDescriptionRecord) (txtypes.TxExecutors, error) {
+	return money.NewTxDecoders()
+}
+
 func (p *MoneyPartition) CreateTxSystem(flags *ShardNodeRunFlags, nodeConf *partition.NodeConf) (txsystem.TransactionSystem, error) {
 	stateFilePath := flags.PathWithDefault(flags.StateFile, StateFileName)
-	state, header, err := loadStateFile(stateFilePath, func(ui types.UnitID) (types.UnitData, error) {
-		return moneysdk.NewUnitData(ui, nodeConf.ShardConf())
-	})
+	state, header, err := loadStateFile(stateFilePath, p.UnitDataConstructor(nodeConf.ShardConf()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to load state file: %w", err)
 	}
@@ -66,8 +75,10 @@ func (p *MoneyPartition) CreateTxSystem(flags *ShardNodeRunFlags, nodeConf *part
 		nodeConf.Observability(),
 		money.WithHashAlgorithm(nodeConf.HashAlgorithm()),
 		money.WithTrustBase(nodeConf.TrustBase()),
+		money.WithTrustBases(nodeConf.Orchestration().TrustBase),
 		money.WithState(state),
 		money.WithExecutedTransactions(header.ExecutedTransactions),
+		money.WithParallelExecution(flags.ParallelExecutionWorkers),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create money tx system: %w", err)
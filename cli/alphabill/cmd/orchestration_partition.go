@@ -15,6 +15,7 @@ import (
 	"github.com/alphabill-org/alphabill/state"
 	"github.com/alphabill-org/alphabill/txsystem"
 	"github.com/alphabill-org/alphabill/txsystem/orchestration"
+	txtypes "github.com/alphabill-org/alphabill/txsystem/types"
 )
 
 type (
@@ -53,11 +54,19 @@ func (p *OrchestrationPartition) NewGenesisState(pdr *types.PartitionDescription
 	return state.NewEmptyState(), nil
 }
 
+func (p *OrchestrationPartition) UnitDataConstructor(pdr *types.PartitionDescriptionRecord) state.UnitDataConstructor {
+	return func(ui types.UnitID) (types.UnitData, error) {
+		return moneysdk.NewUnitData(ui, pdr)
+	}
+}
+
+func (p *OrchestrationPartition) TxDecoders(pdr *types.PartitionDescriptionRecord) (txtypes.TxExecutors, error) {
+	return orchestration.NewTxDecoders()
+}
+
 func (p *OrchestrationPartition) CreateTxSystem(flags *ShardNodeRunFlags, nodeConf *partition.NodeConf) (txsystem.TransactionSystem, error) {
 	stateFilePath := flags.PathWithDefault(flags.StateFile, StateFileName)
-	state, header, err := loadStateFile(stateFilePath, func(ui types.UnitID) (types.UnitData, error) {
-		return moneysdk.NewUnitData(ui, nodeConf.ShardConf())
-	})
+	state, header, err := loadStateFile(stateFilePath, p.UnitDataConstructor(nodeConf.ShardConf()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to load state file: %w", err)
 	}
@@ -6,12 +6,15 @@ import (
 
 	"github.com/alphabill-org/alphabill-go-base/types"
 	"github.com/alphabill-org/alphabill-go-base/types/hex"
+	"github.com/alphabill-org/alphabill/partition"
+	abmoney "github.com/alphabill-org/alphabill/txsystem/money"
 )
 
 const (
 	moneyInitialBillValue          = "initialBillValue"
 	moneyInitialBillOwnerPredicate = "initialBillOwnerPredicate"
 	moneyDCMoneySupplyValue        = "dcMoneySupplyValue"
+	moneyRewardPoolValue           = "rewardPoolValue"
 
 	tokensAdminOwnerPredicate = "adminOwnerPredicate"
 	tokensFeelessMode         = "feeless-mode"
@@ -22,7 +25,8 @@ const (
 type MoneyPartitionParams struct {
 	InitialBillValue          uint64
 	InitialBillOwnerPredicate types.PredicateBytes
-	DCMoneySupplyValue        uint64 // The initial value for Dust Collector money supply. Total money supply is initial bill + DC money supply.
+	DCMoneySupplyValue        uint64 // The initial value for Dust Collector money supply. Total money supply is initial bill + DC money supply + reward pool.
+	RewardPoolValue           uint64 // The initial value of the reward pool the validator rewards are paid from.
 }
 
 type OrchestrationPartitionParams struct {
@@ -37,6 +41,9 @@ type TokensPartitionParams struct {
 func ParseMoneyPartitionParams(shardConf *types.PartitionDescriptionRecord) (*MoneyPartitionParams, error) {
 	var params MoneyPartitionParams
 	for key, valueStr := range shardConf.PartitionParams {
+		if partition.IsBlockLimitParam(key) || abmoney.IsRewardOwnerParam(key) {
+			continue // partition type independent, parsed by the node (block limits) or money partition (reward owners)
+		}
 		switch key {
 		case moneyInitialBillValue:
 			parsedValue, err := parseUint64(key, valueStr)
@@ -56,6 +63,12 @@ func ParseMoneyPartitionParams(shardConf *types.PartitionDescriptionRecord) (*Mo
 				return nil, err
 			}
 			params.DCMoneySupplyValue = parsedValue
+		case moneyRewardPoolValue:
+			parsedValue, err := parseUint64(key, valueStr)
+			if err != nil {
+				return nil, err
+			}
+			params.RewardPoolValue = parsedValue
 		default:
 			return nil, fmt.Errorf("unexpected partition param: %s", key)
 		}
@@ -66,6 +79,9 @@ func ParseMoneyPartitionParams(shardConf *types.PartitionDescriptionRecord) (*Mo
 func ParseOrchestrationPartitionParams(shardConf *types.PartitionDescriptionRecord) (*OrchestrationPartitionParams, error) {
 	var params OrchestrationPartitionParams
 	for key, valueStr := range shardConf.PartitionParams {
+		if partition.IsBlockLimitParam(key) || abmoney.IsRewardOwnerParam(key) {
+			continue // partition type independent, parsed by the node (block limits) or money partition (reward owners)
+		}
 		switch key {
 		case orchestrationOwnerPredicate:
 			value, err := hex.Decode([]byte(valueStr))
@@ -83,6 +99,9 @@ func ParseOrchestrationPartitionParams(shardConf *types.PartitionDescriptionReco
 func ParseTokensPartitionParams(shardConf *types.PartitionDescriptionRecord) (*TokensPartitionParams, error) {
 	var params TokensPartitionParams
 	for key, valueStr := range shardConf.PartitionParams {
+		if partition.IsBlockLimitParam(key) || abmoney.IsRewardOwnerParam(key) {
+			continue // partition type independent, parsed by the node (block limits) or money partition (reward owners)
+		}
 		switch key {
 		case tokensAdminOwnerPredicate:
 			{
@@ -0,0 +1,282 @@
+package cmd
+
+import (
+	"bytes"
+	gocrypto "crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alphabill-org/alphabill-go-base/cbor"
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill-go-base/types/hex"
+	"github.com/alphabill-org/alphabill-go-base/util"
+
+	"github.com/alphabill-org/alphabill/partition"
+	"github.com/alphabill-org/alphabill/rpc"
+)
+
+type (
+	proofVerifyFlags struct {
+		*baseFlags
+		trustBaseFlags
+		shardConfFlags
+		TxProof   string
+		UnitProof string
+	}
+
+	// proofReport collects the results of the verification steps
+	proofReport struct {
+		out    io.Writer
+		failed bool
+	}
+)
+
+func newProofCmd(baseFlags *baseFlags) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "proof",
+		Short: "Tools to work with transaction and unit state proofs",
+	}
+	cmd.AddCommand(proofVerifyCmd(baseFlags))
+	return cmd
+}
+
+func proofVerifyCmd(baseFlags *baseFlags) *cobra.Command {
+	flags := &proofVerifyFlags{baseFlags: baseFlags}
+	var cmd = &cobra.Command{
+		Use:   "verify",
+		Short: "Verifies a transaction proof or a unit state proof against the trust base",
+		Long: `Verifies a transaction proof or a unit state proof against the trust base.
+
+The transaction proof is the CBOR encoded TxRecordProof (ie "txRecordProof" of the
+state_getTransactionProof response), either as a hex string or a file containing
+the proof (binary or hex encoded).
+
+The unit state proof is either the JSON response of state_getUnit called with the
+state proof (the unit data is decoded using the shard configuration) or the CBOR
+encoded UnitStateWithProof, as a hex string or a file (binary or hex encoded). The
+unicity certificate of the unit state proof must commit to the shard configuration.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return proofVerify(cmd.OutOrStdout(), flags)
+		},
+	}
+	flags.addTrustBaseFlags(cmd)
+	flags.addShardConfFlags(cmd)
+	cmd.Flags().StringVar(&flags.TxProof, "tx-proof", "", "transaction proof, hex encoded or path to the proof file")
+	cmd.Flags().StringVar(&flags.UnitProof, "unit-proof", "", "unit state proof, hex encoded or path to the proof file (CBOR or JSON response of state_getUnit)")
+	cmd.MarkFlagsOneRequired("tx-proof", "unit-proof")
+	cmd.MarkFlagsMutuallyExclusive("tx-proof", "unit-proof")
+	return cmd
+}
+
+func proofVerify(out io.Writer, flags *proofVerifyFlags) error {
+	trustBase, err := flags.loadTrustBase(flags.baseFlags)
+	if err != nil {
+		return err
+	}
+	report := &proofReport{out: out}
+	if flags.TxProof != "" {
+		err = verifyTxProof(report, flags.TxProof, trustBase)
+	} else {
+		err = verifyUnitProof(report, flags, trustBase)
+	}
+	if err != nil {
+		return err
+	}
+	if report.failed {
+		fmt.Fprintln(out, "RESULT: proof is NOT valid")
+		return errors.New("proof verification failed")
+	}
+	fmt.Fprintln(out, "RESULT: proof is valid")
+	return nil
+}
+
+func verifyTxProof(report *proofReport, input string, trustBase types.RootTrustBase) error {
+	proofBytes, err := readCBORInput(input)
+	if err != nil {
+		return err
+	}
+	proof := &types.TxRecordProof{}
+	if err := cbor.Unmarshal(proofBytes, proof); err != nil {
+		return fmt.Errorf("failed to decode transaction proof: %w", err)
+	}
+	if proof.TxRecord == nil || proof.TxProof == nil {
+		return errors.New("invalid transaction proof: transaction record or proof is missing")
+	}
+	txo, err := proof.TxRecord.GetTransactionOrderV1()
+	if err != nil {
+		return fmt.Errorf("failed to decode transaction order: %w", err)
+	}
+	txHash, err := txo.Hash(gocrypto.SHA256)
+	if err != nil {
+		return fmt.Errorf("failed to calculate transaction hash: %w", err)
+	}
+	fmt.Fprintf(report.out, "transaction %X\n", txHash)
+	fmt.Fprintf(report.out, "  partition: %s, unit: %s, type: %d\n", txo.PartitionID, txo.UnitID, txo.Type)
+	if txr := proof.TxRecord.ServerMetadata; txr != nil {
+		fmt.Fprintf(report.out, "  status: %d, actual fee: %d\n", txr.SuccessIndicator, txr.ActualFee)
+	}
+
+	uc := &types.UnicityCertificate{}
+	if err := cbor.Unmarshal(proof.TxProof.UnicityCertificate, uc); err != nil {
+		return fmt.Errorf("failed to decode unicity certificate: %w", err)
+	}
+	fmt.Fprintf(report.out, "  round: %d, root round: %d\n", uc.GetRoundNumber(), uc.GetRootRoundNumber())
+	report.check("unicity certificate (signatures, unicity tree and shard tree)", verifyUC(uc, txo.PartitionID, nil, trustBase))
+	report.check("transaction tree path and block hash", types.VerifyTxProof(proof, trustBase, gocrypto.SHA256))
+	return nil
+}
+
+func verifyUnitProof(report *proofReport, flags *proofVerifyFlags, trustBase types.RootTrustBase) error {
+	shardConf, err := flags.loadShardConf(flags.baseFlags)
+	if err != nil {
+		return err
+	}
+	shardConfHash, err := shardConf.Hash(gocrypto.SHA256)
+	if err != nil {
+		return fmt.Errorf("failed to calculate shard conf hash: %w", err)
+	}
+	p, ok := flags.partitions[shardConf.PartitionTypeID]
+	if !ok {
+		return fmt.Errorf("unsupported partition type %d", shardConf.PartitionTypeID)
+	}
+
+	unitID, unitState, stateProof, err := readUnitProof(flags.UnitProof, p, shardConf)
+	if err != nil {
+		return err
+	}
+	uc := &types.UnicityCertificate{}
+	if err := cbor.Unmarshal(stateProof.UnicityCertificate, uc); err != nil {
+		return fmt.Errorf("failed to decode unicity certificate: %w", err)
+	}
+	if uc.UnicityTreeCertificate == nil {
+		return errors.New("invalid unicity certificate: unicity tree certificate is missing")
+	}
+	if partitionID := uc.UnicityTreeCertificate.Partition; partitionID != shardConf.PartitionID {
+		return fmt.Errorf("unit of partition %s doesn't belong to the partition %s of the shard configuration", partitionID, shardConf.PartitionID)
+	}
+	fmt.Fprintf(report.out, "unit %s\n", unitID)
+	fmt.Fprintf(report.out, "  partition: %s, shard: %s\n", shardConf.PartitionID, uc.ShardTreeCertificate.Shard.String())
+	fmt.Fprintf(report.out, "  round: %d, root round: %d\n", uc.GetRoundNumber(), uc.GetRootRoundNumber())
+	if shardID := uc.ShardTreeCertificate.Shard; shardID.Key() != shardConf.ShardID.Key() {
+		report.check("shard of the shard configuration", fmt.Errorf("unit of shard %s doesn't belong to the shard %s", shardID.String(), shardConf.ShardID.String()))
+	}
+	report.check("unicity certificate (signatures, unicity tree and shard tree)", verifyUC(uc, shardConf.PartitionID, shardConfHash, trustBase))
+
+	stateHash, summaryValue, err := stateProof.CalculateStateTreeOutput(gocrypto.SHA256)
+	if err == nil && uc.InputRecord != nil {
+		if !bytes.Equal(stateHash, uc.InputRecord.Hash) {
+			err = fmt.Errorf("state tree root %X not equal to certified state hash %X", stateHash, uc.InputRecord.Hash)
+		} else if !bytes.Equal(util.Uint64ToBytes(summaryValue), uc.InputRecord.SummaryValue) {
+			err = fmt.Errorf("state tree summary value %d not equal to certified summary value %X", summaryValue, uc.InputRecord.SummaryValue)
+		}
+	}
+	report.check("state tree path", err)
+
+	ucValidator, err := partition.NewDefaultUnicityCertificateValidator(shardConf.PartitionID, uc.ShardTreeCertificate.Shard, trustBase, gocrypto.SHA256)
+	if err != nil {
+		return fmt.Errorf("failed to create unicity certificate validator: %w", err)
+	}
+	report.check("unit tree path and unit data", stateProof.Verify(gocrypto.SHA256, unitState, ucValidator, shardConfHash))
+	return nil
+}
+
+/*
+readUnitProof reads the unit state proof, which is either the JSON response of the
+state_getUnit (called with the state proof) or the CBOR encoded UnitStateWithProof
+(as returned by the unit proof index), as a hex string or a file.
+*/
+func readUnitProof(input string, p Partition, shardConf *types.PartitionDescriptionRecord) (types.UnitID, *types.UnitState, *types.UnitStateProof, error) {
+	if data, err := os.ReadFile(filepath.Clean(input)); err == nil && bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		var unit rpc.Unit[json.RawMessage]
+		if err := json.Unmarshal(data, &unit); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to decode unit state proof: %w", err)
+		}
+		if unit.StateProof == nil {
+			return nil, nil, nil, errors.New("unit state proof is missing")
+		}
+		unitData, err := p.UnitDataConstructor(shardConf)(unit.UnitID)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to create unit data: %w", err)
+		}
+		if err := json.Unmarshal(unit.Data, unitData); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to decode unit data: %w", err)
+		}
+		unitState, err := types.NewUnitState(unitData, 0, unit.StateLockTx)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to create unit state: %w", err)
+		}
+		return unit.UnitID, unitState, unit.StateProof, nil
+	}
+
+	proofBytes, err := readCBORInput(input)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	unitProof := &types.UnitStateWithProof{}
+	if err := cbor.Unmarshal(proofBytes, unitProof); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to decode unit state proof: %w", err)
+	}
+	if unitProof.State == nil || unitProof.Proof == nil {
+		return nil, nil, nil, errors.New("invalid unit state proof: unit state or proof is missing")
+	}
+	return unitProof.Proof.UnitID, unitProof.State, unitProof.Proof, nil
+}
+
+func verifyUC(uc *types.UnicityCertificate, partitionID types.PartitionID, shardConfHash []byte, trustBase types.RootTrustBase) error {
+	ucValidator, err := partition.NewDefaultUnicityCertificateValidator(partitionID, uc.ShardTreeCertificate.Shard, trustBase, gocrypto.SHA256)
+	if err != nil {
+		return err
+	}
+	return ucValidator.Validate(uc, shardConfHash)
+}
+
+func (r *proofReport) check(name string, err error) {
+	if err != nil {
+		r.failed = true
+		fmt.Fprintf(r.out, "  %s: FAILED: %v\n", name, err)
+		return
+	}
+	fmt.Fprintf(r.out, "  %s: OK\n", name)
+}
+
+/*
+readCBORInput returns the CBOR value from the input, which is either a hex string
+or a path to a file containing the value as binary or hex encoded CBOR.
+*/
+func readCBORInput(input string) ([]byte, error) {
+	data := []byte(input)
+	if util.FileExists(input) {
+		var err error
+		if data, err = os.ReadFile(filepath.Clean(input)); err != nil {
+			return nil, fmt.Errorf("failed to read input file: %w", err)
+		}
+	}
+	if s := strings.TrimSpace(string(data)); isHex(s) {
+		b, err := hex.Decode([]byte(s))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode hex encoded input: %w", err)
+		}
+		return b, nil
+	}
+	return data, nil
+}
+
+func isHex(s string) bool {
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	if len(s) == 0 || len(s)%2 != 0 {
+		return false
+	}
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	gocrypto "crypto"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/cbor"
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill-go-base/util"
+	testobserve "github.com/alphabill-org/alphabill/internal/testutils/observability"
+	testsig "github.com/alphabill-org/alphabill/internal/testutils/sig"
+	"github.com/alphabill-org/alphabill/internal/testutils/trustbase"
+)
+
+func Test_ProofVerify(t *testing.T) {
+	signer, verifier := testsig.CreateSignerAndVerifier(t)
+	homeDir := writeShardConf(t, defaultMoneyShardConf)
+	require.NoError(t, util.WriteJsonFile(filepath.Join(homeDir, trustBaseFileName), trustbase.NewTrustBase(t, verifier)))
+
+	blocks := createTestBlocks(t, signer, defaultMoneyShardConf, 1)
+	proof, err := types.NewTxRecordProof(blocks[0], 0, gocrypto.SHA256)
+	require.NoError(t, err)
+	proofBytes, err := cbor.Marshal(proof)
+	require.NoError(t, err)
+
+	run := func(t *testing.T, args ...string) (string, error) {
+		out := &bytes.Buffer{}
+		cmd := New(testobserve.NewFactory(t))
+		cmd.baseCmd.SetOut(out)
+		cmd.baseCmd.SetArgs(append(append([]string{"proof", "verify"}, args...), "--home", homeDir))
+		err := cmd.Execute(context.Background())
+		return out.String(), err
+	}
+
+	t.Run("hex encoded proof", func(t *testing.T) {
+		out, err := run(t, "--tx-proof", fmt.Sprintf("0x%x", proofBytes))
+		require.NoError(t, err, out)
+		require.Contains(t, out, "unicity certificate (signatures, unicity tree and shard tree): OK")
+		require.Contains(t, out, "transaction tree path and block hash: OK")
+		require.Contains(t, out, "RESULT: proof is valid")
+	})
+
+	t.Run("proof file", func(t *testing.T) {
+		proofFile := filepath.Join(t.TempDir(), "proof.cbor")
+		require.NoError(t, os.WriteFile(proofFile, proofBytes, 0600))
+		out, err := run(t, "--tx-proof", proofFile)
+		require.NoError(t, err, out)
+		require.Contains(t, out, "RESULT: proof is valid")
+	})
+
+	t.Run("not certified by the trust base", func(t *testing.T) {
+		_, otherVerifier := testsig.CreateSignerAndVerifier(t)
+		otherTrustBase := filepath.Join(t.TempDir(), trustBaseFileName)
+		require.NoError(t, util.WriteJsonFile(otherTrustBase, trustbase.NewTrustBase(t, otherVerifier)))
+		out, err := run(t, "--tx-proof", fmt.Sprintf("%x", proofBytes), "--trust-base", otherTrustBase)
+		require.EqualError(t, err, "proof verification failed")
+		require.Contains(t, out, "unicity certificate (signatures, unicity tree and shard tree): FAILED")
+		require.Contains(t, out, "RESULT: proof is NOT valid")
+	})
+
+	t.Run("invalid proof", func(t *testing.T) {
+		_, err := run(t, "--tx-proof", "0x0102")
+		require.ErrorContains(t, err, "failed to decode transaction proof")
+	})
+
+	t.Run("invalid unit proof", func(t *testing.T) {
+		_, err := run(t, "--unit-proof", "0x0102")
+		require.ErrorContains(t, err, "failed to decode unit state proof")
+
+		// CBOR encoded proof without the unit state
+		proofBytes, err := cbor.Marshal(&types.UnitStateWithProof{Proof: &types.UnitStateProof{}})
+		require.NoError(t, err)
+		_, err = run(t, "--unit-proof", fmt.Sprintf("%x", proofBytes))
+		require.ErrorContains(t, err, "invalid unit state proof: unit state or proof is missing")
+
+		// JSON response of state_getUnit without the proof
+		proofFile := filepath.Join(t.TempDir(), "unit.json")
+		require.NoError(t, os.WriteFile(proofFile, []byte(`{"unitId":"0x01"}`), 0600))
+		_, err = run(t, "--unit-proof", proofFile)
+		require.ErrorContains(t, err, "unit state proof is missing")
+	})
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	abcrypto "github.com/alphabill-org/alphabill-go-base/crypto"
+	"github.com/alphabill-org/alphabill/partition"
+	"github.com/alphabill-org/alphabill/remotesigner"
+)
+
+const signerStoreFileName = "signer.db"
+
+type (
+	// remoteSignerFlags are the flags of the nodes delegating the signing to a remote signer
+	remoteSignerFlags struct {
+		RemoteSignerAddress string
+		RemoteSignerCert    string
+		RemoteSignerKey     string
+		RemoteSignerCA      string
+	}
+
+	remoteSignerRunFlags struct {
+		*baseFlags
+		keyConfFlags
+
+		Address     string
+		TLSCert     string
+		TLSKey      string
+		TLSClientCA string
+		DBFile      string
+	}
+)
+
+func (f *remoteSignerFlags) addRemoteSignerFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.RemoteSignerAddress, "remote-signer-address", "",
+		`address of the remote signer, "host:port" or "unix:/path/to/socket". When set the signing key of the key configuration is not used and all the signatures are delegated to the remote signer`)
+	cmd.Flags().StringVar(&f.RemoteSignerCert, "remote-signer-cert", "", "path to the TLS certificate presented to the remote signer")
+	cmd.Flags().StringVar(&f.RemoteSignerKey, "remote-signer-key", "", "path to the private key of the TLS certificate presented to the remote signer")
+	cmd.Flags().StringVar(&f.RemoteSignerCA, "remote-signer-ca", "", "path to the CA certificate of the remote signer")
+}
+
+/*
+signer returns the remote signer client when the remote signer is configured,
+otherwise the signer of the signing key in the key configuration.
+*/
+func (f *remoteSignerFlags) signer(ctx context.Context, keyConf *partition.KeyConf) (abcrypto.Signer, error) {
+	if f.RemoteSignerAddress == "" {
+		return keyConf.Signer()
+	}
+	if f.RemoteSignerCert == "" || f.RemoteSignerKey == "" || f.RemoteSignerCA == "" {
+		return nil, errors.New("remote signer requires --remote-signer-cert, --remote-signer-key and --remote-signer-ca")
+	}
+	tlsConf, err := remotesigner.ClientTLSConfig(f.RemoteSignerCert, f.RemoteSignerKey, f.RemoteSignerCA)
+	if err != nil {
+		return nil, fmt.Errorf("remote signer TLS configuration: %w", err)
+	}
+	signer, err := remotesigner.Dial(ctx, f.RemoteSignerAddress, tlsConf)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to remote signer %s: %w", f.RemoteSignerAddress, err)
+	}
+	if len(keyConf.SigKey.PrivateKey) > 0 {
+		// the key configuration may still contain the signing key of the node
+		if err := verifyRemoteSignerKey(signer, keyConf); err != nil {
+			return nil, errors.Join(err, signer.Close())
+		}
+	}
+	return signer, nil
+}
+
+func verifyRemoteSignerKey(signer *remotesigner.Client, keyConf *partition.KeyConf) error {
+	keySigner, err := keyConf.Signer()
+	if err != nil {
+		return err
+	}
+	verifier, err := keySigner.Verifier()
+	if err != nil {
+		return fmt.Errorf("invalid signing key: %w", err)
+	}
+	pubKey, err := verifier.MarshalPublicKey()
+	if err != nil {
+		return fmt.Errorf("marshaling public key of the signing key: %w", err)
+	}
+	if err := signer.VerifyPublicKey(pubKey); err != nil {
+		return fmt.Errorf("remote signer doesn't use the signing key of the key configuration: %w", err)
+	}
+	return nil
+}
+
+func newRemoteSignerCmd(baseFlags *baseFlags) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "remote-signer",
+		Short: "Tools to run a remote signer of the validator signing key",
+	}
+	cmd.AddCommand(remoteSignerRunCmd(baseFlags))
+	return cmd
+}
+
+func remoteSignerRunCmd(baseFlags *baseFlags) *cobra.Command {
+	flags := &remoteSignerRunFlags{baseFlags: baseFlags}
+	var cmd = &cobra.Command{
+		Use:   "run",
+		Short: "Runs the remote signer",
+		Long: `Runs the remote signer which signs the requests of the nodes with the signing key
+of the key configuration. The nodes are authenticated with TLS client certificates.
+
+The signer refuses to sign two different block certification requests for the same
+round of a shard, the last signed requests are stored in the signer database.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return remoteSignerRun(cmd.Context(), flags)
+		},
+	}
+	flags.addKeyConfFlags(cmd, false)
+	cmd.Flags().StringVar(&flags.Address, "address", "", `address to listen on, "host:port" or "unix:/path/to/socket"`)
+	cmd.Flags().StringVar(&flags.TLSCert, "tls-cert", "", "path to the TLS certificate of the signer")
+	cmd.Flags().StringVar(&flags.TLSKey, "tls-key", "", "path to the private key of the TLS certificate")
+	cmd.Flags().StringVar(&flags.TLSClientCA, "tls-client-ca", "", "path to the CA certificate of the node client certificates")
+	cmd.Flags().StringVar(&flags.DBFile, "signer-db", "",
+		fmt.Sprintf("path to the signer database (default %s)", filepath.Join("$AB_HOME", signerStoreFileName)))
+	_ = cmd.MarkFlagRequired("address")
+	_ = cmd.MarkFlagRequired("tls-cert")
+	_ = cmd.MarkFlagRequired("tls-key")
+	_ = cmd.MarkFlagRequired("tls-client-ca")
+	return cmd
+}
+
+func remoteSignerRun(ctx context.Context, flags *remoteSignerRunFlags) error {
+	keyConf, err := flags.loadKeyConf(flags.baseFlags, false)
+	if err != nil {
+		return err
+	}
+	signer, err := keyConf.Signer()
+	if err != nil {
+		return err
+	}
+	db, err := flags.initStore(flags.DBFile, signerStoreFileName)
+	if err != nil {
+		return err
+	}
+	log := flags.observe.Logger()
+	server, err := remotesigner.NewServer(signer, db, log)
+	if err != nil {
+		return fmt.Errorf("creating remote signer: %w", err)
+	}
+
+	tlsConf, err := remotesigner.ServerTLSConfig(flags.TLSCert, flags.TLSKey, flags.TLSClientCA)
+	if err != nil {
+		return fmt.Errorf("remote signer TLS configuration: %w", err)
+	}
+	listener, err := remotesigner.Listen(flags.Address, tlsConf)
+	if err != nil {
+		return fmt.Errorf("listening on %s: %w", flags.Address, err)
+	}
+	log.InfoContext(ctx, fmt.Sprintf("remote signer listening on %s", flags.Address))
+	if err := server.Serve(ctx, listener); err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
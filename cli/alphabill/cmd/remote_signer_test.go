@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	testobserve "github.com/alphabill-org/alphabill/internal/testutils/observability"
+)
+
+func TestRemoteSignerFlags_signer(t *testing.T) {
+	keyConf, err := generateKeys()
+	require.NoError(t, err)
+
+	t.Run("local signer", func(t *testing.T) {
+		signer, err := (&remoteSignerFlags{}).signer(context.Background(), keyConf)
+		require.NoError(t, err)
+		privKey, err := signer.MarshalPrivateKey()
+		require.NoError(t, err)
+		require.EqualValues(t, keyConf.SigKey.PrivateKey, privKey)
+	})
+
+	t.Run("TLS configuration missing", func(t *testing.T) {
+		flags := &remoteSignerFlags{RemoteSignerAddress: "localhost:1", RemoteSignerCert: "cert.pem"}
+		_, err := flags.signer(context.Background(), keyConf)
+		require.EqualError(t, err, "remote signer requires --remote-signer-cert, --remote-signer-key and --remote-signer-ca")
+	})
+
+	t.Run("invalid certificate", func(t *testing.T) {
+		dir := t.TempDir()
+		flags := &remoteSignerFlags{
+			RemoteSignerAddress: "localhost:1",
+			RemoteSignerCert:    filepath.Join(dir, "cert.pem"),
+			RemoteSignerKey:     filepath.Join(dir, "key.pem"),
+			RemoteSignerCA:      filepath.Join(dir, "ca.pem"),
+		}
+		_, err := flags.signer(context.Background(), keyConf)
+		require.ErrorContains(t, err, "remote signer TLS configuration: loading certificate")
+	})
+}
+
+func TestRemoteSignerRun_requiredFlags(t *testing.T) {
+	cmd := New(testobserve.NewFactory(t))
+	cmd.baseCmd.SetOut(&bytes.Buffer{})
+	cmd.baseCmd.SetArgs([]string{"remote-signer", "run", "--home", t.TempDir()})
+	err := cmd.Execute(context.Background())
+	require.ErrorContains(t, err, `required flag(s) "address", "tls-cert", "tls-client-ca", "tls-key" not set`)
+}
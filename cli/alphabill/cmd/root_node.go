@@ -11,32 +11,40 @@ import (
 	"time"
 
 	"github.com/ainvaltin/httpsrv"
+	"github.com/gorilla/mux"
 	"github.com/libp2p/go-libp2p/core/peer"
-	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/spf13/cobra"
 	"golang.org/x/sync/errgroup"
 
 	abcrypto "github.com/alphabill-org/alphabill-go-base/crypto"
 	"github.com/alphabill-org/alphabill-go-base/types"
 	"github.com/alphabill-org/alphabill-go-base/util"
-	"github.com/alphabill-org/alphabill/keyvaluedb"
 	"github.com/alphabill-org/alphabill/logger"
 	"github.com/alphabill-org/alphabill/network"
 	"github.com/alphabill-org/alphabill/network/protocol/abdrc"
 	"github.com/alphabill-org/alphabill/observability"
 	"github.com/alphabill-org/alphabill/partition"
 	"github.com/alphabill-org/alphabill/rootchain"
+	"github.com/alphabill-org/alphabill/rootchain/blockarchive"
 	"github.com/alphabill-org/alphabill/rootchain/consensus"
 	"github.com/alphabill-org/alphabill/rootchain/consensus/storage"
 	"github.com/alphabill-org/alphabill/rootchain/consensus/trustbase"
+	"github.com/alphabill-org/alphabill/rootchain/epochstats"
+	"github.com/alphabill-org/alphabill/rootchain/evidence"
+	"github.com/alphabill-org/alphabill/rootchain/liveness"
 	"github.com/alphabill-org/alphabill/rootchain/partitions"
+	"github.com/alphabill-org/alphabill/rootchain/ucarchive"
+	"github.com/alphabill-org/alphabill/rpc"
 )
 
 const (
 	rootStoreFileName          = "rootchain.db"
 	trustBaseStoreFileName     = "trustbase.db"
 	orchestrationStoreFileName = "orchestration.db"
+	evidenceStoreFileName      = "evidence.db"
+	ucArchiveFileName          = "uc-archive.db"
+	blockArchiveFileName       = "block-archive.db"
+	epochStatsFileName         = "epoch-stats.db"
 	defaultNetworkTimeout      = 300 * time.Millisecond
 )
 
@@ -46,11 +54,21 @@ type (
 		keyConfFlags
 		trustBaseFlags
 		p2pFlags
+		remoteSignerFlags
+		governanceFlags
 
 		RootStoreFile          string // path to Bolt storage file
 		TrustBaseStoreFile     string
 		OrchestrationStoreFile string
+		EvidenceStoreFile      string
+		UCArchiveFile          string
+		UCArchiveRetention     uint64 // number of root rounds the certificates are archived for
+		BlockArchiveFile       string
+		BlockArchiveRetention  uint64 // number of root rounds the root blocks are archived for
+		EpochStatsFile         string
 		ShardConfFiles         []string // paths to shard conf files
+		LivenessStallRounds    uint64   // number of root rounds without progress after which shard is reported as stalled
+		LivenessAbsentRounds   uint64   // number of rounds without certification request after which validator is reported
 
 		BlockRate        uint32
 		MaxRequests      uint   // validator partition certification request channel capacity
@@ -98,6 +116,8 @@ func rootNodeRunCmd(baseFlags *baseFlags) *cobra.Command {
 	flags.addKeyConfFlags(cmd, false)
 	flags.addTrustBaseFlags(cmd)
 	flags.addP2PFlags(cmd)
+	flags.addRemoteSignerFlags(cmd)
+	flags.addGovernanceFlags(cmd)
 
 	cmd.Flags().UintVar(&flags.MaxRequests, "max-requests", 1000, "request buffer capacity")
 	cmd.Flags().StringVar(&flags.RPCServerAddress, "rpc-server-address", "",
@@ -109,6 +129,25 @@ func rootNodeRunCmd(baseFlags *baseFlags) *cobra.Command {
 		fmt.Sprintf("path to the trust base database (default: %s)", filepath.Join("$AB_HOME", trustBaseStoreFileName)))
 	cmd.Flags().StringVar(&flags.OrchestrationStoreFile, "orchestration-db", "",
 		fmt.Sprintf("path to the orchestration database (default: %s)", filepath.Join("$AB_HOME", orchestrationStoreFileName)))
+	cmd.Flags().StringVar(&flags.EvidenceStoreFile, "evidence-db", "",
+		fmt.Sprintf("path to the equivocation evidence database (default: %s)", filepath.Join("$AB_HOME", evidenceStoreFileName)))
+
+	cmd.Flags().StringVar(&flags.UCArchiveFile, "uc-archive-db", "",
+		fmt.Sprintf("path to the unicity certificate archive database (default: %s)", filepath.Join("$AB_HOME", ucArchiveFileName)))
+	cmd.Flags().Uint64Var(&flags.UCArchiveRetention, "uc-archive-retention", 0,
+		"number of root rounds the issued unicity certificates are archived for, 0 means forever")
+	cmd.Flags().StringVar(&flags.BlockArchiveFile, "block-archive-db", "",
+		fmt.Sprintf("path to the root block archive database (default: %s)", filepath.Join("$AB_HOME", blockArchiveFileName)))
+	cmd.Flags().Uint64Var(&flags.BlockArchiveRetention, "block-archive-retention", 0,
+		"number of root rounds the committed root blocks and timeout certificates are archived for, 0 means forever")
+
+	cmd.Flags().StringVar(&flags.EpochStatsFile, "epoch-stats-db", "",
+		fmt.Sprintf("path to the database of the per epoch validator fees and statistics of the shards (default: %s)", filepath.Join("$AB_HOME", epochStatsFileName)))
+
+	cmd.Flags().Uint64Var(&flags.LivenessStallRounds, "liveness-stall-rounds", 20,
+		"number of root rounds without input record change after which the shard is reported as stalled, 0 disables the reporting")
+	cmd.Flags().Uint64Var(&flags.LivenessAbsentRounds, "liveness-absent-rounds", 3,
+		"number of consecutive shard rounds without certification request after which the validator is reported as absent, 0 disables the reporting")
 
 	cmd.Flags().StringSliceVarP(&flags.ShardConfFiles, "shard-conf", "", []string{}, "path to shard conf files")
 	cmd.Flags().Uint32Var(&flags.BlockRate, "block-rate", consensus.BlockRate, "block rate (consensus parameter)")
@@ -155,18 +194,26 @@ func rootNodeRun(ctx context.Context, flags *rootNodeRunFlags) error {
 	if err != nil {
 		return err
 	}
-	trustBaseStore, err := flags.initStore(flags.TrustBaseStoreFile, trustBaseStoreFileName)
+	trustBaseDB, err := flags.initStore(flags.TrustBaseStoreFile, trustBaseStoreFileName)
 	if err != nil {
 		return err
 	}
+	trustBaseStore, err := trustbase.NewStore(trustBaseDB)
+	if err != nil {
+		return fmt.Errorf("consensus trust base storage init failed: %w", err)
+	}
 
 	// load trust base
 	trustBase, err := loadTrustBase(trustBaseStore, flags)
 	if err != nil {
 		return fmt.Errorf("root trust base init failed: %w", err)
 	}
+	trustBases, err := trustbase.NewSchedule(trustBaseStore)
+	if err != nil {
+		return fmt.Errorf("loading root epochs: %w", err)
+	}
 
-	signer, err := keyConf.Signer()
+	signer, err := flags.signer(ctx, keyConf)
 	if err != nil {
 		return err
 	}
@@ -174,7 +221,7 @@ func rootNodeRun(ctx context.Context, flags *rootNodeRunFlags) error {
 	if err != nil {
 		return fmt.Errorf("invalid root node signing key: %w", err)
 	}
-	if err = verifyKeyPresentInTrustBase(host.ID(), trustBase, ver); err != nil {
+	if err = verifyKeyPresentInTrustBases(host.ID(), trustBases, ver); err != nil {
 		return fmt.Errorf("root node key not found in trust base: %w", err)
 	}
 
@@ -192,6 +239,54 @@ func rootNodeRun(ctx context.Context, flags *rootNodeRunFlags) error {
 	if err := loadShardConfFiles(flags.ShardConfFiles, orchestration); err != nil {
 		return fmt.Errorf("failed to load shard conf files: %w", err)
 	}
+	governance, err := flags.loadGovernance()
+	if err != nil {
+		return err
+	}
+	if flags.RPCServerAddress != "" {
+		flags.warnUnsignedShardConf(ctx, log)
+	}
+
+	evidenceDB, err := flags.initStore(flags.EvidenceStoreFile, evidenceStoreFileName)
+	if err != nil {
+		return err
+	}
+	evidenceStore, err := evidence.NewStore(evidenceDB, obs.Meter("rootchain.evidence"))
+	if err != nil {
+		return fmt.Errorf("creating evidence store: %w", err)
+	}
+
+	ucArchiveDB, err := flags.initStore(flags.UCArchiveFile, ucArchiveFileName)
+	if err != nil {
+		return err
+	}
+	ucArchive, err := ucarchive.New(ucArchiveDB, flags.UCArchiveRetention)
+	if err != nil {
+		return fmt.Errorf("creating unicity certificate archive: %w", err)
+	}
+
+	blockArchiveDB, err := flags.initStore(flags.BlockArchiveFile, blockArchiveFileName)
+	if err != nil {
+		return err
+	}
+	blockArchive, err := blockarchive.New(blockArchiveDB, flags.BlockArchiveRetention)
+	if err != nil {
+		return fmt.Errorf("creating root block archive: %w", err)
+	}
+
+	epochStatsDB, err := flags.initStore(flags.EpochStatsFile, epochStatsFileName)
+	if err != nil {
+		return err
+	}
+	epochStats, err := epochstats.NewStore(epochStatsDB)
+	if err != nil {
+		return fmt.Errorf("creating epoch stats store: %w", err)
+	}
+
+	livenessMonitor, err := liveness.New(flags.LivenessStallRounds, flags.LivenessAbsentRounds, obs.Meter("rootchain.liveness"), log)
+	if err != nil {
+		return fmt.Errorf("creating liveness monitor: %w", err)
+	}
 
 	consensusParams := consensus.NewConsensusParams()
 	consensusParams.BlockRate = time.Duration(flags.BlockRate) * time.Millisecond
@@ -205,6 +300,11 @@ func rootNodeRun(ctx context.Context, flags *rootNodeRunFlags) error {
 		rootStore,
 		obs,
 		consensus.WithConsensusParams(*consensusParams),
+		consensus.WithEvidenceStore(evidenceStore),
+		consensus.WithUCArchive(ucArchive),
+		consensus.WithBlockArchive(blockArchive),
+		consensus.WithEpochStats(epochStats),
+		consensus.WithTrustBases(trustBases),
 	)
 	if err != nil {
 		return fmt.Errorf("failed initiate distributed consensus manager: %w", err)
@@ -217,6 +317,10 @@ func rootNodeRun(ctx context.Context, flags *rootNodeRunFlags) error {
 		partitionNet,
 		cm,
 		obs,
+		rootchain.WithEvidenceStore(evidenceStore),
+		rootchain.WithLivenessMonitor(livenessMonitor),
+		rootchain.WithTrustBases(trustBases),
+		rootchain.WithShardConfs(orchestration),
 	)
 	if err != nil {
 		return fmt.Errorf("failed initiate root node: %w", err)
@@ -231,21 +335,49 @@ func rootNodeRun(ctx context.Context, flags *rootNodeRunFlags) error {
 			return nil // do not kill the group!
 		}
 
-		mux := http.NewServeMux()
-		if pr := flags.observe.PrometheusRegisterer(); pr != nil {
-			mux.Handle("/api/v1/metrics", promhttp.HandlerFor(pr.(prometheus.Gatherer), promhttp.HandlerOpts{MaxRequestsInFlight: 1}))
+		routers := []rpc.Registrar{
+			rpc.MetricsEndpoints(obs.PrometheusRegisterer()),
+			rpc.RegistrarFunc(func(r *mux.Router) {
+				if governance != nil {
+					r.HandleFunc("/configurations", putSignedShardConfigHandler(governance, orchestration.AddApprovedShardConfig)).Methods(http.MethodPut)
+				} else if flags.AllowUnsignedShardConf {
+					r.HandleFunc("/configurations", putShardConfigHandler(orchestration.AddShardConfig)).Methods(http.MethodPut)
+				}
+				r.HandleFunc("/trustbases", putTrustBaseHandler(func(tb *types.RootTrustBaseV1) (uint64, error) {
+					return trustBases.Add(tb, cm.CurrentRound())
+				})).Methods(http.MethodPut)
+				r.HandleFunc("/roundInfo", getRoundInfoHandler(cm.GetState, obs)).Methods(http.MethodGet)
+				r.HandleFunc("/evidence", getEvidenceHandler(evidenceStore.List, obs)).Methods(http.MethodGet)
+			}),
 		}
-		mux.HandleFunc("PUT /api/v1/configurations", putShardConfigHandler(orchestration.AddShardConfig))
-		mux.HandleFunc("GET /api/v1/roundInfo", getRoundInfoHandler(cm.GetState, obs))
-		return httpsrv.Run(ctx,
-			&http.Server{
-				Addr:              flags.RPCServerAddress,
-				Handler:           mux,
-				ReadTimeout:       3 * time.Second,
-				ReadHeaderTimeout: time.Second,
-				WriteTimeout:      5 * time.Second,
-				IdleTimeout:       30 * time.Second,
-			})
+		rpcConf := &rpc.ServerConfiguration{
+			Address:                flags.RPCServerAddress,
+			ReadTimeout:            3 * time.Second,
+			ReadHeaderTimeout:      time.Second,
+			WriteTimeout:           5 * time.Second,
+			IdleTimeout:            30 * time.Second,
+			MaxBodyBytes:           rpc.DefaultMaxBodyBytes,
+			BatchItemLimit:         rpc.DefaultBatchItemLimit,
+			BatchResponseSizeLimit: rpc.DefaultBatchResponseSizeLimit,
+			APIs: []rpc.API{
+				{
+					Namespace: "root",
+					Service: rpc.NewRootAPI(cm, trustBaseStore, obs,
+						rpc.WithUCArchive(ucArchive),
+						rpc.WithBlockArchive(blockArchive),
+						rpc.WithLivenessMonitor(livenessMonitor),
+						rpc.WithEpochStats(epochStats),
+						rpc.WithShardConfApprovals(orchestration),
+						rpc.WithEvidenceStore(evidenceStore),
+					),
+				},
+			},
+		}
+		rpcServer, err := rpc.NewHTTPServer(rpcConf, obs, routers...)
+		if err != nil {
+			return fmt.Errorf("creating RPC server: %w", err)
+		}
+		return httpsrv.Run(ctx, rpcServer)
 	})
 
 	return g.Wait()
@@ -292,13 +424,23 @@ func verifyKeyPresentInTrustBase(nodeID peer.ID, trustBase types.RootTrustBase,
 	return fmt.Errorf("node not part of trust base")
 }
 
+/*
+verifyKeyPresentInTrustBases checks that the node is the validator of at least one
+of the scheduled root epochs - the validator of the next epoch must be able to start
+before the epoch starts.
+*/
+func verifyKeyPresentInTrustBases(nodeID peer.ID, trustBases *trustbase.Schedule, ver abcrypto.Verifier) (err error) {
+	for epoch := uint64(0); epoch <= trustBases.LastEpoch(); epoch++ {
+		if err = verifyKeyPresentInTrustBase(nodeID, trustBases.TrustBase(epoch), ver); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
 // loadTrustBase returns the stored trust base if it exists, otherwise
 // loads and the stores the trust base from given file.
-func loadTrustBase(store keyvaluedb.KeyValueDB, flags *rootNodeRunFlags) (types.RootTrustBase, error) {
-	trustBaseStore, err := trustbase.NewStore(store)
-	if err != nil {
-		return nil, fmt.Errorf("consensus trust base storage init failed: %w", err)
-	}
+func loadTrustBase(trustBaseStore *trustbase.Store, flags *rootNodeRunFlags) (types.RootTrustBase, error) {
 	trustBase, err := trustBaseStore.LoadTrustBase(0)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load trust base: %w", err)
@@ -337,6 +479,68 @@ func putShardConfigHandler(addShardConfFn func(shardConf *types.PartitionDescrip
 	}
 }
 
+/*
+putTrustBaseHandler schedules the trust base of the next root epoch, the trust base
+must be signed by the quorum of the validators of the current last epoch.
+*/
+func putTrustBaseHandler(addTrustBaseFn func(tb *types.RootTrustBaseV1) (uint64, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		var tb *types.RootTrustBaseV1
+		if err := json.NewDecoder(r.Body).Decode(&tb); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "parsing request body: decoding trust base json: %v", err)
+			return
+		}
+		if tb == nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, "parsing request body: trust base is nil")
+			return
+		}
+
+		epoch, err := addTrustBaseFn(tb)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, "scheduling trust base: %v", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"epoch":"%d"}`, epoch)
+	}
+}
+
+/*
+putSignedShardConfigHandler registers the shard conf approved by the quorum of the
+governance approvers together with the audit record of the approval.
+*/
+func putSignedShardConfigHandler(governance *partitions.Governance, addShardConfFn func(shardConf *types.PartitionDescriptionRecord, approval *partitions.Approval) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		var signedShardConf *partitions.SignedShardConf
+		if err := json.NewDecoder(r.Body).Decode(&signedShardConf); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "parsing request body: decoding signed shard conf json: %v", err)
+			return
+		}
+
+		approval, err := governance.Verify(signedShardConf)
+		if err != nil {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprintf(w, "verifying approvals: %v", err)
+			return
+		}
+
+		if err := addShardConfFn(signedShardConf.ShardConf, approval); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, "registering shard conf: %v", err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
 func parseShardConf(r io.ReadCloser) (*types.PartitionDescriptionRecord, error) {
 	defer r.Close()
 	var shardConf *types.PartitionDescriptionRecord
@@ -402,3 +606,24 @@ func getRoundInfoHandler(getState func() (*abdrc.StateMsg, error), obs Observabi
 		}
 	}
 }
+
+/*
+getEvidenceHandler returns the equivocation evidence detected by the root node.
+*/
+func getEvidenceHandler(list func() ([]*evidence.Evidence, error), obs Observability) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		items, err := list()
+		if err != nil {
+			obs.Logger().Warn(fmt.Sprintf("GET evidence request: failed to load evidence: %v", err))
+			http.Error(w, "failed to load evidence", http.StatusInternalServerError)
+			return
+		}
+		if items == nil {
+			items = []*evidence.Evidence{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(items); err != nil {
+			obs.Logger().Warn(fmt.Sprintf("GET evidence request: failed to write response: %v", err))
+		}
+	}
+}
@@ -19,9 +19,13 @@ import (
 	"github.com/alphabill-org/alphabill-go-base/types"
 	"github.com/alphabill-org/alphabill/internal/testutils/net"
 	"github.com/alphabill-org/alphabill/internal/testutils/observability"
+	testsig "github.com/alphabill-org/alphabill/internal/testutils/sig"
 	testtime "github.com/alphabill-org/alphabill/internal/testutils/time"
+	"github.com/alphabill-org/alphabill/internal/testutils/trustbase"
 	"github.com/alphabill-org/alphabill/network/protocol/abdrc"
 	rctypes "github.com/alphabill-org/alphabill/rootchain/consensus/types"
+	"github.com/alphabill-org/alphabill/rootchain/evidence"
+	"github.com/alphabill-org/alphabill/rootchain/partitions"
 )
 
 func TestRootValidator_OK(t *testing.T) {
@@ -241,6 +245,89 @@ func Test_cfgHandler(t *testing.T) {
 	})
 }
 
+func Test_signedCfgHandler(t *testing.T) {
+	signer, verifier := testsig.CreateSignerAndVerifier(t)
+	governance, err := partitions.NewGovernance(1, []*types.NodeInfo{trustbase.NewNodeInfoFromVerifier(t, "op1", verifier)})
+	require.NoError(t, err)
+
+	t.Run("not approved", func(t *testing.T) {
+		hf := putSignedShardConfigHandler(governance, func(shardConf *types.PartitionDescriptionRecord, approval *partitions.Approval) error {
+			t.Error("unexpected call of addConfig callback")
+			return nil
+		})
+		body, err := json.Marshal(&partitions.SignedShardConf{ShardConf: defaultMoneyShardConf})
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		hf(w, httptest.NewRequest(http.MethodPut, "/api/v1/configurations", bytes.NewBuffer(body)))
+		require.EqualValues(t, http.StatusForbidden, w.Result().StatusCode)
+		require.Contains(t, w.Body.String(), "shard conf is not approved")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		cbCall := false
+		hf := putSignedShardConfigHandler(governance, func(shardConf *types.PartitionDescriptionRecord, approval *partitions.Approval) error {
+			cbCall = true
+			require.Equal(t, defaultMoneyShardConf, shardConf)
+			require.Equal(t, []string{"op1"}, approval.Approvers)
+			return nil
+		})
+		signedShardConf := &partitions.SignedShardConf{ShardConf: defaultMoneyShardConf}
+		require.NoError(t, signedShardConf.Sign("op1", signer))
+		body, err := json.Marshal(signedShardConf)
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		hf(w, httptest.NewRequest(http.MethodPut, "/api/v1/configurations", bytes.NewBuffer(body)))
+		require.EqualValues(t, http.StatusOK, w.Result().StatusCode)
+		require.True(t, cbCall, "add configuration callback has not been called")
+	})
+}
+
+func Test_trustBaseHandler(t *testing.T) {
+	t.Run("invalid request body", func(t *testing.T) {
+		hf := putTrustBaseHandler(func(tb *types.RootTrustBaseV1) (uint64, error) {
+			t.Error("unexpected call of addTrustBase callback")
+			return 0, nil
+		})
+		w := httptest.NewRecorder()
+		hf(w, httptest.NewRequest(http.MethodPut, "/api/v1/trustbases", bytes.NewBufferString("null")))
+		res := w.Result()
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.EqualValues(t, http.StatusBadRequest, res.StatusCode)
+		require.Equal(t, `parsing request body: trust base is nil`, string(body))
+	})
+
+	tbJson, err := json.Marshal(&types.RootTrustBaseV1{Version: 1, Epoch: 2, EpochStartRound: 100})
+	require.NoError(t, err)
+
+	t.Run("scheduling fails", func(t *testing.T) {
+		hf := putTrustBaseHandler(func(tb *types.RootTrustBaseV1) (uint64, error) {
+			return 0, fmt.Errorf("not signed")
+		})
+		w := httptest.NewRecorder()
+		hf(w, httptest.NewRequest(http.MethodPut, "/api/v1/trustbases", bytes.NewBuffer(tbJson)))
+		res := w.Result()
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.EqualValues(t, http.StatusInternalServerError, res.StatusCode)
+		require.Equal(t, `scheduling trust base: not signed`, string(body))
+	})
+
+	t.Run("success", func(t *testing.T) {
+		hf := putTrustBaseHandler(func(tb *types.RootTrustBaseV1) (uint64, error) {
+			require.EqualValues(t, 100, tb.EpochStartRound)
+			return 1, nil
+		})
+		w := httptest.NewRecorder()
+		hf(w, httptest.NewRequest(http.MethodPut, "/api/v1/trustbases", bytes.NewBuffer(tbJson)))
+		res := w.Result()
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.EqualValues(t, http.StatusOK, res.StatusCode)
+		require.JSONEq(t, `{"epoch":"1"}`, string(body))
+	})
+}
+
 func Test_roundInfoHandler(t *testing.T) {
 	t.Run("state provider error", func(t *testing.T) {
 		hf := getRoundInfoHandler(func() (*abdrc.StateMsg, error) {
@@ -290,6 +377,42 @@ func Test_roundInfoHandler(t *testing.T) {
 	})
 }
 
+func Test_evidenceHandler(t *testing.T) {
+	t.Run("store error", func(t *testing.T) {
+		hf := getEvidenceHandler(func() ([]*evidence.Evidence, error) {
+			return nil, fmt.Errorf("some error")
+		}, observability.Default(t))
+		res, body := doRequest(t, hf, http.MethodGet, "/api/v1/evidence")
+		require.EqualValues(t, http.StatusInternalServerError, res.StatusCode)
+		require.Equal(t, "failed to load evidence\n", string(body))
+	})
+
+	t.Run("no evidence", func(t *testing.T) {
+		hf := getEvidenceHandler(func() ([]*evidence.Evidence, error) {
+			return nil, nil
+		}, observability.Default(t))
+		res, body := doRequest(t, hf, http.MethodGet, "/api/v1/evidence")
+		require.EqualValues(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, "[]\n", string(body))
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		hf := getEvidenceHandler(func() ([]*evidence.Evidence, error) {
+			return []*evidence.Evidence{{Kind: evidence.KindVote, NodeID: "node1", Round: 5}}, nil
+		}, observability.Default(t))
+		res, body := doRequest(t, hf, http.MethodGet, "/api/v1/evidence")
+		require.EqualValues(t, http.StatusOK, res.StatusCode)
+		require.EqualValues(t, "application/json", res.Header.Get("Content-Type"))
+
+		var actual []*evidence.Evidence
+		require.NoError(t, json.Unmarshal(body, &actual))
+		require.Len(t, actual, 1)
+		require.Equal(t, evidence.KindVote, actual[0].Kind)
+		require.Equal(t, "node1", actual[0].NodeID)
+		require.EqualValues(t, 5, actual[0].Round)
+	})
+}
+
 func doRequest(t *testing.T, hf http.HandlerFunc, method, path string) (*http.Response, []byte) {
 	req := httptest.NewRequest(method, path, nil)
 	rec := httptest.NewRecorder()
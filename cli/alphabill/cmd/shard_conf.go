@@ -23,6 +23,7 @@ func newShardConfCmd(baseConfig *baseFlags) *cobra.Command {
 	}
 	cmd.AddCommand(shardConfGenerateCmd(baseConfig))
 	cmd.AddCommand(shardConfGenesisCmd(baseConfig))
+	cmd.AddCommand(shardConfSignCmd(baseConfig))
 	return cmd
 }
 
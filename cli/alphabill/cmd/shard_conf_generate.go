@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/alphabill-org/alphabill-go-base/types"
 	"github.com/alphabill-org/alphabill-go-base/util"
+	"github.com/alphabill-org/alphabill/partition"
 	"github.com/spf13/cobra"
 )
 
@@ -29,6 +31,10 @@ type (
 		EpochStart      uint64
 		NodeInfoFiles   []string
 
+		BlockMaxTxCount uint32
+		BlockMaxSize    uint64
+		BlockMaxGas     uint64
+
 		MoneyInitialBillOwnerPredicate string
 		TokensAdminOwnerPredicate      string
 		TokensFeelessMode              bool
@@ -55,6 +61,9 @@ func shardConfGenerateCmd(baseFlags *baseFlags) *cobra.Command {
 	if err := cmd.MarkFlagRequired("epoch-start"); err != nil {
 		panic(err)
 	}
+	cmd.Flags().Uint32Var(&flags.BlockMaxTxCount, "block-max-tx-count", 0, "maximum number of transactions in a block, 0 means unlimited")
+	cmd.Flags().Uint64Var(&flags.BlockMaxSize, "block-max-size", 0, "maximum total size of transactions in a block in bytes, 0 means unlimited")
+	cmd.Flags().Uint64Var(&flags.BlockMaxGas, "block-max-gas", 0, "maximum total gas of transactions in a block, 0 means unlimited")
 	cmd.Flags().StringSliceVarP(&flags.NodeInfoFiles, "node-info", "n", []string{}, "path to node info files")
 	cmd.Flags().StringVar(&flags.MoneyInitialBillOwnerPredicate, "initial-bill-owner-predicate", "",
 		"initial bill owner predicate (money partition only)")
@@ -116,9 +125,21 @@ func shardConfGenerate(flags *ShardConfGenerateFlags) error {
 }
 
 func defaultPartitionParams(partitionTypeID types.PartitionTypeID, flags *ShardConfGenerateFlags) map[string]string {
-	partition, ok := flags.baseFlags.partitions[partitionTypeID]
-	if !ok {
-		return make(map[string]string, 1)
+	var params map[string]string
+	if p, ok := flags.baseFlags.partitions[partitionTypeID]; ok {
+		params = p.DefaultPartitionParams(flags)
+	} else {
+		params = make(map[string]string, 1)
+	}
+
+	if flags.BlockMaxTxCount > 0 {
+		params[partition.BlockMaxTxCountParam] = strconv.FormatUint(uint64(flags.BlockMaxTxCount), 10)
+	}
+	if flags.BlockMaxSize > 0 {
+		params[partition.BlockMaxSizeParam] = strconv.FormatUint(flags.BlockMaxSize, 10)
+	}
+	if flags.BlockMaxGas > 0 {
+		params[partition.BlockMaxGasParam] = strconv.FormatUint(flags.BlockMaxGas, 10)
 	}
-	return partition.DefaultPartitionParams(flags)
+	return params
 }
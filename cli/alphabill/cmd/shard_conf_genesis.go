@@ -86,6 +86,12 @@ func newMoneyGenesisState(pdr *types.PartitionDescriptionRecord) (*state.State,
 		return nil, fmt.Errorf("could not set DC money supply: %w", err)
 	}
 
+	if params.RewardPoolValue > 0 {
+		if err := addInitialRewardPool(s, params); err != nil {
+			return nil, fmt.Errorf("could not set reward pool: %w", err)
+		}
+	}
+
 	return s, nil
 }
 
@@ -107,6 +113,15 @@ func addInitialDustCollectorMoneySupply(s *state.State, params *MoneyPartitionPa
 	return err
 }
 
+func addInitialRewardPool(s *state.State, params *MoneyPartitionParams) error {
+	billData := money.NewBillData(params.RewardPoolValue, abmoney.RewardPoolPredicate)
+	err := s.Apply(state.AddUnit(abmoney.RewardPoolMoneySupplyID, billData))
+	if err == nil {
+		err = s.AddUnitLog(abmoney.RewardPoolMoneySupplyID, nil)
+	}
+	return err
+}
+
 func writeStateFile(path string, s *state.State) error {
 	stateFile, err := os.Create(filepath.Clean(path))
 	if err != nil {
@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
 	"testing"
@@ -17,6 +18,7 @@ import (
 	"github.com/alphabill-org/alphabill-go-base/types"
 	"github.com/alphabill-org/alphabill-go-base/util"
 	testobserve "github.com/alphabill-org/alphabill/internal/testutils/observability"
+	abmoney "github.com/alphabill-org/alphabill/txsystem/money"
 )
 
 var defaultMoneyShardConf = &types.PartitionDescriptionRecord{
@@ -76,6 +78,25 @@ func Test_MoneyGenesis(t *testing.T) {
 		require.NoError(t, cmd.Execute(context.Background()))
 	})
 
+	t.Run("MoneyGenesisStateWithRewardPool", func(t *testing.T) {
+		shardConf := *shardConf
+		shardConf.PartitionParams = maps.Clone(shardConf.PartitionParams)
+		shardConf.PartitionParams[moneyRewardPoolValue] = "3"
+		shardConf.PartitionParams[abmoney.RewardOwnerParamPrefix+"16Uiu2HAm"] = fmt.Sprintf("0x%x", templates.AlwaysTrueBytes())
+
+		s, err := newMoneyGenesisState(&shardConf)
+		require.NoError(t, err)
+		unit, err := s.GetUnit(abmoney.RewardPoolMoneySupplyID, true)
+		require.NoError(t, err)
+		bd, ok := unit.Data().(*moneysdk.BillData)
+		require.True(t, ok)
+		require.EqualValues(t, 3, bd.Value)
+		require.EqualValues(t, abmoney.RewardPoolPredicate, bd.OwnerPredicate)
+		// the reward pool doesn't replace the initial bill
+		_, err = s.GetUnit(moneyPartitionInitialBillID, true)
+		require.NoError(t, err)
+	})
+
 	t.Run("TokensGenesisState", func(t *testing.T) {
 		adminOwnerPredicate := "830041025820f34a250bf4f2d3a432a43381cecc4ab071224d9ceccb6277b5779b937f59055f"
 		shardConf := &types.PartitionDescriptionRecord{
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alphabill-org/alphabill-go-base/util"
+
+	"github.com/alphabill-org/alphabill/rootchain/partitions"
+)
+
+const signedShardConfFileName = "signed-shard-conf.json"
+
+type (
+	shardConfSignFlags struct {
+		*baseFlags
+		keyConfFlags
+		shardConfFlags
+
+		SignedShardConfFile string
+	}
+
+	governanceFlags struct {
+		GovernanceFile         string
+		AllowUnsignedShardConf bool
+	}
+)
+
+func shardConfSignCmd(baseFlags *baseFlags) *cobra.Command {
+	flags := &shardConfSignFlags{baseFlags: baseFlags}
+	var cmd = &cobra.Command{
+		Use:   "sign",
+		Short: "Approve a shard configuration change by signing it with the governance key",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return shardConfSign(flags)
+		},
+	}
+	flags.addKeyConfFlags(cmd, false)
+	flags.addShardConfFlags(cmd)
+	cmd.Flags().StringVar(&flags.SignedShardConfFile, "signed-shard-conf", "",
+		fmt.Sprintf("path to the signed shard conf, the signature is added when the file exists (default: %s)", filepath.Join("$AB_HOME", signedShardConfFileName)))
+	return cmd
+}
+
+func shardConfSign(flags *shardConfSignFlags) error {
+	keyConf, err := flags.loadKeyConf(flags.baseFlags, false)
+	if err != nil {
+		return err
+	}
+	nodeID, err := keyConf.NodeID()
+	if err != nil {
+		return fmt.Errorf("failed to get node id: %w", err)
+	}
+	signer, err := keyConf.Signer()
+	if err != nil {
+		return err
+	}
+
+	signedShardConfPath := flags.PathWithDefault(flags.SignedShardConfFile, signedShardConfFileName)
+	signedShardConf := &partitions.SignedShardConf{}
+	if util.FileExists(signedShardConfPath) {
+		if signedShardConf, err = util.ReadJsonFile(signedShardConfPath, &partitions.SignedShardConf{}); err != nil {
+			return fmt.Errorf("failed to read signed shard conf: %w", err)
+		}
+	} else if signedShardConf.ShardConf, err = flags.loadShardConf(flags.baseFlags); err != nil {
+		return err
+	}
+
+	if err = signedShardConf.Sign(nodeID.String(), signer); err != nil {
+		return fmt.Errorf("failed to sign shard conf: %w", err)
+	}
+	if err = util.WriteJsonFile(signedShardConfPath, signedShardConf); err != nil {
+		return fmt.Errorf("failed to save signed shard conf: %w", err)
+	}
+	return nil
+}
+
+func (f *governanceFlags) addGovernanceFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.GovernanceFile, "governance", "",
+		"path to the governance file (approvers and quorum), when set the shard conf changes submitted over RPC must be signed by the quorum of the approvers")
+	cmd.Flags().BoolVar(&f.AllowUnsignedShardConf, "allow-unsigned-shard-conf", false,
+		"accept the shard conf changes submitted over RPC without governance approvals, must not be used together with --governance")
+}
+
+/*
+loadGovernance returns the governance of the shard conf changes, nil when the
+governance file is not configured.
+*/
+func (f *governanceFlags) loadGovernance() (*partitions.Governance, error) {
+	if f.GovernanceFile == "" {
+		return nil, nil
+	}
+	if f.AllowUnsignedShardConf {
+		return nil, errors.New("--allow-unsigned-shard-conf can't be used together with --governance")
+	}
+	gov, err := util.ReadJsonFile(f.GovernanceFile, &partitions.Governance{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read governance file %q: %w", f.GovernanceFile, err)
+	}
+	governance, err := partitions.NewGovernance(gov.Quorum, gov.Approvers)
+	if err != nil {
+		return nil, fmt.Errorf("invalid governance file %q: %w", f.GovernanceFile, err)
+	}
+	return governance, nil
+}
+
+/*
+warnUnsignedShardConf logs a warning when the shard conf changes submitted over RPC
+are not protected by the governance: the changes are either accepted from anyone who
+can reach the RPC port or, unless explicitly allowed, not accepted at all.
+*/
+func (f *governanceFlags) warnUnsignedShardConf(ctx context.Context, log *slog.Logger) {
+	switch {
+	case f.GovernanceFile != "":
+	case f.AllowUnsignedShardConf:
+		log.WarnContext(ctx, "shard conf changes submitted over RPC are accepted without governance approvals (--allow-unsigned-shard-conf)")
+	default:
+		log.WarnContext(ctx, "shard conf changes over RPC are disabled, use --governance to require approvals or --allow-unsigned-shard-conf to accept unsigned changes")
+	}
+}
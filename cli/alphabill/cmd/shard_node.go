@@ -14,5 +14,6 @@ func newShardNodeCmd(baseFlags *baseFlags, shardNodeRunFn nodeRunnable) *cobra.C
 	}
 	cmd.AddCommand(shardNodeInitCmd(baseFlags))
 	cmd.AddCommand(shardNodeRunCmd(baseFlags, shardNodeRunFn))
+	cmd.AddCommand(shardNodeVerifyChainCmd(baseFlags))
 	return cmd
 }
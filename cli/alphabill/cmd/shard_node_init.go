@@ -3,7 +3,9 @@ package cmd
 import (
 	"context"
 	"crypto/rand"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 
@@ -24,8 +26,10 @@ const (
 
 type (
 	keyConfFlags struct {
-		KeyConfFile string
-		Generate    bool
+		KeyConfFile    string
+		Generate       bool
+		Encrypt        bool
+		PassphraseFile string
 	}
 
 	shardNodeInitFlags struct {
@@ -88,12 +92,20 @@ func shardNodeInit(_ context.Context, flags *shardNodeInitFlags) error {
 func (c *keyConfFlags) addKeyConfFlags(cmd *cobra.Command, enableGenerate bool) {
 	if enableGenerate {
 		cmd.Flags().BoolVarP(&c.Generate, "generate", "g", false, "generate a new key configuration if none exist")
+		cmd.Flags().BoolVar(&c.Encrypt, "encrypt-keys", false,
+			fmt.Sprintf("encrypt the generated keys with a passphrase, implied when --key-passphrase-file or %s is set", envKeyPassphrase))
 	}
 	cmd.Flags().StringVarP(&c.KeyConfFile, "key-conf", "k", "",
 		fmt.Sprintf("path to the key configuration file (default: %s)", filepath.Join("$AB_HOME", keyConfFileName)))
+	cmd.Flags().StringVar(&c.PassphraseFile, "key-passphrase-file", "",
+		fmt.Sprintf("path to the file containing the passphrase of the encrypted key configuration, %s or prompt is used when not set", envKeyPassphrase))
 }
 
-func (c *keyConfFlags) loadKeyConf(baseFlags *baseFlags, generate bool) (ret *partition.KeyConf, err error) {
+/*
+loadKeyConf loads the key configuration, the encrypted key configuration is decrypted
+with the passphrase. Generates new keys when "generate" is true and the file doesn't exist.
+*/
+func (c *keyConfFlags) loadKeyConf(baseFlags *baseFlags, generate bool) (*partition.KeyConf, error) {
 	keyConfPath := baseFlags.PathWithDefault(c.KeyConfFile, keyConfFileName)
 
 	if generate && !util.FileExists(keyConfPath) {
@@ -105,13 +117,30 @@ func (c *keyConfFlags) loadKeyConf(baseFlags *baseFlags, generate bool) (ret *pa
 		if err != nil {
 			return nil, err
 		}
-		if err := util.WriteJsonFile(keyConfPath, keyConf); err != nil {
+		var fileContent any = keyConf
+		if c.encryptionRequested() {
+			passphrase, err := c.passphrase(true)
+			if err != nil {
+				return nil, err
+			}
+			if fileContent, err = encryptKeyConf(keyConf, passphrase); err != nil {
+				return nil, err
+			}
+		}
+		if err := writeKeyConfFile(keyConfPath, fileContent); err != nil {
 			return nil, err
 		}
 		return keyConf, nil
 	}
 
-	return ret, baseFlags.loadConf(keyConfPath, keyConfFileName, &ret)
+	if err := checkKeyFilePermissions(keyConfPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	keyConf, err := readKeyConfFile(keyConfPath, c.passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %q: %w", keyConfPath, err)
+	}
+	return keyConf, nil
 }
 
 func generateKeys() (*partition.KeyConf, error) {
@@ -34,6 +34,8 @@ type ShardNodeRunFlags struct {
 	trustBaseFlags
 	p2pFlags
 	rpcFlags
+	remoteSignerFlags
+	governanceFlags
 
 	StateFile      string
 	BlockStoreFile string
@@ -49,6 +51,7 @@ type ShardNodeRunFlags struct {
 	LedgerReplicationTimeoutMs      uint32
 	BlockSubscriptionTimeoutMs      uint32
 	T1TimeoutMs                     uint32
+	TxPrevalidationWorkers          int
 }
 
 func shardNodeRunCmd(baseFlags *baseFlags, shardNodeRunFn nodeRunnable) *cobra.Command {
@@ -70,6 +73,8 @@ func shardNodeRunCmd(baseFlags *baseFlags, shardNodeRunFn nodeRunnable) *cobra.C
 	flags.addShardConfFlags(cmd)
 	flags.addP2PFlags(cmd)
 	flags.addRPCFlags(cmd)
+	flags.addRemoteSignerFlags(cmd)
+	flags.addGovernanceFlags(cmd)
 
 	cmd.Flags().StringVarP(&flags.StateFile, "state", "", "",
 		fmt.Sprintf("path to the state file (default %s)", filepath.Join("$AB_HOME", StateFileName)))
@@ -94,12 +99,20 @@ func shardNodeRunCmd(baseFlags *baseFlags, shardNodeRunFn nodeRunnable) *cobra.C
 	cmd.Flags().Uint32Var(&flags.BlockSubscriptionTimeoutMs, "block-subscription-timeout", 3000,
 		"time since last received block when when to trigger recovery (in ms) for non-validating nodes")
 	cmd.Flags().Uint32Var(&flags.T1TimeoutMs, "t1-timeout", partition.DefaultT1Timeout, "T1 timeout (consensus parameter)")
+	cmd.Flags().IntVar(&flags.TxPrevalidationWorkers, "tx-prevalidation-workers", 0,
+		"number of workers verifying signatures of incoming transactions (default number of CPUs)")
+	cmd.Flags().IntVar(&flags.ParallelExecutionWorkers, "parallel-execution-workers", 0,
+		"number of workers executing non-conflicting transactions of a block in parallel, supported by money partition (default 0, ie disabled)")
 
 	hideFlags(cmd, "t1-timeout")
 	return cmd
 }
 
 func shardNodeRun(ctx context.Context, flags *ShardNodeRunFlags) error {
+	governance, err := flags.loadGovernance()
+	if err != nil {
+		return err
+	}
 	node, nodeConf, err := createNode(ctx, flags)
 	if err != nil {
 		return fmt.Errorf("failed to create node: %w", err)
@@ -110,6 +123,9 @@ func shardNodeRun(ctx context.Context, flags *ShardNodeRunFlags) error {
 	partitionType := partitionTypeIDToString(node.PartitionTypeID(), flags)
 
 	log.InfoContext(ctx, fmt.Sprintf("starting %s node: BuildInfo=%s", partitionType, debug.ReadBuildInfo()))
+	if !flags.rpcFlags.IsAddressEmpty() {
+		flags.warnUnsignedShardConf(ctx, log)
+	}
 	g, ctx := errgroup.WithContext(ctx)
 
 	g.Go(func() error { return node.Run(ctx) })
@@ -120,7 +136,7 @@ func shardNodeRun(ctx context.Context, flags *ShardNodeRunFlags) error {
 		}
 		routers := []rpc.Registrar{
 			rpc.MetricsEndpoints(obs.PrometheusRegisterer()),
-			rpc.NodeEndpoints(node, obs),
+			rpc.NodeEndpoints(node, governance, flags.AllowUnsignedShardConf, obs),
 		}
 		if flags.rpcFlags.Router != nil {
 			routers = append(routers, flags.rpcFlags.Router)
@@ -208,6 +224,10 @@ func createNode(ctx context.Context, flags *ShardNodeRunFlags) (*partition.Node,
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to calculate nodeID: %w", err)
 	}
+	signer, err := flags.signer(ctx, keyConf)
+	if err != nil {
+		return nil, nil, err
+	}
 	log := flags.observe.Logger().With(
 		logger.NodeID(nodeID),
 		logger.Shard(shardConf.PartitionID, shardConf.ShardID))
@@ -228,6 +248,7 @@ func createNode(ctx context.Context, flags *ShardNodeRunFlags) (*partition.Node,
 		shardConf,
 		trustBase,
 		obs,
+		partition.WithSigner(signer),
 		partition.WithAddress(flags.p2pFlags.Address),
 		partition.WithAnnounceAddresses(flags.AnnounceAddresses),
 		partition.WithBootstrapAddresses(flags.BootstrapAddresses),
@@ -243,6 +264,8 @@ func createNode(ctx context.Context, flags *ShardNodeRunFlags) (*partition.Node,
 		partition.WithOwnerIndex(ownerIndexer),
 		partition.WithBlockSubscriptionTimeout(time.Duration(flags.BlockSubscriptionTimeoutMs)*time.Millisecond),
 		partition.WithT1Timeout(time.Duration(flags.T1TimeoutMs)*time.Millisecond),
+		partition.WithTxPrevalidationWorkers(flags.TxPrevalidationWorkers),
+		partition.WithParallelExecution(flags.ParallelExecutionWorkers),
 	)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create node configuration: %w", err)
@@ -491,14 +491,3 @@ func sendTokensTx(t *testing.T, ctx context.Context, rpcClient *ethrpc.Client) {
 // 	require.NoError(t, err)
 // 	require.NotNil(t, res)
 // }
-
-func buildRpcUrl(url string) string {
-	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
-		url = "http://" + url
-	}
-	url = strings.TrimSuffix(url, "/")
-	if !strings.HasSuffix(url, "/rpc") {
-		url = url + "/rpc"
-	}
-	return url
-}
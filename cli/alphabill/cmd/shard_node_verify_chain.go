@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alphabill-org/alphabill/partition"
+)
+
+type shardNodeVerifyChainFlags struct {
+	*baseFlags
+	shardConfFlags
+	trustBaseFlags
+
+	StateFile      string
+	BlockStoreFile string
+	ShardStoreFile string
+}
+
+func shardNodeVerifyChainCmd(baseFlags *baseFlags) *cobra.Command {
+	flags := &shardNodeVerifyChainFlags{baseFlags: baseFlags}
+	var cmd = &cobra.Command{
+		Use:   "verify-chain",
+		Short: "Replays the blocks and verifies them against the unicity certificates",
+		Long: `Re-executes the blocks of the block database on top of the state file and checks
+that the state root, summary value and block hash of each block match the input
+record certified by the unicity certificate of the block. Reports the first round
+(and unit) where the replayed chain diverges from the certified one.
+
+The shard configurations and root trust bases of the later epochs are read from
+the shard configuration database of the node, when it exists.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return shardNodeVerifyChain(cmd.Context(), flags, cmd.OutOrStdout())
+		},
+	}
+
+	flags.addTrustBaseFlags(cmd)
+	flags.addShardConfFlags(cmd)
+	cmd.Flags().StringVarP(&flags.StateFile, "state", "", "",
+		fmt.Sprintf("path to the state file to start the replay from (default %s)", filepath.Join("$AB_HOME", StateFileName)))
+	cmd.Flags().StringVarP(&flags.BlockStoreFile, "block-db", "", "",
+		fmt.Sprintf("path to the block database (default %s)", filepath.Join("$AB_HOME", blockStoreFileName)))
+	cmd.Flags().StringVarP(&flags.ShardStoreFile, "shard-db", "", "",
+		fmt.Sprintf("path to the shard configuration database (default %s)", filepath.Join("$AB_HOME", shardStoreFileName)))
+	return cmd
+}
+
+func shardNodeVerifyChain(ctx context.Context, flags *shardNodeVerifyChainFlags, out io.Writer) error {
+	shardConf, err := flags.loadShardConf(flags.baseFlags)
+	if err != nil {
+		return err
+	}
+	trustBase, err := flags.loadTrustBase(flags.baseFlags)
+	if err != nil {
+		return err
+	}
+	blockStore, err := flags.openStore(flags.BlockStoreFile, blockStoreFileName)
+	if err != nil {
+		return err
+	}
+	opts := []partition.NodeOption{partition.WithBlockStore(blockStore)}
+	// without the shard store only the epochs of the shard conf and the trust base
+	// given by the flags are known
+	shardStore, err := flags.openStore(flags.ShardStoreFile, shardStoreFileName)
+	switch {
+	case err == nil:
+		opts = append(opts, partition.WithShardStore(shardStore))
+	case !errors.Is(err, fs.ErrNotExist):
+		return err
+	}
+
+	// the node keys are not used for the replay but required by the node configuration
+	keyConf, err := generateKeys()
+	if err != nil {
+		return fmt.Errorf("failed to generate keys: %w", err)
+	}
+	nodeConf, err := partition.NewNodeConf(keyConf, shardConf, trustBase, flags.observe, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to create node configuration: %w", err)
+	}
+	txSystem, err := createTxSystem(&ShardNodeRunFlags{baseFlags: flags.baseFlags, StateFile: flags.StateFile}, nodeConf)
+	if err != nil {
+		return err
+	}
+	ucValidator, err := partition.NewUnicityCertificateValidator(shardConf.PartitionID, shardConf.ShardID, nodeConf.Orchestration(), nodeConf.HashAlgorithm())
+	if err != nil {
+		return fmt.Errorf("failed to create unicity certificate validator: %w", err)
+	}
+	shardConfs := partition.NewEpochShardConfs(shardConf, shardStore)
+
+	lastRound, err := partition.VerifyChain(ctx, txSystem, blockStore, ucValidator, shardConfs, nodeConf.HashAlgorithm(), flags.observe.Logger())
+	if err != nil {
+		var d *partition.ChainDivergence
+		if errors.As(err, &d) {
+			fmt.Fprintf(out, "chain diverges in round %d", d.Round)
+			if d.TxIndex >= 0 {
+				fmt.Fprintf(out, ", transaction %d", d.TxIndex)
+			}
+			if d.UnitID != nil {
+				fmt.Fprintf(out, ", unit %s", d.UnitID)
+			}
+			fmt.Fprintln(out)
+			for _, id := range d.Units {
+				fmt.Fprintf(out, "target unit of the round: %s\n", id)
+			}
+		}
+		return fmt.Errorf("verified up to round %d: %w", lastRound, err)
+	}
+	fmt.Fprintf(out, "chain verified up to round %d\n", lastRound)
+	return nil
+}
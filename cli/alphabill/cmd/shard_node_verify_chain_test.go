@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/util"
+	testobserve "github.com/alphabill-org/alphabill/internal/testutils/observability"
+	testsig "github.com/alphabill-org/alphabill/internal/testutils/sig"
+	"github.com/alphabill-org/alphabill/internal/testutils/trustbase"
+)
+
+func Test_ShardNodeVerifyChain(t *testing.T) {
+	signer, verifier := testsig.CreateSignerAndVerifier(t)
+	homeDir := writeShardConf(t, defaultMoneyShardConf)
+	require.NoError(t, util.WriteJsonFile(filepath.Join(homeDir, trustBaseFileName), trustbase.NewTrustBase(t, verifier)))
+
+	run := func(t *testing.T, args ...string) (string, error) {
+		out := &bytes.Buffer{}
+		cmd := New(testobserve.NewFactory(t))
+		cmd.baseCmd.SetOut(out)
+		cmd.baseCmd.SetArgs(append(args, "--home", homeDir))
+		err := cmd.Execute(context.Background())
+		return out.String(), err
+	}
+
+	_, err := run(t, "shard-conf", "genesis")
+	require.NoError(t, err)
+
+	t.Run("block database missing", func(t *testing.T) {
+		blockDB := filepath.Join(t.TempDir(), "blocks.db")
+		_, err := run(t, "shard-node", "verify-chain", "--block-db", blockDB)
+		require.ErrorIs(t, err, fs.ErrNotExist)
+		require.NoFileExists(t, blockDB)
+	})
+
+	t.Run("empty block database", func(t *testing.T) {
+		blockDB := filepath.Join(t.TempDir(), "blocks.db")
+		writeTestBlocks(t, blockDB)
+		out, err := run(t, "shard-node", "verify-chain", "--block-db", blockDB)
+		require.NoError(t, err)
+		require.Equal(t, "chain verified up to round 0\n", out)
+	})
+
+	t.Run("chain diverges", func(t *testing.T) {
+		// the blocks are certified but do not extend the genesis state
+		blockDB := filepath.Join(t.TempDir(), "blocks.db")
+		writeTestBlocks(t, blockDB, createTestBlocks(t, signer, defaultMoneyShardConf, 2)...)
+		out, err := run(t, "shard-node", "verify-chain", "--block-db", blockDB)
+		require.ErrorContains(t, err, "verified up to round 0")
+		require.Contains(t, out, "chain diverges in round 1")
+	})
+}
@@ -0,0 +1,299 @@
+package cmd
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alphabill-org/alphabill-go-base/cbor"
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill-go-base/types/hex"
+
+	"github.com/alphabill-org/alphabill/state"
+)
+
+type (
+	stateFlags struct {
+		*baseFlags
+		shardConfFlags
+	}
+
+	stateInspectFlags struct {
+		stateFlags
+		StateFile string
+		UnitID    string
+	}
+
+	// stateSummary is the output of the "state inspect" command
+	stateSummary struct {
+		RoundNumber              uint64             `json:"roundNumber"` // round of the UC, 0 for genesis state
+		NodeRecordCount          uint64             `json:"nodeRecordCount"`
+		ExecutedTransactionCount int                `json:"executedTransactionCount"`
+		RootHash                 hex.Bytes          `json:"rootHash"`
+		SummaryValue             uint64             `json:"summaryValue"`
+		UnitTypes                []*unitTypeSummary `json:"unitTypes"`
+	}
+
+	unitTypeSummary struct {
+		UnitType     uint32 `json:"unitType"`
+		UnitCount    uint64 `json:"unitCount"`
+		SummaryValue uint64 `json:"summaryValue"`
+	}
+
+	// unitInfo is the output of the "state inspect --unit-id" command
+	unitInfo struct {
+		UnitID      hex.Bytes      `json:"unitId"`
+		Data        types.UnitData `json:"data"`
+		StateLockTx hex.Bytes      `json:"stateLockTx,omitempty"`
+	}
+
+	// stateDiff lists the units which differ in two states, in the order of unit IDs
+	stateDiff struct {
+		Removed []types.UnitID // units only in the first state
+		Added   []types.UnitID // units only in the second state
+		Changed []types.UnitID // units with different data or state lock
+	}
+
+	encodedUnit struct {
+		id          types.UnitID
+		data        []byte
+		stateLockTx []byte
+	}
+)
+
+func newStateCmd(baseFlags *baseFlags) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "state",
+		Short: "Tools to work with state files",
+	}
+	cmd.AddCommand(stateInspectCmd(baseFlags))
+	cmd.AddCommand(stateDiffCmd(baseFlags))
+	return cmd
+}
+
+func stateInspectCmd(baseFlags *baseFlags) *cobra.Command {
+	flags := &stateInspectFlags{stateFlags: stateFlags{baseFlags: baseFlags}}
+	var cmd = &cobra.Command{
+		Use:   "inspect",
+		Short: "Prints the summary of a state file or the data of a unit",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return stateInspect(cmd.OutOrStdout(), flags)
+		},
+	}
+	flags.addShardConfFlags(cmd)
+	cmd.Flags().StringVarP(&flags.StateFile, "state", "", "",
+		fmt.Sprintf("path to the state file (default %s)", filepath.Join("$AB_HOME", StateFileName)))
+	cmd.Flags().StringVarP(&flags.UnitID, "unit-id", "u", "", "hex encoded ID of the unit to print")
+	return cmd
+}
+
+func stateDiffCmd(baseFlags *baseFlags) *cobra.Command {
+	flags := &stateFlags{baseFlags: baseFlags}
+	var cmd = &cobra.Command{
+		Use:   "diff <state file> <state file>",
+		Short: "Compares two state files of a shard unit by unit",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return stateDiffFiles(cmd.OutOrStdout(), flags, args[0], args[1])
+		},
+	}
+	flags.addShardConfFlags(cmd)
+	return cmd
+}
+
+func stateInspect(out io.Writer, flags *stateInspectFlags) error {
+	shardConf, err := flags.loadShardConf(flags.baseFlags)
+	if err != nil {
+		return err
+	}
+	s, header, err := flags.loadState(flags.PathWithDefault(flags.StateFile, StateFileName), shardConf)
+	if err != nil {
+		return err
+	}
+
+	if flags.UnitID != "" {
+		unitID, err := hex.Decode([]byte(flags.UnitID))
+		if err != nil {
+			return fmt.Errorf("invalid unit ID: %w", err)
+		}
+		unit, err := s.GetUnit(unitID, true)
+		if err != nil {
+			return fmt.Errorf("failed to load unit: %w", err)
+		}
+		u, err := state.ToUnitV1(unit)
+		if err != nil {
+			return err
+		}
+		return writeJSON(out, &unitInfo{UnitID: unitID, Data: u.Data(), StateLockTx: u.StateLockTx()})
+	}
+
+	summary, err := summarizeState(s, header, shardConf)
+	if err != nil {
+		return err
+	}
+	return writeJSON(out, summary)
+}
+
+func stateDiffFiles(out io.Writer, flags *stateFlags, path1, path2 string) error {
+	shardConf, err := flags.loadShardConf(flags.baseFlags)
+	if err != nil {
+		return err
+	}
+	s1, header1, err := flags.loadState(path1, shardConf)
+	if err != nil {
+		return err
+	}
+	s2, header2, err := flags.loadState(path2, shardConf)
+	if err != nil {
+		return err
+	}
+
+	if r1, r2 := header1.UnicityCertificate.GetRoundNumber(), header2.UnicityCertificate.GetRoundNumber(); r1 != r2 {
+		fmt.Fprintf(out, "round: %d != %d\n", r1, r2)
+	}
+	diff, err := diffStates(s1, s2)
+	if err != nil {
+		return err
+	}
+	for _, id := range diff.Removed {
+		fmt.Fprintf(out, "- %s\n", id)
+	}
+	for _, id := range diff.Added {
+		fmt.Fprintf(out, "+ %s\n", id)
+	}
+	for _, id := range diff.Changed {
+		fmt.Fprintf(out, "~ %s\n", id)
+	}
+	if len(diff.Removed)+len(diff.Added)+len(diff.Changed) == 0 {
+		fmt.Fprintln(out, "units are equal")
+	}
+	return nil
+}
+
+func (f *stateFlags) loadState(path string, shardConf *types.PartitionDescriptionRecord) (*state.State, *state.Header, error) {
+	partition, ok := f.partitions[shardConf.PartitionTypeID]
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported partition type %d", shardConf.PartitionTypeID)
+	}
+	s, header, err := loadStateFile(path, partition.UnitDataConstructor(shardConf))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load state file: %w", err)
+	}
+	return s, header, nil
+}
+
+func summarizeState(s *state.State, header *state.Header, shardConf *types.PartitionDescriptionRecord) (*stateSummary, error) {
+	summaryValue, rootHash, err := s.CalculateRoot()
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate state root: %w", err)
+	}
+	summary := &stateSummary{
+		RoundNumber:              header.UnicityCertificate.GetRoundNumber(),
+		NodeRecordCount:          header.NodeRecordCount,
+		ExecutedTransactionCount: len(header.ExecutedTransactions),
+		RootHash:                 rootHash,
+		SummaryValue:             summaryValue,
+	}
+	unitTypes := make(map[uint32]*unitTypeSummary)
+	err = s.Traverse(state.NewInorderTraverser(func(unitID types.UnitID, unit state.Unit) error {
+		unitType, err := shardConf.ExtractUnitType(unitID)
+		if err != nil {
+			return fmt.Errorf("extracting type of unit %s: %w", unitID, err)
+		}
+		u, err := state.ToUnitV1(unit)
+		if err != nil {
+			return err
+		}
+		ts, ok := unitTypes[unitType]
+		if !ok {
+			ts = &unitTypeSummary{UnitType: unitType}
+			unitTypes[unitType] = ts
+			summary.UnitTypes = append(summary.UnitTypes, ts)
+		}
+		ts.UnitCount++
+		ts.SummaryValue += u.Data().SummaryValueInput()
+		return nil
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to traverse state: %w", err)
+	}
+	slices.SortFunc(summary.UnitTypes, func(a, b *unitTypeSummary) int { return cmp.Compare(a.UnitType, b.UnitType) })
+	return summary, nil
+}
+
+/*
+diffStates compares the committed units of the states, the units are equal when
+the CBOR encoding of the unit data and the state lock transaction are equal.
+*/
+func diffStates(s1, s2 *state.State) (*stateDiff, error) {
+	units1, err := stateUnits(s1)
+	if err != nil {
+		return nil, err
+	}
+	units2, err := stateUnits(s2)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &stateDiff{}
+	i, j := 0, 0
+	for i < len(units1) || j < len(units2) {
+		var c int
+		switch {
+		case i == len(units1):
+			c = 1
+		case j == len(units2):
+			c = -1
+		default:
+			c = units1[i].id.Compare(units2[j].id)
+		}
+		switch {
+		case c < 0:
+			diff.Removed = append(diff.Removed, units1[i].id)
+			i++
+		case c > 0:
+			diff.Added = append(diff.Added, units2[j].id)
+			j++
+		default:
+			if !bytes.Equal(units1[i].data, units2[j].data) || !bytes.Equal(units1[i].stateLockTx, units2[j].stateLockTx) {
+				diff.Changed = append(diff.Changed, units1[i].id)
+			}
+			i++
+			j++
+		}
+	}
+	return diff, nil
+}
+
+// stateUnits returns the committed units of the state in the order of unit IDs
+func stateUnits(s *state.State) ([]encodedUnit, error) {
+	var units []encodedUnit
+	err := s.Traverse(state.NewInorderTraverser(func(unitID types.UnitID, unit state.Unit) error {
+		u, err := state.ToUnitV1(unit)
+		if err != nil {
+			return err
+		}
+		data, err := cbor.Marshal(u.Data())
+		if err != nil {
+			return fmt.Errorf("encoding data of unit %s: %w", unitID, err)
+		}
+		units = append(units, encodedUnit{id: unitID, data: data, stateLockTx: u.StateLockTx()})
+		return nil
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to traverse state: %w", err)
+	}
+	return units, nil
+}
+
+func writeJSON(out io.Writer, v any) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
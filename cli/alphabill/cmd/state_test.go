@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	moneysdk "github.com/alphabill-org/alphabill-go-base/txsystem/money"
+	"github.com/alphabill-org/alphabill-go-base/types/hex"
+	testobserve "github.com/alphabill-org/alphabill/internal/testutils/observability"
+)
+
+func Test_State(t *testing.T) {
+	newGenesis := func(t *testing.T, initialBillValue string) string {
+		shardConf := *defaultMoneyShardConf
+		shardConf.PartitionParams = map[string]string{moneyInitialBillValue: initialBillValue, moneyDCMoneySupplyValue: "100"}
+		homeDir := writeShardConf(t, &shardConf)
+		cmd := New(testobserve.NewFactory(t))
+		cmd.baseCmd.SetArgs([]string{"shard-conf", "genesis", "--home", homeDir})
+		require.NoError(t, cmd.Execute(context.Background()))
+		return homeDir
+	}
+	run := func(t *testing.T, args ...string) string {
+		out := &bytes.Buffer{}
+		cmd := New(testobserve.NewFactory(t))
+		cmd.baseCmd.SetOut(out)
+		cmd.baseCmd.SetArgs(args)
+		require.NoError(t, cmd.Execute(context.Background()))
+		return out.String()
+	}
+	homeDir := newGenesis(t, "500")
+
+	t.Run("inspect", func(t *testing.T) {
+		var summary stateSummary
+		require.NoError(t, json.Unmarshal([]byte(run(t, "state", "inspect", "--home", homeDir)), &summary))
+		require.EqualValues(t, 0, summary.RoundNumber)
+		require.EqualValues(t, 2, summary.NodeRecordCount)
+		require.EqualValues(t, 600, summary.SummaryValue)
+		require.NotEmpty(t, summary.RootHash)
+		require.Equal(t, []*unitTypeSummary{{UnitType: moneysdk.BillUnitType, UnitCount: 2, SummaryValue: 600}}, summary.UnitTypes)
+	})
+
+	t.Run("inspect unit", func(t *testing.T) {
+		var unit struct {
+			UnitID hex.Bytes `json:"unitId"`
+			Data   struct {
+				Value string `json:"value"`
+			} `json:"data"`
+		}
+		out := run(t, "state", "inspect", "--home", homeDir, "--unit-id", fmt.Sprintf("0x%x", []byte(moneyPartitionInitialBillID)))
+		require.NoError(t, json.Unmarshal([]byte(out), &unit), out)
+		require.EqualValues(t, moneyPartitionInitialBillID, unit.UnitID)
+		require.Equal(t, "500", unit.Data.Value)
+	})
+
+	t.Run("inspect unknown unit", func(t *testing.T) {
+		cmd := New(testobserve.NewFactory(t))
+		cmd.baseCmd.SetArgs([]string{"state", "inspect", "--home", homeDir, "--unit-id", "0x0102"})
+		require.ErrorContains(t, cmd.Execute(context.Background()), "failed to load unit")
+	})
+
+	t.Run("diff", func(t *testing.T) {
+		statePath := filepath.Join(homeDir, StateFileName)
+		require.Equal(t, "units are equal\n", run(t, "state", "diff", "--home", homeDir, statePath, statePath))
+
+		otherStatePath := filepath.Join(newGenesis(t, "501"), StateFileName)
+		require.Equal(t, fmt.Sprintf("~ %s\n", moneyPartitionInitialBillID),
+			run(t, "state", "diff", "--home", homeDir, statePath, otherStatePath))
+	})
+}
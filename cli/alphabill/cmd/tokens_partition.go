@@ -15,6 +15,7 @@ import (
 	"github.com/alphabill-org/alphabill/txsystem"
 	"github.com/alphabill-org/alphabill/txsystem/tokens"
 	tokenc "github.com/alphabill-org/alphabill/txsystem/tokens/encoder"
+	txtypes "github.com/alphabill-org/alphabill/txsystem/types"
 )
 
 type (
@@ -49,11 +50,23 @@ func (p *TokensPartition) NewGenesisState(pdr *types.PartitionDescriptionRecord)
 	return state.NewEmptyState(), nil
 }
 
+func (p *TokensPartition) UnitDataConstructor(pdr *types.PartitionDescriptionRecord) state.UnitDataConstructor {
+	return func(ui types.UnitID) (types.UnitData, error) {
+		return tokenssdk.NewUnitData(ui, pdr)
+	}
+}
+
+func (p *TokensPartition) TxDecoders(pdr *types.PartitionDescriptionRecord) (txtypes.TxExecutors, error) {
+	params, err := ParseTokensPartitionParams(pdr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate tokens partition params: %w", err)
+	}
+	return tokens.NewTxDecoders(len(params.AdminOwnerPredicate) > 0)
+}
+
 func (p *TokensPartition) CreateTxSystem(flags *ShardNodeRunFlags, nodeConf *partition.NodeConf) (txsystem.TransactionSystem, error) {
 	stateFilePath := flags.PathWithDefault(flags.StateFile, StateFileName)
-	state, header, err := loadStateFile(stateFilePath, func(ui types.UnitID) (types.UnitData, error) {
-		return tokenssdk.NewUnitData(ui, nodeConf.ShardConf())
-	})
+	state, header, err := loadStateFile(stateFilePath, p.UnitDataConstructor(nodeConf.ShardConf()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to load state file: %w", err)
 	}
@@ -124,6 +124,7 @@ function start_root_nodes() {
                     $bootNodeParam \
                     --trust-base testab/trust-base.json \
                     --rpc-server-address "localhost:$rpcPort" \
+                    --allow-unsigned-shard-conf \
                     --log-format text \
                     --log-level debug \
                     --metrics prometheus \
@@ -21,6 +21,8 @@ import (
 	"github.com/alphabill-org/alphabill/network/protocol/certification"
 	"github.com/alphabill-org/alphabill/network/protocol/handshake"
 	"github.com/alphabill-org/alphabill/network/protocol/replication"
+	"github.com/alphabill-org/alphabill/network/protocol/rootepoch"
+	"github.com/alphabill-org/alphabill/network/protocol/shardconf"
 	abtypes "github.com/alphabill-org/alphabill/rootchain/consensus/types"
 	"github.com/alphabill-org/alphabill/txbuffer"
 )
@@ -58,6 +60,10 @@ func NewMockNetwork(t *testing.T) *MockNet {
 		{protocolID: network.ProtocolLedgerReplicationResp, msgStruct: replication.LedgerReplicationResponse{}},
 		{protocolID: network.ProtocolHandshake, msgStruct: handshake.Handshake{}},
 		{protocolID: network.ProtocolUnicityCertificates, msgStruct: certification.CertificationResponse{}},
+		{protocolID: network.ProtocolTrustBaseReq, msgStruct: rootepoch.TrustBaseRequest{}},
+		{protocolID: network.ProtocolTrustBase, msgStruct: rootepoch.TrustBaseResponse{}},
+		{protocolID: network.ProtocolShardConfReq, msgStruct: shardconf.ShardConfRequest{}},
+		{protocolID: network.ProtocolShardConf, msgStruct: shardconf.ShardConfResponse{}},
 	})
 	if err != nil {
 		panic(fmt.Errorf("failed to register protocols: %w", err))
@@ -170,8 +176,8 @@ func (m *MockNet) ProcessTransactions(ctx context.Context, txProcessor network.T
 		if err != nil {
 			return
 		}
-		if err := txProcessor(ctx, tx); err != nil {
-			continue
+		if err := txProcessor(ctx, tx); errors.Is(err, network.ErrStopProcessing) {
+			return
 		}
 	}
 }
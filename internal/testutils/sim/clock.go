@@ -0,0 +1,107 @@
+package testsim
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+/*
+Clock is the time source of the simulated network, it decides when the delayed
+messages are delivered.
+
+The clock only drives the network: the protocol timers of the simulated nodes
+(T1 timeout of the shard nodes, round timers of the root chain pacemaker, T2
+timeout monitoring etc) always run on real time.
+*/
+type Clock interface {
+	Now() time.Time
+	// AfterFunc calls "f" in its own goroutine (or synchronously in case of
+	// ManualClock) after the duration "d" has passed.
+	AfterFunc(d time.Duration, f func())
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) AfterFunc(d time.Duration, f func()) { time.AfterFunc(d, f) }
+
+/*
+ManualClock is a Clock which only moves forward when Advance is called, this
+allows to control the delivery of delayed messages deterministically. It is meant
+for testing the Network itself and for the scenarios where the test decides when
+the messages held by the Delay rules are released - as the nodes keep running on
+real time the ManualClock doesn't make the timing of a Simulation deterministic.
+
+Zero value is not useable, use NewManualClock to create the clock.
+*/
+type ManualClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	seq    uint64
+	timers []*manualTimer
+}
+
+type manualTimer struct {
+	at  time.Time
+	seq uint64 // timers with the same deadline fire in the order of creation
+	f   func()
+}
+
+func NewManualClock(start time.Time) *ManualClock {
+	return &ManualClock{now: start}
+}
+
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *ManualClock) AfterFunc(d time.Duration, f func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seq++
+	c.timers = append(c.timers, &manualTimer{at: c.now.Add(d), seq: c.seq, f: f})
+}
+
+/*
+Advance moves the clock forward by "d" and synchronously calls the functions of
+the timers which expire during that period, in the order of their deadlines.
+*/
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	target := c.now.Add(d)
+	c.mu.Unlock()
+
+	for {
+		c.mu.Lock()
+		idx := -1
+		for i, t := range c.timers {
+			if t.at.After(target) {
+				continue
+			}
+			if idx == -1 || t.at.Before(c.timers[idx].at) || (t.at.Equal(c.timers[idx].at) && t.seq < c.timers[idx].seq) {
+				idx = i
+			}
+		}
+		if idx == -1 {
+			c.now = target
+			c.mu.Unlock()
+			return
+		}
+		t := c.timers[idx]
+		c.timers = slices.Delete(c.timers, idx, idx+1)
+		c.now = t.at
+		c.mu.Unlock()
+		t.f()
+	}
+}
+
+// Pending returns the number of timers which haven't fired yet.
+func (c *ManualClock) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
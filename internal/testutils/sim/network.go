@@ -0,0 +1,492 @@
+package testsim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/alphabill-org/alphabill-go-base/cbor"
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill/network/protocol/abdrc"
+	"github.com/alphabill-org/alphabill/network/protocol/blockproposal"
+	"github.com/alphabill-org/alphabill/network/protocol/certification"
+	"github.com/alphabill-org/alphabill/network/protocol/handshake"
+	"github.com/alphabill-org/alphabill/network/protocol/replication"
+)
+
+// message types the different kinds of connections are able to receive,
+// mirrors the protocols registered by the libp2p networks
+var (
+	rootProtocols      = []any{certification.BlockCertificationRequest{}, handshake.Handshake{}}
+	consensusProtocols = []any{abdrc.IrChangeReqMsg{}, abdrc.ProposalMsg{}, abdrc.VoteMsg{}, abdrc.TimeoutMsg{}, abdrc.StateRequestMsg{}, abdrc.StateMsg{}}
+	shardProtocols     = []any{replication.LedgerReplicationRequest{}, replication.LedgerReplicationResponse{}, types.Block{}}
+	validatorProtocols = []any{blockproposal.BlockProposal{}, certification.CertificationResponse{}, types.TransactionOrder{}}
+)
+
+type (
+	// Network is an in-memory network connecting the simulated root and shard nodes.
+	//
+	// Messages are CBOR encoded when sent and decoded into a new value for every
+	// receiver, so nodes never share message data (just like with the real network).
+	// What happens to the message on the way is decided by the rules (see AddRule)
+	// and by the network split (see Split). Messages are delivered to every receiver
+	// in the order the network releases them.
+	Network struct {
+		ctx   context.Context
+		clock Clock
+
+		mu         sync.Mutex
+		rules      []*rule
+		groups     map[peer.ID]int
+		endpoints  map[peer.ID][]*endpoint
+		validators []*ValidatorConn
+		stats      Stats
+	}
+
+	NetworkOption func(n *Network)
+
+	// Message is a message in flight between two nodes.
+	Message struct {
+		From peer.ID
+		To   peer.ID
+		// Payload is the message as it was sent by the node, filters must not modify it!
+		Payload any
+
+		data []byte       // CBOR encoded payload
+		typ  reflect.Type // struct type of the payload
+	}
+
+	// Filter returns true when the rule should be applied to the message.
+	// nil Filter matches all messages.
+	Filter func(msg *Message) bool
+
+	// Action describes what the network does with a message matched by a rule.
+	// The actions are applied in the order: Drop, Duplicate, Reorder, Delay.
+	Action struct {
+		// Drop the message
+		Drop bool
+		// Duplicate sends this many extra copies of the message
+		Duplicate int
+		// Reorder holds back the matching messages until this many have been
+		// collected and then releases them in reverse order
+		Reorder int
+		// Delay the delivery of the message by this duration (of the network's clock)
+		Delay time.Duration
+	}
+
+	// Stats are the message counters of the network.
+	Stats struct {
+		Sent       uint64 // number of messages sent by the nodes (counted per receiver)
+		Delivered  uint64 // number of messages delivered to the nodes, includes duplicates
+		Dropped    uint64 // number of messages dropped by rules, network split or because there is no receiver
+		Duplicated uint64 // number of extra copies created by rules
+		Delayed    uint64 // number of messages delayed by rules
+	}
+
+	rule struct {
+		filter Filter
+		action Action
+		held   []*Message // messages held back by the Reorder action
+	}
+)
+
+/*
+WithClock sets the clock which decides when the delayed messages are delivered,
+see the Clock for the scope of the clock.
+*/
+func WithClock(clock Clock) NetworkOption {
+	return func(n *Network) {
+		n.clock = clock
+	}
+}
+
+/*
+NewNetwork creates a new simulated network for the test "t". The network uses
+real time for delays unless a different Clock is set with the WithClock option.
+*/
+func NewNetwork(t *testing.T, opts ...NetworkOption) *Network {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	n := &Network{
+		ctx:       ctx,
+		clock:     realClock{},
+		groups:    make(map[peer.ID]int),
+		endpoints: make(map[peer.ID][]*endpoint),
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+/*
+AddRule adds a rule which applies the action "a" to the messages matched by the
+filter "f". The rules are evaluated in the order they were added and the first
+matching rule decides the fate of the message.
+
+Returned function removes the rule, messages held back by the rule are released.
+*/
+func (n *Network) AddRule(f Filter, a Action) (remove func()) {
+	r := &rule{filter: f, action: a}
+	n.mu.Lock()
+	n.rules = append(n.rules, r)
+	n.mu.Unlock()
+
+	return func() {
+		n.mu.Lock()
+		idx := slices.Index(n.rules, r)
+		if idx == -1 {
+			n.mu.Unlock()
+			return
+		}
+		n.rules = slices.Delete(n.rules, idx, idx+1)
+		held := r.release()
+		n.mu.Unlock()
+		n.schedule(held, r.action.Delay)
+	}
+}
+
+// Drop drops all the messages matched by the filter.
+func (n *Network) Drop(f Filter) (remove func()) {
+	return n.AddRule(f, Action{Drop: true})
+}
+
+// Delay delays all the messages matched by the filter by "d".
+func (n *Network) Delay(f Filter, d time.Duration) (remove func()) {
+	return n.AddRule(f, Action{Delay: d})
+}
+
+// Duplicate delivers "copies" extra copies of the messages matched by the filter.
+func (n *Network) Duplicate(f Filter, copies int) (remove func()) {
+	return n.AddRule(f, Action{Duplicate: copies})
+}
+
+// Reorder delivers the messages matched by the filter in reverse order in batches of "window" messages.
+func (n *Network) Reorder(f Filter, window int) (remove func()) {
+	return n.AddRule(f, Action{Reorder: window})
+}
+
+// ClearRules removes all the rules, messages held back by the rules are released.
+func (n *Network) ClearRules() {
+	n.mu.Lock()
+	rules := n.rules
+	n.rules = nil
+	n.mu.Unlock()
+
+	for _, r := range rules {
+		n.mu.Lock()
+		held := r.release()
+		n.mu.Unlock()
+		n.schedule(held, r.action.Delay)
+	}
+}
+
+/*
+Split splits the network into groups, nodes in different groups can't send
+messages to each other. Nodes which are not listed in any group can communicate
+with everybody. Calling Split replaces the previous split.
+*/
+func (n *Network) Split(groups ...[]peer.ID) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.groups = make(map[peer.ID]int)
+	for i, g := range groups {
+		for _, id := range g {
+			n.groups[id] = i
+		}
+	}
+}
+
+// Heal removes the network split.
+func (n *Network) Heal() {
+	n.Split()
+}
+
+func (n *Network) Stats() Stats {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.stats
+}
+
+/*
+ConnectRoot connects root node "id" to the network. Returned connections are to
+be used as the partition network and the consensus network of the root node.
+*/
+func (n *Network) ConnectRoot(id peer.ID) (partitionNet, consensusNet *Conn) {
+	return n.connect(id, rootProtocols), n.connect(id, consensusProtocols)
+}
+
+func (n *Network) connect(id peer.ID, protocols []any) *Conn {
+	c := &Conn{net: n, id: id, rcv: make(chan any, 100)}
+	c.ep = n.addEndpoint(id, protocols, c.receive)
+	return c
+}
+
+func (n *Network) addEndpoint(id peer.ID, protocols []any, consume func(ctx context.Context, msg any)) *endpoint {
+	ep := &endpoint{
+		protocols: make(map[reflect.Type]struct{}),
+		signal:    make(chan struct{}, 1),
+		consume:   consume,
+	}
+	ep.register(protocols)
+
+	n.mu.Lock()
+	n.endpoints[id] = append(n.endpoints[id], ep)
+	n.mu.Unlock()
+
+	go ep.run(n.ctx)
+	return ep
+}
+
+func (n *Network) send(from peer.ID, payload any, receivers []peer.ID) error {
+	typ, err := msgType(payload)
+	if err != nil {
+		return err
+	}
+	data, err := cbor.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshaling %T as CBOR: %w", payload, err)
+	}
+	for _, to := range receivers {
+		n.route(&Message{From: from, To: to, Payload: payload, data: data, typ: typ})
+	}
+	return nil
+}
+
+func (n *Network) route(msg *Message) {
+	n.mu.Lock()
+	n.stats.Sent++
+	if n.isolated(msg.From, msg.To) {
+		n.stats.Dropped++
+		n.mu.Unlock()
+		return
+	}
+	idx := slices.IndexFunc(n.rules, func(r *rule) bool { return r.filter == nil || r.filter(msg) })
+	if idx == -1 {
+		n.mu.Unlock()
+		n.deliver(msg)
+		return
+	}
+
+	r := n.rules[idx]
+	if r.action.Drop {
+		n.stats.Dropped++
+		n.mu.Unlock()
+		return
+	}
+	msgs := []*Message{msg}
+	for range r.action.Duplicate {
+		msgs = append(msgs, msg)
+		n.stats.Duplicated++
+	}
+	if r.action.Reorder > 1 {
+		r.held = append(r.held, msgs...)
+		if len(r.held) < r.action.Reorder {
+			n.mu.Unlock()
+			return
+		}
+		msgs = r.release()
+	}
+	if r.action.Delay > 0 {
+		n.stats.Delayed += uint64(len(msgs))
+	}
+	n.mu.Unlock()
+	n.schedule(msgs, r.action.Delay)
+}
+
+// isolated returns true when the nodes are in different groups of the network split.
+func (n *Network) isolated(from, to peer.ID) bool {
+	gf, okf := n.groups[from]
+	gt, okt := n.groups[to]
+	return okf && okt && gf != gt
+}
+
+func (n *Network) schedule(msgs []*Message, delay time.Duration) {
+	if len(msgs) == 0 {
+		return
+	}
+	deliver := func() {
+		for _, msg := range msgs {
+			n.deliver(msg)
+		}
+	}
+	if delay > 0 {
+		n.clock.AfterFunc(delay, deliver)
+		return
+	}
+	deliver()
+}
+
+func (n *Network) deliver(msg *Message) {
+	n.mu.Lock()
+	idx := slices.IndexFunc(n.endpoints[msg.To], func(ep *endpoint) bool { return ep.accepts(msg.typ) })
+	if idx == -1 {
+		// receiver is not connected or doesn't support the protocol
+		n.stats.Dropped++
+		n.mu.Unlock()
+		return
+	}
+	ep := n.endpoints[msg.To][idx]
+	n.stats.Delivered++
+	n.mu.Unlock()
+
+	v := reflect.New(msg.typ)
+	if err := cbor.Unmarshal(msg.data, v.Interface()); err != nil {
+		panic(fmt.Errorf("decoding %s message from %s: %w", msg.typ, msg.From, err))
+	}
+	ep.push(v.Interface())
+}
+
+/*
+release returns the messages held back by the rule in reverse order.
+Must be called while holding the network lock.
+*/
+func (r *rule) release() []*Message {
+	msgs := r.held
+	r.held = nil
+	slices.Reverse(msgs)
+	return msgs
+}
+
+func msgType(msg any) (reflect.Type, error) {
+	typ := reflect.TypeOf(msg)
+	if typ == nil {
+		return nil, errors.New("message is nil")
+	}
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("message must be struct or pointer to struct, got %T", msg)
+	}
+	return typ, nil
+}
+
+// From matches messages sent by any of the nodes.
+func From(ids ...peer.ID) Filter {
+	return func(msg *Message) bool { return slices.Contains(ids, msg.From) }
+}
+
+// To matches messages sent to any of the nodes.
+func To(ids ...peer.ID) Filter {
+	return func(msg *Message) bool { return slices.Contains(ids, msg.To) }
+}
+
+// MsgType matches messages of type T (or pointer to T).
+func MsgType[T any]() Filter {
+	typ, err := msgType(new(T))
+	if err != nil {
+		panic(err)
+	}
+	return func(msg *Message) bool { return msg.typ == typ }
+}
+
+// All matches messages which are matched by all the filters.
+func All(filters ...Filter) Filter {
+	return func(msg *Message) bool {
+		for _, f := range filters {
+			if f != nil && !f(msg) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+/*
+endpoint receives the messages of the registered types for a node, messages are
+queued so that slow consumer doesn't block the senders.
+*/
+type endpoint struct {
+	mu        sync.Mutex
+	protocols map[reflect.Type]struct{}
+	queue     []any
+	signal    chan struct{}
+	consume   func(ctx context.Context, msg any)
+}
+
+func (ep *endpoint) register(protocols []any) {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+	for _, p := range protocols {
+		ep.protocols[reflect.TypeOf(p)] = struct{}{}
+	}
+}
+
+func (ep *endpoint) unregister(protocols []any) {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+	for _, p := range protocols {
+		delete(ep.protocols, reflect.TypeOf(p))
+	}
+}
+
+func (ep *endpoint) accepts(typ reflect.Type) bool {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+	_, ok := ep.protocols[typ]
+	return ok
+}
+
+func (ep *endpoint) push(msg any) {
+	ep.mu.Lock()
+	ep.queue = append(ep.queue, msg)
+	ep.mu.Unlock()
+
+	select {
+	case ep.signal <- struct{}{}:
+	default:
+	}
+}
+
+func (ep *endpoint) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ep.signal:
+		}
+		for {
+			ep.mu.Lock()
+			if len(ep.queue) == 0 {
+				ep.mu.Unlock()
+				break
+			}
+			msg := ep.queue[0]
+			ep.queue = ep.queue[1:]
+			ep.mu.Unlock()
+			ep.consume(ctx, msg)
+		}
+	}
+}
+
+// Conn is a connection of a node to the simulated network.
+type Conn struct {
+	net *Network
+	id  peer.ID
+	ep  *endpoint
+	rcv chan any
+}
+
+func (c *Conn) Send(ctx context.Context, msg any, receivers ...peer.ID) error {
+	return c.net.send(c.id, msg, receivers)
+}
+
+func (c *Conn) ReceivedChannel() <-chan any {
+	return c.rcv
+}
+
+func (c *Conn) receive(ctx context.Context, msg any) {
+	select {
+	case c.rcv <- msg:
+	case <-ctx.Done():
+	}
+}
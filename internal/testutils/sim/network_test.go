@@ -0,0 +1,152 @@
+package testsim
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill/network/protocol/certification"
+	"github.com/alphabill-org/alphabill/network/protocol/handshake"
+)
+
+func TestNetwork(t *testing.T) {
+	const nodeA, nodeB, nodeC peer.ID = "A", "B", "C"
+
+	setup := func(t *testing.T) (*Network, *ManualClock, *Conn, *Conn) {
+		clock := NewManualClock(time.Now())
+		nw := NewNetwork(t, WithClock(clock))
+		a, _ := nw.ConnectRoot(nodeA)
+		b, _ := nw.ConnectRoot(nodeB)
+		return nw, clock, a, b
+	}
+	handshakeMsg := func(partitionID uint32) *handshake.Handshake {
+		return &handshake.Handshake{PartitionID: types.PartitionID(partitionID), NodeID: "node"}
+	}
+	receive := func(t *testing.T, c *Conn) *handshake.Handshake {
+		t.Helper()
+		select {
+		case msg := <-c.ReceivedChannel():
+			return msg.(*handshake.Handshake)
+		case <-time.After(time.Second):
+			t.Fatal("expected message")
+			return nil
+		}
+	}
+	noMessage := func(t *testing.T, c *Conn) {
+		t.Helper()
+		select {
+		case msg := <-c.ReceivedChannel():
+			t.Fatalf("unexpected message %#v", msg)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+
+	t.Run("message is delivered as a copy", func(t *testing.T) {
+		nw, _, a, b := setup(t)
+		msg := handshakeMsg(1)
+		require.NoError(t, a.Send(context.Background(), msg, nodeB))
+		rcv := receive(t, b)
+		require.Equal(t, msg, rcv)
+		require.NotSame(t, msg, rcv)
+		require.Equal(t, Stats{Sent: 1, Delivered: 1}, nw.Stats())
+	})
+
+	t.Run("unknown receiver and protocol", func(t *testing.T) {
+		nw, _, a, b := setup(t)
+		require.NoError(t, a.Send(context.Background(), handshakeMsg(1), nodeC))
+		// root nodes do not accept certification responses
+		require.NoError(t, a.Send(context.Background(), &certification.CertificationResponse{Partition: 1}, nodeB))
+		noMessage(t, b)
+		require.EqualValues(t, 2, nw.Stats().Dropped)
+		require.ErrorContains(t, a.Send(context.Background(), nil, nodeB), "message is nil")
+	})
+
+	t.Run("drop", func(t *testing.T) {
+		nw, _, a, b := setup(t)
+		remove := nw.Drop(All(From(nodeA), MsgType[handshake.Handshake]()))
+		require.NoError(t, a.Send(context.Background(), handshakeMsg(1), nodeB))
+		noMessage(t, b)
+		remove()
+		require.NoError(t, a.Send(context.Background(), handshakeMsg(2), nodeB))
+		require.EqualValues(t, 2, receive(t, b).PartitionID)
+		require.Equal(t, Stats{Sent: 2, Delivered: 1, Dropped: 1}, nw.Stats())
+	})
+
+	t.Run("delay", func(t *testing.T) {
+		nw, clock, a, b := setup(t)
+		nw.Delay(To(nodeB), time.Second)
+		require.NoError(t, a.Send(context.Background(), handshakeMsg(1), nodeB))
+		clock.Advance(500 * time.Millisecond)
+		noMessage(t, b)
+		require.Equal(t, 1, clock.Pending())
+		clock.Advance(500 * time.Millisecond)
+		require.EqualValues(t, 1, receive(t, b).PartitionID)
+		require.Equal(t, Stats{Sent: 1, Delivered: 1, Delayed: 1}, nw.Stats())
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		nw, _, a, b := setup(t)
+		nw.Duplicate(nil, 2)
+		require.NoError(t, a.Send(context.Background(), handshakeMsg(1), nodeB))
+		for range 3 {
+			require.EqualValues(t, 1, receive(t, b).PartitionID)
+		}
+		noMessage(t, b)
+		require.Equal(t, Stats{Sent: 1, Delivered: 3, Duplicated: 2}, nw.Stats())
+	})
+
+	t.Run("reorder", func(t *testing.T) {
+		nw, _, a, b := setup(t)
+		remove := nw.Reorder(To(nodeB), 3)
+		for i := range 5 {
+			require.NoError(t, a.Send(context.Background(), handshakeMsg(uint32(i+1)), nodeB))
+		}
+		for _, id := range []uint32{3, 2, 1} {
+			require.EqualValues(t, id, receive(t, b).PartitionID)
+		}
+		noMessage(t, b)
+		// removing the rule releases the held messages
+		remove()
+		for _, id := range []uint32{5, 4} {
+			require.EqualValues(t, id, receive(t, b).PartitionID)
+		}
+	})
+
+	t.Run("split and heal", func(t *testing.T) {
+		nw, _, a, b := setup(t)
+		c, _ := nw.ConnectRoot(nodeC)
+		nw.Split([]peer.ID{nodeA}, []peer.ID{nodeB})
+		require.NoError(t, a.Send(context.Background(), handshakeMsg(1), nodeB, nodeC))
+		require.NoError(t, b.Send(context.Background(), handshakeMsg(2), nodeA))
+		noMessage(t, b)
+		noMessage(t, a)
+		require.EqualValues(t, 1, receive(t, c).PartitionID)
+
+		nw.Heal()
+		require.NoError(t, a.Send(context.Background(), handshakeMsg(3), nodeB))
+		require.EqualValues(t, 3, receive(t, b).PartitionID)
+		require.Equal(t, Stats{Sent: 4, Delivered: 2, Dropped: 2}, nw.Stats())
+	})
+}
+
+func TestManualClock(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+	var fired []int
+	clock.AfterFunc(2*time.Second, func() { fired = append(fired, 2) })
+	clock.AfterFunc(time.Second, func() { fired = append(fired, 1) })
+	clock.AfterFunc(2*time.Second, func() { fired = append(fired, 3) })
+	clock.AfterFunc(5*time.Second, func() { fired = append(fired, 5) })
+
+	clock.Advance(time.Second)
+	require.Equal(t, []int{1}, fired)
+	require.Equal(t, time.Unix(1, 0), clock.Now())
+
+	clock.Advance(3 * time.Second)
+	require.Equal(t, []int{1, 2, 3}, fired)
+	require.Equal(t, 1, clock.Pending())
+	require.Equal(t, time.Unix(4, 0), clock.Now())
+}
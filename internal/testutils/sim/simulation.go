@@ -0,0 +1,284 @@
+package testsim
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+	test "github.com/alphabill-org/alphabill/internal/testutils"
+	testlogger "github.com/alphabill-org/alphabill/internal/testutils/logger"
+	testobserve "github.com/alphabill-org/alphabill/internal/testutils/observability"
+	testevent "github.com/alphabill-org/alphabill/internal/testutils/partition/event"
+	testpeer "github.com/alphabill-org/alphabill/internal/testutils/peer"
+	"github.com/alphabill-org/alphabill/logger"
+	"github.com/alphabill-org/alphabill/observability"
+	"github.com/alphabill-org/alphabill/partition"
+	"github.com/alphabill-org/alphabill/rootchain"
+	"github.com/alphabill-org/alphabill/rootchain/consensus"
+	"github.com/alphabill-org/alphabill/rootchain/consensus/storage"
+	"github.com/alphabill-org/alphabill/rootchain/partitions"
+	"github.com/alphabill-org/alphabill/rootchain/testutils"
+	"github.com/alphabill-org/alphabill/txsystem"
+	testtransaction "github.com/alphabill-org/alphabill/txsystem/testutils/transaction"
+)
+
+const (
+	networkID = 5
+	// the nodes run on real time (see Clock), their timeouts are shortened by
+	// the speedFactor to make the simulations faster
+	speedFactor = 4
+)
+
+type (
+	// Simulation is a set of root nodes and shard nodes which communicate over
+	// the simulated Network, use the Network to inject faults.
+	Simulation struct {
+		Network   *Network
+		TrustBase types.RootTrustBase
+		RootNodes []*RootNode
+		Shards    map[types.PartitionShardID]*Shard
+		ctx       context.Context
+	}
+
+	RootNode struct {
+		*rootchain.Node
+		ConsensusManager *consensus.ConsensusManager
+		orchestration    *partitions.Orchestration
+		done             chan error
+	}
+
+	Shard struct {
+		shardConf *types.PartitionDescriptionRecord
+		Nodes     []*ShardNode
+	}
+
+	ShardNode struct {
+		*partition.Node
+		Conn         *ValidatorConn
+		EventHandler *testevent.TestEventHandler
+		done         chan error
+	}
+
+	TxSystemProvider func(trustBase types.RootTrustBase) txsystem.TransactionSystem
+)
+
+/*
+New creates and starts a root chain of "rootNodeCount" nodes connected over the
+simulated network. The nodes are stopped when the test "t" ends.
+
+The clock set with the WithClock option controls the delivery of the delayed
+messages only, the consensus timers of the nodes run on real time.
+*/
+func New(t *testing.T, rootNodeCount int, opts ...NetworkOption) *Simulation {
+	ctx, cancel := context.WithCancel(context.Background())
+	nodes, nodeInfos := testutils.CreateTestNodes(t, rootNodeCount)
+	trustBase, err := types.NewTrustBaseGenesis(networkID, nodeInfos)
+	require.NoError(t, err)
+
+	s := &Simulation{
+		Network:   NewNetwork(t, opts...),
+		TrustBase: trustBase,
+		Shards:    make(map[types.PartitionShardID]*Shard),
+		ctx:       ctx,
+	}
+	t.Cleanup(func() {
+		cancel()
+		s.waitStop(t)
+	})
+
+	for _, node := range nodes {
+		log := testlogger.New(t).With(logger.NodeID(node.PeerConf.ID))
+		obs := observability.WithLogger(testobserve.Default(t), log)
+
+		orchestration, err := partitions.NewOrchestration(networkID, filepath.Join(t.TempDir(), "orchestration.db"), log)
+		require.NoError(t, err)
+		rcDB, err := storage.NewBoltStorage(filepath.Join(t.TempDir(), "root.db"))
+		require.NoError(t, err)
+
+		consensusParams := consensus.NewConsensusParams()
+		consensusParams.BlockRate /= speedFactor
+
+		partitionNet, consensusNet := s.Network.ConnectRoot(node.PeerConf.ID)
+		cm, err := consensus.NewConsensusManager(
+			node.PeerConf.ID,
+			trustBase,
+			orchestration,
+			consensusNet,
+			node.Signer,
+			rcDB,
+			obs,
+			consensus.WithConsensusParams(*consensusParams))
+		require.NoError(t, err)
+
+		// root node needs the peer only for its ID, messages go through the simulated network
+		rootNode, err := rootchain.New(testpeer.CreatePeer(t, node.PeerConf), partitionNet, cm, obs)
+		require.NoError(t, err)
+
+		s.RootNodes = append(s.RootNodes, &RootNode{
+			Node:             rootNode,
+			ConsensusManager: cm,
+			orchestration:    orchestration,
+			done:             make(chan error, 1),
+		})
+	}
+
+	for _, rn := range s.RootNodes {
+		go func() {
+			rn.done <- rn.Run(ctx)
+		}()
+	}
+	return s
+}
+
+/*
+AddShard adds a shard of "nodeCount" validators to the root chain and starts the
+shard nodes once the root chain has activated the shard.
+*/
+func (s *Simulation) AddShard(t *testing.T, shardConf *types.PartitionDescriptionRecord, nodeCount int, txSystemProvider TxSystemProvider) *Shard {
+	nodes, nodeInfos := testutils.CreateTestNodes(t, nodeCount)
+	shardConf.Validators = nodeInfos
+
+	for _, rn := range s.RootNodes {
+		require.NoError(t, rn.orchestration.AddShardConfig(shardConf))
+	}
+	require.Eventually(t, func() bool {
+		_, err := s.RootNodes[0].ConsensusManager.ShardInfo(shardConf.PartitionID, shardConf.ShardID)
+		return err == nil
+	}, test.WaitDuration, test.WaitTick)
+
+	shard := &Shard{shardConf: shardConf}
+	for _, node := range nodes {
+		eventHandler := &testevent.TestEventHandler{}
+		log := testlogger.New(t).With(logger.NodeID(node.PeerConf.ID))
+		obs := observability.WithLogger(testobserve.Default(t), log)
+
+		conn := s.Network.ConnectValidator(t, node.PeerConf.ID, shardConf.PartitionID, shardConf.ShardID)
+		nodeConf, err := partition.NewNodeConf(
+			node.KeyConf(t),
+			shardConf,
+			s.TrustBase,
+			obs,
+			partition.WithAddress("/ip4/127.0.0.1/tcp/0"),
+			partition.WithValidatorNetwork(conn),
+			partition.WithEventHandler(eventHandler.HandleEvent, 100),
+			partition.WithT1Timeout(partition.DefaultT1Timeout*time.Millisecond/speedFactor),
+		)
+		require.NoError(t, err)
+
+		n, err := partition.NewNode(s.ctx, txSystemProvider(s.TrustBase), nodeConf)
+		require.NoError(t, err)
+		shard.Nodes = append(shard.Nodes, &ShardNode{
+			Node:         n,
+			Conn:         conn,
+			EventHandler: eventHandler,
+			done:         make(chan error, 1),
+		})
+	}
+	s.Shards[shard.PartitionShardID()] = shard
+
+	for _, n := range shard.Nodes {
+		go func() {
+			n.done <- n.Run(s.ctx)
+		}()
+	}
+	return shard
+}
+
+// RootNodeIDs returns the IDs of the root nodes.
+func (s *Simulation) RootNodeIDs() []peer.ID {
+	var ids []peer.ID
+	for _, rn := range s.RootNodes {
+		ids = append(ids, rn.GetPeer().ID())
+	}
+	return ids
+}
+
+func (s *Simulation) waitStop(t *testing.T) {
+	var nodes []chan error
+	for _, shard := range s.Shards {
+		for _, n := range shard.Nodes {
+			nodes = append(nodes, n.done)
+		}
+	}
+	for _, rn := range s.RootNodes {
+		nodes = append(nodes, rn.done)
+	}
+	for _, done := range nodes {
+		select {
+		case err := <-done:
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("unexpected node exit error: %v", err)
+			}
+		case <-time.After(10 * time.Second):
+			t.Error("node didn't stop within timeout")
+		}
+	}
+	for _, shard := range s.Shards {
+		for _, n := range shard.Nodes {
+			if err := n.Peer().Close(); err != nil {
+				t.Errorf("closing shard node peer: %v", err)
+			}
+		}
+	}
+}
+
+func (s *Shard) PartitionShardID() types.PartitionShardID {
+	return types.PartitionShardID{PartitionID: s.shardConf.PartitionID, ShardID: s.shardConf.ShardID.Key()}
+}
+
+// NodeIDs returns the IDs of the shard nodes.
+func (s *Shard) NodeIDs() []peer.ID {
+	var ids []peer.ID
+	for _, n := range s.Nodes {
+		ids = append(ids, n.Peer().ID())
+	}
+	return ids
+}
+
+// SubmitTx sends transaction to the first node of the shard.
+func (s *Shard) SubmitTx(tx *types.TransactionOrder) error {
+	_, err := s.Nodes[0].SubmitTx(context.Background(), tx)
+	return err
+}
+
+// Ready returns true when all the nodes of the shard have initialized.
+func (s *Shard) Ready() bool {
+	for _, n := range s.Nodes {
+		if _, err := n.LatestBlockNumber(); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsTx returns func which checks whether any of the shard nodes has the transaction in its blockchain.
+func (s *Shard) ContainsTx(t *testing.T, tx *types.TransactionOrder) func() bool {
+	txBytes := testtransaction.TxoToBytes(t, tx)
+	return func() bool {
+		for _, n := range s.Nodes {
+			number, err := n.LatestBlockNumber()
+			if err != nil {
+				continue
+			}
+			for i := uint64(0); i <= number; i++ {
+				b, err := n.GetBlock(context.Background(), number-i)
+				if err != nil || b == nil {
+					continue
+				}
+				for _, txr := range b.Transactions {
+					if bytes.Equal(txr.TransactionOrder, txBytes) {
+						return true
+					}
+				}
+			}
+		}
+		return false
+	}
+}
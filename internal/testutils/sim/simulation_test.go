@@ -0,0 +1,50 @@
+package testsim
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+	test "github.com/alphabill-org/alphabill/internal/testutils"
+	testtxsystem "github.com/alphabill-org/alphabill/internal/testutils/txsystem"
+	"github.com/alphabill-org/alphabill/network/protocol/certification"
+	"github.com/alphabill-org/alphabill/txsystem"
+	testtransaction "github.com/alphabill-org/alphabill/txsystem/testutils/transaction"
+)
+
+func TestSimulation(t *testing.T) {
+	shardConf := &types.PartitionDescriptionRecord{
+		Version:         1,
+		NetworkID:       networkID,
+		PartitionID:     0x01020401,
+		PartitionTypeID: 1,
+		ShardID:         types.ShardID{},
+		TypeIDLen:       8,
+		UnitIDLen:       256,
+		T2Timeout:       2500 * time.Millisecond,
+	}
+
+	sim := New(t, 3)
+	shard := sim.AddShard(t, shardConf, 3, func(tb types.RootTrustBase) txsystem.TransactionSystem {
+		return &testtxsystem.CounterTxSystem{FixedState: testtxsystem.MockState{}}
+	})
+	require.Eventually(t, shard.Ready, test.WaitDuration*3, test.WaitTick)
+
+	tx := testtransaction.NewTransactionOrder(t, testtransaction.WithPartitionID(shardConf.PartitionID))
+	require.NoError(t, shard.SubmitTx(tx))
+	require.Eventually(t, shard.ContainsTx(t, tx), test.WaitDuration*2, test.WaitTick)
+
+	// cut the shard off from the root chain, shard can't make progress without UCs
+	sim.Network.Split(shard.NodeIDs(), sim.RootNodeIDs())
+	tx = testtransaction.NewTransactionOrder(t, testtransaction.WithPartitionID(shardConf.PartitionID))
+	require.NoError(t, shard.SubmitTx(tx))
+	require.Never(t, shard.ContainsTx(t, tx), time.Second, test.WaitTick)
+	require.NotZero(t, sim.Network.Stats().Dropped)
+
+	// heal the network, shard must recover even when certification responses are duplicated
+	sim.Network.Duplicate(MsgType[certification.CertificationResponse](), 1)
+	sim.Network.Heal()
+	require.Eventually(t, shard.ContainsTx(t, tx), test.WaitDuration*2, test.WaitTick)
+}
@@ -0,0 +1,123 @@
+package testsim
+
+import (
+	"context"
+	"crypto"
+	"sync/atomic"
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+	testobserve "github.com/alphabill-org/alphabill/internal/testutils/observability"
+	"github.com/alphabill-org/alphabill/network"
+	"github.com/alphabill-org/alphabill/txbuffer"
+)
+
+/*
+ValidatorConn is a connection of a shard node to the simulated network, it
+implements the partition.ValidatorNetwork interface.
+
+Forwarded transactions and published blocks are sent over the simulated network
+so the rules apply to them too.
+*/
+type ValidatorConn struct {
+	*Conn
+	partitionID types.PartitionID
+	shardID     types.ShardID
+	txBuffer    *txbuffer.TxBuffer
+	subscribed  atomic.Bool
+}
+
+/*
+ConnectValidator connects shard node "id" of the shard "partitionID", "shardID"
+to the network.
+*/
+func (n *Network) ConnectValidator(t *testing.T, id peer.ID, partitionID types.PartitionID, shardID types.ShardID) *ValidatorConn {
+	txBuffer, err := txbuffer.New(100, crypto.SHA256, partitionID, shardID, testobserve.Default(t))
+	require.NoError(t, err)
+
+	c := &ValidatorConn{
+		Conn:        &Conn{net: n, id: id, rcv: make(chan any, 100)},
+		partitionID: partitionID,
+		shardID:     shardID,
+		txBuffer:    txBuffer,
+	}
+	c.ep = n.addEndpoint(id, shardProtocols, c.receive)
+
+	n.mu.Lock()
+	n.validators = append(n.validators, c)
+	n.mu.Unlock()
+	return c
+}
+
+func (c *ValidatorConn) receive(ctx context.Context, msg any) {
+	switch m := msg.(type) {
+	case *types.TransactionOrder:
+		// forwarded transactions go directly to the tx buffer
+		_, _ = c.txBuffer.Add(ctx, m)
+	case *types.Block:
+		if c.subscribed.Load() {
+			c.Conn.receive(ctx, m)
+		}
+	default:
+		c.Conn.receive(ctx, m)
+	}
+}
+
+func (c *ValidatorConn) AddTransaction(ctx context.Context, tx *types.TransactionOrder) ([]byte, error) {
+	return c.txBuffer.Add(ctx, tx)
+}
+
+func (c *ValidatorConn) ProcessTransactions(ctx context.Context, txProcessor network.TxProcessor) {
+	for {
+		tx, err := c.txBuffer.Remove(ctx)
+		if err != nil {
+			return
+		}
+		_ = txProcessor(ctx, tx)
+	}
+}
+
+func (c *ValidatorConn) ForwardTransactions(ctx context.Context, receiverFunc network.TxReceiver) {
+	for {
+		tx, err := c.txBuffer.Remove(ctx)
+		if err != nil {
+			return
+		}
+		// like the libp2p network, failure to forward the tx is not fatal
+		_ = c.Send(ctx, tx, receiverFunc())
+	}
+}
+
+// PublishBlock sends the block to the other nodes of the shard which have subscribed to blocks.
+func (c *ValidatorConn) PublishBlock(ctx context.Context, block *types.Block) error {
+	c.net.mu.Lock()
+	var receivers []peer.ID
+	for _, v := range c.net.validators {
+		if v != c && v.partitionID == c.partitionID && v.shardID.Key() == c.shardID.Key() && v.subscribed.Load() {
+			receivers = append(receivers, v.id)
+		}
+	}
+	c.net.mu.Unlock()
+	return c.Send(ctx, block, receivers...)
+}
+
+func (c *ValidatorConn) SubscribeToBlocks(ctx context.Context) error {
+	c.subscribed.Store(true)
+	return nil
+}
+
+func (c *ValidatorConn) UnsubscribeFromBlocks() {
+	c.subscribed.Store(false)
+}
+
+func (c *ValidatorConn) RegisterValidatorProtocols() error {
+	c.ep.register(validatorProtocols)
+	return nil
+}
+
+func (c *ValidatorConn) UnregisterValidatorProtocols() {
+	c.ep.unregister(validatorProtocols)
+}
@@ -33,6 +33,15 @@ func (x *IrChangeReqMsg) IsValid() error {
 	return nil
 }
 
+/*
+IRChangeRequestSigner is implemented by the signers which sign the IR change request
+message rather than its bytes, ie the remote signer which doesn't sign the messages
+it can't decode. Sign uses it when the signer implements it.
+*/
+type IRChangeRequestSigner interface {
+	SignIRChangeRequest(msg *IrChangeReqMsg) ([]byte, error)
+}
+
 func (x *IrChangeReqMsg) Sign(signer crypto.Signer) error {
 	if signer == nil {
 		return errSignerIsNil
@@ -40,6 +49,14 @@ func (x *IrChangeReqMsg) Sign(signer crypto.Signer) error {
 	if err := x.IsValid(); err != nil {
 		return fmt.Errorf("ir change request msg not valid: %w", err)
 	}
+	if rs, ok := signer.(IRChangeRequestSigner); ok {
+		signature, err := rs.SignIRChangeRequest(x)
+		if err != nil {
+			return fmt.Errorf("failed to sign ir change request: %w", err)
+		}
+		x.Signature = signature
+		return nil
+	}
 	bs, err := x.bytes()
 	if err != nil {
 		return fmt.Errorf("failed to marshal ir change request msg: %w", err)
@@ -58,7 +58,7 @@ func (x *ProposalMsg) Verify(tb types.RootTrustBase) error {
 	if err != nil {
 		return fmt.Errorf("block serialization failed: %w", err)
 	}
-	if _, err := tb.VerifySignature(bb, x.Signature, x.Block.Author); err != nil {
+	if _, err := abdrc.TrustBaseForRound(tb, x.Block.Round).VerifySignature(bb, x.Signature, x.Block.Author); err != nil {
 		return fmt.Errorf("signature verification failed: %w", err)
 	}
 
@@ -71,6 +71,15 @@ func (x *ProposalMsg) Verify(tb types.RootTrustBase) error {
 	return nil
 }
 
+/*
+ProposalSigner is implemented by the signers which sign the proposed block rather
+than its hash, ie the remote signer which enforces the anti-double-signing rules.
+Sign uses it when the signer implements it.
+*/
+type ProposalSigner interface {
+	SignRootProposal(block *abdrc.BlockData) ([]byte, error)
+}
+
 func (x *ProposalMsg) Sign(signer crypto.Signer) error {
 	if signer == nil {
 		return errSignerIsNil
@@ -78,6 +87,14 @@ func (x *ProposalMsg) Sign(signer crypto.Signer) error {
 	if err := x.IsValid(); err != nil {
 		return fmt.Errorf("invalid proposal: %w", err)
 	}
+	if ps, ok := signer.(ProposalSigner); ok {
+		signature, err := ps.SignRootProposal(x.Block)
+		if err != nil {
+			return fmt.Errorf("sign failed: %w", err)
+		}
+		x.Signature = signature
+		return nil
+	}
 
 	// Sign block hash
 	hash, err := x.Block.Hash(gocrypto.SHA256)
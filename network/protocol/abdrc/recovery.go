@@ -62,6 +62,9 @@ type StateMsg struct {
 	_             struct{} `cbor:",toarray"`
 	CommittedHead *CommittedBlock
 	Pending       []*rctypes.BlockData
+	// trust bases of the scheduled root epochs following the genesis epoch, so
+	// that the node which missed the scheduling of an epoch is able to catch up
+	TrustBases []*types.RootTrustBaseV1
 }
 
 /*
@@ -116,7 +119,9 @@ func (sm *StateMsg) Verify(hashAlgorithm crypto.Hash, tb types.RootTrustBase) er
 		}
 	}
 	for _, c := range sm.CommittedHead.ShardInfo {
-		if err := c.UC.Verify(tb, hashAlgorithm, c.UC.UnicityTreeCertificate.Partition, nil); err != nil {
+		// the seal of the round R is signed by the validators voting in the round R+1
+		ucTrustBase := rctypes.TrustBaseForRound(tb, c.UC.GetRootRoundNumber()+1)
+		if err := c.UC.Verify(ucTrustBase, hashAlgorithm, c.UC.UnicityTreeCertificate.Partition, nil); err != nil {
 			return fmt.Errorf("certificate for %s is invalid: %w", c.UC.UnicityTreeCertificate.Partition, err)
 		}
 	}
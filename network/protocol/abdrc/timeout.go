@@ -63,7 +63,7 @@ func (x *TimeoutMsg) Verify(tb types.RootTrustBase) error {
 	if err := x.IsValid(); err != nil {
 		return fmt.Errorf("validation failed: %w", err)
 	}
-	if _, err := tb.VerifySignature(x.Bytes(), x.Signature, x.Author); err != nil {
+	if _, err := abdrc.TrustBaseForRound(tb, x.Timeout.GetRound()).VerifySignature(x.Bytes(), x.Signature, x.Author); err != nil {
 		return fmt.Errorf("signature verification failed: %w", err)
 	}
 	if err := x.Timeout.Verify(tb); err != nil {
@@ -77,10 +77,27 @@ func (x *TimeoutMsg) Verify(tb types.RootTrustBase) error {
 	return nil
 }
 
+/*
+TimeoutSigner is implemented by the signers which sign the timeout message rather
+than its bytes, ie the remote signer which enforces the anti-double-signing rules.
+Sign uses it when the signer implements it.
+*/
+type TimeoutSigner interface {
+	SignTimeout(msg *TimeoutMsg) ([]byte, error)
+}
+
 func (x *TimeoutMsg) Sign(s crypto.Signer) error {
 	if err := x.IsValid(); err != nil {
 		return fmt.Errorf("timeout validation failed, %w", err)
 	}
+	if ts, ok := s.(TimeoutSigner); ok {
+		sig, err := ts.SignTimeout(x)
+		if err != nil {
+			return fmt.Errorf("sign error, %w", err)
+		}
+		x.Signature = sig
+		return nil
+	}
 	sig, err := s.SignBytes(x.Bytes())
 	if err != nil {
 		return fmt.Errorf("sign error, %w", err)
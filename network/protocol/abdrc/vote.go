@@ -20,6 +20,15 @@ type VoteMsg struct {
 	Signature        hex.Bytes            `json:"signature"`        // Vote signature on hash of consensus info
 }
 
+/*
+VoteSigner is implemented by the signers which sign the vote rather than the bytes
+of its commit info, ie the remote signer which enforces the anti-double-signing
+rules. Sign uses it when the signer implements it.
+*/
+type VoteSigner interface {
+	SignVote(vote *VoteMsg) ([]byte, error)
+}
+
 func (x *VoteMsg) Sign(signer crypto.Signer) error {
 	if signer == nil {
 		return errSignerIsNil
@@ -28,6 +37,14 @@ func (x *VoteMsg) Sign(signer crypto.Signer) error {
 	if len(x.LedgerCommitInfo.PreviousHash) < 1 {
 		return fmt.Errorf("invalid round info hash")
 	}
+	if vs, ok := signer.(VoteSigner); ok {
+		signature, err := vs.SignVote(x)
+		if err != nil {
+			return fmt.Errorf("failed to sign vote: %w", err)
+		}
+		x.Signature = signature
+		return nil
+	}
 	bs, err := x.LedgerCommitInfo.SigBytes()
 	if err != nil {
 		return fmt.Errorf("failed to marshal unicity seal: %w", err)
@@ -71,7 +88,7 @@ func (x *VoteMsg) Verify(tb types.RootTrustBase) error {
 	if err != nil {
 		return fmt.Errorf("failed to marshal unicity seal: %w", err)
 	}
-	if _, err := tb.VerifySignature(bs, x.Signature, x.Author); err != nil {
+	if _, err := drctypes.TrustBaseForRound(tb, x.VoteInfo.RoundNumber).VerifySignature(bs, x.Signature, x.Author); err != nil {
 		return fmt.Errorf("vote from '%s' signature verification error: %w", x.Author, err)
 	}
 	return nil
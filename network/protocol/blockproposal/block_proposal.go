@@ -72,10 +72,27 @@ func (x *BlockProposal) Hash(algorithm gocrypto.Hash) ([]byte, error) {
 	return h, nil
 }
 
+/*
+ProposalSigner is implemented by the signers which sign the block proposal rather
+than its hash, ie the remote signer which enforces the anti-double-signing rules.
+Sign uses it when the signer implements it.
+*/
+type ProposalSigner interface {
+	SignBlockProposal(prop *BlockProposal, algorithm gocrypto.Hash) ([]byte, error)
+}
+
 func (x *BlockProposal) Sign(algorithm gocrypto.Hash, signer crypto.Signer) error {
 	if signer == nil {
 		return ErrSignerIsNil
 	}
+	if ps, ok := signer.(ProposalSigner); ok {
+		signature, err := ps.SignBlockProposal(x, algorithm)
+		if err != nil {
+			return err
+		}
+		x.Signature = signature
+		return nil
+	}
 	hash, err := x.Hash(algorithm)
 	if err != nil {
 		return err
@@ -68,10 +68,27 @@ func (x *BlockCertificationRequest) IsValid(v crypto.Verifier) error {
 	return nil
 }
 
+/*
+RequestSigner is implemented by the signers which sign the certification request
+rather than its bytes, ie the remote signer which enforces the anti-double-signing
+rules. Sign uses it when the signer implements it.
+*/
+type RequestSigner interface {
+	SignCertificationRequest(req *BlockCertificationRequest) ([]byte, error)
+}
+
 func (x *BlockCertificationRequest) Sign(signer crypto.Signer) error {
 	if signer == nil {
 		return errors.New("signer is nil")
 	}
+	if rs, ok := signer.(RequestSigner); ok {
+		signature, err := rs.SignCertificationRequest(x)
+		if err != nil {
+			return fmt.Errorf("sign error, %w", err)
+		}
+		x.Signature = signature
+		return nil
+	}
 	bs, err := x.Bytes()
 	if err != nil {
 		return fmt.Errorf("failed to marshal block certification request, %w", err)
@@ -0,0 +1,73 @@
+package rootepoch
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill/rootchain/consensus/trustbase"
+)
+
+var (
+	ErrRequestIsNil       = errors.New("trust base request is nil")
+	ErrResponseIsNil      = errors.New("trust base response is nil")
+	ErrInvalidPartitionID = errors.New("invalid partition identifier")
+	ErrMissingNodeID      = errors.New("missing node identifier")
+	ErrTrustBaseIsNil     = errors.New("trust base is nil")
+)
+
+type (
+	// TrustBaseRequest is sent by the shard node to the root node to fetch the trust
+	// base of the root epoch.
+	TrustBaseRequest struct {
+		_           struct{} `cbor:",toarray"`
+		PartitionID types.PartitionID
+		ShardID     types.ShardID
+		NodeID      string
+		Epoch       uint64
+	}
+
+	// TrustBaseResponse is the trust base of the root epoch sent by the root node.
+	TrustBaseResponse struct {
+		_         struct{} `cbor:",toarray"`
+		Epoch     uint64
+		TrustBase *types.RootTrustBaseV1
+	}
+)
+
+func (r *TrustBaseRequest) IsValid() error {
+	if r == nil {
+		return ErrRequestIsNil
+	}
+	if r.PartitionID == 0 {
+		return ErrInvalidPartitionID
+	}
+	if len(r.NodeID) == 0 {
+		return ErrMissingNodeID
+	}
+	return nil
+}
+
+func (r *TrustBaseResponse) IsValid() error {
+	if r == nil {
+		return ErrResponseIsNil
+	}
+	if r.TrustBase == nil {
+		return ErrTrustBaseIsNil
+	}
+	return nil
+}
+
+/*
+Verify checks that the trust base is the trust base of the root epoch following the
+epoch of "prev", ie it has been signed by the quorum of the validators of "prev".
+*/
+func (r *TrustBaseResponse) Verify(prev types.RootTrustBase) error {
+	if err := r.IsValid(); err != nil {
+		return err
+	}
+	if err := trustbase.VerifyNext(prev, r.TrustBase); err != nil {
+		return fmt.Errorf("invalid trust base of the root epoch %d: %w", r.Epoch, err)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package rootepoch
+
+import (
+	"testing"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrustBaseRequest_IsValid(t *testing.T) {
+	var req *TrustBaseRequest
+	require.ErrorIs(t, req.IsValid(), ErrRequestIsNil)
+
+	req = &TrustBaseRequest{PartitionID: 0, NodeID: "test", Epoch: 1}
+	require.ErrorIs(t, req.IsValid(), ErrInvalidPartitionID)
+
+	req = &TrustBaseRequest{PartitionID: 1, Epoch: 1}
+	require.ErrorIs(t, req.IsValid(), ErrMissingNodeID)
+
+	req = &TrustBaseRequest{PartitionID: 1, NodeID: "test", Epoch: 1}
+	require.NoError(t, req.IsValid())
+}
+
+func TestTrustBaseResponse_IsValid(t *testing.T) {
+	var rsp *TrustBaseResponse
+	require.ErrorIs(t, rsp.IsValid(), ErrResponseIsNil)
+
+	rsp = &TrustBaseResponse{Epoch: 1}
+	require.ErrorIs(t, rsp.IsValid(), ErrTrustBaseIsNil)
+	require.ErrorIs(t, rsp.Verify(&types.RootTrustBaseV1{}), ErrTrustBaseIsNil)
+
+	rsp = &TrustBaseResponse{Epoch: 1, TrustBase: &types.RootTrustBaseV1{}}
+	require.NoError(t, rsp.IsValid())
+}
@@ -0,0 +1,77 @@
+package shardconf
+
+import (
+	"bytes"
+	gocrypto "crypto"
+	"errors"
+	"fmt"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+)
+
+var (
+	ErrRequestIsNil       = errors.New("shard conf request is nil")
+	ErrResponseIsNil      = errors.New("shard conf response is nil")
+	ErrInvalidPartitionID = errors.New("invalid partition identifier")
+	ErrMissingNodeID      = errors.New("missing node identifier")
+	ErrShardConfIsNil     = errors.New("shard conf is nil")
+)
+
+type (
+	// ShardConfRequest is sent by the shard node to the root node to fetch the shard
+	// conf of the epoch of the shard.
+	ShardConfRequest struct {
+		_           struct{} `cbor:",toarray"`
+		PartitionID types.PartitionID
+		ShardID     types.ShardID
+		NodeID      string
+		Epoch       uint64
+	}
+
+	// ShardConfResponse is the shard conf of the epoch sent by the root node.
+	ShardConfResponse struct {
+		_         struct{} `cbor:",toarray"`
+		ShardConf *types.PartitionDescriptionRecord
+	}
+)
+
+func (r *ShardConfRequest) IsValid() error {
+	if r == nil {
+		return ErrRequestIsNil
+	}
+	if r.PartitionID == 0 {
+		return ErrInvalidPartitionID
+	}
+	if len(r.NodeID) == 0 {
+		return ErrMissingNodeID
+	}
+	return nil
+}
+
+func (r *ShardConfResponse) IsValid() error {
+	if r == nil {
+		return ErrResponseIsNil
+	}
+	if r.ShardConf == nil {
+		return ErrShardConfIsNil
+	}
+	return nil
+}
+
+/*
+Verify checks that the shard conf is the one certified by the root chain, ie its hash
+matches the shard conf hash of the unicity certificate.
+*/
+func (r *ShardConfResponse) Verify(shardConfHash []byte, hashAlg gocrypto.Hash) error {
+	if err := r.IsValid(); err != nil {
+		return err
+	}
+	hash, err := r.ShardConf.Hash(hashAlg)
+	if err != nil {
+		return fmt.Errorf("calculating shard conf hash: %w", err)
+	}
+	if !bytes.Equal(hash, shardConfHash) {
+		return fmt.Errorf("shard conf hash %X does not match the certified hash %X", hash, shardConfHash)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package shardconf
+
+import (
+	gocrypto "crypto"
+	"testing"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShardConfRequest_IsValid(t *testing.T) {
+	var req *ShardConfRequest
+	require.ErrorIs(t, req.IsValid(), ErrRequestIsNil)
+
+	req = &ShardConfRequest{PartitionID: 0, NodeID: "test", Epoch: 1}
+	require.ErrorIs(t, req.IsValid(), ErrInvalidPartitionID)
+
+	req = &ShardConfRequest{PartitionID: 1, Epoch: 1}
+	require.ErrorIs(t, req.IsValid(), ErrMissingNodeID)
+
+	req = &ShardConfRequest{PartitionID: 1, NodeID: "test", Epoch: 1}
+	require.NoError(t, req.IsValid())
+}
+
+func TestShardConfResponse_Verify(t *testing.T) {
+	var rsp *ShardConfResponse
+	require.ErrorIs(t, rsp.IsValid(), ErrResponseIsNil)
+
+	rsp = &ShardConfResponse{}
+	require.ErrorIs(t, rsp.IsValid(), ErrShardConfIsNil)
+	require.ErrorIs(t, rsp.Verify(nil, gocrypto.SHA256), ErrShardConfIsNil)
+
+	rsp = &ShardConfResponse{ShardConf: &types.PartitionDescriptionRecord{Version: 1, NetworkID: 5, PartitionID: 1, Epoch: 1}}
+	require.NoError(t, rsp.IsValid())
+	hash, err := rsp.ShardConf.Hash(gocrypto.SHA256)
+	require.NoError(t, err)
+	require.NoError(t, rsp.Verify(hash, gocrypto.SHA256))
+	require.ErrorContains(t, rsp.Verify([]byte{1, 2, 3}, gocrypto.SHA256), "does not match the certified hash")
+}
@@ -6,12 +6,18 @@ import (
 
 	"github.com/alphabill-org/alphabill/network/protocol/certification"
 	"github.com/alphabill-org/alphabill/network/protocol/handshake"
+	"github.com/alphabill-org/alphabill/network/protocol/rootepoch"
+	"github.com/alphabill-org/alphabill/network/protocol/shardconf"
 )
 
 const (
 	ProtocolHandshake           = "/ab/handshake/0.0.1"
 	ProtocolBlockCertification  = "/ab/block-certification/0.0.1"
 	ProtocolUnicityCertificates = "/ab/certificates/0.0.1"
+	ProtocolTrustBaseReq        = "/ab/trust-base-req/0.0.1"
+	ProtocolTrustBase           = "/ab/trust-base/0.0.1"
+	ProtocolShardConfReq        = "/ab/shard-conf-req/0.0.1"
+	ProtocolShardConf           = "/ab/shard-conf/0.0.1"
 )
 
 /*
@@ -25,6 +31,8 @@ func NewLibP2PRootChainNetwork(self *Peer, capacity uint, sendCertificateTimeout
 
 	sendProtocolDescriptions := []sendProtocolDescription{
 		{protocolID: ProtocolUnicityCertificates, timeout: sendCertificateTimeout, msgType: certification.CertificationResponse{}},
+		{protocolID: ProtocolTrustBase, timeout: sendCertificateTimeout, msgType: rootepoch.TrustBaseResponse{}},
+		{protocolID: ProtocolShardConf, timeout: sendCertificateTimeout, msgType: shardconf.ShardConfResponse{}},
 	}
 	if err = n.registerSendProtocols(sendProtocolDescriptions); err != nil {
 		return nil, fmt.Errorf("registering send protocols: %w", err)
@@ -39,6 +47,14 @@ func NewLibP2PRootChainNetwork(self *Peer, capacity uint, sendCertificateTimeout
 			protocolID: ProtocolHandshake,
 			typeFn:     func() any { return &handshake.Handshake{} },
 		},
+		{
+			protocolID: ProtocolTrustBaseReq,
+			typeFn:     func() any { return &rootepoch.TrustBaseRequest{} },
+		},
+		{
+			protocolID: ProtocolShardConfReq,
+			typeFn:     func() any { return &shardconf.ShardConfRequest{} },
+		},
 	}
 	if err = n.registerReceiveProtocols(receiveProtocolDescriptions); err != nil {
 		return nil, fmt.Errorf("registering receive protocols: %w", err)
@@ -16,6 +16,8 @@ import (
 	"github.com/alphabill-org/alphabill/network/protocol/certification"
 	"github.com/alphabill-org/alphabill/network/protocol/handshake"
 	"github.com/alphabill-org/alphabill/network/protocol/replication"
+	"github.com/alphabill-org/alphabill/network/protocol/rootepoch"
+	"github.com/alphabill-org/alphabill/network/protocol/shardconf"
 	"github.com/alphabill-org/alphabill/observability"
 	"github.com/alphabill-org/alphabill/txbuffer"
 	pubsub "github.com/libp2p/go-libp2p-pubsub"
@@ -46,6 +48,12 @@ var DefaultValidatorNetworkOptions = ValidatorNetworkOptions{
 	HandshakeTimeout:                 300 * time.Millisecond,
 }
 
+var errTxPrevalidation = errors.New("transaction pre-validation failed")
+
+// ErrStopProcessing is returned by the TxProcessor when it doesn't accept any more
+// transactions, ProcessTransactions stops pulling transactions from the buffer.
+var ErrStopProcessing = errors.New("stop processing transactions")
+
 type (
 	ValidatorNetworkOptions struct {
 		// How many messages will be buffered (ReceivedChannel) in case of slow consumer.
@@ -55,6 +63,11 @@ type (
 		TxBufferSize            uint
 		TxBufferHashAlgorithm   crypto.Hash
 
+		// When set, transactions received from other nodes are pre-validated
+		// before they are added to the transaction buffer. Transactions which
+		// fail the pre-validation are dropped.
+		TxPrevalidator TxPrevalidator
+
 		// timeout configurations for Send operations.
 		// timeout values are per receiver, ie when calling Send with multiple receivers
 		// each receiver will have it's own timeout. The context used with Send call can
@@ -71,6 +84,14 @@ type (
 
 	TxReceiver func() peer.ID
 
+	// TxPrevalidator verifies the stateless parts of the transaction asynchronously.
+	TxPrevalidator interface {
+		// Submit queues the transaction for validation, "done" is called
+		// with the result of the validation. Returns error when the
+		// transaction can't be queued (done won't be called then).
+		Submit(tx *types.TransactionOrder, done func(error)) error
+	}
+
 	node interface {
 		PartitionID() types.PartitionID
 		ShardID() types.ShardID
@@ -82,6 +103,7 @@ type (
 		*LibP2PNetwork
 		node                 node
 		txBuffer             *txbuffer.TxBuffer
+		txPrevalidator       TxPrevalidator
 		txFwdBy              metric.Int64Counter
 		txFwdTo              metric.Int64Counter
 		fixedAttr            metric.MeasurementOption
@@ -108,9 +130,10 @@ func NewLibP2PValidatorNetwork(ctx context.Context, node node, opts ValidatorNet
 	}
 
 	n := &validatorNetwork{
-		LibP2PNetwork: base,
-		txBuffer:      txBuffer,
-		node:          node,
+		LibP2PNetwork:  base,
+		txBuffer:       txBuffer,
+		txPrevalidator: opts.TxPrevalidator,
+		node:           node,
 	}
 
 	if err := n.initGossipSub(ctx, node.PartitionID()); err != nil {
@@ -142,6 +165,16 @@ func NewLibP2PValidatorNetwork(ctx context.Context, node node, opts ValidatorNet
 			timeout:    opts.HandshakeTimeout,
 			msgType:    handshake.Handshake{},
 		},
+		{
+			protocolID: ProtocolTrustBaseReq,
+			timeout:    opts.HandshakeTimeout,
+			msgType:    rootepoch.TrustBaseRequest{},
+		},
+		{
+			protocolID: ProtocolShardConfReq,
+			timeout:    opts.HandshakeTimeout,
+			msgType:    shardconf.ShardConfRequest{},
+		},
 	}
 	if err = n.registerSendProtocols(sendProtocolDescriptions); err != nil {
 		return nil, fmt.Errorf("registering send protocols: %w", err)
@@ -156,6 +189,14 @@ func NewLibP2PValidatorNetwork(ctx context.Context, node node, opts ValidatorNet
 			protocolID: ProtocolLedgerReplicationResp,
 			typeFn:     func() any { return &replication.LedgerReplicationResponse{} },
 		},
+		{
+			protocolID: ProtocolTrustBase,
+			typeFn:     func() any { return &rootepoch.TrustBaseResponse{} },
+		},
+		{
+			protocolID: ProtocolShardConf,
+			typeFn:     func() any { return &shardconf.ShardConfResponse{} },
+		},
 	}
 	if err = n.registerReceiveProtocols(receiveProtocolDescriptions); err != nil {
 		return nil, fmt.Errorf("registering receive protocols: %w", err)
@@ -290,6 +331,9 @@ func (n *validatorNetwork) ProcessTransactions(ctx context.Context, txProcessor
 			return
 		}
 		if err := txProcessor(ctx, tx); err != nil {
+			if errors.Is(err, ErrStopProcessing) {
+				return
+			}
 			n.log.WarnContext(ctx, "processing transaction", logger.Error(err), logger.UnitID(tx.UnitID))
 		}
 	}
@@ -392,18 +436,40 @@ func (n *validatorNetwork) handleTransactions(stream libp2pNetwork.Stream) {
 			return
 		}
 
-		_, err := n.txBuffer.Add(ctx, tx)
+		if n.txPrevalidator == nil {
+			n.addForwardedTx(ctx, tx)
+			continue
+		}
+		err := n.txPrevalidator.Submit(tx, func(err error) {
+			if err != nil {
+				n.log.DebugContext(ctx, "transaction pre-validation failed", logger.Error(err), logger.UnitID(tx.UnitID))
+				n.recordForwardedTx(ctx, tx, errTxPrevalidation)
+				return
+			}
+			n.addForwardedTx(ctx, tx)
+		})
 		if err != nil {
-			n.log.WarnContext(ctx, "adding tx to buffer", logger.Error(err))
-			span.AddEvent(err.Error())
+			n.log.WarnContext(ctx, "queueing tx for pre-validation", logger.Error(err))
+			n.recordForwardedTx(ctx, tx, err)
 		}
+	}
+}
 
-		n.txFwdTo.Add(ctx, 1, metric.WithAttributes(
-			attribute.Int("tx", int(tx.Type)),
-			attribute.String("status", statusCodeOfTxBufferError(err))),
-			n.fixedAttr,
-		)
+func (n *validatorNetwork) addForwardedTx(ctx context.Context, tx *types.TransactionOrder) {
+	_, err := n.txBuffer.Add(ctx, tx)
+	if err != nil {
+		n.log.WarnContext(ctx, "adding tx to buffer", logger.Error(err))
+		trace.SpanFromContext(ctx).AddEvent(err.Error())
 	}
+	n.recordForwardedTx(ctx, tx, err)
+}
+
+func (n *validatorNetwork) recordForwardedTx(ctx context.Context, tx *types.TransactionOrder, err error) {
+	n.txFwdTo.Add(ctx, 1, metric.WithAttributes(
+		attribute.Int("tx", int(tx.Type)),
+		attribute.String("status", statusCodeOfTxBufferError(err))),
+		n.fixedAttr,
+	)
 }
 
 func (n *validatorNetwork) handleBlocks(ctx context.Context) {
@@ -439,6 +505,8 @@ func statusCodeOfTxBufferError(err error) string {
 		return "buf.double"
 	case errors.Is(err, txbuffer.ErrTxBufferFull):
 		return "buf.full"
+	case errors.Is(err, errTxPrevalidation):
+		return "invalid"
 	default:
 		return "err"
 	}
@@ -0,0 +1,134 @@
+package partition
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill/txsystem/fc"
+)
+
+// Shard configuration (PartitionDescriptionRecord.PartitionParams) keys of the
+// block proposal limits. The limits are independent of the partition type.
+const (
+	BlockMaxTxCountParam = "blockMaxTxCount"
+	BlockMaxSizeParam    = "blockMaxSize"
+	BlockMaxGasParam     = "blockMaxGas"
+)
+
+var ErrBlockLimitExceeded = errors.New("block limit exceeded")
+
+type (
+	// BlockLimits defines the upper bounds of a block proposal. Zero value of
+	// a field means that the corresponding dimension is not limited.
+	//
+	// The size of the block is the sum of the sizes of the encoded transaction
+	// orders. The gas of the block is the sum of the gas charged for the
+	// transactions (actual fee converted to gas units), so in feeless mode
+	// the gas limit has no effect.
+	BlockLimits struct {
+		MaxTxCount uint32
+		MaxSize    uint64
+		MaxGas     uint64
+	}
+
+	// blockUsage accumulates the resources used by the transactions of a block.
+	blockUsage struct {
+		txCount uint32
+		size    uint64
+		gas     uint64
+	}
+)
+
+/*
+IsBlockLimitParam returns true when "key" is one of the partition
+type independent block limit parameters of the shard configuration.
+*/
+func IsBlockLimitParam(key string) bool {
+	switch key {
+	case BlockMaxTxCountParam, BlockMaxSizeParam, BlockMaxGasParam:
+		return true
+	}
+	return false
+}
+
+/*
+ParseBlockLimits extracts the block limits from the shard configuration.
+Parameters not related to block limits are ignored.
+*/
+func ParseBlockLimits(shardConf *types.PartitionDescriptionRecord) (BlockLimits, error) {
+	var limits BlockLimits
+	for key, valueStr := range shardConf.PartitionParams {
+		switch key {
+		case BlockMaxTxCountParam:
+			v, err := strconv.ParseUint(valueStr, 10, 32)
+			if err != nil {
+				return limits, fmt.Errorf("failed to parse param %q value: %w", key, err)
+			}
+			limits.MaxTxCount = uint32(v)
+		case BlockMaxSizeParam:
+			v, err := strconv.ParseUint(valueStr, 10, 64)
+			if err != nil {
+				return limits, fmt.Errorf("failed to parse param %q value: %w", key, err)
+			}
+			limits.MaxSize = v
+		case BlockMaxGasParam:
+			v, err := strconv.ParseUint(valueStr, 10, 64)
+			if err != nil {
+				return limits, fmt.Errorf("failed to parse param %q value: %w", key, err)
+			}
+			limits.MaxGas = v
+		}
+	}
+	return limits, nil
+}
+
+/*
+admit checks whether a transaction of given size, which may spend at most
+"maxGas" units of gas, can be added to a block with current usage "u".
+*/
+func (l BlockLimits) admit(u blockUsage, txSize, maxGas uint64) error {
+	if l.MaxTxCount > 0 && u.txCount >= l.MaxTxCount {
+		return fmt.Errorf("%w: transaction count %d reached", ErrBlockLimitExceeded, l.MaxTxCount)
+	}
+	if l.MaxSize > 0 && u.size+txSize > l.MaxSize {
+		return fmt.Errorf("%w: block size %d + %d exceeds %d bytes", ErrBlockLimitExceeded, u.size, txSize, l.MaxSize)
+	}
+	if l.MaxGas > 0 && u.gas+maxGas > l.MaxGas {
+		return fmt.Errorf("%w: block gas %d + %d exceeds %d", ErrBlockLimitExceeded, u.gas, maxGas, l.MaxGas)
+	}
+	return nil
+}
+
+// Validate checks that the transactions of a block do not exceed the limits.
+func (l BlockLimits) Validate(txs []*types.TransactionRecord) error {
+	var u blockUsage
+	for _, txr := range txs {
+		u.add(txr)
+	}
+	if l.MaxTxCount > 0 && u.txCount > l.MaxTxCount {
+		return fmt.Errorf("%w: block has %d transactions, allowed %d", ErrBlockLimitExceeded, u.txCount, l.MaxTxCount)
+	}
+	if l.MaxSize > 0 && u.size > l.MaxSize {
+		return fmt.Errorf("%w: block size is %d bytes, allowed %d", ErrBlockLimitExceeded, u.size, l.MaxSize)
+	}
+	if l.MaxGas > 0 && u.gas > l.MaxGas {
+		return fmt.Errorf("%w: block gas is %d, allowed %d", ErrBlockLimitExceeded, u.gas, l.MaxGas)
+	}
+	return nil
+}
+
+func (u *blockUsage) add(txr *types.TransactionRecord) {
+	u.txCount++
+	u.size += uint64(len(txr.TransactionOrder))
+	u.gas += txr.GetActualFee() * fc.GasUnitsPerTema
+}
+
+/*
+txGasBudget returns the maximum amount of gas the transaction may be
+charged for, ie the maximum fee authorized by the client in gas units.
+*/
+func txGasBudget(tx *types.TransactionOrder) uint64 {
+	return tx.MaxFee() * fc.GasUnitsPerTema
+}
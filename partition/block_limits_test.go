@@ -0,0 +1,153 @@
+package partition
+
+import (
+	gocrypto "crypto"
+	"strconv"
+	"testing"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+	test "github.com/alphabill-org/alphabill/internal/testutils"
+	testevent "github.com/alphabill-org/alphabill/internal/testutils/partition/event"
+	testtxsystem "github.com/alphabill-org/alphabill/internal/testutils/txsystem"
+	"github.com/alphabill-org/alphabill/network"
+	"github.com/alphabill-org/alphabill/network/protocol/blockproposal"
+	"github.com/alphabill-org/alphabill/partition/event"
+	"github.com/alphabill-org/alphabill/txsystem/fc"
+	testtransaction "github.com/alphabill-org/alphabill/txsystem/testutils/transaction"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBlockLimits(t *testing.T) {
+	t.Run("no limits", func(t *testing.T) {
+		limits, err := ParseBlockLimits(&types.PartitionDescriptionRecord{
+			PartitionParams: map[string]string{"initialBillValue": "100"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, BlockLimits{}, limits)
+	})
+
+	t.Run("all limits", func(t *testing.T) {
+		limits, err := ParseBlockLimits(&types.PartitionDescriptionRecord{
+			PartitionParams: map[string]string{
+				BlockMaxTxCountParam: "10",
+				BlockMaxSizeParam:    "2048",
+				BlockMaxGasParam:     "5000",
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, BlockLimits{MaxTxCount: 10, MaxSize: 2048, MaxGas: 5000}, limits)
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		_, err := ParseBlockLimits(&types.PartitionDescriptionRecord{
+			PartitionParams: map[string]string{BlockMaxTxCountParam: "-1"},
+		})
+		require.ErrorContains(t, err, `failed to parse param "blockMaxTxCount" value`)
+	})
+}
+
+func TestBlockLimits_Validate(t *testing.T) {
+	txr := func(size int, fee uint64) *types.TransactionRecord {
+		return &types.TransactionRecord{
+			TransactionOrder: make([]byte, size),
+			ServerMetadata:   &types.ServerMetadata{ActualFee: fee},
+		}
+	}
+	txs := []*types.TransactionRecord{txr(100, 1), txr(50, 2)}
+
+	require.NoError(t, BlockLimits{}.Validate(txs))
+	require.NoError(t, BlockLimits{MaxTxCount: 2, MaxSize: 150, MaxGas: 3 * fc.GasUnitsPerTema}.Validate(txs))
+	require.ErrorIs(t, BlockLimits{MaxTxCount: 1}.Validate(txs), ErrBlockLimitExceeded)
+	require.ErrorIs(t, BlockLimits{MaxSize: 149}.Validate(txs), ErrBlockLimitExceeded)
+	require.ErrorIs(t, BlockLimits{MaxGas: 3*fc.GasUnitsPerTema - 1}.Validate(txs), ErrBlockLimitExceeded)
+}
+
+func TestBlockLimits_admit(t *testing.T) {
+	limits := BlockLimits{MaxTxCount: 2, MaxSize: 100, MaxGas: 1000}
+
+	require.NoError(t, limits.admit(blockUsage{}, 100, 1000))
+	require.NoError(t, limits.admit(blockUsage{txCount: 1, size: 50, gas: 500}, 50, 500))
+	require.ErrorIs(t, limits.admit(blockUsage{txCount: 2}, 1, 1), ErrBlockLimitExceeded)
+	require.ErrorIs(t, limits.admit(blockUsage{txCount: 1, size: 50}, 51, 0), ErrBlockLimitExceeded)
+	require.ErrorIs(t, limits.admit(blockUsage{txCount: 1, gas: 500}, 0, 501), ErrBlockLimitExceeded)
+}
+
+func TestNode_LeaderStopsAtBlockLimit(t *testing.T) {
+	// all the test transactions are of the same size, the unit ID identifies the transaction
+	newTx := func(t *testing.T, i byte) *types.TransactionOrder {
+		return testtransaction.NewTransactionOrder(t, testtransaction.WithUnitID(append(make(types.UnitID, 32), i)))
+	}
+	txBytes, err := newTx(t, 0).MarshalCBOR()
+	require.NoError(t, err)
+	txSize := len(txBytes)
+	blockUnitIDs := func(t *testing.T, b *types.Block) (ids []types.UnitID) {
+		for _, txr := range b.Transactions {
+			txo, err := txr.GetTransactionOrderV1()
+			require.NoError(t, err)
+			ids = append(ids, txo.UnitID)
+		}
+		return ids
+	}
+	// every test case allows two transactions per block, the tx system charges
+	// fee 2 and the max fee of the test transactions is 2
+	testCases := []struct {
+		name   string
+		params map[string]string
+	}{
+		{name: "tx count", params: map[string]string{BlockMaxTxCountParam: "2"}},
+		{name: "size", params: map[string]string{BlockMaxSizeParam: strconv.Itoa(2*txSize + txSize/2)}},
+		{name: "gas", params: map[string]string{BlockMaxGasParam: strconv.FormatUint(5*fc.GasUnitsPerTema, 10)}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp := runSingleValidatorNodePartitionWithParams(t, &testtxsystem.CounterTxSystem{FixedState: testtxsystem.MockState{}, Fee: 2}, tc.params)
+			tp.WaitHandshake(t)
+
+			for i := range byte(3) {
+				require.NoError(t, tp.SubmitTx(newTx(t, i+1)))
+			}
+			// the proposal is sent out without waiting for the T1 timeout
+			require.Eventually(t, RequestReceived(tp, network.ProtocolBlockCertification), test.WaitDuration, test.WaitTick)
+			tp.eh.Reset()
+			tp.SubmitUnicityCertificate(t, tp.IssueBlockUC(t))
+			testevent.ContainsEvent(t, tp.eh, event.BlockFinalized)
+			require.Equal(t, []types.UnitID{newTx(t, 1).UnitID, newTx(t, 2).UnitID}, blockUnitIDs(t, tp.GetLatestBlock(t)))
+
+			// the transaction which didn't fit is included into the next block
+			// ahead of the transactions received later
+			tp.mockNet.ResetSentMessages(network.ProtocolBlockCertification)
+			require.NoError(t, tp.SubmitTx(newTx(t, 4)))
+			require.NoError(t, tp.SubmitTx(newTx(t, 5)))
+			require.Eventually(t, RequestReceived(tp, network.ProtocolBlockCertification), test.WaitDuration, test.WaitTick)
+			tp.eh.Reset()
+			tp.SubmitUnicityCertificate(t, tp.IssueBlockUC(t))
+			testevent.ContainsEvent(t, tp.eh, event.BlockFinalized)
+			require.Equal(t, []types.UnitID{newTx(t, 3).UnitID, newTx(t, 4).UnitID}, blockUnitIDs(t, tp.GetLatestBlock(t)))
+		})
+	}
+}
+
+func TestNode_FollowerRejectsProposalExceedingBlockLimit(t *testing.T) {
+	tp := runSingleValidatorNodePartitionWithParams(t, &testtxsystem.CounterTxSystem{}, map[string]string{BlockMaxTxCountParam: "1"})
+	tp.WaitHandshake(t)
+	uc1 := tp.GetCommittedUC(t)
+	uc2, _, err := tp.CreateUnicityCertificate(t,
+		uc1.InputRecord,
+		uc1.UnicitySeal.RootChainRoundNumber,
+	)
+	require.NoError(t, err)
+
+	bp := &blockproposal.BlockProposal{
+		PartitionID:        uc2.UnicityTreeCertificate.Partition,
+		NodeID:             tp.nodeID(t),
+		UnicityCertificate: uc2,
+		Transactions: []*types.TransactionRecord{
+			testtransaction.NewTransactionRecord(t),
+			testtransaction.NewTransactionRecord(t),
+		},
+	}
+	require.NoError(t, bp.Sign(gocrypto.SHA256, tp.nodeConf.signer))
+	tp.SubmitBlockProposal(bp)
+	ContainsError(t, tp, ErrBlockLimitExceeded.Error())
+	require.Empty(t, tp.mockNet.SentMessages(network.ProtocolBlockCertification))
+}
@@ -0,0 +1,306 @@
+package partition
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/alphabill-org/alphabill-go-base/cbor"
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill-go-base/util"
+
+	"github.com/alphabill-org/alphabill/keyvaluedb"
+	"github.com/alphabill-org/alphabill/txsystem"
+)
+
+/*
+ChainDivergence is returned by VerifyChain when the replayed chain doesn't match
+the certified one.
+*/
+type ChainDivergence struct {
+	Round uint64
+	// TxIndex is the index of the first transaction of the block whose
+	// execution result differs from the one recorded in the block, -1 when
+	// the divergence was detected on the block level.
+	TxIndex int
+	// UnitID of the first diverging unit, nil when it is not known.
+	UnitID types.UnitID
+	// Units are the target units of the transactions of the round, set when
+	// the divergence was detected on the block level (ie the diverging unit
+	// is not known but it must be one of these units).
+	Units []types.UnitID
+	Err   error
+}
+
+func (e *ChainDivergence) Error() string {
+	if e.TxIndex < 0 {
+		if len(e.Units) > 0 {
+			return fmt.Sprintf("round %d, units %s: %v", e.Round, e.Units, e.Err)
+		}
+		return fmt.Sprintf("round %d: %v", e.Round, e.Err)
+	}
+	return fmt.Sprintf("round %d, transaction %d, unit %s: %v", e.Round, e.TxIndex, e.UnitID, e.Err)
+}
+
+func (e *ChainDivergence) Unwrap() error { return e.Err }
+
+/*
+VerifyChain re-executes the blocks in the block store, starting from the round
+following the committed round of the transaction system, and checks that the
+resulting state root, summary value and block hash of each block match the
+InputRecord certified by the unicity certificate of the block. The unicity
+certificate of the block must commit to the shard configuration of the shard epoch
+of the block.
+
+Transactions are executed one by one, without using the parallel execution of
+the transaction system, so that the replay is the reference for the chain.
+
+Returns the round of the last verified block. When the replay diverges from the
+certified chain the error is of type *ChainDivergence. When the divergence can't
+be attributed to a transaction (ie the state root differs after the block has
+been executed) the target units of the round are reported as candidates.
+*/
+func VerifyChain(ctx context.Context, txSystem txsystem.TransactionSystem, blockStore keyvaluedb.KeyValueDB, ucValidator UnicityCertificateValidator, shardConfs ShardConfHashes, hashAlgorithm crypto.Hash, log *slog.Logger) (lastRound uint64, err error) {
+	committedUC := txSystem.CommittedUC()
+	lastRound = committedUC.GetRoundNumber()
+
+	dbIt := blockStore.Find(util.Uint64ToBytes(lastRound + 1))
+	defer func() { err = errors.Join(err, dbIt.Close()) }()
+	for ; dbIt.Valid(); dbIt.Next() {
+		if err := ctx.Err(); err != nil {
+			return lastRound, err
+		}
+		if len(dbIt.Key()) != 8 {
+			// not a block, ie pending block proposal
+			continue
+		}
+		var b types.Block
+		roundNo := util.BytesToUint64(dbIt.Key())
+		if err := dbIt.Value(&b); err != nil {
+			return lastRound, fmt.Errorf("failed to read block %d from db: %w", roundNo, err)
+		}
+		if err := verifyBlock(txSystem, &b, committedUC, ucValidator, shardConfs, hashAlgorithm); err != nil {
+			var d *ChainDivergence
+			if !errors.As(err, &d) {
+				d = &ChainDivergence{Round: roundNo, TxIndex: -1, Err: err}
+			}
+			if d.TxIndex < 0 {
+				d.Units = blockTargetUnits(&b)
+			}
+			return lastRound, d
+		}
+		committedUC = txSystem.CommittedUC()
+		lastRound = committedUC.GetRoundNumber()
+		log.DebugContext(ctx, fmt.Sprintf("verified block of round %d, %d transactions", lastRound, len(b.Transactions)))
+	}
+	return lastRound, nil
+}
+
+func verifyBlock(txSystem txsystem.TransactionSystem, b *types.Block, committedUC *types.UnicityCertificate, ucValidator UnicityCertificateValidator, shardConfs ShardConfHashes, hashAlgorithm crypto.Hash) error {
+	uc, err := VerifyCertifiedBlock(b, committedUC, ucValidator, shardConfs, hashAlgorithm)
+	if err != nil {
+		if uc == nil {
+			return err
+		}
+		return &ChainDivergence{Round: uc.GetRoundNumber(), TxIndex: -1, Err: err}
+	}
+	round := uc.GetRoundNumber()
+
+	state, err := txSystem.StateSummary()
+	if err != nil {
+		return fmt.Errorf("reading current state: %w", err)
+	}
+	// block must extend current state unless it's applied on uncertified genesis state
+	if committedUC != nil && !bytes.Equal(uc.InputRecord.PreviousHash, state.Root()) {
+		return &ChainDivergence{Round: round, TxIndex: -1,
+			Err: fmt.Errorf("block does not extend current state, expected state hash: %X, actual state hash: %X", uc.InputRecord.PreviousHash, state.Root())}
+	}
+
+	if err := txSystem.BeginBlock(round); err != nil {
+		return fmt.Errorf("beginning block: %w", err)
+	}
+	var sumOfEarnedFees uint64
+	for i, txr := range b.Transactions {
+		tx, err := txr.GetTransactionOrderV1()
+		if err != nil {
+			txSystem.Revert()
+			return fmt.Errorf("failed to get transaction order: %w", err)
+		}
+		replayed, err := txSystem.Execute(tx)
+		if err != nil {
+			txSystem.Revert()
+			return &ChainDivergence{Round: round, TxIndex: i, UnitID: tx.UnitID, Err: fmt.Errorf("executing transaction: %w", err)}
+		}
+		if unitID, err := compareServerMetadata(tx, txr.ServerMetadata, replayed.ServerMetadata); err != nil {
+			txSystem.Revert()
+			return &ChainDivergence{Round: round, TxIndex: i, UnitID: unitID, Err: err}
+		}
+		sumOfEarnedFees += replayed.GetActualFee()
+	}
+	state, err = txSystem.EndBlock()
+	if err != nil {
+		txSystem.Revert()
+		return fmt.Errorf("ending block: %w", err)
+	}
+	if err := verifyTxSystemState(state, sumOfEarnedFees, uc.InputRecord); err != nil {
+		txSystem.Revert()
+		return &ChainDivergence{Round: round, TxIndex: -1, Err: err}
+	}
+	if err := txSystem.Commit(uc); err != nil {
+		return fmt.Errorf("committing block: %w", err)
+	}
+	return nil
+}
+
+/*
+blockTargetUnits returns the (unique) target units of the transactions
+of the block, in the order of the transactions.
+*/
+func blockTargetUnits(b *types.Block) []types.UnitID {
+	var units []types.UnitID
+	seen := make(map[string]struct{})
+	for _, txr := range b.Transactions {
+		if txr.ServerMetadata == nil {
+			continue
+		}
+		for _, id := range txr.ServerMetadata.TargetUnits {
+			if _, ok := seen[string(id)]; !ok {
+				seen[string(id)] = struct{}{}
+				units = append(units, id)
+			}
+		}
+	}
+	return units
+}
+
+/*
+VerifyCertifiedBlock checks that the block is certified by a valid unicity
+certificate, which commits to the shard configuration of the shard epoch of the
+block, and, when "prevUC" is not nil, that the block extends the block certified
+by "prevUC". Returns the unicity certificate of the block, which is not nil when
+the error is caused by the content of the block.
+*/
+func VerifyCertifiedBlock(b *types.Block, prevUC *types.UnicityCertificate, ucValidator UnicityCertificateValidator, shardConfs ShardConfHashes, hashAlgorithm crypto.Hash) (*types.UnicityCertificate, error) {
+	uc, err := getUCv1(b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract UC from block: %w", err)
+	}
+	if uc.InputRecord == nil {
+		return nil, errors.New("unicity certificate input record is nil")
+	}
+	shardConfHash, err := shardConfs.ShardConfHash(uc.InputRecord.Epoch)
+	if err != nil {
+		return nil, fmt.Errorf("shard conf of the block of round %d: %w", uc.GetRoundNumber(), err)
+	}
+	if err := ucValidator.Validate(uc, shardConfHash); err != nil {
+		return uc, fmt.Errorf("invalid unicity certificate: %w", err)
+	}
+	if err := b.IsValid(hashAlgorithm, nil); err != nil {
+		return uc, fmt.Errorf("invalid block: %w", err)
+	}
+	ir, err := b.CalculateBlockHash(hashAlgorithm)
+	if err != nil {
+		return uc, fmt.Errorf("calculating block hash: %w", err)
+	}
+	if !bytes.Equal(ir.BlockHash, uc.InputRecord.BlockHash) {
+		return uc, fmt.Errorf("block hash '%X' not equal to unicity certificate value '%X'", ir.BlockHash, uc.InputRecord.BlockHash)
+	}
+	if prevUC == nil {
+		return uc, nil
+	}
+	if !uc.IsSuccessor(prevUC) {
+		return uc, fmt.Errorf("missing blocks after round %d", prevUC.GetRoundNumber())
+	}
+	if !bytes.Equal(b.Header.PreviousBlockHash, prevUC.GetBlockHash()) {
+		return uc, fmt.Errorf("previous block hash '%X' not equal to the hash of the previous block '%X'", b.Header.PreviousBlockHash, prevUC.GetBlockHash())
+	}
+	return uc, nil
+}
+
+/*
+EpochShardConfs returns the hashes of the shard configurations stored in the shard
+store of the node. The shard configuration given to NewEpochShardConfs is used for
+its epoch when the shard store doesn't have it (or there is no shard store).
+*/
+type EpochShardConfs struct {
+	shardConf *types.PartitionDescriptionRecord
+	store     *shardStore
+	hashes    map[uint64][]byte
+}
+
+/*
+NewEpochShardConfs returns the shard conf hashes of the shard store "db", which may be nil.
+*/
+func NewEpochShardConfs(shardConf *types.PartitionDescriptionRecord, db keyvaluedb.KeyValueDB) *EpochShardConfs {
+	s := &EpochShardConfs{shardConf: shardConf, hashes: make(map[uint64][]byte)}
+	if db != nil {
+		s.store = newShardStore(db, nil)
+	}
+	return s
+}
+
+// ShardConfHash returns the hash of the shard configuration of the shard epoch.
+func (s *EpochShardConfs) ShardConfHash(epoch uint64) ([]byte, error) {
+	if h, ok := s.hashes[epoch]; ok {
+		return h, nil
+	}
+	var shardConf *types.PartitionDescriptionRecord
+	if s.store != nil {
+		var err error
+		if shardConf, err = s.store.loadShardConf(epoch); err != nil && !errors.Is(err, errShardConfNotFound) {
+			return nil, fmt.Errorf("loading shard conf of epoch %d: %w", epoch, err)
+		}
+	}
+	if shardConf == nil && s.shardConf != nil && s.shardConf.Epoch == epoch {
+		shardConf = s.shardConf
+	}
+	if shardConf == nil {
+		return nil, fmt.Errorf("epoch %d: %w", epoch, errShardConfNotFound)
+	}
+	h, err := shardConf.Hash(crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("calculating shard conf hash: %w", err)
+	}
+	s.hashes[epoch] = h
+	return h, nil
+}
+
+/*
+compareServerMetadata returns an error when the execution result of the replayed
+transaction differs from the one recorded in the block. The unit ID returned is
+the first target unit which differs, or the unit of the transaction.
+*/
+func compareServerMetadata(tx *types.TransactionOrder, recorded, replayed *types.ServerMetadata) (types.UnitID, error) {
+	if recorded == nil || replayed == nil {
+		return tx.UnitID, fmt.Errorf("server metadata missing")
+	}
+	for i, id := range recorded.TargetUnits {
+		if i >= len(replayed.TargetUnits) || !bytes.Equal(id, replayed.TargetUnits[i]) {
+			return id, fmt.Errorf("target units %v not equal to recorded %v", replayed.TargetUnits, recorded.TargetUnits)
+		}
+	}
+	if len(replayed.TargetUnits) > len(recorded.TargetUnits) {
+		return replayed.TargetUnits[len(recorded.TargetUnits)], fmt.Errorf("target units %v not equal to recorded %v", replayed.TargetUnits, recorded.TargetUnits)
+	}
+	if recorded.SuccessIndicator != replayed.SuccessIndicator {
+		return tx.UnitID, fmt.Errorf("success indicator %d not equal to recorded %d", replayed.SuccessIndicator, recorded.SuccessIndicator)
+	}
+	if recorded.ActualFee != replayed.ActualFee {
+		return tx.UnitID, fmt.Errorf("actual fee %d not equal to recorded %d", replayed.ActualFee, recorded.ActualFee)
+	}
+	recordedBytes, err := cbor.Marshal(recorded)
+	if err != nil {
+		return tx.UnitID, fmt.Errorf("encoding recorded server metadata: %w", err)
+	}
+	replayedBytes, err := cbor.Marshal(replayed)
+	if err != nil {
+		return tx.UnitID, fmt.Errorf("encoding replayed server metadata: %w", err)
+	}
+	if !bytes.Equal(recordedBytes, replayedBytes) {
+		return tx.UnitID, fmt.Errorf("server metadata %X not equal to recorded %X", replayedBytes, recordedBytes)
+	}
+	return nil, nil
+}
@@ -0,0 +1,148 @@
+package partition
+
+import (
+	"context"
+	gocrypto "crypto"
+	"testing"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill-go-base/util"
+	"github.com/stretchr/testify/require"
+
+	testlogger "github.com/alphabill-org/alphabill/internal/testutils/logger"
+	testtxsystem "github.com/alphabill-org/alphabill/internal/testutils/txsystem"
+	"github.com/alphabill-org/alphabill/keyvaluedb/memorydb"
+	testtransaction "github.com/alphabill-org/alphabill/txsystem/testutils/transaction"
+)
+
+func TestVerifyChain(t *testing.T) {
+	txs := &testtxsystem.CounterTxSystem{Fee: 1}
+	tp := newSingleValidatorNodePartition(t, txs)
+	uc0 := txs.CommittedUC()
+
+	db, err := memorydb.New()
+	require.NoError(t, err)
+	txsCopy := txs.Clone()
+	block1, uc1 := createSameEpochBlock(t, tp, txsCopy, uc0)
+	block2, uc2 := createSameEpochBlock(t, tp, txsCopy, uc1, testtransaction.NewTransactionRecord(t))
+	block3, uc3 := createNextEpochBlock(t, tp, txsCopy, uc2, testtransaction.NewTransactionRecord(t), testtransaction.NewTransactionRecord(t))
+	block4, _ := createSameEpochBlock(t, tp, txsCopy, uc3, testtransaction.NewTransactionRecord(t))
+	require.NoError(t, db.Write(util.Uint64ToBytes(1), block1))
+	require.NoError(t, db.Write(util.Uint64ToBytes(2), block2))
+	require.NoError(t, db.Write(util.Uint64ToBytes(3), block3))
+	// pending block proposal is not part of the chain
+	require.NoError(t, db.Write(util.Uint32ToBytes(proposalKey), block4))
+
+	// genesis state of the transaction system
+	genesisTxSystem := func(fee uint64) *testtxsystem.CounterTxSystem {
+		s := &testtxsystem.CounterTxSystem{Fee: fee}
+		s.SetCommittedUC(uc0)
+		return s
+	}
+	log := testlogger.New(t)
+	// all the test blocks are certified with the shard conf of the node
+	shardConfHash, err := tp.nodeConf.shardConf.Hash(gocrypto.SHA256)
+	require.NoError(t, err)
+	shardConfs := shardConfHashFunc(func(epoch uint64) ([]byte, error) { return shardConfHash, nil })
+
+	t.Run("success", func(t *testing.T) {
+		lastRound, err := VerifyChain(context.Background(), genesisTxSystem(1), db, tp.nodeConf.ucValidator, shardConfs, gocrypto.SHA256, log)
+		require.NoError(t, err)
+		require.EqualValues(t, 3, lastRound)
+	})
+
+	t.Run("transaction result differs", func(t *testing.T) {
+		lastRound, err := VerifyChain(context.Background(), genesisTxSystem(2), db, tp.nodeConf.ucValidator, shardConfs, gocrypto.SHA256, log)
+		require.EqualValues(t, 1, lastRound)
+		var d *ChainDivergence
+		require.ErrorAs(t, err, &d)
+		require.EqualValues(t, 2, d.Round)
+		require.Equal(t, 0, d.TxIndex)
+		require.ErrorContains(t, err, "actual fee 2 not equal to recorded 1")
+		tx, err := block2.Transactions[0].GetTransactionOrderV1()
+		require.NoError(t, err)
+		require.Equal(t, tx.UnitID, d.UnitID)
+	})
+
+	t.Run("state differs", func(t *testing.T) {
+		s := genesisTxSystem(1)
+		s.EndBlockChangesState = true
+		lastRound, err := VerifyChain(context.Background(), s, db, tp.nodeConf.ucValidator, shardConfs, gocrypto.SHA256, log)
+		require.EqualValues(t, 0, lastRound)
+		var d *ChainDivergence
+		require.ErrorAs(t, err, &d)
+		require.EqualValues(t, 1, d.Round)
+		require.Equal(t, -1, d.TxIndex)
+		require.ErrorContains(t, err, "transaction system state does not match unicity certificate")
+		// block 1 has no transactions so there are no candidate units
+		require.Empty(t, d.Units)
+
+	})
+
+	t.Run("state differs after block with transactions", func(t *testing.T) {
+		// verify the first block only so that the replay continues from round 2
+		db1, err := memorydb.New()
+		require.NoError(t, err)
+		require.NoError(t, db1.Write(util.Uint64ToBytes(1), block1))
+		s := genesisTxSystem(1)
+		lastRound, err := VerifyChain(context.Background(), s, db1, tp.nodeConf.ucValidator, shardConfs, gocrypto.SHA256, log)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, lastRound)
+
+		s.EndBlockChangesState = true
+		lastRound, err = VerifyChain(context.Background(), s, db, tp.nodeConf.ucValidator, shardConfs, gocrypto.SHA256, log)
+		require.EqualValues(t, 1, lastRound)
+		var d *ChainDivergence
+		require.ErrorAs(t, err, &d)
+		require.EqualValues(t, 2, d.Round)
+		require.Equal(t, -1, d.TxIndex)
+		require.Nil(t, d.UnitID)
+		// the diverging unit must be one of the target units of the round
+		tx, err := block2.Transactions[0].GetTransactionOrderV1()
+		require.NoError(t, err)
+		require.Equal(t, []types.UnitID{tx.UnitID}, d.Units)
+	})
+
+	t.Run("invalid unicity certificate", func(t *testing.T) {
+		other := newSingleValidatorNodePartition(t, &testtxsystem.CounterTxSystem{})
+		_, err := VerifyChain(context.Background(), genesisTxSystem(1), db, other.nodeConf.ucValidator, shardConfs, gocrypto.SHA256, log)
+		var d *ChainDivergence
+		require.ErrorAs(t, err, &d)
+		require.EqualValues(t, 1, d.Round)
+		require.ErrorContains(t, err, "invalid unicity certificate")
+	})
+
+	t.Run("unicity certificate commits to another shard conf", func(t *testing.T) {
+		otherShardConfs := shardConfHashFunc(func(epoch uint64) ([]byte, error) {
+			if epoch == 1 {
+				return make([]byte, 32), nil
+			}
+			return shardConfHash, nil
+		})
+		lastRound, err := VerifyChain(context.Background(), genesisTxSystem(1), db, tp.nodeConf.ucValidator, otherShardConfs, gocrypto.SHA256, log)
+		require.EqualValues(t, 2, lastRound)
+		var d *ChainDivergence
+		require.ErrorAs(t, err, &d)
+		require.EqualValues(t, 3, d.Round)
+		require.ErrorContains(t, err, "invalid unicity certificate")
+	})
+
+	t.Run("shard conf of the epoch not known", func(t *testing.T) {
+		// only the shard conf of the epoch 0 is known
+		lastRound, err := VerifyChain(context.Background(), genesisTxSystem(1), db, tp.nodeConf.ucValidator, NewEpochShardConfs(tp.nodeConf.shardConf, nil), gocrypto.SHA256, log)
+		require.EqualValues(t, 2, lastRound)
+		require.ErrorIs(t, err, errShardConfNotFound)
+	})
+
+	t.Run("empty block store", func(t *testing.T) {
+		emptyDB, err := memorydb.New()
+		require.NoError(t, err)
+		lastRound, err := VerifyChain(context.Background(), genesisTxSystem(1), emptyDB, tp.nodeConf.ucValidator, shardConfs, gocrypto.SHA256, log)
+		require.NoError(t, err)
+		require.EqualValues(t, uc0.GetRoundNumber(), lastRound)
+	})
+}
+
+type shardConfHashFunc func(epoch uint64) ([]byte, error)
+
+func (f shardConfHashFunc) ShardConfHash(epoch uint64) ([]byte, error) { return f(epoch) }
@@ -1,9 +1,11 @@
 package partition
 
 import (
+	"bytes"
 	"crypto"
 	"errors"
 	"fmt"
+	"runtime"
 	"time"
 
 	p2pcrypto "github.com/libp2p/go-libp2p/core/crypto"
@@ -38,7 +40,8 @@ type (
 	NodeConf struct {
 		keyConf       *KeyConf
 		shardConf     *types.PartitionDescriptionRecord
-		trustBase     types.RootTrustBase
+		trustBase     types.RootTrustBase // genesis trust base of the root chain
+		orchestration *Orchestration
 		observability Observability
 
 		address               string
@@ -57,6 +60,10 @@ type (
 		proofIndexConfig proofIndexConfig
 		ownerIndexer     *OwnerIndexer
 		t1Timeout        time.Duration // T1 timeout of the node. Time to wait before node creates a new block proposal.
+		// number of workers verifying stateless parts of incoming transactions
+		txPrevalidationWorkers int
+		// number of workers executing the transactions of a block in parallel, see WithParallelExecution
+		parallelExecutionWorkers int
 
 		eventHandler             event.Handler
 		eventChCapacity          int
@@ -110,16 +117,10 @@ func NewNodeConf(
 	if trustBase == nil {
 		return nil, ErrTrustBaseIsNil
 	}
-	signer, err := keyConf.Signer()
-	if err != nil {
-		return nil, err
-	}
-
 	c := &NodeConf{
 		keyConf:       keyConf,
 		shardConf:     shardConf,
 		trustBase:     trustBase,
-		signer:        signer,
 		hashAlgorithm: crypto.SHA256,
 		proofIndexConfig: proofIndexConfig{
 			historyLen: 20,
@@ -129,6 +130,15 @@ func NewNodeConf(
 	for _, option := range nodeOptions {
 		option(c)
 	}
+	if c.signer == nil {
+		signer, err := keyConf.Signer()
+		if err != nil {
+			return nil, err
+		}
+		c.signer = signer
+	} else if err := c.verifySigner(); err != nil {
+		return nil, err
+	}
 	// init default for those not specified by the user
 	if err := c.initMissingDefaults(); err != nil {
 		return nil, fmt.Errorf("initializing missing configuration to default values: %w", err)
@@ -191,6 +201,18 @@ func WithBlockProposalValidator(blockProposalValidator BlockProposalValidator) N
 	}
 }
 
+/*
+WithSigner sets the signer of the block proposals and certification requests,
+by default the signer is created from the signing key of the key configuration.
+The public key of the signer must match the signing key of the key configuration
+(when present) and the signing key of the node in the shard configuration.
+*/
+func WithSigner(signer abcrypto.Signer) NodeOption {
+	return func(c *NodeConf) {
+		c.signer = signer
+	}
+}
+
 func WithBlockStore(blockStore keyvaluedb.KeyValueDB) NodeOption {
 	return func(c *NodeConf) {
 		c.blockStore = blockStore
@@ -235,6 +257,29 @@ func WithTxValidator(txValidator TxValidator) NodeOption {
 	}
 }
 
+/*
+WithTxPrevalidationWorkers sets the number of workers which verify the stateless
+parts of incoming transactions (signatures etc) in parallel before the transactions
+are added to the transaction buffer. By default the number of CPUs is used.
+*/
+func WithTxPrevalidationWorkers(workers int) NodeOption {
+	return func(c *NodeConf) {
+		c.txPrevalidationWorkers = workers
+	}
+}
+
+/*
+WithParallelExecution tells the node that the transaction system has been configured
+to execute the transactions of a block in parallel using given number of workers
+(see txsystem.WithParallelExecution). Only then the transactions of the block
+proposals are executed as a batch, by default they are executed one by one.
+*/
+func WithParallelExecution(workers int) NodeOption {
+	return func(c *NodeConf) {
+		c.parallelExecutionWorkers = workers
+	}
+}
+
 func WithBlockSubscriptionTimeout(t time.Duration) NodeOption {
 	return func(c *NodeConf) {
 		c.blockSubscriptionTimeout = t
@@ -266,19 +311,17 @@ func (c *NodeConf) initMissingDefaults() error {
 		}
 	}
 
+	if c.orchestration, err = LoadOrchestration(c.trustBase, c.shardStore); err != nil {
+		return fmt.Errorf("loading root trust bases: %w", err)
+	}
+
 	if c.bpValidator == nil {
-		c.bpValidator, err = NewDefaultBlockProposalValidator(
-			c.shardConf.PartitionID, c.shardConf.ShardID, c.trustBase, c.hashAlgorithm)
-		if err != nil {
-			return fmt.Errorf("initializing block proposal validator: %w", err)
-		}
+		c.bpValidator = newBlockProposalValidator(
+			c.shardConf.PartitionID, c.shardConf.ShardID, c.orchestration, c.hashAlgorithm)
 	}
 	if c.ucValidator == nil {
-		c.ucValidator, err = NewDefaultUnicityCertificateValidator(
-			c.shardConf.PartitionID, c.shardConf.ShardID, c.trustBase, c.hashAlgorithm)
-		if err != nil {
-			return fmt.Errorf("initializing unicity certificate validator: %w", err)
-		}
+		c.ucValidator = newUnicityCertificateValidator(
+			c.shardConf.PartitionID, c.shardConf.ShardID, c.orchestration, c.hashAlgorithm)
 	}
 	if c.txValidator == nil {
 		c.txValidator, err = NewDefaultTxValidator(c.PartitionID())
@@ -301,6 +344,9 @@ func (c *NodeConf) initMissingDefaults() error {
 	if c.blockSubscriptionTimeout == 0 {
 		c.blockSubscriptionTimeout = DefaultBlockSubscriptionTimeout
 	}
+	if c.txPrevalidationWorkers <= 0 {
+		c.txPrevalidationWorkers = runtime.NumCPU()
+	}
 	return nil
 }
 
@@ -329,14 +375,56 @@ func (c *NodeConf) TrustBase() types.RootTrustBase {
 	return c.trustBase
 }
 
-func (c *NodeConf) Orchestration() Orchestration {
-	return Orchestration{c.trustBase}
+func (c *NodeConf) Orchestration() *Orchestration {
+	return c.orchestration
 }
 
 func (c *NodeConf) Observability() Observability {
 	return c.observability
 }
 
+/*
+verifySigner checks that the signer set with WithSigner (ie the remote signer)
+signs with the signing key of the node.
+*/
+func (c *NodeConf) verifySigner() error {
+	verifier, err := c.signer.Verifier()
+	if err != nil {
+		return fmt.Errorf("invalid signer: %w", err)
+	}
+	pubKey, err := verifier.MarshalPublicKey()
+	if err != nil {
+		return fmt.Errorf("marshaling public key of the signer: %w", err)
+	}
+	if len(c.keyConf.SigKey.PrivateKey) > 0 {
+		keySigner, err := c.keyConf.Signer()
+		if err != nil {
+			return err
+		}
+		keyVerifier, err := keySigner.Verifier()
+		if err != nil {
+			return fmt.Errorf("invalid signing key: %w", err)
+		}
+		sigKey, err := keyVerifier.MarshalPublicKey()
+		if err != nil {
+			return fmt.Errorf("marshaling public key of the signing key: %w", err)
+		}
+		if !bytes.Equal(pubKey, sigKey) {
+			return fmt.Errorf("public key of the signer %X doesn't match the signing key %X of the key configuration", pubKey, sigKey)
+		}
+	}
+	nodeID, err := c.keyConf.NodeID()
+	if err != nil {
+		return err
+	}
+	for _, v := range c.shardConf.Validators {
+		if v.NodeID == nodeID.String() && !bytes.Equal(pubKey, v.SigKey) {
+			return fmt.Errorf("public key of the signer %X doesn't match the signing key %X of the node in the shard configuration", pubKey, []byte(v.SigKey))
+		}
+	}
+	return nil
+}
+
 func (c *NodeConf) HashAlgorithm() crypto.Hash {
 	return c.hashAlgorithm
 }
@@ -371,8 +459,19 @@ func (c *NodeConf) OwnerIndexer() *OwnerIndexer {
 	return c.ownerIndexer
 }
 
+/*
+getRootNodes returns the validators of the last root epoch known to the node.
+*/
 func (c *NodeConf) getRootNodes() (peer.IDSlice, error) {
-	nodes := c.trustBase.GetRootNodes()
+	trustBase, err := c.orchestration.TrustBase(c.orchestration.LastEpoch())
+	if err != nil {
+		return nil, err
+	}
+	return rootNodeIDs(trustBase)
+}
+
+func rootNodeIDs(trustBase types.RootTrustBase) (peer.IDSlice, error) {
+	nodes := trustBase.GetRootNodes()
 	idSlice := make(peer.IDSlice, len(nodes))
 	for i, node := range nodes {
 		id, err := peer.Decode(node.NodeID)
@@ -115,3 +115,40 @@ func TestNewNodeConf_WithDefaults(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, rootNodes, 1)
 }
+
+func TestNewNodeConf_WithSigner(t *testing.T) {
+	keyConf, nodeInfo := createKeyConf(t)
+	shardConf := &types.PartitionDescriptionRecord{
+		Version:         1,
+		NetworkID:       5,
+		PartitionID:     0x01010101,
+		PartitionTypeID: 999,
+		TypeIDLen:       8,
+		UnitIDLen:       256,
+		T2Timeout:       2500 * time.Millisecond,
+		EpochStart:      1,
+		Validators:      []*types.NodeInfo{nodeInfo},
+	}
+	signer, verifier := testsig.CreateSignerAndVerifier(t)
+	trustBase := trustbase.NewTrustBase(t, verifier)
+
+	// signer doesn't use the signing key of the key configuration
+	_, err := NewNodeConf(keyConf, shardConf, trustBase, testobserve.Default(t), WithSigner(signer))
+	require.ErrorContains(t, err, "doesn't match the signing key")
+
+	// the signing key is not needed when the signer is provided
+	keyConf.SigKey = Key{}
+	_, err = NewNodeConf(keyConf, shardConf, trustBase, testobserve.Default(t))
+	require.ErrorContains(t, err, "invalid signing key")
+
+	// signer doesn't use the signing key of the node in the shard configuration
+	_, err = NewNodeConf(keyConf, shardConf, trustBase, testobserve.Default(t), WithSigner(signer))
+	require.ErrorContains(t, err, "doesn't match the signing key")
+	require.ErrorContains(t, err, "of the node in the shard configuration")
+
+	nodeInfo.SigKey, err = verifier.MarshalPublicKey()
+	require.NoError(t, err)
+	conf, err := NewNodeConf(keyConf, shardConf, trustBase, testobserve.Default(t), WithSigner(signer))
+	require.NoError(t, err)
+	require.Equal(t, signer, conf.signer)
+}
@@ -21,6 +21,7 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/alphabill-org/alphabill-go-base/cbor"
+	abhash "github.com/alphabill-org/alphabill-go-base/hash"
 	"github.com/alphabill-org/alphabill-go-base/types"
 	"github.com/alphabill-org/alphabill-go-base/util"
 
@@ -31,6 +32,8 @@ import (
 	"github.com/alphabill-org/alphabill/network/protocol/certification"
 	"github.com/alphabill-org/alphabill/network/protocol/handshake"
 	"github.com/alphabill-org/alphabill/network/protocol/replication"
+	"github.com/alphabill-org/alphabill/network/protocol/rootepoch"
+	"github.com/alphabill-org/alphabill/network/protocol/shardconf"
 	"github.com/alphabill-org/alphabill/observability"
 	"github.com/alphabill-org/alphabill/partition/event"
 	"github.com/alphabill-org/alphabill/txsystem"
@@ -103,16 +106,23 @@ type (
 		// Can be nil if latest UC was received with a block (recovery or block propagation protocols).
 		ltr                  atomic.Pointer[certification.TechnicalRecord]
 		proposedTransactions []*types.TransactionRecord
+		proposalUsage        blockUsage
 		sumOfEarnedFees      uint64
 		pendingBlockProposal *types.Block
 		leader               Leader
 		blockStore           keyvaluedb.KeyValueDB
 		proofIndexer         *ProofIndexer
 		ownerIndexer         *OwnerIndexer
+		txPrevalidator       *txPrevalidator
 		stopTxProcessor      atomic.Value
 		t1event              chan struct{}
 		epochChangeEvent     chan struct{}
 		peer                 *network.Peer
+		// transaction which didn't fit into the previous block proposal, it is processed
+		// before the transactions in the buffer when the node builds the next proposal
+		overflowTx atomic.Pointer[types.TransactionOrder]
+		// shard conf of the current epoch received from the root chain before any UC committed to it
+		pendingShardConf atomic.Pointer[types.PartitionDescriptionRecord]
 
 		rootNodes         peer.IDSlice
 		shardStore        *shardStore
@@ -197,6 +207,7 @@ func NewNode(ctx context.Context, txSystem txsystem.TransactionSystem, conf *Nod
 		tracer:            tracer,
 	}
 	n.log = conf.observability.RoundLogger(n.currentRoundNumber)
+	n.txPrevalidator = newTxPrevalidator(conf.txPrevalidationWorkers, n.prevalidateTx)
 	n.proofIndexer = NewProofIndexer(conf.hashAlgorithm, conf.proofIndexConfig.store,
 		conf.proofIndexConfig.historyLen, observability.WithLogger(conf.observability, n.log))
 	n.resetProposal()
@@ -320,6 +331,10 @@ func (n *Node) Run(ctx context.Context) error {
 		return n.proofIndexer.loop(ctx)
 	})
 
+	g.Go(func() error {
+		return n.txPrevalidator.Run(ctx)
+	})
+
 	g.Go(func() error {
 		err := n.loop(ctx)
 		n.log.DebugContext(ctx, "node main loop exit", logger.Error(err))
@@ -372,6 +387,7 @@ func (n *Node) initNetwork(ctx context.Context, peerConf *network.PeerConfigurat
 
 	opts := network.DefaultValidatorNetworkOptions
 	opts.TxBufferHashAlgorithm = n.conf.hashAlgorithm
+	opts.TxPrevalidator = n.txPrevalidator
 
 	n.network, err = network.NewLibP2PValidatorNetwork(ctx, n, opts, observe)
 	if err != nil {
@@ -434,6 +450,144 @@ func (n *Node) sendHandshake(ctx context.Context) {
 	}
 }
 
+/*
+requestTrustBase asks the root chain for the trust base of the root epoch following
+the last root epoch known to the node.
+*/
+func (n *Node) requestTrustBase(ctx context.Context) {
+	epoch := n.conf.orchestration.LastEpoch() + 1
+	rootIDs, err := randomNodeSelector(n.rootNodes, defaultHandshakeNodes)
+	if err != nil {
+		n.log.WarnContext(ctx, "selecting root nodes for trust base request", logger.Error(err))
+		return
+	}
+	n.log.InfoContext(ctx, fmt.Sprintf("requesting trust base of the root epoch %d", epoch))
+	if err = n.network.Send(ctx,
+		rootepoch.TrustBaseRequest{
+			PartitionID: n.PartitionID(),
+			ShardID:     n.ShardID(),
+			NodeID:      n.peer.ID().String(),
+			Epoch:       epoch,
+		},
+		rootIDs...); err != nil {
+		n.log.WarnContext(ctx, "error sending trust base request", logger.Error(err))
+	}
+}
+
+/*
+handleTrustBaseResponse adds the trust base of the next root epoch received from the
+root chain, the certification requests are sent to the root nodes of the new epoch.
+*/
+func (n *Node) handleTrustBaseResponse(ctx context.Context, rsp *rootepoch.TrustBaseResponse) error {
+	added, err := n.conf.orchestration.AddTrustBase(rsp)
+	if err != nil {
+		return fmt.Errorf("adding trust base: %w", err)
+	}
+	if !added {
+		return nil
+	}
+	rootNodes, err := rootNodeIDs(rsp.TrustBase)
+	if err != nil {
+		return err
+	}
+	n.rootNodes = rootNodes
+	n.log.InfoContext(ctx, fmt.Sprintf("added trust base of the root epoch %d", rsp.Epoch))
+	return nil
+}
+
+/*
+requestShardConf asks the root chain for the shard conf of the epoch the node hasn't
+been able to load.
+*/
+func (n *Node) requestShardConf(ctx context.Context, epoch uint64) {
+	rootIDs, err := randomNodeSelector(n.rootNodes, defaultHandshakeNodes)
+	if err != nil {
+		n.log.WarnContext(ctx, "selecting root nodes for shard conf request", logger.Error(err))
+		return
+	}
+	n.log.InfoContext(ctx, fmt.Sprintf("requesting shard conf of the epoch %d", epoch))
+	if err = n.network.Send(ctx,
+		shardconf.ShardConfRequest{
+			PartitionID: n.PartitionID(),
+			ShardID:     n.ShardID(),
+			NodeID:      n.peer.ID().String(),
+			Epoch:       epoch,
+		},
+		rootIDs...); err != nil {
+		n.log.WarnContext(ctx, "error sending shard conf request", logger.Error(err))
+	}
+}
+
+/*
+fetchShardConf requests the shard conf of the current epoch when the node hasn't been
+able to load it yet. It is called on every T1 timeout and monitoring tick (and the epoch
+change event triggered by every new UC) until the shard store catches up with the epoch.
+*/
+func (n *Node) fetchShardConf(ctx context.Context) {
+	if epoch := n.currentEpoch(); n.shardStore.LoadedEpoch() != epoch {
+		n.requestShardConf(ctx, epoch)
+	}
+}
+
+/*
+handleShardConfResponse stores the shard conf of the current epoch received from the
+root chain and triggers the epoch change. The shard conf is accepted only when its hash
+matches the shard conf hash certified in the latest unicity certificate. The UC which
+announces the epoch change still commits to the shard conf of the previous epoch so the
+shard conf is kept until a UC committing to it is received. As the node can't certify
+blocks of the new epoch without its shard conf, that UC is the repeat UC issued by the
+root chain after the T2 timeout, ie the shard stalls for a T2 timeout at the epoch change
+unless the shard conf has been submitted to the node in advance.
+*/
+func (n *Node) handleShardConfResponse(ctx context.Context, rsp *shardconf.ShardConfResponse) error {
+	if err := rsp.IsValid(); err != nil {
+		return fmt.Errorf("invalid shard conf response: %w", err)
+	}
+	epoch := n.currentEpoch()
+	if rsp.ShardConf.Epoch != epoch || n.shardStore.LoadedEpoch() == epoch {
+		// not the shard conf the node is waiting for
+		return nil
+	}
+	if n.luc.Load() == nil || n.ltr.Load() == nil {
+		return errors.New("shard conf can't be verified without the latest unicity certificate")
+	}
+	n.pendingShardConf.Store(rsp.ShardConf)
+	return n.registerPendingShardConf(ctx)
+}
+
+/*
+registerPendingShardConf registers the shard conf received from the root chain once the
+latest UC commits to it and triggers the epoch change.
+*/
+func (n *Node) registerPendingShardConf(ctx context.Context) error {
+	shardConf := n.pendingShardConf.Load()
+	if shardConf == nil {
+		return nil
+	}
+	if shardConf.Epoch != n.currentEpoch() || n.shardStore.LoadedEpoch() == shardConf.Epoch {
+		n.pendingShardConf.CompareAndSwap(shardConf, nil)
+		return nil
+	}
+	luc := n.luc.Load()
+	if luc == nil {
+		return nil
+	}
+	rsp := shardconf.ShardConfResponse{ShardConf: shardConf}
+	if err := rsp.Verify(luc.ShardConfHash, n.conf.hashAlgorithm); err != nil {
+		n.log.DebugContext(ctx, fmt.Sprintf("shard conf of the epoch %d is not certified yet", shardConf.Epoch), logger.Error(err))
+		return nil
+	}
+	n.pendingShardConf.CompareAndSwap(shardConf, nil)
+	if err := n.RegisterShardConf(shardConf); err != nil {
+		return err
+	}
+	select {
+	case n.epochChangeEvent <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
 func verifyTxSystemState(state *txsystem.StateSummary, sumOfEarnedFees uint64, ucIR *types.InputRecord) error {
 	if ucIR == nil {
 		return errors.New("unicity certificate input record is nil")
@@ -458,16 +612,17 @@ func (n *Node) applyBlockTransactions(ctx context.Context, round uint64, txs []*
 	if err := n.transactionSystem.BeginBlock(round); err != nil {
 		return nil, 0, err
 	}
-	for _, txr := range txs {
-		txo, err := txr.GetTransactionOrderV1()
-		if err != nil {
-			return nil, 0, fmt.Errorf("failed to get transaction order: %w", err)
-		}
-		tr, err := n.validateAndExecuteTx(ctx, txo, round)
-		if err != nil {
-			n.log.WarnContext(ctx, "processing transaction", logger.Error(err), logger.UnitID(txo.UnitID))
-			return nil, 0, fmt.Errorf("processing transaction '%v': %w", txo.UnitID, err)
-		}
+	txos, err := transactionOrders(txs)
+	if err != nil {
+		return nil, 0, err
+	}
+	trs, err := n.validateAndExecuteTxs(ctx, txos, round)
+	if err != nil {
+		txo := txos[len(trs)]
+		n.log.WarnContext(ctx, "processing transaction", logger.Error(err), logger.UnitID(txo.UnitID))
+		return nil, 0, fmt.Errorf("processing transaction '%v': %w", txo.UnitID, err)
+	}
+	for _, tr := range trs {
 		sumOfEarnedFees += tr.GetActualFee()
 	}
 	state, err := n.transactionSystem.EndBlock()
@@ -610,6 +765,10 @@ func (n *Node) handleMessage(ctx context.Context, msg any) (rErr error) {
 		return n.handleLedgerReplicationResponse(ctx, mt)
 	case *types.Block:
 		return n.handleBlock(ctx, mt)
+	case *rootepoch.TrustBaseResponse:
+		return n.handleTrustBaseResponse(ctx, mt)
+	case *shardconf.ShardConfResponse:
+		return n.handleShardConfResponse(ctx, mt)
 	default:
 		return fmt.Errorf("unknown message: %T", mt)
 	}
@@ -651,6 +810,14 @@ func statusCodeOfTxError(err error) string {
 
 func (n *Node) process(ctx context.Context, tx *types.TransactionOrder) error {
 	trx, err := n.validateAndExecuteTx(ctx, tx, n.currentRoundNumber())
+	return n.addToProposal(ctx, tx, trx, err)
+}
+
+/*
+addToProposal adds the executed transaction to the block proposal, "err" is the
+error returned by the execution of the transaction.
+*/
+func (n *Node) addToProposal(ctx context.Context, tx *types.TransactionOrder, trx *types.TransactionRecord, err error) error {
 	if err != nil || (n.IsFeelessMode() && trx.TxStatus() != types.TxStatusSuccessful) {
 		n.sendEvent(event.TransactionFailed, tx)
 		if err == nil {
@@ -663,12 +830,74 @@ func (n *Node) process(ctx context.Context, tx *types.TransactionOrder) error {
 		return fmt.Errorf("executing transaction %X: %w", txHash, err)
 	}
 	n.proposedTransactions = append(n.proposedTransactions, trx)
+	n.proposalUsage.add(trx)
 	n.sumOfEarnedFees += trx.GetActualFee()
 	n.sendEvent(event.TransactionProcessed, tx)
 	n.log.DebugContext(ctx, fmt.Sprintf("transaction processed, proposal size: %d", len(n.proposedTransactions)), logger.UnitID(tx.UnitID))
 	return nil
 }
 
+/*
+processLeaderTx executes the transaction as part of the block proposal built by
+the leader. When the transaction doesn't fit into the proposal (see BlockLimits)
+it is kept for the next proposal, the proposal is sent out without waiting for
+the T1 timeout and network.ErrStopProcessing is returned so that no more
+transactions are pulled from the buffer.
+*/
+func (n *Node) processLeaderTx(ctx context.Context, tx *types.TransactionOrder) error {
+	limits := n.shardStore.BlockLimits()
+	txBytes, err := tx.MarshalCBOR()
+	if err != nil {
+		return fmt.Errorf("encoding transaction: %w", err)
+	}
+	if err := limits.admit(n.proposalUsage, uint64(len(txBytes)), txGasBudget(tx)); err != nil {
+		if n.proposalUsage.txCount == 0 {
+			// the transaction wouldn't fit even into an empty block
+			n.sendEvent(event.TransactionFailed, tx)
+			return fmt.Errorf("transaction rejected: %w", err)
+		}
+		n.log.DebugContext(ctx, "block proposal is full", logger.Error(err))
+		n.overflowTx.Store(tx)
+		n.signalProposalFull(ctx)
+		return network.ErrStopProcessing
+	}
+	if err := n.process(ctx, tx); err != nil {
+		return err
+	}
+	if limits.MaxTxCount > 0 && n.proposalUsage.txCount >= limits.MaxTxCount {
+		n.signalProposalFull(ctx)
+		return network.ErrStopProcessing
+	}
+	return nil
+}
+
+/*
+takeOverflowTx returns the transaction which didn't fit into the previous block
+proposal. When the node is not the leader of the round the transaction is returned
+to the buffer to be forwarded to the leader.
+*/
+func (n *Node) takeOverflowTx(ctx context.Context) *types.TransactionOrder {
+	tx := n.overflowTx.Swap(nil)
+	if tx == nil || n.leader.IsLeader(n.peer.ID()) {
+		return tx
+	}
+	if _, err := n.network.AddTransaction(ctx, tx); err != nil {
+		n.log.WarnContext(ctx, "returning transaction to the buffer", logger.Error(err), logger.UnitID(tx.UnitID))
+	}
+	return nil
+}
+
+/*
+signalProposalFull triggers the T1 timeout event early, ie the leader
+sends out the block proposal without waiting for the T1 timer.
+*/
+func (n *Node) signalProposalFull(ctx context.Context) {
+	select {
+	case n.t1event <- struct{}{}:
+	case <-ctx.Done():
+	}
+}
+
 func (n *Node) validateAndExecuteTx(ctx context.Context, tx *types.TransactionOrder, round uint64) (_ *types.TransactionRecord, rErr error) {
 	defer func(start time.Time) {
 		txTypeAttr := attribute.Int("tx", int(tx.Type))
@@ -686,6 +915,72 @@ func (n *Node) validateAndExecuteTx(ctx context.Context, tx *types.TransactionOr
 	return txr, nil
 }
 
+/*
+validateAndExecuteTxs validates and executes the transactions of a block, using
+the batch execution of the transaction system when parallel execution has been
+enabled (see WithParallelExecution). Processing stops at the first failing
+transaction, ie the error returned is for the transaction txs[len(records)].
+*/
+func (n *Node) validateAndExecuteTxs(ctx context.Context, txs []*types.TransactionOrder, round uint64) ([]*types.TransactionRecord, error) {
+	batchExecutor, ok := n.transactionSystem.(txsystem.BatchExecutor)
+	if !ok || n.conf.parallelExecutionWorkers < 2 || len(txs) < 2 {
+		trs := make([]*types.TransactionRecord, 0, len(txs))
+		for _, tx := range txs {
+			tr, err := n.validateAndExecuteTx(ctx, tx, round)
+			if err != nil {
+				return trs, err
+			}
+			trs = append(trs, tr)
+		}
+		return trs, nil
+	}
+
+	start := time.Now()
+	// transactions preceding the first invalid one are executed as they
+	// would have been when processing the transactions one by one
+	valid := len(txs)
+	var invalidErr error
+	for i, tx := range txs {
+		if err := n.conf.txValidator.Validate(tx, round); err != nil {
+			valid = i
+			invalidErr = fmt.Errorf("invalid transaction: %w", err)
+			break
+		}
+	}
+	trs, err := batchExecutor.ExecuteBatch(txs[:valid])
+	if err != nil {
+		err = fmt.Errorf("executing transaction in transaction system: %w", err)
+	} else {
+		err = invalidErr
+	}
+	// the execution time of an individual transaction of the batch is not
+	// known so the duration of the batch is divided evenly between them
+	processed := txs[:min(len(trs)+1, len(txs))]
+	txDur := time.Since(start).Seconds() / float64(len(processed))
+	for i, tx := range processed {
+		var txErr error
+		if i == len(trs) {
+			txErr = err
+		}
+		txTypeAttr := attribute.Int("tx", int(tx.Type))
+		n.execTxCnt.Add(ctx, 1, metric.WithAttributeSet(attribute.NewSet(txTypeAttr, attribute.String("status", statusCodeOfTxError(txErr)))), n.fixedAttr)
+		n.execTxDur.Record(ctx, txDur, metric.WithAttributeSet(attribute.NewSet(txTypeAttr)), n.fixedAttr)
+	}
+	return trs, err
+}
+
+func transactionOrders(txs []*types.TransactionRecord) ([]*types.TransactionOrder, error) {
+	txos := make([]*types.TransactionOrder, 0, len(txs))
+	for _, txr := range txs {
+		txo, err := txr.GetTransactionOrderV1()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get transaction order: %w", err)
+		}
+		txos = append(txos, txo)
+	}
+	return txos, nil
+}
+
 // handleBlockProposal processes a block proposals. Performs the following steps:
 //  1. Block proposal as a whole is validated:
 //     * It must have valid signature, correct transaction partition ID, valid UC;
@@ -716,6 +1011,9 @@ func (n *Node) handleBlockProposal(ctx context.Context, prop *blockproposal.Bloc
 	// Let's not verify shardConfHash inside the UC of BlockProposal,
 	// we might not have the shardConf for it.
 	if err := n.conf.bpValidator.Validate(prop, sigVerifier, nil); err != nil {
+		if errors.Is(err, ErrUnknownRootEpoch) {
+			n.requestTrustBase(ctx)
+		}
 		return fmt.Errorf("block proposal validation failed, %w", err)
 	}
 
@@ -755,15 +1053,26 @@ func (n *Node) handleBlockProposal(ctx context.Context, prop *blockproposal.Bloc
 	if !uc.IsInitial() && !bytes.Equal(uc.GetStateHash(), txState.Root()) {
 		return fmt.Errorf("transaction system start state mismatch error, expected: %X, got: %X", txState.Root(), uc.GetStateHash())
 	}
+	// Reject oversized proposals before executing the transactions.
+	blockLimits := n.shardStore.BlockLimits()
+	if err := blockLimits.Validate(prop.Transactions); err != nil {
+		return fmt.Errorf("invalid block proposal: %w", err)
+	}
 	if err := n.transactionSystem.BeginBlock(n.currentRoundNumber()); err != nil {
 		return fmt.Errorf("transaction system BeginBlock error, %w", err)
 	}
-	for _, tx := range prop.Transactions {
-		txo, err := tx.GetTransactionOrderV1()
-		if err != nil {
-			return fmt.Errorf("failed to get transaction order: %w", err)
+	txos, err := transactionOrders(prop.Transactions)
+	if err != nil {
+		return err
+	}
+	trs, execErr := n.validateAndExecuteTxs(ctx, txos, n.currentRoundNumber())
+	for i, txo := range txos[:min(len(trs)+1, len(txos))] {
+		var trx *types.TransactionRecord
+		txErr := execErr
+		if i < len(trs) {
+			trx, txErr = trs[i], nil
 		}
-		if err = n.process(ctx, txo); err != nil {
+		if err = n.addToProposal(ctx, txo, trx, txErr); err != nil {
 			txHash, err2 := txo.Hash(n.conf.hashAlgorithm)
 			if err2 != nil {
 				return fmt.Errorf("hashing transaction during processing: %w", err2)
@@ -771,6 +1080,10 @@ func (n *Node) handleBlockProposal(ctx context.Context, prop *blockproposal.Bloc
 			return fmt.Errorf("processing transaction %X: %w", txHash, err)
 		}
 	}
+	// The gas was checked against the fees claimed by the leader, verify it against the fees actually charged.
+	if err := blockLimits.Validate(n.proposedTransactions); err != nil {
+		return fmt.Errorf("invalid block proposal: %w", err)
+	}
 	if err = n.sendCertificationRequest(ctx, prop.NodeID.String()); err != nil {
 		return fmt.Errorf("certification request send failed, %w", err)
 	}
@@ -785,17 +1098,40 @@ func (n *Node) updateLUC(ctx context.Context, uc *types.UnicityCertificate, tr *
 	}
 
 	// Only verify shardConfHash if we have received a supposedly current UC (with TR) _and_
-	// UC epoch matches the loaded epoch. If loaded epoch doesn't match then shardConfHashes can't match either,
-	// but we still need to process the UC to trigger epoch change.
+	// the epoch of the shard conf the UC commits to matches the loaded epoch. If loaded epoch doesn't
+	// match then shardConfHashes can't match either, but we still need to process the UC to trigger
+	// epoch change. Once the previous TR has announced the next epoch the root chain commits to the
+	// shard conf of the next epoch, even in the repeat UCs of the last round of the current epoch.
 	var shardConfHash []byte
-	if tr != nil && n.shardStore.LoadedEpoch() == uc.InputRecord.Epoch {
-		shardConfHash = n.shardStore.ShardConfHash()
+	ucEpoch := uc.InputRecord.Epoch
+	ltr := n.ltr.Load()
+	announced := ltr != nil && ltr.Epoch > ucEpoch && !uc.IsDuplicate(n.luc.Load())
+	if announced {
+		ucEpoch = ltr.Epoch
+	}
+	if tr != nil {
+		if n.shardStore.LoadedEpoch() == ucEpoch {
+			shardConfHash = n.shardStore.ShardConfHash()
+		} else if announced {
+			// The epoch change hasn't been handled yet or the node doesn't have the shard conf of
+			// the announced epoch. In the latter case the UC, verified against the root trust base,
+			// is accepted without the check as the shard conf fetched from the root chain is verified
+			// against the hash certified in it (see registerPendingShardConf).
+			hash, err := n.shardStore.EpochShardConfHash(ucEpoch)
+			if err != nil && !errors.Is(err, errShardConfNotFound) {
+				return fmt.Errorf("loading shard conf hash of the epoch %d: %w", ucEpoch, err)
+			}
+			shardConfHash = hash
+		}
 	}
 
 	// UC is validated cryptographically.
 	// TR has already been validated to match the hash in UC
 	// when receiving a CertificationResponse or a BlockProposal.
 	if err := n.conf.ucValidator.Validate(uc, shardConfHash); err != nil {
+		if errors.Is(err, ErrUnknownRootEpoch) {
+			n.requestTrustBase(ctx)
+		}
 		n.sendEvent(event.Error, err)
 		return fmt.Errorf("certificate invalid, %w", err)
 	}
@@ -1129,6 +1465,8 @@ func (n *Node) handleT1TimeoutEvent(ctx context.Context) {
 	ctx, span := n.tracer.Start(ctx, "node.handleT1TimeoutEvent", trace.WithNewRoot(), trace.WithAttributes(n.attrRound()))
 	defer span.End()
 
+	n.fetchShardConf(ctx)
+
 	if !n.IsValidator() {
 		n.log.DebugContext(ctx, "T1 timeout: node is non-validator")
 		return
@@ -1159,12 +1497,18 @@ func (n *Node) handleT1TimeoutEvent(ctx context.Context) {
 }
 
 func (n *Node) handleEpochChangeEvent(ctx context.Context) {
+	if err := n.registerPendingShardConf(ctx); err != nil {
+		n.log.WarnContext(ctx, "registering shard conf received from the root chain", logger.Error(err))
+	}
 	wasValidator := n.IsValidator()
 	newEpoch := n.currentEpoch()
 
 	if err := n.shardStore.LoadEpoch(newEpoch); err != nil {
 		// Log the error and let the node continue with the old configuration
 		n.log.ErrorContext(ctx, fmt.Sprintf("failed to load shard configuration for epoch %d", newEpoch), logger.Error(err))
+		if errors.Is(err, errShardConfNotFound) {
+			n.requestShardConf(ctx, newEpoch)
+		}
 	} else if wasValidator != n.IsValidator() {
 		// Configuration loaded for the new epoch, and our validator status changed
 		if wasValidator {
@@ -1181,6 +1525,8 @@ func (n *Node) handleMonitoring(ctx context.Context, lastUCReceived, lastBlockRe
 	ctx, span := n.tracer.Start(ctx, "node.handleMonitoring", trace.WithNewRoot(), trace.WithAttributes(n.attrRound(), attribute.String("last UC", lastUCReceived.String())))
 	defer span.End()
 
+	n.fetchShardConf(ctx)
+
 	// check if we have not heard from root validator for T2 timeout + 1 sec
 	// a new repeat UC must have been made by now (assuming root is fine) try and get it from other root nodes
 	if n.IsValidator() && time.Since(lastUCReceived) > n.conf.GetT2Timeout()+time.Second {
@@ -1555,6 +1901,7 @@ func (n *Node) sendCertificationRequest(ctx context.Context, blockAuthor string)
 	}
 	n.pendingBlockProposal = pendingProposal
 	n.proposedTransactions = []*types.TransactionRecord{}
+	n.proposalUsage = blockUsage{}
 	n.sumOfEarnedFees = 0
 
 	// send new input record for certification
@@ -1571,6 +1918,19 @@ func (n *Node) sendCertificationRequest(ctx context.Context, blockAuthor string)
 	if req.StateSize, err = n.transactionSystem.StateSize(); err != nil {
 		return fmt.Errorf("calculating state size: %w", err)
 	}
+	// persist the request before signing so that a conflicting request is never signed, even after a crash
+	irHash, err := abhash.HashValues(n.conf.hashAlgorithm, req.InputRecord, req.BlockSize, req.StateSize)
+	if err != nil {
+		return fmt.Errorf("calculating certification request hash: %w", err)
+	}
+	if err = n.shardStore.StoreSignedRequest(&signedRequest{
+		Round:     ir.RoundNumber,
+		Epoch:     ir.Epoch,
+		Timestamp: ir.Timestamp,
+		IRHash:    irHash,
+	}); err != nil {
+		return fmt.Errorf("double-sign protection: %w", err)
+	}
 	if err = req.Sign(n.conf.signer); err != nil {
 		return fmt.Errorf("failed to sign certification request: %w", err)
 	}
@@ -1586,11 +1946,17 @@ func (n *Node) sendCertificationRequest(ctx context.Context, blockAuthor string)
 
 func (n *Node) resetProposal() {
 	n.proposedTransactions = []*types.TransactionRecord{}
+	n.proposalUsage = blockUsage{}
 	n.pendingBlockProposal = nil
 }
 
+/*
+SubmitTx pre-validates the transaction and adds it to the transaction buffer.
+Pre-validation is done by the worker pool of the node, when the pool is not
+running or is overloaded the transaction is validated by the caller.
+*/
 func (n *Node) SubmitTx(ctx context.Context, tx *types.TransactionOrder) (txOrderHash []byte, err error) {
-	if err = n.conf.txValidator.Validate(tx, n.currentRoundNumber()); err != nil {
+	if err = n.txPrevalidator.Validate(ctx, tx); err != nil {
 		return nil, err
 	}
 
@@ -1711,13 +2077,7 @@ func (n *Node) CurrentRoundInfo(ctx context.Context) (*RoundInfo, error) {
 }
 
 func (n *Node) GetTrustBase(epochNumber uint64) (types.RootTrustBase, error) {
-	// TODO verify epoch number after epoch switching is implemented
-	// fast-track solution is to restart all partition nodes with new config on epoch change
-	trustBase := n.conf.trustBase
-	if trustBase == nil {
-		return nil, fmt.Errorf("trust base for epoch %d does not exist", epochNumber)
-	}
-	return trustBase, nil
+	return n.conf.orchestration.TrustBase(epochNumber)
 }
 
 func (n *Node) FilterValidatorNodes(exclude peer.ID) []peer.ID {
@@ -1778,8 +2138,16 @@ func (n *Node) startProcessingTransactions(ctx context.Context) {
 			receiverFunc = n.leader.Get
 		}
 
+		if tx := n.takeOverflowTx(processCtx); tx != nil {
+			if err := n.processLeaderTx(processCtx, tx); err != nil {
+				if errors.Is(err, network.ErrStopProcessing) {
+					return
+				}
+				n.log.WarnContext(processCtx, "processing transaction", logger.Error(err), logger.UnitID(tx.UnitID))
+			}
+		}
 		if n.leader.IsLeader(n.peer.ID()) {
-			n.network.ProcessTransactions(processCtx, n.process)
+			n.network.ProcessTransactions(processCtx, n.processLeaderTx)
 		} else {
 			n.network.ForwardTransactions(processCtx, receiverFunc)
 		}
@@ -4,6 +4,7 @@ import (
 	"context"
 	gocrypto "crypto"
 	"errors"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -17,6 +18,7 @@ import (
 	"github.com/alphabill-org/alphabill/network"
 	"github.com/alphabill-org/alphabill/network/protocol/blockproposal"
 	"github.com/alphabill-org/alphabill/network/protocol/certification"
+	"github.com/alphabill-org/alphabill/network/protocol/shardconf"
 	"github.com/alphabill-org/alphabill/partition/event"
 	testtransaction "github.com/alphabill-org/alphabill/txsystem/testutils/transaction"
 	"github.com/stretchr/testify/require"
@@ -491,6 +493,94 @@ func TestNode_HandleUnicityCertificate_SwitchToNonValidator(t *testing.T) {
 	tp.CreateBlock(t)
 }
 
+func TestNode_FetchShardConfOfNextEpoch(t *testing.T) {
+	tp := runSingleValidatorNodePartition(t, &testtxsystem.CounterTxSystem{})
+	tp.WaitHandshake(t)
+	require.Equal(t, 2, len(tp.node.Validators()))
+
+	// shard conf of the epoch 1 is not registered with the node
+	shardConf1 := createShardConfWithNewNode(t, tp.nodeConf.shardConf)
+	shardConf1Hash, err := shardConf1.Hash(gocrypto.SHA256)
+	require.NoError(t, err)
+	shardConfRequested := func() bool {
+		for _, msg := range tp.mockNet.SentMessages(network.ProtocolShardConfReq) {
+			if req, ok := msg.Message.(shardconf.ShardConfRequest); ok && req.Epoch == 1 {
+				return true
+			}
+		}
+		return false
+	}
+
+	// UC announcing the epoch 1 still commits to the shard conf of the epoch 0,
+	// the node requests the shard conf of the next epoch from the root chain
+	ir := tp.GetCommittedUC(t).InputRecord.NewRepeatIR()
+	tp.ReceiveCertResponseWithEpoch(t, ir, 200, 1)
+	require.Eventually(t, shardConfRequested, test.WaitDuration, test.WaitTick)
+
+	// the response can't be verified before a UC commits to it, the node keeps it
+	tp.mockNet.Receive(&shardconf.ShardConfResponse{ShardConf: shardConf1})
+	require.Eventually(t, func() bool {
+		return tp.node.pendingShardConf.Load() != nil
+	}, test.WaitDuration, test.WaitTick)
+	require.Equal(t, 2, len(tp.node.Validators()))
+
+	// the request is retried until the shard conf is loaded
+	tp.mockNet.ResetSentMessages(network.ProtocolShardConfReq)
+	tp.SubmitMonitorTimeout(t)
+	require.Eventually(t, shardConfRequested, test.WaitDuration, test.WaitTick)
+
+	// the next UC commits to the shard conf of the epoch 1 and the epoch changes
+	tp.ReceiveCertResponseWithShardConf(t, ir.NewRepeatIR(), 300, 1, shardConf1Hash)
+	require.Eventually(t, func() bool {
+		return len(tp.node.Validators()) == 3
+	}, test.WaitDuration, test.WaitTick)
+	require.True(t, tp.node.IsValidator())
+	require.Equal(t, []byte(shardConf1Hash), tp.node.shardStore.ShardConfHash())
+	require.Nil(t, tp.node.pendingShardConf.Load())
+}
+
+func TestNode_UpdateLUC_ShardConfOfAnnouncedEpoch(t *testing.T) {
+	setup := func(t *testing.T) (*SingleNodePartition, *types.PartitionDescriptionRecord, []byte, *types.InputRecord) {
+		tp := runSingleValidatorNodePartition(t, &testtxsystem.CounterTxSystem{})
+		tp.WaitHandshake(t)
+		shardConf1 := createShardConfWithNewNode(t, tp.nodeConf.shardConf)
+		shardConf1Hash, err := shardConf1.Hash(gocrypto.SHA256)
+		require.NoError(t, err)
+		return tp, shardConf1, shardConf1Hash, tp.GetCommittedUC(t).InputRecord.NewRepeatIR()
+	}
+	lucRootRound := func(tp *SingleNodePartition, round uint64) func() bool {
+		return func() bool { return tp.node.luc.Load().GetRootRoundNumber() == round }
+	}
+
+	t.Run("shard conf of the epoch is not known", func(t *testing.T) {
+		tp, _, _, ir := setup(t)
+		tp.ReceiveCertResponseWithEpoch(t, ir, 200, 1)
+		require.Eventually(t, lucRootRound(tp, 200), test.WaitDuration, test.WaitTick)
+
+		// the repeat UC commits to the shard conf of the announced epoch which the node
+		// doesn't have, the UC is accepted without checking the shard conf hash
+		tp.ReceiveCertResponseWithShardConf(t, ir.NewRepeatIR(), 300, 1, test.RandomBytes(32))
+		require.Eventually(t, lucRootRound(tp, 300), test.WaitDuration, test.WaitTick)
+	})
+
+	t.Run("shard conf of the epoch is known", func(t *testing.T) {
+		tp, shardConf1, shardConf1Hash, ir := setup(t)
+		require.NoError(t, tp.node.RegisterShardConf(shardConf1))
+		tp.ReceiveCertResponseWithEpoch(t, ir, 200, 1)
+		require.Eventually(t, lucRootRound(tp, 200), test.WaitDuration, test.WaitTick)
+		require.Eventually(t, func() bool { return tp.node.shardStore.LoadedEpoch() == 1 }, test.WaitDuration, test.WaitTick)
+
+		// the repeat UC must commit to the shard conf of the announced epoch
+		tp.eh.Reset()
+		tp.ReceiveCertResponseWithShardConf(t, ir.NewRepeatIR(), 300, 1, test.RandomBytes(32))
+		testevent.ContainsEvent(t, tp.eh, event.Error)
+		require.EqualValues(t, 200, tp.node.luc.Load().GetRootRoundNumber())
+
+		tp.ReceiveCertResponseWithShardConf(t, ir.NewRepeatIR(), 300, 1, shardConf1Hash)
+		require.Eventually(t, lucRootRound(tp, 300), test.WaitDuration, test.WaitTick)
+	})
+}
+
 func TestBlockProposal_BlockProposalIsNil(t *testing.T) {
 	tp := runSingleValidatorNodePartition(t, &testtxsystem.CounterTxSystem{})
 	tp.SubmitBlockProposal(nil)
@@ -737,3 +827,47 @@ func TestNode_GetTransactionRecord_NotFound(t *testing.T) {
 	require.ErrorIs(t, err, ErrIndexNotFound)
 	require.Nil(t, proof)
 }
+
+// batchTxSystem counts the calls of the batch execution
+type batchTxSystem struct {
+	*testtxsystem.CounterTxSystem
+	batches atomic.Int32
+}
+
+func (s *batchTxSystem) ExecuteBatch(txs []*types.TransactionOrder) ([]*types.TransactionRecord, error) {
+	s.batches.Add(1)
+	trs := make([]*types.TransactionRecord, 0, len(txs))
+	for _, tx := range txs {
+		tr, err := s.Execute(tx)
+		if err != nil {
+			return trs, err
+		}
+		trs = append(trs, tr)
+	}
+	return trs, nil
+}
+
+func TestNode_validateAndExecuteTxs(t *testing.T) {
+	txs := []*types.TransactionOrder{
+		testtransaction.NewTransactionOrder(t),
+		testtransaction.NewTransactionOrder(t),
+	}
+
+	t.Run("parallel execution not enabled", func(t *testing.T) {
+		system := &batchTxSystem{CounterTxSystem: &testtxsystem.CounterTxSystem{}}
+		tp := runSingleValidatorNodePartition(t, system)
+		trs, err := tp.node.validateAndExecuteTxs(context.Background(), txs, 1)
+		require.NoError(t, err)
+		require.Len(t, trs, 2)
+		require.Zero(t, system.batches.Load())
+	})
+
+	t.Run("parallel execution enabled", func(t *testing.T) {
+		system := &batchTxSystem{CounterTxSystem: &testtxsystem.CounterTxSystem{}}
+		tp := runSingleValidatorNodePartition(t, system, WithParallelExecution(4))
+		trs, err := tp.node.validateAndExecuteTxs(context.Background(), txs, 1)
+		require.NoError(t, err)
+		require.Len(t, trs, 2)
+		require.EqualValues(t, 1, system.batches.Load())
+	})
+}
@@ -1,14 +1,120 @@
 package partition
 
-import "github.com/alphabill-org/alphabill-go-base/types"
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill/keyvaluedb"
+	"github.com/alphabill-org/alphabill/network/protocol/rootepoch"
+)
+
+// ErrUnknownRootEpoch is returned when the trust base of the root epoch is not known (yet).
+var ErrUnknownRootEpoch = errors.New("unknown root epoch")
+
+// prefix of the keys of the root trust bases in the shard store, the key of the epoch
+// is the prefix followed by the 8 byte epoch number
+var trustBaseKeyPrefix = []byte("rootTrustBase")
 
 /*
-static orchestration until real thing is implemented.
+Orchestration keeps the trust bases of the root epochs known to the node, starting
+with the genesis trust base (root epoch 0). The trust bases of the later epochs are
+fetched from the root chain and accepted only when signed by the quorum of the
+validators of the previous epoch. When the DB is set the accepted trust bases are
+persisted and loaded again on restart.
 */
 type Orchestration struct {
-	trustBase types.RootTrustBase
+	mu         sync.RWMutex
+	trustBases []types.RootTrustBase // index is the root epoch number
+	db         keyvaluedb.KeyValueDB
+}
+
+/*
+NewOrchestration returns the in-memory orchestration starting with the genesis trust base.
+*/
+func NewOrchestration(genesis types.RootTrustBase) *Orchestration {
+	return &Orchestration{trustBases: []types.RootTrustBase{genesis}}
+}
+
+/*
+LoadOrchestration returns the orchestration which persists the trust bases of the root
+epochs in the DB. The trust bases stored in the DB are verified against the trust base
+of the previous epoch, starting from the genesis trust base.
+*/
+func LoadOrchestration(genesis types.RootTrustBase, db keyvaluedb.KeyValueDB) (*Orchestration, error) {
+	if genesis == nil {
+		return nil, ErrTrustBaseIsNil
+	}
+	if db == nil {
+		return nil, errors.New("trust base DB is nil")
+	}
+	orc := &Orchestration{trustBases: []types.RootTrustBase{genesis}, db: db}
+	for epoch := uint64(1); ; epoch++ {
+		tb := &types.RootTrustBaseV1{}
+		found, err := db.Read(trustBaseKey(epoch), tb)
+		if err != nil {
+			return nil, fmt.Errorf("reading trust base of the root epoch %d: %w", epoch, err)
+		}
+		if !found {
+			return orc, nil
+		}
+		rsp := &rootepoch.TrustBaseResponse{Epoch: epoch, TrustBase: tb}
+		if err := rsp.Verify(orc.trustBases[epoch-1]); err != nil {
+			return nil, err
+		}
+		orc.trustBases = append(orc.trustBases, tb)
+	}
+}
+
+// TrustBase returns the trust base of the root epoch.
+func (orc *Orchestration) TrustBase(epoch uint64) (types.RootTrustBase, error) {
+	orc.mu.RLock()
+	defer orc.mu.RUnlock()
+	if epoch >= uint64(len(orc.trustBases)) {
+		return nil, fmt.Errorf("trust base of the root epoch %d: %w", epoch, ErrUnknownRootEpoch)
+	}
+	return orc.trustBases[epoch], nil
+}
+
+// LastEpoch returns the number of the last root epoch known to the node.
+func (orc *Orchestration) LastEpoch() uint64 {
+	orc.mu.RLock()
+	defer orc.mu.RUnlock()
+	return uint64(len(orc.trustBases) - 1)
+}
+
+/*
+AddTrustBase adds the trust base of the next root epoch. Returns false when the
+trust base of the epoch is already known.
+*/
+func (orc *Orchestration) AddTrustBase(rsp *rootepoch.TrustBaseResponse) (bool, error) {
+	if err := rsp.IsValid(); err != nil {
+		return false, err
+	}
+	orc.mu.Lock()
+	defer orc.mu.Unlock()
+
+	next := uint64(len(orc.trustBases))
+	if rsp.Epoch < next {
+		return false, nil
+	}
+	if rsp.Epoch > next {
+		return false, fmt.Errorf("expected trust base of the root epoch %d, got epoch %d", next, rsp.Epoch)
+	}
+	if err := rsp.Verify(orc.trustBases[next-1]); err != nil {
+		return false, err
+	}
+	if orc.db != nil {
+		if err := orc.db.Write(trustBaseKey(rsp.Epoch), rsp.TrustBase); err != nil {
+			return false, fmt.Errorf("storing trust base of the root epoch %d: %w", rsp.Epoch, err)
+		}
+	}
+	orc.trustBases = append(orc.trustBases, rsp.TrustBase)
+	return true, nil
 }
 
-func (orc Orchestration) TrustBase(epoch uint64) (types.RootTrustBase, error) {
-	return orc.trustBase, nil
+func trustBaseKey(epoch uint64) []byte {
+	return binary.BigEndian.AppendUint64(append([]byte{}, trustBaseKeyPrefix...), epoch)
 }
@@ -0,0 +1,98 @@
+package partition
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	abcrypto "github.com/alphabill-org/alphabill-go-base/crypto"
+	"github.com/alphabill-org/alphabill-go-base/types"
+	testsig "github.com/alphabill-org/alphabill/internal/testutils/sig"
+	"github.com/alphabill-org/alphabill/internal/testutils/trustbase"
+	"github.com/alphabill-org/alphabill/keyvaluedb/memorydb"
+	"github.com/alphabill-org/alphabill/network/protocol/rootepoch"
+)
+
+func TestOrchestration(t *testing.T) {
+	signer, verifier := testsig.CreateSignerAndVerifier(t)
+	genesis := trustbase.NewTrustBaseFromVerifiers(t, map[string]abcrypto.Verifier{"root": verifier})
+	orc := NewOrchestration(genesis)
+	require.EqualValues(t, 0, orc.LastEpoch())
+
+	tb, err := orc.TrustBase(0)
+	require.NoError(t, err)
+	require.Equal(t, genesis, tb)
+
+	_, err = orc.TrustBase(1)
+	require.ErrorIs(t, err, ErrUnknownRootEpoch)
+
+	_, nextVerifier := testsig.CreateSignerAndVerifier(t)
+	next := trustbase.NewTrustBaseFromVerifiers(t, map[string]abcrypto.Verifier{"next": nextVerifier}).(*types.RootTrustBaseV1)
+	next.Epoch = genesis.(*types.RootTrustBaseV1).Epoch + 1
+	next.EpochStartRound = 100
+
+	t.Run("not the next epoch", func(t *testing.T) {
+		added, err := orc.AddTrustBase(&rootepoch.TrustBaseResponse{Epoch: 2, TrustBase: next})
+		require.EqualError(t, err, "expected trust base of the root epoch 1, got epoch 2")
+		require.False(t, added)
+	})
+
+	t.Run("not signed by the previous epoch", func(t *testing.T) {
+		added, err := orc.AddTrustBase(&rootepoch.TrustBaseResponse{Epoch: 1, TrustBase: next})
+		require.ErrorContains(t, err, "invalid trust base of the root epoch 1")
+		require.False(t, added)
+	})
+
+	require.NoError(t, next.Sign("root", signer))
+	added, err := orc.AddTrustBase(&rootepoch.TrustBaseResponse{Epoch: 1, TrustBase: next})
+	require.NoError(t, err)
+	require.True(t, added)
+	require.EqualValues(t, 1, orc.LastEpoch())
+
+	tb, err = orc.TrustBase(1)
+	require.NoError(t, err)
+	require.Equal(t, next, tb)
+
+	// already known epoch is ignored
+	added, err = orc.AddTrustBase(&rootepoch.TrustBaseResponse{Epoch: 1, TrustBase: next})
+	require.NoError(t, err)
+	require.False(t, added)
+}
+
+func TestLoadOrchestration(t *testing.T) {
+	signer, verifier := testsig.CreateSignerAndVerifier(t)
+	genesis := trustbase.NewTrustBaseFromVerifiers(t, map[string]abcrypto.Verifier{"root": verifier})
+	db, err := memorydb.New()
+	require.NoError(t, err)
+
+	_, err = LoadOrchestration(nil, db)
+	require.ErrorIs(t, err, ErrTrustBaseIsNil)
+	_, err = LoadOrchestration(genesis, nil)
+	require.EqualError(t, err, "trust base DB is nil")
+
+	orc, err := LoadOrchestration(genesis, db)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, orc.LastEpoch())
+
+	_, nextVerifier := testsig.CreateSignerAndVerifier(t)
+	next := trustbase.NewTrustBaseFromVerifiers(t, map[string]abcrypto.Verifier{"next": nextVerifier}).(*types.RootTrustBaseV1)
+	next.Epoch = genesis.(*types.RootTrustBaseV1).Epoch + 1
+	next.EpochStartRound = 100
+	require.NoError(t, next.Sign("root", signer))
+	added, err := orc.AddTrustBase(&rootepoch.TrustBaseResponse{Epoch: 1, TrustBase: next})
+	require.NoError(t, err)
+	require.True(t, added)
+
+	// the trust bases are loaded from the DB on restart
+	orc, err = LoadOrchestration(genesis, db)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, orc.LastEpoch())
+	tb, err := orc.TrustBase(1)
+	require.NoError(t, err)
+	require.Equal(t, next.GetRootNodes(), tb.GetRootNodes())
+
+	// the stored trust base must be signed by the validators of the previous epoch
+	other := trustbase.NewTrustBaseFromVerifiers(t, map[string]abcrypto.Verifier{"root": nextVerifier})
+	_, err = LoadOrchestration(other, db)
+	require.ErrorContains(t, err, "invalid trust base of the root epoch 1")
+}
@@ -1,8 +1,10 @@
 package partition
 
 import (
+	"bytes"
 	gocrypto "crypto"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"log/slog"
 	"maps"
@@ -16,6 +18,21 @@ import (
 	"github.com/libp2p/go-libp2p/core/peer"
 )
 
+// key of the last signed block certification request, the epoch keys are 8 bytes long
+var signedRequestKey = []byte("lastSignedCertificationRequest")
+
+// errShardConfNotFound is returned when the shard conf of the epoch hasn't been stored
+var errShardConfNotFound = errors.New("shard conf not found")
+
+// signedRequest is the last block certification request signed by the node
+type signedRequest struct {
+	_         struct{} `cbor:",toarray"`
+	Round     uint64
+	Epoch     uint64
+	Timestamp uint64
+	IRHash    []byte
+}
+
 type shardStore struct {
 	db  keyvaluedb.KeyValueDB
 	log *slog.Logger
@@ -25,6 +42,7 @@ type shardStore struct {
 	epoch           uint64
 	epochValidators map[peer.ID]crypto.Verifier
 	shardConfHash   []byte
+	blockLimits     BlockLimits
 }
 
 func newShardStore(db keyvaluedb.KeyValueDB, log *slog.Logger) *shardStore {
@@ -37,7 +55,7 @@ func newShardStore(db keyvaluedb.KeyValueDB, log *slog.Logger) *shardStore {
 func (s *shardStore) StoreShardConf(shardConf *types.PartitionDescriptionRecord) error {
 	var prevShardConf *types.PartitionDescriptionRecord
 	if shardConf.Epoch > 0 {
-		prevEpoch := shardConf.Epoch-1
+		prevEpoch := shardConf.Epoch - 1
 		var err error
 		prevShardConf, err = s.loadShardConf(prevEpoch)
 		if err != nil {
@@ -65,6 +83,10 @@ func (s *shardStore) LoadEpoch(epoch uint64) error {
 	if err != nil {
 		return fmt.Errorf("failed to calculate shard conf hash: %w", err)
 	}
+	blockLimits, err := ParseBlockLimits(shardConf)
+	if err != nil {
+		return fmt.Errorf("failed to parse block limits: %w", err)
+	}
 
 	validators := make(map[peer.ID]crypto.Verifier, len(shardConf.Validators))
 	for _, vi := range shardConf.Validators {
@@ -82,6 +104,7 @@ func (s *shardStore) LoadEpoch(epoch uint64) error {
 	s.epoch = shardConf.Epoch
 	s.epochValidators = validators
 	s.shardConfHash = shardConfHash
+	s.blockLimits = blockLimits
 	s.log.Debug(fmt.Sprintf("Loaded shard configuration for epoch %d with hash %x", epoch, shardConfHash))
 	return nil
 }
@@ -123,6 +146,59 @@ func (s *shardStore) ShardConfHash() []byte {
 	return s.shardConfHash
 }
 
+/*
+EpochShardConfHash returns the hash of the stored shard conf of the epoch, the epoch
+doesn't have to be loaded. Returns errShardConfNotFound when the shard conf of the
+epoch hasn't been stored.
+*/
+func (s *shardStore) EpochShardConfHash(epoch uint64) ([]byte, error) {
+	shardConf, err := s.loadShardConf(epoch)
+	if err != nil {
+		return nil, err
+	}
+	return shardConf.Hash(gocrypto.SHA256)
+}
+
+func (s *shardStore) BlockLimits() BlockLimits {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.blockLimits
+}
+
+/*
+StoreSignedRequest persists the block certification request the node is about to
+sign. Returns error when the request conflicts with the previously signed one, the
+request is allowed when
+  - its round is greater than the round of the previously signed request or;
+  - it is the same request as signed previously (e.g. re-sent after restart) or;
+  - it's for the same round but with newer timestamp, ie the round is re-certified
+    after a repeat UC.
+*/
+func (s *shardStore) StoreSignedRequest(req *signedRequest) error {
+	var last signedRequest
+	found, err := s.db.Read(signedRequestKey, &last)
+	if err != nil {
+		return fmt.Errorf("reading last signed certification request: %w", err)
+	}
+	if found {
+		switch {
+		case req.Epoch < last.Epoch:
+			return fmt.Errorf("already signed certification request for epoch %d, refusing to sign for epoch %d", last.Epoch, req.Epoch)
+		case req.Round < last.Round:
+			return fmt.Errorf("already signed certification request for round %d, refusing to sign for round %d", last.Round, req.Round)
+		case req.Round > last.Round:
+		case bytes.Equal(req.IRHash, last.IRHash):
+			return nil
+		case req.Timestamp <= last.Timestamp:
+			return fmt.Errorf("already signed a different certification request for round %d", req.Round)
+		}
+	}
+	if err := s.db.Write(signedRequestKey, req); err != nil {
+		return fmt.Errorf("saving signed certification request: %w", err)
+	}
+	return nil
+}
+
 func (s *shardStore) loadShardConf(epoch uint64) (*types.PartitionDescriptionRecord, error) {
 	v := &types.PartitionDescriptionRecord{}
 	found, err := s.db.Read(epochToKey(epoch), v)
@@ -130,7 +206,7 @@ func (s *shardStore) loadShardConf(epoch uint64) (*types.PartitionDescriptionRec
 		return nil, fmt.Errorf("reading shard conf: %w", err)
 	}
 	if !found {
-		return nil, fmt.Errorf("shard conf not found")
+		return nil, errShardConfNotFound
 	}
 	return v, nil
 }
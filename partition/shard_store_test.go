@@ -89,3 +89,31 @@ func createShardConfWithRemovedNode(t *testing.T, prev *types.PartitionDescripti
 	new.Validators = validators
 	return &new
 }
+
+func TestShardStore_StoreSignedRequest(t *testing.T) {
+	db, err := memorydb.New()
+	require.NoError(t, err)
+	ss := newShardStore(db, logger.New(t))
+
+	req := &signedRequest{Round: 10, Epoch: 1, Timestamp: 100, IRHash: []byte{1}}
+	require.NoError(t, ss.StoreSignedRequest(req))
+	// the same request can be signed again
+	require.NoError(t, ss.StoreSignedRequest(req))
+
+	require.EqualError(t, ss.StoreSignedRequest(&signedRequest{Round: 10, Epoch: 1, Timestamp: 100, IRHash: []byte{2}}),
+		"already signed a different certification request for round 10")
+	require.EqualError(t, ss.StoreSignedRequest(&signedRequest{Round: 9, Epoch: 1, Timestamp: 101, IRHash: []byte{2}}),
+		"already signed certification request for round 10, refusing to sign for round 9")
+	require.EqualError(t, ss.StoreSignedRequest(&signedRequest{Round: 11, Epoch: 0, Timestamp: 101, IRHash: []byte{2}}),
+		"already signed certification request for epoch 1, refusing to sign for epoch 0")
+
+	// round re-certified after repeat UC
+	require.NoError(t, ss.StoreSignedRequest(&signedRequest{Round: 10, Epoch: 1, Timestamp: 101, IRHash: []byte{2}}))
+	require.EqualError(t, ss.StoreSignedRequest(req), "already signed a different certification request for round 10")
+
+	// the state survives restart
+	ss = newShardStore(db, logger.New(t))
+	require.NoError(t, ss.StoreSignedRequest(&signedRequest{Round: 10, Epoch: 1, Timestamp: 101, IRHash: []byte{2}}))
+	require.Error(t, ss.StoreSignedRequest(&signedRequest{Round: 10, Epoch: 1, Timestamp: 101, IRHash: []byte{3}}))
+	require.NoError(t, ss.StoreSignedRequest(&signedRequest{Round: 11, Epoch: 1, Timestamp: 101, IRHash: []byte{3}}))
+}
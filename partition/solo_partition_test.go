@@ -69,6 +69,11 @@ func newSingleNonValidatorNodePartition(t *testing.T, txSystem txsystem.Transact
 }
 
 func newSingleNodeShard(t *testing.T, txSystem txsystem.TransactionSystem, validator bool, nodeOptions ...NodeOption) *SingleNodePartition {
+	return newSingleNodeShardWithParams(t, txSystem, validator, nil, nodeOptions...)
+}
+
+// newSingleNodeShardWithParams creates the shard with given PartitionParams in the shard conf.
+func newSingleNodeShardWithParams(t *testing.T, txSystem txsystem.TransactionSystem, validator bool, params map[string]string, nodeOptions ...NodeOption) *SingleNodePartition {
 	// the only running node
 	keyConf, nodeInfo := createKeyConf(t)
 	nodeID, err := keyConf.NodeID()
@@ -89,6 +94,7 @@ func newSingleNodeShard(t *testing.T, txSystem txsystem.TransactionSystem, valid
 		Epoch:           0,
 		EpochStart:      1,
 		Validators:      []*types.NodeInfo{fakeNodeInfo},
+		PartitionParams: params,
 	}
 
 	if validator {
@@ -139,9 +145,15 @@ func runSingleNonValidatorNodePartition(t *testing.T, txSystem txsystem.Transact
 }
 
 func runSingleNodePartition(t *testing.T, txSystem txsystem.TransactionSystem, validator bool, nodeOptions ...NodeOption) *SingleNodePartition {
-	ctx, cancel := context.WithCancel(context.Background())
+	return runSingleNodeShard(t, newSingleNodeShard(t, txSystem, validator, nodeOptions...))
+}
+
+func runSingleValidatorNodePartitionWithParams(t *testing.T, txSystem txsystem.TransactionSystem, params map[string]string, nodeOptions ...NodeOption) *SingleNodePartition {
+	return runSingleNodeShard(t, newSingleNodeShardWithParams(t, txSystem, true, params, nodeOptions...))
+}
 
-	shard := newSingleNodeShard(t, txSystem, validator, nodeOptions...)
+func runSingleNodeShard(t *testing.T, shard *SingleNodePartition) *SingleNodePartition {
+	ctx, cancel := context.WithCancel(context.Background())
 	done := shard.start(ctx, t)
 	t.Cleanup(func() {
 		cancel()
@@ -317,6 +329,28 @@ func (sn *SingleNodePartition) CreateUnicityCertificate(t *testing.T, ir *types.
 }
 
 func (sn *SingleNodePartition) CreateUnicityCertificateTR(t *testing.T, ir *types.InputRecord, rootRoundNumber uint64, epoch uint64) (*types.UnicityCertificate, certification.TechnicalRecord, error) {
+	return sn.createUnicityCertificateTR(t, ir, rootRoundNumber, epoch, sn.node.shardStore.ShardConfHash())
+}
+
+/*
+ReceiveCertResponseWithShardConf sends the CertificationResponse which commits to the
+shard conf with the given hash rather than the shard conf loaded by the node.
+*/
+func (sn *SingleNodePartition) ReceiveCertResponseWithShardConf(t *testing.T, ir *types.InputRecord, rootRoundNumber uint64, epoch uint64, shardConfHash []byte) {
+	uc, tr, err := sn.createUnicityCertificateTR(t, ir, rootRoundNumber, epoch, shardConfHash)
+	if err != nil {
+		t.Fatalf("creating UC and TR: %v", err)
+	}
+
+	sn.mockNet.Receive(&certification.CertificationResponse{
+		Partition: sn.nodeConf.PartitionID(),
+		Shard:     sn.nodeConf.ShardID(),
+		Technical: tr,
+		UC:        *uc,
+	})
+}
+
+func (sn *SingleNodePartition) createUnicityCertificateTR(t *testing.T, ir *types.InputRecord, rootRoundNumber uint64, epoch uint64, shardConfHash []byte) (*types.UnicityCertificate, certification.TechnicalRecord, error) {
 	tr := technicalRecord(t, ir, []string{sn.nodeID(t).String()})
 	tr.Epoch = epoch
 	trHash, err := tr.Hash()
@@ -324,7 +358,6 @@ func (sn *SingleNodePartition) CreateUnicityCertificateTR(t *testing.T, ir *type
 		return nil, tr, fmt.Errorf("calculating TechnicalRecord hash: %w", err)
 	}
 
-	shardConfHash := sn.node.shardStore.ShardConfHash()
 	sTree, err := types.CreateShardTree(types.ShardingScheme{}, []types.ShardTreeInput{
 		{Shard: types.ShardID{}, IR: ir, TRHash: trHash, ShardConfHash: shardConfHash},
 	}, gocrypto.SHA256)
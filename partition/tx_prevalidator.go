@@ -0,0 +1,145 @@
+package partition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync/atomic"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill/predicates/templates"
+)
+
+const txPrevalidationQueueLen = 1000
+
+var ErrPrevalidationQueueFull = errors.New("transaction pre-validation queue is full")
+
+type (
+	// txPrevalidator verifies the stateless parts of the incoming transactions
+	// in a pool of workers before they are added to the transaction buffer.
+	//
+	// Transactions are assigned to the workers by unit ID so the transactions
+	// targeting the same unit are validated (and thus added to the buffer)
+	// in the order they were submitted.
+	txPrevalidator struct {
+		validate func(tx *types.TransactionOrder) error
+		queues   []chan prevalidationJob
+		running  atomic.Bool // are the workers running, ie is Run active
+	}
+
+	prevalidationJob struct {
+		tx   *types.TransactionOrder
+		done func(error)
+	}
+)
+
+func newTxPrevalidator(workers int, validate func(tx *types.TransactionOrder) error) *txPrevalidator {
+	p := &txPrevalidator{
+		validate: validate,
+		queues:   make([]chan prevalidationJob, max(workers, 1)),
+	}
+	for i := range p.queues {
+		p.queues[i] = make(chan prevalidationJob, txPrevalidationQueueLen/len(p.queues)+1)
+	}
+	return p
+}
+
+/*
+Run starts the workers, blocks until ctx is cancelled.
+*/
+func (p *txPrevalidator) Run(ctx context.Context) error {
+	p.running.Store(true)
+	defer p.running.Store(false)
+
+	g, ctx := errgroup.WithContext(ctx)
+	for _, queue := range p.queues {
+		g.Go(func() error {
+			for {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case job := <-queue:
+					job.done(p.validate(job.tx))
+				}
+			}
+		})
+	}
+	return g.Wait()
+}
+
+/*
+Submit queues the transaction for validation, "done" is called (by the worker
+goroutine) with the result of the validation.
+*/
+func (p *txPrevalidator) Submit(tx *types.TransactionOrder, done func(error)) error {
+	if tx == nil {
+		return errors.New("transaction is nil")
+	}
+	h := fnv.New32a()
+	h.Write(tx.UnitID)
+	select {
+	case p.queues[h.Sum32()%uint32(len(p.queues))] <- prevalidationJob{tx: tx, done: done}:
+		return nil
+	default:
+		return ErrPrevalidationQueueFull
+	}
+}
+
+/*
+Validate submits the transaction for validation and waits for the result.
+
+When the workers are not running or the queue of the worker is full the
+transaction is validated by the calling goroutine instead, ie the caller is
+never rejected because of the load (but the order of the transactions of
+the same unit is not guaranteed then).
+*/
+func (p *txPrevalidator) Validate(ctx context.Context, tx *types.TransactionOrder) error {
+	if tx == nil {
+		return errors.New("transaction is nil")
+	}
+	if !p.running.Load() {
+		return p.validate(tx)
+	}
+	result := make(chan error, 1)
+	if err := p.Submit(tx, func(err error) { result <- err }); err != nil {
+		if errors.Is(err, ErrPrevalidationQueueFull) {
+			return p.validate(tx)
+		}
+		return err
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-result:
+		return err
+	}
+}
+
+/*
+prevalidateTx performs the checks which do not depend on the state of the
+transaction system:
+  - generic transaction validation (TxValidator);
+  - size of the transaction against the block size limit;
+  - signatures in the auth and fee proofs (see templates.PrevalidateSignatures),
+    results of the signature verification are cached for the execution. Invalid
+    signatures do not cause rejection here, that is up to the predicate execution.
+*/
+func (n *Node) prevalidateTx(tx *types.TransactionOrder) error {
+	if err := n.conf.txValidator.Validate(tx, n.currentRoundNumber()); err != nil {
+		return fmt.Errorf("invalid transaction: %w", err)
+	}
+	if maxSize := n.shardStore.BlockLimits().MaxSize; maxSize > 0 {
+		txBytes, err := tx.MarshalCBOR()
+		if err != nil {
+			return fmt.Errorf("encoding transaction: %w", err)
+		}
+		if uint64(len(txBytes)) > maxSize {
+			return fmt.Errorf("%w: transaction size %d bytes exceeds block size limit %d", ErrBlockLimitExceeded, len(txBytes), maxSize)
+		}
+	}
+	templates.PrevalidateSignatures(tx)
+	return nil
+}
@@ -0,0 +1,113 @@
+package partition
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+)
+
+func TestTxPrevalidator(t *testing.T) {
+	errInvalid := errors.New("invalid")
+	validate := func(tx *types.TransactionOrder) error {
+		if tx.Type == 0 {
+			return errInvalid
+		}
+		return nil
+	}
+
+	t.Run("nil tx", func(t *testing.T) {
+		p := newTxPrevalidator(2, validate)
+		require.EqualError(t, p.Submit(nil, func(error) {}), "transaction is nil")
+	})
+
+	t.Run("queue full", func(t *testing.T) {
+		p := newTxPrevalidator(1, validate)
+		tx := &types.TransactionOrder{Payload: types.Payload{Type: 1, UnitID: []byte{1}}}
+		var err error
+		for i := 0; i <= txPrevalidationQueueLen+1 && err == nil; i++ {
+			err = p.Submit(tx, func(error) {})
+		}
+		require.ErrorIs(t, err, ErrPrevalidationQueueFull)
+	})
+
+	t.Run("validate", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		p := newTxPrevalidator(4, validate)
+		done := make(chan error)
+		go func() { done <- p.Run(ctx) }()
+
+		require.NoError(t, p.Validate(ctx, &types.TransactionOrder{Payload: types.Payload{Type: 1, UnitID: []byte{1}}}))
+		require.ErrorIs(t, p.Validate(ctx, &types.TransactionOrder{Payload: types.Payload{Type: 0, UnitID: []byte{2}}}), errInvalid)
+
+		cancel()
+		require.ErrorIs(t, <-done, context.Canceled)
+	})
+
+	t.Run("validated inline when workers are not running", func(t *testing.T) {
+		p := newTxPrevalidator(1, validate)
+		require.NoError(t, p.Validate(context.Background(), &types.TransactionOrder{Payload: types.Payload{Type: 1, UnitID: []byte{1}}}))
+		require.ErrorIs(t, p.Validate(context.Background(), &types.TransactionOrder{Payload: types.Payload{Type: 0, UnitID: []byte{1}}}), errInvalid)
+	})
+
+	t.Run("validated inline when queue is full", func(t *testing.T) {
+		// the only worker is blocked so the queue fills up
+		block := make(chan struct{})
+		p := newTxPrevalidator(1, func(tx *types.TransactionOrder) error {
+			if tx.Type == 2 {
+				<-block
+			}
+			return validate(tx)
+		})
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go p.Run(ctx)
+		require.Eventually(t, p.running.Load, time.Second, 10*time.Millisecond)
+		defer close(block)
+
+		// submit until the queue is full, the capacity is the length of the queue
+		// plus the job held by the blocked worker
+		tx := &types.TransactionOrder{Payload: types.Payload{Type: 2, UnitID: []byte{1}}}
+		capacity := cap(p.queues[0]) + 1
+		var err error
+		for i := 0; i < capacity+10 && err == nil; i++ {
+			err = p.Submit(tx, func(error) {})
+		}
+		require.ErrorIs(t, err, ErrPrevalidationQueueFull)
+
+		require.NoError(t, p.Validate(ctx, &types.TransactionOrder{Payload: types.Payload{Type: 1, UnitID: []byte{1}}}))
+		require.ErrorIs(t, p.Validate(ctx, &types.TransactionOrder{Payload: types.Payload{Type: 0, UnitID: []byte{1}}}), errInvalid)
+	})
+
+	t.Run("order of transactions of the same unit is preserved", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		p := newTxPrevalidator(4, validate)
+		go p.Run(ctx)
+
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		var order []uint16
+		for i := range uint16(100) {
+			wg.Add(1)
+			tx := &types.TransactionOrder{Payload: types.Payload{Type: i + 1, UnitID: []byte{7}}}
+			require.NoError(t, p.Submit(tx, func(err error) {
+				defer wg.Done()
+				require.NoError(t, err)
+				mu.Lock()
+				order = append(order, tx.Type)
+				mu.Unlock()
+			}))
+		}
+		wg.Wait()
+		for i, typ := range order {
+			require.EqualValues(t, i+1, typ)
+		}
+	})
+}
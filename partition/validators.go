@@ -30,11 +30,21 @@ type (
 		Validate(bp *blockproposal.BlockProposal, sigVerifier crypto.Verifier, shardConfHash []byte) error
 	}
 
+	// RootTrustBases returns the trust bases of the root epochs.
+	RootTrustBases interface {
+		TrustBase(epoch uint64) (types.RootTrustBase, error)
+	}
+
+	// ShardConfHashes returns the hashes of the shard configurations of the shard epochs.
+	ShardConfHashes interface {
+		ShardConfHash(epoch uint64) ([]byte, error)
+	}
+
 	// DefaultUnicityCertificateValidator is a default implementation of UnicityCertificateValidator.
 	DefaultUnicityCertificateValidator struct {
 		partitionID types.PartitionID
 		shardID     types.ShardID
-		trustBase   types.RootTrustBase
+		trustBases  RootTrustBases
 		hashAlg     gocrypto.Hash
 	}
 
@@ -42,7 +52,7 @@ type (
 	DefaultBlockProposalValidator struct {
 		partitionID types.PartitionID
 		shardID     types.ShardID
-		trustBase   types.RootTrustBase
+		trustBases  RootTrustBases
 		hashAlg     gocrypto.Hash
 	}
 
@@ -95,16 +105,45 @@ func NewDefaultUnicityCertificateValidator(
 	if trustBase == nil {
 		return nil, types.ErrRootValidatorInfoMissing
 	}
+	return newUnicityCertificateValidator(partitionID, shardID, NewOrchestration(trustBase), hashAlg), nil
+}
+
+/*
+NewUnicityCertificateValidator creates a new instance of default UnicityCertificateValidator
+which verifies the certificates against the trust base of the root epoch of the unicity seal.
+*/
+func NewUnicityCertificateValidator(
+	partitionID types.PartitionID,
+	shardID types.ShardID,
+	trustBases RootTrustBases,
+	hashAlg gocrypto.Hash,
+) (UnicityCertificateValidator, error) {
+	if trustBases == nil {
+		return nil, types.ErrRootValidatorInfoMissing
+	}
+	return newUnicityCertificateValidator(partitionID, shardID, trustBases, hashAlg), nil
+}
+
+func newUnicityCertificateValidator(
+	partitionID types.PartitionID,
+	shardID types.ShardID,
+	trustBases RootTrustBases,
+	hashAlg gocrypto.Hash,
+) *DefaultUnicityCertificateValidator {
 	return &DefaultUnicityCertificateValidator{
 		partitionID: partitionID,
 		shardID:     shardID,
-		trustBase:   trustBase,
+		trustBases:  trustBases,
 		hashAlg:     hashAlg,
-	}, nil
+	}
 }
 
 func (ucv *DefaultUnicityCertificateValidator) Validate(uc *types.UnicityCertificate, shardConfHash []byte) error {
-	return uc.Verify(ucv.trustBase, ucv.hashAlg, ucv.partitionID, shardConfHash)
+	tb, err := ucv.trustBases.TrustBase(rootEpoch(uc))
+	if err != nil {
+		return err
+	}
+	return uc.Verify(tb, ucv.hashAlg, ucv.partitionID, shardConfHash)
 }
 
 // NewDefaultBlockProposalValidator creates a new instance of default BlockProposalValidator.
@@ -117,20 +156,44 @@ func NewDefaultBlockProposalValidator(
 	if trustBase == nil {
 		return nil, types.ErrRootValidatorInfoMissing
 	}
+	return newBlockProposalValidator(partitionID, shardID, NewOrchestration(trustBase), hashAlg), nil
+}
+
+func newBlockProposalValidator(
+	partitionID types.PartitionID,
+	shardID types.ShardID,
+	trustBases RootTrustBases,
+	hashAlg gocrypto.Hash,
+) *DefaultBlockProposalValidator {
 	return &DefaultBlockProposalValidator{
 		partitionID: partitionID,
 		shardID:     shardID,
-		trustBase:   trustBase,
+		trustBases:  trustBases,
 		hashAlg:     hashAlg,
-	}, nil
+	}
 }
 
 func (bpv *DefaultBlockProposalValidator) Validate(bp *blockproposal.BlockProposal, nodeSignatureVerifier crypto.Verifier, shardConfHash []byte) error {
+	var tb types.RootTrustBase
+	if bp != nil {
+		var err error
+		if tb, err = bpv.trustBases.TrustBase(rootEpoch(bp.UnicityCertificate)); err != nil {
+			return err
+		}
+	}
 	return bp.IsValid(
 		nodeSignatureVerifier,
-		bpv.trustBase,
+		tb,
 		bpv.hashAlg,
 		bpv.partitionID,
 		shardConfHash,
 	)
 }
+
+// rootEpoch returns the root epoch of the unicity seal of the certificate.
+func rootEpoch(uc *types.UnicityCertificate) uint64 {
+	if uc == nil || uc.UnicitySeal == nil {
+		return 0
+	}
+	return uc.UnicitySeal.Epoch
+}
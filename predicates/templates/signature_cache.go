@@ -0,0 +1,163 @@
+package templates
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/alphabill-org/alphabill-go-base/cbor"
+	"github.com/alphabill-org/alphabill-go-base/crypto"
+	"github.com/alphabill-org/alphabill-go-base/predicates/templates"
+	"github.com/alphabill-org/alphabill-go-base/types"
+)
+
+const (
+	defaultSignatureCacheSize = 100_000
+	// how deep to descend into the auth proof when looking for signatures
+	maxProofScanDepth = 4
+)
+
+/*
+sigCache memoizes successful P2PKH signature verifications so that signatures
+verified while pre-validating incoming transactions (possibly concurrently)
+do not have to be verified again when the transaction is executed.
+
+Verification of a signature is a pure function of the public key, signature and
+the signed message so sharing the cache between predicate engines is safe. Failed
+verifications are not cached so that invalid signatures can't be used to evict
+valid ones.
+*/
+var sigCache = newSignatureCache(defaultSignatureCacheSize)
+
+type signatureCache struct {
+	mu       sync.Mutex
+	verified map[[32]byte]struct{}
+	// keys in insertion order, used as a ring buffer to evict the oldest items
+	keys [][32]byte
+	next int
+}
+
+func newSignatureCache(size int) *signatureCache {
+	return &signatureCache{
+		verified: make(map[[32]byte]struct{}, size),
+		keys:     make([][32]byte, size),
+	}
+}
+
+func (c *signatureCache) has(key [32]byte) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.verified[key]
+	return ok
+}
+
+func (c *signatureCache) add(key [32]byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.verified[key]; ok {
+		return
+	}
+	if old := c.keys[c.next]; old != ([32]byte{}) {
+		delete(c.verified, old)
+	}
+	c.keys[c.next] = key
+	c.next = (c.next + 1) % len(c.keys)
+	c.verified[key] = struct{}{}
+}
+
+/*
+signatureCacheKey returns the cache key of the verification of the signature "sig"
+of the "msg" against "pubKey". Each field is prefixed with its length so that
+different combinations of the fields can't produce the same key.
+*/
+func signatureCacheKey(pubKey, sig, msg []byte) [32]byte {
+	h := sha256.New()
+	for _, field := range [][]byte{pubKey, sig, msg} {
+		h.Write(binary.BigEndian.AppendUint64(nil, uint64(len(field))))
+		h.Write(field)
+	}
+	var key [32]byte
+	h.Sum(key[:0])
+	return key
+}
+
+/*
+verifySignature verifies secp256k1 signature "sig" of the "msg" against "pubKey".
+Returns false without error when the signature doesn't verify. Successful
+verifications are cached.
+*/
+func verifySignature(pubKey, sig, msg []byte) (bool, error) {
+	key := signatureCacheKey(pubKey, sig, msg)
+	if sigCache.has(key) {
+		return true, nil
+	}
+
+	verifier, err := crypto.NewVerifierSecp256k1(pubKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to create verifier: %w", err)
+	}
+	if err = verifier.VerifyBytes(sig, msg); err != nil {
+		if errors.Is(err, crypto.ErrVerificationFailed) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to verify signature: %w", err)
+	}
+	sigCache.add(key)
+	return true, nil
+}
+
+/*
+PrevalidateSignatures verifies the P2PKH256 signatures found in the auth proof and
+fee proof of the transaction and caches the valid ones so that the P2PKH256 template
+doesn't need to verify them again when the transaction is executed.
+
+As the predicates which the proofs are meant to satisfy are not known without the
+state, the proofs are scanned for values with the structure of the P2PKH256 signature.
+The scan is best effort: it only fills the cache, whether the transaction is valid is
+decided by the predicate execution. Proofs which can't be decoded are skipped.
+*/
+func PrevalidateSignatures(tx *types.TransactionOrder) {
+	if len(tx.AuthProof) > 0 {
+		var proof any
+		if err := tx.UnmarshalAuthProof(&proof); err == nil {
+			if sigBytes, err := tx.AuthProofSigBytes(); err == nil {
+				verifyEmbeddedSignatures(proof, sigBytes, maxProofScanDepth)
+			}
+		}
+	}
+	if len(tx.FeeProof) > 0 {
+		if sigBytes, err := tx.FeeProofSigBytes(); err == nil {
+			verifyEmbeddedSignatures([]byte(tx.FeeProof), sigBytes, maxProofScanDepth)
+		}
+	}
+}
+
+func verifyEmbeddedSignatures(v any, sigBytes []byte, depth int) {
+	if depth < 0 {
+		return
+	}
+	switch t := v.(type) {
+	case []byte:
+		if sig, ok := decodeP2PKH256Signature(t); ok {
+			// valid signature is cached, failures are reported by the predicate execution
+			_, _ = verifySignature(sig.PubKey, sig.Sig, sigBytes)
+		}
+	case []any:
+		for _, item := range t {
+			verifyEmbeddedSignatures(item, sigBytes, depth-1)
+		}
+	}
+}
+
+func decodeP2PKH256Signature(data []byte) (*templates.P2pkh256Signature, bool) {
+	sig := &templates.P2pkh256Signature{}
+	if err := cbor.Unmarshal(data, sig); err != nil {
+		return nil, false
+	}
+	if len(sig.Sig) != 65 || len(sig.PubKey) != 33 {
+		return nil, false
+	}
+	return sig, true
+}
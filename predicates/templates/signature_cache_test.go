@@ -0,0 +1,106 @@
+package templates
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/cbor"
+	"github.com/alphabill-org/alphabill-go-base/crypto"
+	"github.com/alphabill-org/alphabill-go-base/predicates/templates"
+	"github.com/alphabill-org/alphabill-go-base/types"
+	testsig "github.com/alphabill-org/alphabill/internal/testutils/sig"
+)
+
+func TestSignatureCache(t *testing.T) {
+	c := newSignatureCache(2)
+	k1 := signatureCacheKey([]byte{1}, nil, nil)
+	k2 := signatureCacheKey([]byte{2}, nil, nil)
+	k3 := signatureCacheKey([]byte{3}, nil, nil)
+
+	require.False(t, c.has(k1))
+
+	c.add(k1)
+	c.add(k2)
+	require.True(t, c.has(k1))
+	require.True(t, c.has(k2))
+
+	// adding third item evicts the oldest one
+	c.add(k3)
+	require.False(t, c.has(k1))
+	require.True(t, c.has(k2))
+	require.True(t, c.has(k3))
+	require.Len(t, c.verified, 2)
+
+	// moving bytes from one field to another must change the key
+	require.NotEqual(t, signatureCacheKey([]byte{1}, []byte{2}, nil), signatureCacheKey([]byte{1, 2}, nil, nil))
+	require.NotEqual(t, signatureCacheKey(nil, []byte{1}, []byte{2}), signatureCacheKey(nil, nil, []byte{1, 2}))
+}
+
+func TestPrevalidateSignatures(t *testing.T) {
+	signer, err := crypto.NewInMemorySecp256K1Signer()
+	require.NoError(t, err)
+
+	newTx := func(t *testing.T) *types.TransactionOrder {
+		tx := &types.TransactionOrder{
+			Version: 1,
+			Payload: types.Payload{
+				PartitionID: 1,
+				Type:        22,
+				UnitID:      []byte{0, 0, 1, 1, 2, 2},
+			},
+		}
+		require.NoError(t, tx.SetAttributes("not really attributes"))
+		return tx
+	}
+
+	t.Run("no proofs", func(t *testing.T) {
+		require.NotPanics(t, func() { PrevalidateSignatures(newTx(t)) })
+	})
+
+	t.Run("valid owner proof", func(t *testing.T) {
+		tx := newTx(t)
+		ownerProof := testsig.NewAuthProofSignature(t, tx, signer)
+		// auth proofs are usually structs with owner proof as a field
+		require.NoError(t, tx.SetAuthProof([]any{ownerProof}))
+		PrevalidateSignatures(tx)
+
+		// result of the verification has been cached
+		sig, ok := decodeP2PKH256Signature(ownerProof)
+		require.True(t, ok)
+		sigBytes, err := tx.AuthProofSigBytes()
+		require.NoError(t, err)
+		require.True(t, sigCache.has(signatureCacheKey(sig.PubKey, sig.Sig, sigBytes)))
+	})
+
+	t.Run("invalid owner proof", func(t *testing.T) {
+		tx := newTx(t)
+		ownerProof := testsig.NewAuthProofSignature(t, tx, signer)
+		sig, ok := decodeP2PKH256Signature(ownerProof)
+		require.True(t, ok)
+		sig.Sig[4] ^= 0xFF
+		ownerProof, err = cbor.Marshal(sig)
+		require.NoError(t, err)
+		require.NoError(t, tx.SetAuthProof(ownerProof))
+		PrevalidateSignatures(tx)
+
+		// the failed verification is not cached, the tx is rejected by the predicate execution
+		sigBytes, err := tx.AuthProofSigBytes()
+		require.NoError(t, err)
+		require.False(t, sigCache.has(signatureCacheKey(sig.PubKey, sig.Sig, sigBytes)))
+		valid, err := verifySignature(sig.PubKey, sig.Sig, sigBytes)
+		require.NoError(t, err)
+		require.False(t, valid)
+	})
+
+	t.Run("proof which is not a signature is ignored", func(t *testing.T) {
+		tx := newTx(t)
+		proof, err := cbor.Marshal(templates.P2pkh256Signature{Sig: []byte{1, 2, 3}, PubKey: []byte{4}})
+		require.NoError(t, err)
+		require.NoError(t, tx.SetAuthProof([]any{proof, []byte{5, 6}}))
+		sigBytes, err := tx.AuthProofSigBytes()
+		require.NoError(t, err)
+		PrevalidateSignatures(tx)
+		require.False(t, sigCache.has(signatureCacheKey([]byte{4}, []byte{1, 2, 3}, sigBytes)))
+	})
+}
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"context"
 	"crypto/sha256"
-	"errors"
 	"fmt"
 	"time"
 
@@ -12,7 +11,6 @@ import (
 	"go.opentelemetry.io/otel/metric"
 
 	"github.com/alphabill-org/alphabill-go-base/cbor"
-	"github.com/alphabill-org/alphabill-go-base/crypto"
 	sdkpredicates "github.com/alphabill-org/alphabill-go-base/predicates"
 	"github.com/alphabill-org/alphabill-go-base/predicates/templates"
 	"github.com/alphabill-org/alphabill-go-base/types"
@@ -123,15 +121,5 @@ func executeP2PKH256(pubKeyHash, args []byte, env predicates.TxContext) (bool, e
 		return false, nil
 	}
 
-	verifier, err := crypto.NewVerifierSecp256k1(p2pkh256Signature.PubKey)
-	if err != nil {
-		return false, fmt.Errorf("failed to create verifier: %w", err)
-	}
-	if err = verifier.VerifyBytes(p2pkh256Signature.Sig, sigBytes); err != nil {
-		if errors.Is(err, crypto.ErrVerificationFailed) {
-			return false, nil
-		}
-		return false, fmt.Errorf("failed to verify signature: %w", err)
-	}
-	return true, nil
+	return verifySignature(p2pkh256Signature.PubKey, p2pkh256Signature.Sig, sigBytes)
 }
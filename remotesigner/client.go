@@ -0,0 +1,217 @@
+package remotesigner
+
+import (
+	"bytes"
+	"context"
+	gocrypto "crypto"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/alphabill-org/alphabill-go-base/cbor"
+	"github.com/alphabill-org/alphabill-go-base/crypto"
+	"github.com/alphabill-org/alphabill/network/protocol/abdrc"
+	"github.com/alphabill-org/alphabill/network/protocol/blockproposal"
+	"github.com/alphabill-org/alphabill/network/protocol/certification"
+	drctypes "github.com/alphabill-org/alphabill/rootchain/consensus/types"
+)
+
+const defaultRequestTimeout = 5 * time.Second
+
+/*
+Client implements crypto.Signer by delegating the signing to the remote signer.
+
+The requests are sent one at a time over a single connection, the connection is
+re-established when a request fails.
+*/
+type Client struct {
+	network string
+	address string
+	tlsConf *tls.Config
+	timeout time.Duration
+
+	verifier crypto.Verifier
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+var (
+	_ crypto.Signer                = (*Client)(nil)
+	_ certification.RequestSigner  = (*Client)(nil)
+	_ blockproposal.ProposalSigner = (*Client)(nil)
+	_ abdrc.ProposalSigner         = (*Client)(nil)
+	_ abdrc.VoteSigner             = (*Client)(nil)
+	_ abdrc.TimeoutSigner          = (*Client)(nil)
+	_ abdrc.IRChangeRequestSigner  = (*Client)(nil)
+)
+
+/*
+Dial connects to the remote signer and fetches the public key of the signing key.
+See VerifyPublicKey for checking that the signer uses the expected key.
+*/
+func Dial(ctx context.Context, address string, tlsConf *tls.Config) (*Client, error) {
+	network, addr := ParseAddress(address)
+	c := &Client{
+		network: network,
+		address: addr,
+		tlsConf: tlsConf,
+		timeout: defaultRequestTimeout,
+	}
+	pubKey, err := c.call(ctx, methodPublicKey, nil)
+	if err != nil {
+		return nil, fmt.Errorf("requesting public key: %w", err)
+	}
+	if c.verifier, err = crypto.NewVerifierSecp256k1(pubKey); err != nil {
+		_ = c.Close()
+		return nil, fmt.Errorf("invalid public key of the remote signer: %w", err)
+	}
+	return c, nil
+}
+
+/*
+SignBytes always fails, the remote signer doesn't sign raw bytes as it couldn't
+tell what is signed and enforce the anti-double-signing rules then. The messages
+are sent to the signer using the typed methods (SignCertificationRequest etc).
+*/
+func (c *Client) SignBytes(data []byte) ([]byte, error) {
+	return nil, errors.New("remote signer does not sign raw bytes")
+}
+
+/*
+SignHash always fails, see SignBytes.
+*/
+func (c *Client) SignHash(hash []byte) ([]byte, error) {
+	return nil, errors.New("remote signer does not sign raw hashes")
+}
+
+func (c *Client) SignCertificationRequest(req *certification.BlockCertificationRequest) ([]byte, error) {
+	data, err := req.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("encoding certification request: %w", err)
+	}
+	return c.call(context.Background(), methodSignCertificationRequest, data)
+}
+
+func (c *Client) SignBlockProposal(prop *blockproposal.BlockProposal, algorithm gocrypto.Hash) ([]byte, error) {
+	if algorithm != gocrypto.SHA256 {
+		return nil, fmt.Errorf("unsupported hash algorithm %v", algorithm)
+	}
+	data, err := cbor.Marshal(prop)
+	if err != nil {
+		return nil, fmt.Errorf("encoding block proposal: %w", err)
+	}
+	return c.call(context.Background(), methodSignBlockProposal, data)
+}
+
+func (c *Client) SignRootProposal(block *drctypes.BlockData) ([]byte, error) {
+	data, err := cbor.Marshal(block)
+	if err != nil {
+		return nil, fmt.Errorf("encoding root proposal: %w", err)
+	}
+	return c.call(context.Background(), methodSignRootProposal, data)
+}
+
+func (c *Client) SignVote(vote *abdrc.VoteMsg) ([]byte, error) {
+	data, err := cbor.Marshal(vote)
+	if err != nil {
+		return nil, fmt.Errorf("encoding vote: %w", err)
+	}
+	return c.call(context.Background(), methodSignVote, data)
+}
+
+func (c *Client) SignTimeout(msg *abdrc.TimeoutMsg) ([]byte, error) {
+	data, err := cbor.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("encoding timeout: %w", err)
+	}
+	return c.call(context.Background(), methodSignTimeout, data)
+}
+
+func (c *Client) SignIRChangeRequest(msg *abdrc.IrChangeReqMsg) ([]byte, error) {
+	data, err := cbor.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("encoding IR change request: %w", err)
+	}
+	return c.call(context.Background(), methodSignIRChangeRequest, data)
+}
+
+/*
+VerifyPublicKey returns error when the signing key of the remote signer is not
+the one with the public key "pubKey".
+*/
+func (c *Client) VerifyPublicKey(pubKey []byte) error {
+	signerKey, err := c.verifier.MarshalPublicKey()
+	if err != nil {
+		return fmt.Errorf("marshaling public key of the remote signer: %w", err)
+	}
+	if !bytes.Equal(signerKey, pubKey) {
+		return fmt.Errorf("public key of the remote signer %X doesn't match the expected key %X", signerKey, pubKey)
+	}
+	return nil
+}
+
+func (c *Client) MarshalPrivateKey() ([]byte, error) {
+	return nil, errors.New("private key of the remote signer is not accessible")
+}
+
+func (c *Client) Verifier() (crypto.Verifier, error) {
+	return c.verifier, nil
+}
+
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
+
+func (c *Client) call(ctx context.Context, method uint8, data []byte) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	if c.conn == nil {
+		dialer := &tls.Dialer{Config: c.tlsConf}
+		conn, err := dialer.DialContext(ctx, c.network, c.address)
+		if err != nil {
+			return nil, fmt.Errorf("connecting to remote signer: %w", err)
+		}
+		c.conn = conn
+	}
+	resp, err := c.roundTrip(ctx, &request{Method: method, Data: data})
+	if err != nil {
+		// the state of the connection is unknown, reconnect on the next request
+		_ = c.conn.Close()
+		c.conn = nil
+		return nil, err
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("remote signer: %s", resp.Error)
+	}
+	return resp.Result, nil
+}
+
+func (c *Client) roundTrip(ctx context.Context, req *request) (*response, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := c.conn.SetDeadline(deadline); err != nil {
+			return nil, fmt.Errorf("setting deadline: %w", err)
+		}
+	}
+	if err := writeMsg(c.conn, req); err != nil {
+		return nil, err
+	}
+	resp := &response{}
+	if err := readMsg(c.conn, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
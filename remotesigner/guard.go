@@ -0,0 +1,105 @@
+package remotesigner
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/alphabill-org/alphabill-go-base/cbor"
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill/keyvaluedb"
+	"github.com/alphabill-org/alphabill/network/protocol/certification"
+)
+
+// prefixes of the database keys of the last signed messages
+const (
+	keyCertificationRequest byte = 'c'
+	keyBlockProposal        byte = 'b'
+	keyRootProposal         byte = 'r'
+	keyVote                 byte = 'v'
+	keyTimeout              byte = 't'
+)
+
+type (
+	// doubleSignGuard enforces the anti-double-signing rules of the block certification
+	// requests, block proposals, root chain proposals, votes and timeout votes. The last
+	// signed message of every kind (and shard) is persisted and a message is signed only when
+	//   - its round is greater than the round of the last signed message or;
+	//   - the round is the same but the timestamp is newer, which is the case when the
+	//     round is re-certified after a repeat UC (T2 timeout) or the timeout of the
+	//     round is re-signed with a newer high QC or;
+	//   - it is identical to the last signed message.
+	doubleSignGuard struct {
+		db keyvaluedb.KeyValueDB
+	}
+
+	// signedRequest is the last message of a kind signed for a shard
+	signedRequest struct {
+		_         struct{} `cbor:",toarray"`
+		Round     uint64
+		Timestamp uint64
+		Hash      []byte
+	}
+)
+
+/*
+decodeCertificationRequest returns nil when the data is not a block certification request.
+*/
+func decodeCertificationRequest(data []byte) *certification.BlockCertificationRequest {
+	req := &certification.BlockCertificationRequest{}
+	if err := cbor.Unmarshal(data, req); err != nil {
+		return nil
+	}
+	if req.PartitionID == 0 || req.InputRecord == nil {
+		return nil
+	}
+	return req
+}
+
+func shardKey(prefix byte, partitionID types.PartitionID, shardID types.ShardID) []byte {
+	key := binary.BigEndian.AppendUint32([]byte{prefix}, uint32(partitionID))
+	return append(key, shardID.Key()...)
+}
+
+/*
+checkCertificationRequest returns error when signing the certification request
+"data" would violate the anti-double-signing rules, otherwise the request is
+recorded as signed.
+*/
+func (g *doubleSignGuard) checkCertificationRequest(req *certification.BlockCertificationRequest, data []byte) error {
+	hash := sha256.Sum256(data)
+	return g.check(shardKey(keyCertificationRequest, req.PartitionID, req.ShardID), "certification request", signedRequest{
+		Round:     req.InputRecord.RoundNumber,
+		Timestamp: req.InputRecord.Timestamp,
+		Hash:      hash[:],
+	})
+}
+
+/*
+check returns error when signing the message "signed" would violate the
+anti-double-signing rules, otherwise the message is recorded as the last
+signed message under the "key".
+*/
+func (g *doubleSignGuard) check(key []byte, kind string, signed signedRequest) error {
+	var last signedRequest
+	found, err := g.db.Read(key, &last)
+	if err != nil {
+		return fmt.Errorf("reading last signed %s: %w", kind, err)
+	}
+	if found {
+		switch {
+		case signed.Round < last.Round:
+			return fmt.Errorf("refusing to sign round %d, already signed round %d", signed.Round, last.Round)
+		case signed.Round > last.Round:
+		case bytes.Equal(signed.Hash, last.Hash):
+			return nil
+		case signed.Timestamp <= last.Timestamp:
+			return fmt.Errorf("refusing to sign a different %s for round %d", kind, signed.Round)
+		}
+	}
+	if err := g.db.Write(key, &signed); err != nil {
+		return fmt.Errorf("storing signed %s: %w", kind, err)
+	}
+	return nil
+}
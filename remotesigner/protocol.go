@@ -0,0 +1,190 @@
+/*
+Package remotesigner implements a signing daemon which keeps the validator signing
+key on a separate host and a client which implements the crypto.Signer interface by
+delegating all the signatures to the daemon.
+
+The daemon doesn't sign raw bytes or hashes: block certification requests, block
+proposals, root chain proposals, votes, timeout votes and IR change requests are
+sent to the daemon as messages, which it decodes, validates and serializes or
+hashes itself so that the anti-double-signing rules can't be bypassed.
+
+The client and the daemon communicate over a TCP or Unix socket connection secured
+with mutual TLS: both sides must present a certificate signed by the CA the other
+side trusts. The messages are CBOR encoded and prefixed with their length.
+*/
+package remotesigner
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/alphabill-org/alphabill-go-base/cbor"
+)
+
+const (
+	methodPublicKey uint8 = 1
+	// the signing methods send the message to be signed, the signer calculates
+	// the signed bytes (hash) itself and enforces the anti-double-signing rules.
+	// Method 2 was signing raw bytes, it is not supported anymore.
+	methodSignCertificationRequest uint8 = 3
+	methodSignBlockProposal        uint8 = 4
+	methodSignRootProposal         uint8 = 5
+	methodSignVote                 uint8 = 6
+	methodSignTimeout              uint8 = 7
+	methodSignIRChangeRequest      uint8 = 8
+
+	// maximum size of a message, block proposals include the transactions of the block
+	maxMessageSize = 64 << 20
+
+	unixAddressPrefix = "unix:"
+)
+
+type (
+	request struct {
+		_      struct{} `cbor:",toarray"`
+		Method uint8
+		Data   []byte
+	}
+
+	response struct {
+		_      struct{} `cbor:",toarray"`
+		Result []byte
+		Error  string
+	}
+)
+
+/*
+ParseAddress returns the network and the address of the signer address, addresses
+with the "unix:" prefix are Unix socket paths, others are TCP "host:port" addresses.
+*/
+func ParseAddress(address string) (network, addr string) {
+	if path, ok := strings.CutPrefix(address, unixAddressPrefix); ok {
+		return "unix", path
+	}
+	return "tcp", address
+}
+
+/*
+Listen creates a TLS listener on the address, see ParseAddress for the address format.
+*/
+func Listen(address string, tlsConf *tls.Config) (net.Listener, error) {
+	network, addr := ParseAddress(address)
+	ln, err := net.Listen(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	return tls.NewListener(ln, tlsConf), nil
+}
+
+/*
+ServerTLSConfig returns the TLS configuration of the signer daemon, the clients
+must present a certificate signed by the CA in the "clientCAFile".
+*/
+func ServerTLSConfig(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading certificate: %w", err)
+	}
+	pool, err := loadCertPool(clientCAFile)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+		MinVersion:   tls.VersionTLS13,
+	}, nil
+}
+
+/*
+ClientTLSConfig returns the TLS configuration of the signer client, the signer
+must present a certificate signed by the CA in the "serverCAFile".
+
+The CA is expected to be dedicated to the signer deployment so the host name of
+the signer is not verified, which allows to use Unix sockets and IP addresses.
+*/
+func ClientTLSConfig(certFile, keyFile, serverCAFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading certificate: %w", err)
+	}
+	pool, err := loadCertPool(serverCAFile)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS13,
+		// the default verification is replaced with VerifyConnection which skips the host name check
+		InsecureSkipVerify: true, // #nosec G402
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return errors.New("signer did not present a certificate")
+			}
+			opts := x509.VerifyOptions{
+				Roots:         pool,
+				Intermediates: x509.NewCertPool(),
+				KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+			}
+			for _, c := range cs.PeerCertificates[1:] {
+				opts.Intermediates.AddCert(c)
+			}
+			if _, err := cs.PeerCertificates[0].Verify(opts); err != nil {
+				return fmt.Errorf("verifying signer certificate: %w", err)
+			}
+			return nil
+		},
+	}, nil
+}
+
+func loadCertPool(caFile string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(filepath.Clean(caFile))
+	if err != nil {
+		return nil, fmt.Errorf("reading CA certificate: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in %q", caFile)
+	}
+	return pool, nil
+}
+
+func writeMsg(w io.Writer, msg any) error {
+	data, err := cbor.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("encoding message: %w", err)
+	}
+	buf := binary.BigEndian.AppendUint32(make([]byte, 0, 4+len(data)), uint32(len(data))) // #nosec G115 message size is limited
+	if _, err := w.Write(append(buf, data...)); err != nil {
+		return fmt.Errorf("writing message: %w", err)
+	}
+	return nil
+}
+
+func readMsg(r io.Reader, msg any) error {
+	var length [4]byte
+	if _, err := io.ReadFull(r, length[:]); err != nil {
+		return fmt.Errorf("reading message length: %w", err)
+	}
+	size := binary.BigEndian.Uint32(length[:])
+	if size == 0 || size > maxMessageSize {
+		return fmt.Errorf("invalid message length %d", size)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return fmt.Errorf("reading message: %w", err)
+	}
+	if err := cbor.Unmarshal(data, msg); err != nil {
+		return fmt.Errorf("decoding message: %w", err)
+	}
+	return nil
+}
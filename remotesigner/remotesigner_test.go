@@ -0,0 +1,418 @@
+package remotesigner
+
+import (
+	"context"
+	gocrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/cbor"
+	abcrypto "github.com/alphabill-org/alphabill-go-base/crypto"
+	"github.com/alphabill-org/alphabill-go-base/types"
+	testlogger "github.com/alphabill-org/alphabill/internal/testutils/logger"
+	testsig "github.com/alphabill-org/alphabill/internal/testutils/sig"
+	testtb "github.com/alphabill-org/alphabill/internal/testutils/trustbase"
+	"github.com/alphabill-org/alphabill/keyvaluedb/memorydb"
+	"github.com/alphabill-org/alphabill/network/protocol/abdrc"
+	"github.com/alphabill-org/alphabill/network/protocol/blockproposal"
+	"github.com/alphabill-org/alphabill/network/protocol/certification"
+	drctypes "github.com/alphabill-org/alphabill/rootchain/consensus/types"
+)
+
+func TestRemoteSigner(t *testing.T) {
+	certs := newTestCerts(t)
+	signer, verifier := testsig.CreateSignerAndVerifier(t)
+	address := startServer(t, signer, certs)
+
+	tlsConf, err := ClientTLSConfig(certs.clientCert, certs.clientKey, certs.ca)
+	require.NoError(t, err)
+	client, err := Dial(context.Background(), address, tlsConf)
+	require.NoError(t, err)
+	defer client.Close()
+
+	t.Run("public key", func(t *testing.T) {
+		clientVerifier, err := client.Verifier()
+		require.NoError(t, err)
+		pubKey, err := verifier.MarshalPublicKey()
+		require.NoError(t, err)
+		clientPubKey, err := clientVerifier.MarshalPublicKey()
+		require.NoError(t, err)
+		require.Equal(t, pubKey, clientPubKey)
+
+		_, err = client.MarshalPrivateKey()
+		require.EqualError(t, err, "private key of the remote signer is not accessible")
+	})
+
+	t.Run("raw bytes are not signed", func(t *testing.T) {
+		_, err := client.SignBytes([]byte("data"))
+		require.EqualError(t, err, "remote signer does not sign raw bytes")
+		// nor by the signer when sent with the removed raw bytes method
+		_, err = client.call(context.Background(), 2, []byte("data"))
+		require.EqualError(t, err, "remote signer: unknown method 2")
+	})
+
+	t.Run("certification requests", func(t *testing.T) {
+		req := newCertificationRequest(10, 100, []byte{1})
+		require.NoError(t, req.Sign(client))
+		require.NoError(t, req.IsValid(verifier))
+		// re-signing the same request is allowed
+		require.NoError(t, req.Sign(client))
+
+		// different request for the same round
+		require.ErrorContains(t, newCertificationRequest(10, 100, []byte{2}).Sign(client),
+			"remote signer: refusing to sign a different certification request for round 10")
+		// older round
+		require.ErrorContains(t, newCertificationRequest(9, 101, []byte{2}).Sign(client),
+			"remote signer: refusing to sign round 9, already signed round 10")
+		// the round is re-certified after a repeat UC
+		require.NoError(t, newCertificationRequest(10, 101, []byte{2}).Sign(client))
+		require.ErrorContains(t, newCertificationRequest(10, 100, []byte{1}).Sign(client),
+			"refusing to sign a different certification request for round 10")
+		// next round
+		require.NoError(t, newCertificationRequest(11, 101, []byte{3}).Sign(client))
+
+		// other shards are independent
+		req = newCertificationRequest(5, 100, []byte{1})
+		req.PartitionID = 2
+		require.NoError(t, req.Sign(client))
+	})
+
+	t.Run("raw hash is not signed", func(t *testing.T) {
+		_, err := client.SignHash(make([]byte, 32))
+		require.EqualError(t, err, "remote signer does not sign raw hashes")
+	})
+
+	t.Run("block proposals", func(t *testing.T) {
+		prop := newBlockProposal(10, 5)
+		require.NoError(t, prop.Sign(gocrypto.SHA256, client))
+		require.NoError(t, prop.Verify(gocrypto.SHA256, verifier))
+		// re-signing the same proposal is allowed
+		require.NoError(t, prop.Sign(gocrypto.SHA256, client))
+
+		// different proposal for the same round
+		other := newBlockProposal(10, 5)
+		other.Transactions = []*types.TransactionRecord{{Version: 1}}
+		require.ErrorContains(t, other.Sign(gocrypto.SHA256, client),
+			"remote signer: refusing to sign a different block proposal for round 10")
+		// older round
+		require.ErrorContains(t, newBlockProposal(9, 6).Sign(gocrypto.SHA256, client),
+			"remote signer: refusing to sign round 9, already signed round 10")
+		// the round is proposed again after a repeat UC
+		other.UnicityCertificate = newBlockProposal(10, 6).UnicityCertificate
+		require.NoError(t, other.Sign(gocrypto.SHA256, client))
+		// only SHA256 is supported
+		require.ErrorContains(t, newBlockProposal(11, 6).Sign(gocrypto.SHA512, client), "unsupported hash algorithm")
+	})
+
+	t.Run("root proposals", func(t *testing.T) {
+		block := &drctypes.BlockData{Version: 1, Author: "1", Round: 7, Timestamp: 100}
+		sig, err := client.SignRootProposal(block)
+		require.NoError(t, err)
+		hash, err := block.Hash(gocrypto.SHA256)
+		require.NoError(t, err)
+		require.NoError(t, verifier.VerifyHash(sig, hash))
+
+		// different proposal for the same round
+		_, err = client.SignRootProposal(&drctypes.BlockData{Version: 1, Author: "1", Round: 7, Timestamp: 101})
+		require.ErrorContains(t, err, "remote signer: refusing to sign a different root proposal for round 7")
+		// older round
+		_, err = client.SignRootProposal(&drctypes.BlockData{Version: 1, Author: "1", Round: 6, Timestamp: 101})
+		require.ErrorContains(t, err, "remote signer: refusing to sign round 6, already signed round 7")
+		// next round
+		_, err = client.SignRootProposal(&drctypes.BlockData{Version: 1, Author: "1", Round: 8, Timestamp: 101})
+		require.NoError(t, err)
+	})
+
+	t.Run("votes", func(t *testing.T) {
+		vote := newVote(t, 7, []byte{1})
+		require.NoError(t, vote.Sign(client))
+		bs, err := vote.LedgerCommitInfo.SigBytes()
+		require.NoError(t, err)
+		require.NoError(t, verifier.VerifyBytes(vote.Signature, bs))
+		// re-signing the same vote is allowed
+		require.NoError(t, vote.Sign(client))
+
+		// different vote for the same round
+		require.ErrorContains(t, newVote(t, 7, []byte{2}).Sign(client),
+			"remote signer: refusing to sign a different vote for round 7")
+		// older round
+		require.ErrorContains(t, newVote(t, 6, []byte{2}).Sign(client),
+			"remote signer: refusing to sign round 6, already signed round 7")
+		// commit info must commit to the vote info
+		vote = newVote(t, 8, []byte{2})
+		vote.LedgerCommitInfo.PreviousHash = []byte{1, 2, 3}
+		_, err = client.SignVote(vote)
+		require.ErrorContains(t, err, "remote signer: vote info hash does not match the hash in the commit info")
+		// next round
+		require.NoError(t, newVote(t, 8, []byte{2}).Sign(client))
+	})
+
+	t.Run("timeouts", func(t *testing.T) {
+		msg := newTimeout(7, 1)
+		require.NoError(t, msg.Sign(client))
+		require.NoError(t, verifier.VerifyBytes(msg.Signature, msg.Bytes()))
+		// re-signing the same timeout is allowed
+		require.NoError(t, msg.Sign(client))
+
+		// different timeout for the same round
+		require.ErrorContains(t, newTimeout(7, 2).Sign(client),
+			"remote signer: refusing to sign a different timeout for round 7")
+		// older round
+		require.ErrorContains(t, newTimeout(6, 1).Sign(client),
+			"remote signer: refusing to sign round 6, already signed round 7")
+		// invalid timeout
+		msg = newTimeout(8, 1)
+		msg.Timeout.HighQc = nil
+		_, err := client.SignTimeout(msg)
+		require.ErrorContains(t, err, "remote signer: invalid timeout")
+		// next round
+		require.NoError(t, newTimeout(8, 1).Sign(client))
+	})
+
+	t.Run("IR change requests", func(t *testing.T) {
+		msg := &abdrc.IrChangeReqMsg{
+			Author:      "1",
+			IrChangeReq: &drctypes.IRChangeReq{Partition: 1, CertReason: drctypes.Quorum},
+		}
+		require.NoError(t, msg.Sign(client))
+		tb := testtb.NewTrustBaseFromVerifiers(t, map[string]abcrypto.Verifier{"1": verifier})
+		require.NoError(t, msg.Verify(tb))
+
+		// the signer validates the request
+		msg.IrChangeReq.CertReason = drctypes.T2Timeout
+		_, err := client.SignIRChangeRequest(msg)
+		require.ErrorContains(t, err, "remote signer: invalid IR change request")
+	})
+
+	t.Run("verify public key", func(t *testing.T) {
+		pubKey, err := verifier.MarshalPublicKey()
+		require.NoError(t, err)
+		require.NoError(t, client.VerifyPublicKey(pubKey))
+
+		_, otherVerifier := testsig.CreateSignerAndVerifier(t)
+		otherKey, err := otherVerifier.MarshalPublicKey()
+		require.NoError(t, err)
+		require.ErrorContains(t, client.VerifyPublicKey(otherKey), "public key of the remote signer")
+	})
+
+	t.Run("reconnect", func(t *testing.T) {
+		client.mu.Lock()
+		require.NoError(t, client.conn.Close())
+		client.mu.Unlock()
+
+		_, err := client.call(context.Background(), methodPublicKey, nil)
+		require.Error(t, err)
+		pubKey, err := client.call(context.Background(), methodPublicKey, nil)
+		require.NoError(t, err)
+		verifierKey, err := verifier.MarshalPublicKey()
+		require.NoError(t, err)
+		require.Equal(t, verifierKey, pubKey)
+	})
+
+	t.Run("untrusted client", func(t *testing.T) {
+		other := newTestCerts(t)
+		tlsConf, err := ClientTLSConfig(other.clientCert, other.clientKey, certs.ca)
+		require.NoError(t, err)
+		_, err = Dial(context.Background(), address, tlsConf)
+		require.Error(t, err)
+	})
+
+	t.Run("untrusted server", func(t *testing.T) {
+		other := newTestCerts(t)
+		tlsConf, err := ClientTLSConfig(certs.clientCert, certs.clientKey, other.ca)
+		require.NoError(t, err)
+		_, err = Dial(context.Background(), address, tlsConf)
+		require.ErrorContains(t, err, "verifying signer certificate")
+	})
+}
+
+func TestDoubleSignGuard_persisted(t *testing.T) {
+	db, err := memorydb.New()
+	require.NoError(t, err)
+
+	sign := func(round, timestamp uint64, blockHash []byte) error {
+		req := newCertificationRequest(round, timestamp, blockHash)
+		data, err := req.Bytes()
+		require.NoError(t, err)
+		guard := doubleSignGuard{db: db}
+		return guard.checkCertificationRequest(decodeCertificationRequest(data), data)
+	}
+	require.NoError(t, sign(10, 100, []byte{1}))
+	// new guard instance sees the state of the previous one
+	require.EqualError(t, sign(10, 100, []byte{2}), "refusing to sign a different certification request for round 10")
+	require.EqualError(t, sign(10, 99, []byte{2}), "refusing to sign a different certification request for round 10")
+	require.NoError(t, sign(10, 100, []byte{1}))
+}
+
+func Test_decodeCertificationRequest(t *testing.T) {
+	require.Nil(t, decodeCertificationRequest([]byte("data")))
+	require.Nil(t, decodeCertificationRequest(nil))
+
+	data, err := cbor.Marshal(&certification.BlockCertificationRequest{PartitionID: 1})
+	require.NoError(t, err)
+	require.Nil(t, decodeCertificationRequest(data), "input record missing")
+
+	data, err = newCertificationRequest(1, 1, []byte{1}).Bytes()
+	require.NoError(t, err)
+	req := decodeCertificationRequest(data)
+	require.NotNil(t, req)
+	require.EqualValues(t, 1, req.InputRecord.RoundNumber)
+}
+
+func TestParseAddress(t *testing.T) {
+	network, addr := ParseAddress("unix:/run/signer.sock")
+	require.Equal(t, "unix", network)
+	require.Equal(t, "/run/signer.sock", addr)
+
+	network, addr = ParseAddress("localhost:7000")
+	require.Equal(t, "tcp", network)
+	require.Equal(t, "localhost:7000", addr)
+}
+
+func newCertificationRequest(round, timestamp uint64, blockHash []byte) *certification.BlockCertificationRequest {
+	return &certification.BlockCertificationRequest{
+		PartitionID: 1,
+		NodeID:      "1",
+		InputRecord: &types.InputRecord{
+			Version:      1,
+			RoundNumber:  round,
+			Timestamp:    timestamp,
+			PreviousHash: []byte{0, 0, 0, 0},
+			Hash:         []byte{0, 0, 0, 1},
+			BlockHash:    blockHash,
+			SummaryValue: []byte{0, 0, 0, 2},
+		},
+	}
+}
+
+func newBlockProposal(round, rootRound uint64) *blockproposal.BlockProposal {
+	return &blockproposal.BlockProposal{
+		PartitionID: 1,
+		NodeID:      "1",
+		UnicityCertificate: &types.UnicityCertificate{
+			Version:     1,
+			UnicitySeal: &types.UnicitySeal{Version: 1, RootChainRoundNumber: rootRound},
+		},
+		Technical: certification.TechnicalRecord{Round: round, Leader: "1"},
+	}
+}
+
+func newVote(t *testing.T, round uint64, rootHash []byte) *abdrc.VoteMsg {
+	voteInfo := &drctypes.RoundInfo{
+		RoundNumber:       round,
+		Timestamp:         100,
+		ParentRoundNumber: round - 1,
+		CurrentRootHash:   rootHash,
+	}
+	hash, err := voteInfo.Hash(gocrypto.SHA256)
+	require.NoError(t, err)
+	return &abdrc.VoteMsg{
+		VoteInfo:         voteInfo,
+		LedgerCommitInfo: &types.UnicitySeal{Version: 1, PreviousHash: hash},
+		Author:           "1",
+	}
+}
+
+func newTimeout(round, epoch uint64) *abdrc.TimeoutMsg {
+	highQC := &drctypes.QuorumCert{
+		VoteInfo:         &drctypes.RoundInfo{RoundNumber: round - 1, ParentRoundNumber: round - 2, Timestamp: 100},
+		LedgerCommitInfo: &types.UnicitySeal{Version: 1, PreviousHash: []byte{1}},
+	}
+	return abdrc.NewTimeoutMsg(&drctypes.Timeout{Epoch: epoch, Round: round, HighQc: highQC}, "1", nil)
+}
+
+func startServer(t *testing.T, signer abcrypto.Signer, certs *testCerts) string {
+	t.Helper()
+	db, err := memorydb.New()
+	require.NoError(t, err)
+	server, err := NewServer(signer, db, testlogger.New(t))
+	require.NoError(t, err)
+
+	tlsConf, err := ServerTLSConfig(certs.serverCert, certs.serverKey, certs.ca)
+	require.NoError(t, err)
+	listener, err := Listen("127.0.0.1:0", tlsConf)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(ctx, listener) }()
+	t.Cleanup(func() {
+		cancel()
+		require.ErrorIs(t, <-done, context.Canceled)
+	})
+	return listener.Addr().String()
+}
+
+type testCerts struct {
+	ca         string
+	serverCert string
+	serverKey  string
+	clientCert string
+	clientKey  string
+}
+
+/*
+newTestCerts generates a CA and the server and client certificates signed by it.
+*/
+func newTestCerts(t *testing.T) *testCerts {
+	t.Helper()
+	dir := t.TempDir()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	caCert, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	certs := &testCerts{ca: filepath.Join(dir, "ca.pem")}
+	writePEM(t, certs.ca, "CERTIFICATE", caDER)
+
+	newCert := func(name string, usage x509.ExtKeyUsage) (certFile, keyFile string) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		tmpl := &x509.Certificate{
+			SerialNumber: big.NewInt(time.Now().UnixNano()),
+			Subject:      pkix.Name{CommonName: name},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, caCert, &key.PublicKey, caKey)
+		require.NoError(t, err)
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		require.NoError(t, err)
+		certFile = filepath.Join(dir, name+".pem")
+		keyFile = filepath.Join(dir, name+"-key.pem")
+		writePEM(t, certFile, "CERTIFICATE", der)
+		writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+		return certFile, keyFile
+	}
+	certs.serverCert, certs.serverKey = newCert("server", x509.ExtKeyUsageServerAuth)
+	certs.clientCert, certs.clientKey = newCert("client", x509.ExtKeyUsageClientAuth)
+	return certs
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600))
+}
@@ -0,0 +1,281 @@
+package remotesigner
+
+import (
+	"bytes"
+	"context"
+	gocrypto "crypto"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"sync"
+
+	"github.com/alphabill-org/alphabill-go-base/cbor"
+	"github.com/alphabill-org/alphabill-go-base/crypto"
+	"github.com/alphabill-org/alphabill/keyvaluedb"
+	"github.com/alphabill-org/alphabill/logger"
+	"github.com/alphabill-org/alphabill/network/protocol/abdrc"
+	"github.com/alphabill-org/alphabill/network/protocol/blockproposal"
+	drctypes "github.com/alphabill-org/alphabill/rootchain/consensus/types"
+)
+
+/*
+Server is the signing daemon, it signs the requests of the authenticated clients
+with its signing key.
+
+Block certification requests, block proposals, root chain proposals, votes and
+timeout votes are signed only when they do not violate the anti-double-signing
+rules (see doubleSignGuard), the state of the rules is persisted in the database.
+The rules between the votes and the timeout votes of a round are enforced by the
+safety module of the root node. IR change requests are only validated as they just
+forward the certification requests signed by the shard validators. Raw bytes and
+hashes are never signed as the signer couldn't tell what was signed.
+*/
+type Server struct {
+	signer crypto.Signer
+	pubKey []byte
+	guard  doubleSignGuard
+	log    *slog.Logger
+
+	// signing is serialized so that the anti-double-signing checks see the latest state
+	mu sync.Mutex
+}
+
+func NewServer(signer crypto.Signer, db keyvaluedb.KeyValueDB, log *slog.Logger) (*Server, error) {
+	if signer == nil {
+		return nil, errors.New("signer is nil")
+	}
+	if db == nil {
+		return nil, errors.New("database is nil")
+	}
+	verifier, err := signer.Verifier()
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing key: %w", err)
+	}
+	pubKey, err := verifier.MarshalPublicKey()
+	if err != nil {
+		return nil, fmt.Errorf("marshaling public key: %w", err)
+	}
+	return &Server{
+		signer: signer,
+		pubKey: pubKey,
+		guard:  doubleSignGuard{db: db},
+		log:    log,
+	}, nil
+}
+
+/*
+Serve accepts connections on the listener until the context is cancelled.
+*/
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	stop := context.AfterFunc(ctx, func() { _ = listener.Close() })
+	defer stop()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("accepting connection: %w", err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.serveConn(ctx, conn)
+		}()
+	}
+}
+
+func (s *Server) serveConn(ctx context.Context, conn net.Conn) {
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+	defer conn.Close()
+
+	for {
+		req := &request{}
+		if err := readMsg(conn, req); err != nil {
+			if ctx.Err() == nil {
+				s.log.DebugContext(ctx, "closing signer connection", logger.Error(err))
+			}
+			return
+		}
+		if err := writeMsg(conn, s.handle(ctx, req)); err != nil {
+			s.log.WarnContext(ctx, "sending signer response", logger.Error(err))
+			return
+		}
+	}
+}
+
+func (s *Server) handle(ctx context.Context, req *request) *response {
+	var result []byte
+	var err error
+	switch req.Method {
+	case methodPublicKey:
+		result = s.pubKey
+	case methodSignCertificationRequest:
+		result, err = s.signCertificationRequest(req.Data)
+	case methodSignBlockProposal:
+		result, err = s.signBlockProposal(req.Data)
+	case methodSignRootProposal:
+		result, err = s.signRootProposal(req.Data)
+	case methodSignVote:
+		result, err = s.signVote(req.Data)
+	case methodSignTimeout:
+		result, err = s.signTimeout(req.Data)
+	case methodSignIRChangeRequest:
+		result, err = s.signIRChangeRequest(req.Data)
+	default:
+		err = fmt.Errorf("unknown method %d", req.Method)
+	}
+	if err != nil {
+		s.log.WarnContext(ctx, "signing request rejected", logger.Error(err))
+		return &response{Error: err.Error()}
+	}
+	return &response{Result: result}
+}
+
+func (s *Server) signCertificationRequest(data []byte) ([]byte, error) {
+	req := decodeCertificationRequest(data)
+	if req == nil {
+		return nil, errors.New("invalid block certification request")
+	}
+	// sign the canonical encoding of the request, without the signature
+	bs, err := req.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("encoding certification request: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// the request is recorded before signing so the signature can't be
+	// issued without the anti-double-signing state being persisted
+	if err := s.guard.checkCertificationRequest(req, bs); err != nil {
+		return nil, err
+	}
+	return s.signer.SignBytes(bs)
+}
+
+func (s *Server) signBlockProposal(data []byte) ([]byte, error) {
+	prop := &blockproposal.BlockProposal{}
+	if err := cbor.Unmarshal(data, prop); err != nil {
+		return nil, fmt.Errorf("decoding block proposal: %w", err)
+	}
+	if prop.PartitionID == 0 || prop.UnicityCertificate == nil {
+		return nil, errors.New("invalid block proposal")
+	}
+	hash, err := prop.Hash(gocrypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// the proposal of the same round is re-signed after a repeat UC
+	if err := s.guard.check(shardKey(keyBlockProposal, prop.PartitionID, prop.ShardID), "block proposal", signedRequest{
+		Round:     prop.Technical.Round,
+		Timestamp: prop.UnicityCertificate.GetRootRoundNumber(),
+		Hash:      hash,
+	}); err != nil {
+		return nil, err
+	}
+	return s.signer.SignHash(hash)
+}
+
+func (s *Server) signRootProposal(data []byte) ([]byte, error) {
+	block := &drctypes.BlockData{}
+	if err := cbor.Unmarshal(data, block); err != nil {
+		return nil, fmt.Errorf("decoding root proposal: %w", err)
+	}
+	if block.Round == 0 {
+		return nil, errors.New("invalid root proposal")
+	}
+	hash, err := block.Hash(gocrypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("calculating root proposal hash: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.guard.check([]byte{keyRootProposal}, "root proposal", signedRequest{Round: block.Round, Hash: hash}); err != nil {
+		return nil, err
+	}
+	return s.signer.SignHash(hash)
+}
+
+func (s *Server) signVote(data []byte) ([]byte, error) {
+	vote := &abdrc.VoteMsg{}
+	if err := cbor.Unmarshal(data, vote); err != nil {
+		return nil, fmt.Errorf("decoding vote: %w", err)
+	}
+	if vote.VoteInfo == nil || vote.LedgerCommitInfo == nil {
+		return nil, errors.New("invalid vote")
+	}
+	if err := vote.VoteInfo.IsValid(); err != nil {
+		return nil, fmt.Errorf("invalid vote info: %w", err)
+	}
+	// the vote info is not signed but the commit info must commit to it
+	hash, err := vote.VoteInfo.Hash(gocrypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("calculating vote info hash: %w", err)
+	}
+	if !bytes.Equal(hash, vote.LedgerCommitInfo.PreviousHash) {
+		return nil, errors.New("vote info hash does not match the hash in the commit info")
+	}
+	bs, err := vote.LedgerCommitInfo.SigBytes()
+	if err != nil {
+		return nil, fmt.Errorf("encoding commit info: %w", err)
+	}
+	sigHash := sha256.Sum256(bs)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.guard.check([]byte{keyVote}, "vote", signedRequest{Round: vote.VoteInfo.RoundNumber, Hash: sigHash[:]}); err != nil {
+		return nil, err
+	}
+	return s.signer.SignBytes(bs)
+}
+
+func (s *Server) signTimeout(data []byte) ([]byte, error) {
+	msg := &abdrc.TimeoutMsg{}
+	if err := cbor.Unmarshal(data, msg); err != nil {
+		return nil, fmt.Errorf("decoding timeout: %w", err)
+	}
+	if err := msg.IsValid(); err != nil {
+		return nil, fmt.Errorf("invalid timeout: %w", err)
+	}
+	bs := msg.Bytes()
+	hash := sha256.Sum256(bs)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// the timeout of the same round is re-signed when a newer QC has been seen
+	if err := s.guard.check([]byte{keyTimeout}, "timeout", signedRequest{
+		Round:     msg.GetRound(),
+		Timestamp: msg.Timeout.HighQc.GetRound(),
+		Hash:      hash[:],
+	}); err != nil {
+		return nil, err
+	}
+	return s.signer.SignBytes(bs)
+}
+
+func (s *Server) signIRChangeRequest(data []byte) ([]byte, error) {
+	msg := &abdrc.IrChangeReqMsg{}
+	if err := cbor.Unmarshal(data, msg); err != nil {
+		return nil, fmt.Errorf("decoding IR change request: %w", err)
+	}
+	if err := msg.IsValid(); err != nil {
+		return nil, fmt.Errorf("invalid IR change request: %w", err)
+	}
+	// sign the canonical encoding of the message, without the signature
+	msg.Signature = nil
+	bs, err := cbor.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("encoding IR change request: %w", err)
+	}
+	return s.signer.SignBytes(bs)
+}
@@ -0,0 +1,167 @@
+/*
+Package blockarchive implements the history of the root chain.
+
+The block store of the root chain keeps only the last committed block (the root
+of the block tree) and the last timeout certificate. The archive keeps every
+committed root block, with its commit QC, and every timeout certificate so that
+the history of the root chain can be inspected.
+*/
+package blockarchive
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/alphabill-org/alphabill/keyvaluedb"
+	"github.com/alphabill-org/alphabill/rootchain/consensus/storage"
+	rctypes "github.com/alphabill-org/alphabill/rootchain/consensus/types"
+)
+
+var (
+	// committed blocks: prefix | root round -> ExecutedBlock
+	blockPrefix = []byte("b")
+	// timeout certificates: prefix | root round -> TimeoutCert
+	tcPrefix = []byte("t")
+)
+
+type Archive struct {
+	db keyvaluedb.KeyValueDB
+	// number of root rounds the history is kept for, zero means forever
+	retention uint64
+}
+
+/*
+New returns archive backed by "db". Blocks and timeout certificates older than
+"retention" root rounds are deleted, when "retention" is zero the history is kept
+forever.
+*/
+func New(db keyvaluedb.KeyValueDB, retention uint64) (*Archive, error) {
+	if db == nil {
+		return nil, errors.New("archive database is nil")
+	}
+	return &Archive{db: db, retention: retention}, nil
+}
+
+/*
+AddBlock stores the committed block, the block must have commit QC.
+*/
+func (a *Archive) AddBlock(block *storage.ExecutedBlock) error {
+	if block == nil || block.BlockData == nil {
+		return errors.New("block is nil")
+	}
+	if block.CommitQc == nil {
+		return fmt.Errorf("block %d is missing commit QC", block.GetRound())
+	}
+	if err := a.db.Write(roundKey(blockPrefix, block.GetRound()), block); err != nil {
+		return fmt.Errorf("storing block %d: %w", block.GetRound(), err)
+	}
+
+	if a.retention > 0 && block.GetRound() > a.retention {
+		before := block.GetRound() - a.retention
+		if err := errors.Join(a.prune(blockPrefix, before), a.prune(tcPrefix, before)); err != nil {
+			return fmt.Errorf("deleting expired history: %w", err)
+		}
+	}
+	return nil
+}
+
+/*
+AddTimeoutCert stores the timeout certificate.
+*/
+func (a *Archive) AddTimeoutCert(tc *rctypes.TimeoutCert) error {
+	if tc == nil || tc.Timeout == nil {
+		return errors.New("timeout certificate is nil")
+	}
+	if err := a.db.Write(roundKey(tcPrefix, tc.GetRound()), tc); err != nil {
+		return fmt.Errorf("storing timeout certificate of round %d: %w", tc.GetRound(), err)
+	}
+	return nil
+}
+
+/*
+Block returns the committed block of the round, nil when the round is not in the
+archive (the round timed out or is out of the retention window).
+*/
+func (a *Archive) Block(round uint64) (*storage.ExecutedBlock, error) {
+	block := &storage.ExecutedBlock{}
+	found, err := a.db.Read(roundKey(blockPrefix, round), block)
+	if err != nil {
+		return nil, fmt.Errorf("reading block %d: %w", round, err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return block, nil
+}
+
+/*
+Blocks returns up to "limit" committed blocks starting from the round "from" in
+ascending order of round number.
+*/
+func (a *Archive) Blocks(from uint64, limit int) (_ []*storage.ExecutedBlock, rErr error) {
+	it := a.db.Find(roundKey(blockPrefix, from))
+	defer func() { rErr = errors.Join(rErr, it.Close()) }()
+
+	var blocks []*storage.ExecutedBlock
+	for ; it.Valid() && bytes.HasPrefix(it.Key(), blockPrefix) && len(blocks) < limit; it.Next() {
+		block := &storage.ExecutedBlock{}
+		if err := it.Value(block); err != nil {
+			return nil, fmt.Errorf("reading block %x: %w", it.Key(), err)
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
+/*
+TimeoutCert returns the timeout certificate of the round, nil when the round didn't
+time out (or is out of the retention window).
+*/
+func (a *Archive) TimeoutCert(round uint64) (*rctypes.TimeoutCert, error) {
+	tc := &rctypes.TimeoutCert{}
+	found, err := a.db.Read(roundKey(tcPrefix, round), tc)
+	if err != nil {
+		return nil, fmt.Errorf("reading timeout certificate of round %d: %w", round, err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return tc, nil
+}
+
+/*
+prune deletes the items with the "prefix" older than the root round "before".
+*/
+func (a *Archive) prune(prefix []byte, before uint64) error {
+	keys, err := a.keysBefore(prefix, roundKey(prefix, before))
+	if err != nil || len(keys) == 0 {
+		return err
+	}
+	tx, err := a.db.StartTx()
+	if err != nil {
+		return fmt.Errorf("starting transaction: %w", err)
+	}
+	for _, key := range keys {
+		if err := tx.Delete(key); err != nil {
+			return errors.Join(fmt.Errorf("deleting %x: %w", key, err), tx.Rollback())
+		}
+	}
+	return tx.Commit()
+}
+
+func (a *Archive) keysBefore(prefix, limit []byte) (_ [][]byte, rErr error) {
+	it := a.db.Find(prefix)
+	defer func() { rErr = errors.Join(rErr, it.Close()) }()
+
+	var keys [][]byte
+	for ; it.Valid() && bytes.HasPrefix(it.Key(), prefix) && bytes.Compare(it.Key(), limit) < 0; it.Next() {
+		keys = append(keys, bytes.Clone(it.Key()))
+	}
+	return keys, nil
+}
+
+func roundKey(prefix []byte, round uint64) []byte {
+	return binary.BigEndian.AppendUint64(bytes.Clone(prefix), round)
+}
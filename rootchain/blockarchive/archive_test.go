@@ -0,0 +1,115 @@
+package blockarchive
+
+import (
+	"crypto"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill/keyvaluedb/memorydb"
+	"github.com/alphabill-org/alphabill/rootchain/consensus/storage"
+	rctypes "github.com/alphabill-org/alphabill/rootchain/consensus/types"
+)
+
+func TestArchive(t *testing.T) {
+	db, err := memorydb.New()
+	require.NoError(t, err)
+	archive, err := New(db, 0)
+	require.NoError(t, err)
+
+	for _, round := range []uint64{2, 3, 5, 6} {
+		require.NoError(t, archive.AddBlock(newBlock(round)))
+	}
+	require.NoError(t, archive.AddTimeoutCert(newTC(4)))
+
+	block, err := archive.Block(3)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, block.GetRound())
+	require.Equal(t, "leader", block.BlockData.Author)
+	require.NotNil(t, block.CommitQc)
+
+	block, err = archive.Block(4)
+	require.NoError(t, err)
+	require.Nil(t, block)
+
+	tc, err := archive.TimeoutCert(4)
+	require.NoError(t, err)
+	require.EqualValues(t, 4, tc.GetRound())
+
+	tc, err = archive.TimeoutCert(3)
+	require.NoError(t, err)
+	require.Nil(t, tc)
+
+	blocks, err := archive.Blocks(3, 2)
+	require.NoError(t, err)
+	require.Len(t, blocks, 2)
+	require.EqualValues(t, 3, blocks[0].GetRound())
+	require.EqualValues(t, 5, blocks[1].GetRound())
+
+	blocks, err = archive.Blocks(5, 10)
+	require.NoError(t, err)
+	require.Len(t, blocks, 2)
+
+	blocks, err = archive.Blocks(7, 10)
+	require.NoError(t, err)
+	require.Empty(t, blocks)
+
+	require.EqualError(t, archive.AddBlock(&storage.ExecutedBlock{BlockData: &rctypes.BlockData{Round: 7}}), "block 7 is missing commit QC")
+	require.EqualError(t, archive.AddTimeoutCert(nil), "timeout certificate is nil")
+
+	_, err = New(nil, 0)
+	require.EqualError(t, err, "archive database is nil")
+}
+
+func TestArchive_Retention(t *testing.T) {
+	db, err := memorydb.New()
+	require.NoError(t, err)
+	archive, err := New(db, 3)
+	require.NoError(t, err)
+
+	require.NoError(t, archive.AddBlock(newBlock(2)))
+	require.NoError(t, archive.AddTimeoutCert(newTC(3)))
+	require.NoError(t, archive.AddBlock(newBlock(4)))
+	require.NoError(t, archive.AddBlock(newBlock(5)))
+	require.NoError(t, archive.AddBlock(newBlock(6)))
+
+	// history of the rounds 3...6 is kept
+	blocks, err := archive.Blocks(0, 10)
+	require.NoError(t, err)
+	require.Len(t, blocks, 3)
+	require.EqualValues(t, 4, blocks[0].GetRound())
+	tc, err := archive.TimeoutCert(3)
+	require.NoError(t, err)
+	require.NotNil(t, tc)
+
+	require.NoError(t, archive.AddBlock(newBlock(7)))
+	tc, err = archive.TimeoutCert(3)
+	require.NoError(t, err)
+	require.Nil(t, tc)
+}
+
+func newBlock(round uint64) *storage.ExecutedBlock {
+	return &storage.ExecutedBlock{
+		BlockData: &rctypes.BlockData{
+			Version: 1,
+			Author:  "leader",
+			Round:   round,
+			Payload: &rctypes.Payload{},
+			Qc: &rctypes.QuorumCert{
+				VoteInfo:         &rctypes.RoundInfo{RoundNumber: round - 1, ParentRoundNumber: round - 2},
+				LedgerCommitInfo: &types.UnicitySeal{Version: 1, RootChainRoundNumber: round - 1},
+			},
+		},
+		HashAlgo: crypto.SHA256,
+		RootHash: []byte{1, 2, 3},
+		Qc:       &rctypes.QuorumCert{VoteInfo: &rctypes.RoundInfo{RoundNumber: round}},
+		CommitQc: &rctypes.QuorumCert{VoteInfo: &rctypes.RoundInfo{RoundNumber: round + 1}},
+	}
+}
+
+func newTC(round uint64) *rctypes.TimeoutCert {
+	return &rctypes.TimeoutCert{
+		Timeout: &rctypes.Timeout{Round: round, HighQc: &rctypes.QuorumCert{VoteInfo: &rctypes.RoundInfo{RoundNumber: round - 1}}},
+	}
+}
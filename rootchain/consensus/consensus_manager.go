@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"slices"
 	"time"
 
@@ -27,6 +28,8 @@ import (
 	"github.com/alphabill-org/alphabill/rootchain/consensus/leader"
 	"github.com/alphabill-org/alphabill/rootchain/consensus/storage"
 	drctypes "github.com/alphabill-org/alphabill/rootchain/consensus/types"
+	"github.com/alphabill-org/alphabill/rootchain/epochstats"
+	"github.com/alphabill-org/alphabill/rootchain/evidence"
 )
 
 type (
@@ -81,13 +84,17 @@ type (
 		net            RootNet
 		pacemaker      *Pacemaker
 		leaderSelector Leader
-		trustBase      types.RootTrustBase
+		trustBases     TrustBases
 		irReqBuffer    *IrReqBuffer
 		safety         *SafetyModule
 		blockStore     *storage.BlockStore
 		orchestration  Orchestration
 		irReqVerifier  *IRChangeReqVerifier
 		t2Timeouts     *PartitionTimeoutGenerator
+		evidence       EvidenceStore
+		ucArchive      UCArchive
+		blockArchive   BlockArchive
+		epochStats     EpochStats
 		// votes need to be buffered when CM will be the next leader (so other nodes
 		// will send votes to it) but it hasn't got the proposal yet, so it can't process
 		// the votes. voteBuffer maps author id to vote, so we do not buffer same vote
@@ -161,7 +168,11 @@ func NewConsensusManager(
 	if err != nil {
 		return nil, err
 	}
-	ls, err := leaderSelector(trustBase)
+	trustBases := optional.TrustBases
+	if trustBases == nil {
+		trustBases = staticTrustBases{trustBase: trustBase}
+	}
+	ls, err := newEpochLeader(trustBases)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create consensus leader selector: %w", err)
 	}
@@ -174,13 +185,17 @@ func NewConsensusManager(
 		net:            net,
 		pacemaker:      pm,
 		leaderSelector: ls,
-		trustBase:      trustBase,
+		trustBases:     trustBases,
 		irReqBuffer:    NewIrReqBuffer(log),
 		safety:         safetyModule,
 		blockStore:     bStore,
 		orchestration:  orchestration,
 		irReqVerifier:  reqVerifier,
 		t2Timeouts:     t2TimeoutGen,
+		evidence:       optional.Evidence,
+		ucArchive:      optional.UCArchive,
+		blockArchive:   optional.BlockArchive,
+		epochStats:     optional.EpochStats,
 		voteBuffer:     make(map[string]*abdrc.VoteMsg),
 		recovery:       &recoveryState{},
 		log:            log,
@@ -442,8 +457,9 @@ func (x *ConsensusManager) onLocalTimeout(ctx context.Context) {
 	timeoutVoteMsg := x.pacemaker.GetTimeoutVote()
 	if timeoutVoteMsg == nil {
 		// create timeout vote
+		round := x.pacemaker.GetCurrentRound()
 		timeoutVoteMsg = abdrc.NewTimeoutMsg(
-			drctypes.NewTimeout(x.pacemaker.GetCurrentRound(), 0, x.blockStore.GetHighQc()),
+			drctypes.NewTimeout(round, x.trustBases.Epoch(round), x.blockStore.GetHighQc()),
 			x.id.String(),
 			x.pacemaker.LastRoundTC())
 		if err := x.safety.SignTimeout(timeoutVoteMsg, x.pacemaker.LastRoundTC()); err != nil {
@@ -518,7 +534,7 @@ func (x *ConsensusManager) onIRChangeMsg(ctx context.Context, irChangeMsg *abdrc
 	ctx, span := x.tracer.Start(ctx, "ConsensusManager.onIRChangeMsg")
 	defer span.End()
 
-	if err := irChangeMsg.Verify(x.trustBase); err != nil {
+	if err := irChangeMsg.Verify(x.trustBases.ForRound(x.pacemaker.GetCurrentRound())); err != nil {
 		return fmt.Errorf("invalid IR change request from node %s: %w", irChangeMsg.Author, err)
 	}
 	nextLeader := x.leaderSelector.GetLeaderForRound(x.pacemaker.GetCurrentRound() + 1)
@@ -555,7 +571,8 @@ func (x *ConsensusManager) onVoteMsg(ctx context.Context, vote *abdrc.VoteMsg) e
 		return fmt.Errorf("stale vote for round %d from %s", vote.VoteInfo.RoundNumber, vote.Author)
 	}
 	// verify signature on vote
-	if err := vote.Verify(x.trustBase); err != nil {
+	trustBase := x.trustBases.ForRound(vote.VoteInfo.RoundNumber)
+	if err := vote.Verify(trustBase); err != nil {
 		return fmt.Errorf("invalid vote: %w", err)
 	}
 	// if a vote is received for future round it is intended for the node which is going to be the
@@ -575,7 +592,7 @@ func (x *ConsensusManager) onVoteMsg(ctx context.Context, vote *abdrc.VoteMsg) e
 		// NB! it seems that it's quite common that votes arrive before proposal and going into recovery
 		// too early is counterproductive... maybe do not trigger recovery here at all - if we're lucky
 		// proposal will arrive on time, otherwise round will likely TO anyway?
-		if uint64(len(x.voteBuffer)) >= x.trustBase.GetQuorumThreshold() {
+		if uint64(len(x.voteBuffer)) >= trustBase.GetQuorumThreshold() {
 			err := fmt.Errorf("have received %d votes but no proposal, entering recovery", len(x.voteBuffer))
 			if e := x.sendRecoveryRequests(ctx, vote); e != nil {
 				err = errors.Join(err, fmt.Errorf("sending recovery requests failed: %w", e))
@@ -603,8 +620,12 @@ func (x *ConsensusManager) onVoteMsg(ctx context.Context, vote *abdrc.VoteMsg) e
 		return fmt.Errorf("validator is not the leader for round %d", nextRound)
 	}
 
-	qc, mature, err := x.pacemaker.RegisterVote(vote, x.trustBase)
+	qc, mature, err := x.pacemaker.RegisterVote(vote, trustBase)
 	if err != nil {
+		var eqErr *EquivocatingVoteError
+		if errors.As(err, &eqErr) {
+			err = errors.Join(err, x.recordEquivocation(ctx, eqErr))
+		}
 		return fmt.Errorf("failed to register vote: %w", err)
 	}
 	x.log.LogAttrs(ctx, logger.LevelTrace, fmt.Sprintf("processed vote, quorum: %t, mature: %t", qc != nil, mature))
@@ -616,6 +637,23 @@ func (x *ConsensusManager) onVoteMsg(ctx context.Context, vote *abdrc.VoteMsg) e
 	return nil
 }
 
+/*
+recordEquivocation stores the evidence of the root validator sending conflicting votes.
+Both the votes have been verified before registering. The votes are sent to the next
+leader only so the equivocation is detected by the leader of the next round.
+*/
+func (x *ConsensusManager) recordEquivocation(ctx context.Context, eqErr *EquivocatingVoteError) error {
+	ev := evidence.NewVoteEvidence(eqErr.Previous, eqErr.Vote)
+	x.log.WarnContext(ctx, fmt.Sprintf("root validator %s sent conflicting votes for round %d", ev.NodeID, ev.Round), logger.Data(ev))
+	if x.evidence == nil {
+		return nil
+	}
+	if _, err := x.evidence.Add(ctx, ev); err != nil {
+		return fmt.Errorf("recording equivocation evidence: %w", err)
+	}
+	return nil
+}
+
 // onTimeoutMsg handles timeout vote messages from other root validators
 // Timeout votes are broadcast to all nodes on local timeout and all validators try to assemble
 // timeout certificate independently.
@@ -625,8 +663,16 @@ func (x *ConsensusManager) onTimeoutMsg(ctx context.Context, vote *abdrc.Timeout
 	if vote.Timeout.Round < x.pacemaker.GetCurrentRound() {
 		return fmt.Errorf("stale timeout vote for round %d from %s", vote.Timeout.Round, vote.Author)
 	}
+	if err := x.checkEpochScheduled(vote.Timeout.Epoch); err != nil {
+		err = fmt.Errorf("timeout vote triggers recovery: %w", err)
+		if e := x.sendRecoveryRequests(ctx, vote); e != nil {
+			err = errors.Join(err, fmt.Errorf("sending recovery requests failed: %w", e))
+		}
+		return err
+	}
 	// verify signature on vote
-	if err := vote.Verify(x.trustBase); err != nil {
+	trustBase := x.trustBases.ForRound(vote.Timeout.Round)
+	if err := vote.Verify(trustBase); err != nil {
 		return fmt.Errorf("invalid timeout vote: %w", err)
 	}
 	// SyncState, compare last handled QC
@@ -644,7 +690,7 @@ func (x *ConsensusManager) onTimeoutMsg(ctx context.Context, vote *abdrc.Timeout
 	// the highQC is the same for both rounds. So checking the lastTC helps the instance into latest TO round.
 	x.processTC(ctx, vote.LastTC)
 
-	tc, err := x.pacemaker.RegisterTimeoutVote(ctx, vote, x.trustBase)
+	tc, err := x.pacemaker.RegisterTimeoutVote(ctx, vote, trustBase)
 	if err != nil {
 		return fmt.Errorf("failed to register timeout vote: %w", err)
 	}
@@ -683,6 +729,20 @@ func (x *ConsensusManager) checkRecoveryNeeded(qc *drctypes.QuorumCert) error {
 	return nil
 }
 
+/*
+checkEpochScheduled returns error when the root epoch of a received message is not
+in the schedule of the node, ie the node has missed the scheduling of the epoch and
+has to fetch it from the peers with the state (see onStateResponse). The message
+can't be verified before that, the root epochs in the state are verified against
+the trust bases of their previous epochs.
+*/
+func (x *ConsensusManager) checkEpochScheduled(epoch uint64) error {
+	if x.trustBases.TrustBase(epoch) == nil {
+		return fmt.Errorf("root epoch %d is not scheduled", epoch)
+	}
+	return nil
+}
+
 // onProposalMsg handles block proposal messages from other validators.
 // Only a proposal made by the leader of this view/round shall be accepted and processed
 func (x *ConsensusManager) onProposalMsg(ctx context.Context, proposal *abdrc.ProposalMsg) error {
@@ -691,10 +751,20 @@ func (x *ConsensusManager) onProposalMsg(ctx context.Context, proposal *abdrc.Pr
 	if proposal.Block.Round < x.pacemaker.GetCurrentRound() {
 		return fmt.Errorf("stale proposal for round %d from %s", proposal.Block.Round, proposal.Block.Author)
 	}
+	if err := x.checkEpochScheduled(proposal.Block.Epoch); err != nil {
+		err = fmt.Errorf("proposal triggers recovery: %w", err)
+		if e := x.sendRecoveryRequests(ctx, proposal); e != nil {
+			err = errors.Join(err, fmt.Errorf("sending recovery requests failed: %w", e))
+		}
+		return err
+	}
 	// verify signature on proposal (does not verify partition request signatures)
-	if err := proposal.Verify(x.trustBase); err != nil {
+	if err := proposal.Verify(x.trustBases.ForRound(proposal.Block.Round)); err != nil {
 		return fmt.Errorf("invalid proposal: %w", err)
 	}
+	if epoch := x.trustBases.Epoch(proposal.Block.Round); proposal.Block.Epoch != epoch {
+		return fmt.Errorf("invalid proposal: round %d belongs to the root epoch %d, proposal is for the epoch %d", proposal.Block.Round, epoch, proposal.Block.Epoch)
+	}
 	// Check current state against new QC
 	if err := x.checkRecoveryNeeded(proposal.Block.Qc); err != nil {
 		err = fmt.Errorf("proposal triggers recovery: %w", err)
@@ -753,6 +823,7 @@ func (x *ConsensusManager) processQC(ctx context.Context, qc *drctypes.QuorumCer
 	if qc == nil {
 		return
 	}
+	staleQC := x.blockStore.GetHighQc().GetRound() >= qc.GetRound()
 	certs, err := x.blockStore.ProcessQc(qc)
 	if err != nil {
 		x.log.WarnContext(ctx, "failure to process QC triggers recovery", logger.Error(err))
@@ -761,6 +832,16 @@ func (x *ConsensusManager) processQC(ctx context.Context, qc *drctypes.QuorumCer
 		}
 		return
 	}
+	if !staleQC {
+		x.onBlockCommitted(ctx, qc)
+	}
+	if x.ucArchive != nil {
+		ucs := make([]*types.UnicityCertificate, 0, len(certs))
+		for _, cr := range certs {
+			ucs = append(ucs, &cr.UC)
+		}
+		x.archiveCertificates(ctx, ucs)
+	}
 	select {
 	case <-ctx.Done():
 		return // node is exiting certificates have been stored and we are done
@@ -786,6 +867,11 @@ func (x *ConsensusManager) processTC(ctx context.Context, tc *drctypes.TimeoutCe
 	if tc == nil {
 		return
 	}
+	if x.blockArchive != nil {
+		if err := x.blockArchive.AddTimeoutCert(tc); err != nil {
+			x.log.WarnContext(ctx, "archiving timeout certificate", logger.Error(err))
+		}
+	}
 	if err := x.blockStore.ProcessTc(tc); err != nil {
 		// method deletes the block that got TC - it will never be part of the chain.
 		// however, this node might not have even seen the block, in which case error is returned, but this is ok - just log
@@ -794,6 +880,102 @@ func (x *ConsensusManager) processTC(ctx context.Context, tc *drctypes.TimeoutCe
 	x.pacemaker.AdvanceRoundTC(ctx, tc)
 }
 
+/*
+archiveCertificates stores the certificates in the UC archive (when configured).
+Failure to archive is logged but otherwise ignored, it must not stop the root chain.
+*/
+func (x *ConsensusManager) archiveCertificates(ctx context.Context, ucs []*types.UnicityCertificate) {
+	if x.ucArchive == nil || len(ucs) == 0 {
+		return
+	}
+	if err := x.ucArchive.Add(ucs...); err != nil {
+		x.log.WarnContext(ctx, "archiving unicity certificates", logger.Error(err))
+	}
+}
+
+/*
+onBlockCommitted stores the block committed by the "qc" in the block archive and the
+summaries of the shard epochs completed by the block in the epoch stats store (when
+configured). Failures are logged but otherwise ignored.
+*/
+func (x *ConsensusManager) onBlockCommitted(ctx context.Context, qc *drctypes.QuorumCert) {
+	if (x.blockArchive == nil && x.epochStats == nil) || qc.LedgerCommitInfo.RootChainRoundNumber == 0 || qc.GetRound() == drctypes.GenesisRootRound {
+		return
+	}
+	block, err := x.blockStore.Block(qc.GetParentRound())
+	if err != nil {
+		x.log.WarnContext(ctx, fmt.Sprintf("loading committed block %d", qc.GetParentRound()), logger.Error(err))
+		return
+	}
+	if x.blockArchive != nil {
+		if err := x.blockArchive.AddBlock(block); err != nil {
+			x.log.WarnContext(ctx, "archiving committed block", logger.Error(err))
+		}
+	}
+	if x.epochStats != nil {
+		x.storeEpochSummaries(ctx, block)
+	}
+}
+
+/*
+storeEpochSummaries stores the fees and statistics of the shards whose last round
+of the epoch was certified by the block. In such a round the technical record has
+already been advanced into the next epoch while the fees and statistics are still
+those of the ending epoch (they are reset in the next block).
+
+Together with the certificate of the round and the shard conf of the epoch the
+summary is the proof of the fees the validators earned during the epoch.
+*/
+func (x *ConsensusManager) storeEpochSummaries(ctx context.Context, block *storage.ExecutedBlock) {
+	for psID := range block.ShardState.Changed {
+		si, ok := block.ShardState.States[psID]
+		if !ok || si.IR == nil || si.TR.Epoch == si.IR.Epoch {
+			continue
+		}
+		summary := &epochstats.Summary{
+			PartitionID: si.PartitionID,
+			ShardID:     si.ShardID,
+			Epoch:       si.IR.Epoch,
+			Fees:        maps.Clone(si.Fees),
+			Stat:        si.Stat,
+			Certificate: si.LastCR,
+		}
+		var err error
+		if summary.ShardConf, summary.NextEpochFees, err = x.epochShardConf(si, block.GetRound()); err != nil {
+			x.log.WarnContext(ctx, fmt.Sprintf("loading shard conf of the epoch %d", si.IR.Epoch), logger.Error(err), logger.Shard(si.PartitionID, si.ShardID))
+		}
+		if _, err := x.epochStats.Add(summary); err != nil {
+			x.log.WarnContext(ctx, fmt.Sprintf("storing summary of the epoch %d", si.IR.Epoch), logger.Error(err), logger.Shard(si.PartitionID, si.ShardID))
+		}
+	}
+}
+
+/*
+epochShardConf returns the shard conf of the epoch which ended with the round certified
+in the root round "round" and the fee list the next epoch of the shard started with.
+*/
+func (x *ConsensusManager) epochShardConf(si *storage.ShardInfo, round uint64) (*types.PartitionDescriptionRecord, map[string]uint64, error) {
+	nextConf, err := x.orchestration.ShardConfig(si.PartitionID, si.ShardID, round)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading shard conf of the next epoch: %w", err)
+	}
+	if nextConf.Epoch != si.TR.Epoch || nextConf.EpochStart == 0 {
+		return nil, nil, fmt.Errorf("expected shard conf of the epoch %d, got epoch %d", si.TR.Epoch, nextConf.Epoch)
+	}
+	shardConf, err := x.orchestration.ShardConfig(si.PartitionID, si.ShardID, nextConf.EpochStart-1)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading shard conf of the epoch: %w", err)
+	}
+	if shardConf.Epoch != si.IR.Epoch {
+		return nil, nil, fmt.Errorf("expected shard conf of the epoch %d, got epoch %d", si.IR.Epoch, shardConf.Epoch)
+	}
+	nextEpochFees := make(map[string]uint64, len(nextConf.Validators))
+	for _, v := range nextConf.Validators {
+		nextEpochFees[v.NodeID] = 0
+	}
+	return shardConf, nextEpochFees, nil
+}
+
 /*
 sendCertificates reads UCs produced by processing QC and makes them available for
 validator via certResultCh chan (returned by CertificationResult method).
@@ -903,7 +1085,7 @@ func (x *ConsensusManager) processNewRoundEvent(ctx context.Context) {
 			Version:   1,
 			Author:    x.id.String(),
 			Round:     round,
-			Epoch:     0,
+			Epoch:     x.trustBases.Epoch(round),
 			Timestamp: types.NewTimestamp(),
 			Payload:   x.irReqBuffer.GeneratePayload(round, timedOutShards, x.blockStore.IsChangeInProgress),
 			Qc:        x.blockStore.GetHighQc(),
@@ -938,6 +1120,7 @@ func (x *ConsensusManager) onStateReq(ctx context.Context, req *abdrc.StateReque
 	if err != nil {
 		return fmt.Errorf("creating state message: %w", err)
 	}
+	stateMsg.TrustBases = x.trustBases.Scheduled()
 	if err = x.net.Send(ctx, stateMsg, peerID); err != nil {
 		return fmt.Errorf("failed to send state response message: %w", err)
 	}
@@ -952,7 +1135,12 @@ func (x *ConsensusManager) onStateResponse(ctx context.Context, rsp *abdrc.State
 		// we do send out multiple state recovery request so do not return error when we ignore the ones after successful recovery...
 		return nil
 	}
-	if err := rsp.Verify(x.params.HashAlgorithm, x.trustBase); err != nil {
+	// the root epochs are verified against the trust bases of their previous epochs so
+	// they are scheduled before the state, signed by the validators of them, is verified
+	if err := x.trustBases.AddRecovered(rsp.TrustBases); err != nil {
+		return fmt.Errorf("recovery response root epochs: %w", err)
+	}
+	if err := rsp.Verify(x.params.HashAlgorithm, x.trustBases.ForRound(x.pacemaker.GetCurrentRound())); err != nil {
 		return fmt.Errorf("recovery response verification failed: %w", err)
 	}
 	if err := rsp.CanRecoverToRound(x.recovery.ToRound()); err != nil {
@@ -995,6 +1183,9 @@ func (x *ConsensusManager) onStateResponse(ctx context.Context, rsp *abdrc.State
 	}
 	// all ok
 	x.blockStore = blockStore
+	// the certificates of the rounds committed while this node was out of sync are not
+	// available, archive the latest certificates of the shards from the committed head
+	x.archiveCertificates(ctx, blockStore.GetCertificates())
 	x.irReqVerifier = reqVerifier
 	x.t2Timeouts = t2TimeoutGen
 	// exit recovery status and replay buffered messages
@@ -1124,6 +1315,11 @@ func (x *ConsensusManager) GetState() (*abdrc.StateMsg, error) {
 	return x.blockStore.GetState()
 }
 
+// CurrentRound returns the current round of the root chain.
+func (x *ConsensusManager) CurrentRound() uint64 {
+	return x.pacemaker.GetCurrentRound()
+}
+
 // "constant" (ie without variable part) attribute sets for observability
 var (
 	attrSetQCVoteStale = metric.WithAttributeSet(attribute.NewSet(attribute.String("reason", "stale")))
@@ -28,14 +28,18 @@ import (
 	testnetwork "github.com/alphabill-org/alphabill/internal/testutils/network"
 	testobservability "github.com/alphabill-org/alphabill/internal/testutils/observability"
 	"github.com/alphabill-org/alphabill/internal/testutils/trustbase"
+	"github.com/alphabill-org/alphabill/keyvaluedb/memorydb"
 	"github.com/alphabill-org/alphabill/network"
 	"github.com/alphabill-org/alphabill/network/protocol/abdrc"
 	"github.com/alphabill-org/alphabill/network/protocol/certification"
+	"github.com/alphabill-org/alphabill/rootchain/blockarchive"
 	"github.com/alphabill-org/alphabill/rootchain/consensus/storage"
 	abdrctu "github.com/alphabill-org/alphabill/rootchain/consensus/testutils"
 	drctypes "github.com/alphabill-org/alphabill/rootchain/consensus/types"
+	"github.com/alphabill-org/alphabill/rootchain/epochstats"
 	"github.com/alphabill-org/alphabill/rootchain/partitions"
 	"github.com/alphabill-org/alphabill/rootchain/testutils"
+	"github.com/alphabill-org/alphabill/rootchain/ucarchive"
 )
 
 const partitionID types.PartitionID = 0x00FF0001
@@ -354,12 +358,90 @@ func TestIRChangeRequestFromRootValidator_RootTimeout(t *testing.T) {
 	require.Equal(t, uint64(6), stateMsg.Pending[0].Round)
 }
 
+func Test_storeEpochSummaries(t *testing.T) {
+	db, err := memorydb.New()
+	require.NoError(t, err)
+	store, err := epochstats.NewStore(db)
+	require.NoError(t, err)
+	shard0, shard1 := types.ShardID{}.Split()
+	psID0 := types.PartitionShardID{PartitionID: partitionID, ShardID: shard0.Key()}
+	psID1 := types.PartitionShardID{PartitionID: partitionID, ShardID: shard1.Key()}
+
+	// epoch 1 of the shard 0 started in the root round 5 and epoch 2 in the round 20
+	shardConfs := []*types.PartitionDescriptionRecord{
+		{PartitionID: partitionID, ShardID: shard0, Epoch: 1, EpochStart: 5, Validators: []*types.NodeInfo{{NodeID: "1"}, {NodeID: "2"}}},
+		{PartitionID: partitionID, ShardID: shard0, Epoch: 2, EpochStart: 20, Validators: []*types.NodeInfo{{NodeID: "2"}, {NodeID: "3"}}},
+	}
+	orchestration := mockOrchestration{
+		shardConfig: func(partitionID types.PartitionID, shardID types.ShardID, rootRound uint64) (*types.PartitionDescriptionRecord, error) {
+			for _, sc := range slices.Backward(shardConfs) {
+				if sc.PartitionID == partitionID && sc.ShardID.Key() == shardID.Key() && sc.EpochStart <= rootRound {
+					return sc, nil
+				}
+			}
+			return nil, fmt.Errorf("shard conf missing for shard %s_%s", partitionID, shardID)
+		},
+	}
+	cm := &ConsensusManager{epochStats: store, orchestration: orchestration, log: testobservability.Default(t).Logger()}
+
+	lastCR := &certification.CertificationResponse{Partition: partitionID, Shard: shard0, Technical: certification.TechnicalRecord{Round: 8, Epoch: 2}}
+	block := &storage.ExecutedBlock{
+		BlockData: &drctypes.BlockData{Round: 20},
+		ShardState: storage.ShardStates{
+			States: map[types.PartitionShardID]*storage.ShardInfo{
+				// the last round of the epoch 1 was certified
+				psID0: {
+					PartitionID: partitionID,
+					ShardID:     shard0,
+					Fees:        map[string]uint64{"1": 10, "2": 5},
+					Stat:        certification.StatisticalRecord{Blocks: 3, BlockFees: 15},
+					IR:          &types.InputRecord{Epoch: 1},
+					TR:          certification.TechnicalRecord{Epoch: 2},
+					LastCR:      lastCR,
+				},
+				psID1: {
+					PartitionID: partitionID,
+					ShardID:     shard1,
+					Fees:        map[string]uint64{"1": 1},
+					IR:          &types.InputRecord{Epoch: 1},
+					TR:          certification.TechnicalRecord{Epoch: 1},
+				},
+			},
+			Changed: storage.ShardSet{psID0: {}, psID1: {}},
+		},
+	}
+	cm.storeEpochSummaries(t.Context(), block)
+
+	summary, err := store.Summary(partitionID, shard0, 1)
+	require.NoError(t, err)
+	require.Equal(t, map[string]uint64{"1": 10, "2": 5}, summary.Fees)
+	require.EqualValues(t, 3, summary.Stat.Blocks)
+	require.Equal(t, lastCR.Technical, summary.Certificate.Technical)
+	require.EqualValues(t, 1, summary.ShardConf.Epoch)
+	require.Equal(t, map[string]uint64{"2": 0, "3": 0}, summary.NextEpochFees)
+
+	// the epoch of the second shard is still in progress
+	summary, err = store.Summary(partitionID, shard1, 1)
+	require.NoError(t, err)
+	require.Nil(t, summary)
+}
+
 func TestIRChangeRequestFromRootValidator(t *testing.T) {
 	var lastProposalMsg *abdrc.ProposalMsg = nil
 	var lastVoteMsg *abdrc.VoteMsg = nil
 
+	archiveDB, err := memorydb.New()
+	require.NoError(t, err)
+	archive, err := ucarchive.New(archiveDB, 0)
+	require.NoError(t, err)
+
+	blockArchiveDB, err := memorydb.New()
+	require.NoError(t, err)
+	blockArchive, err := blockarchive.New(blockArchiveDB, 0)
+	require.NoError(t, err)
+
 	mockNet := testnetwork.NewRootMockNetwork()
-	cm, rootNode, shardNodes := initConsensusManager(t, mockNet)
+	cm, rootNode, shardNodes := initConsensusManager(t, mockNet, WithUCArchive(archive), WithBlockArchive(blockArchive))
 
 	ctx, ctxCancel := context.WithCancel(context.Background())
 	defer ctxCancel()
@@ -419,6 +501,22 @@ func TestIRChangeRequestFromRootValidator(t *testing.T) {
 	shardConfHash, err := shardConf.Hash(crypto.SHA256)
 	require.NoError(t, err)
 	require.NoError(t, result.Verify(cm.trustBase, crypto.SHA256, partitionID, shardConfHash))
+	// the certificate has been archived
+	archived, err := archive.ShardCertificate(partitionID, shardID, result.GetRoundNumber())
+	require.NoError(t, err)
+	require.NotNil(t, archived)
+	require.Equal(t, result.UnicitySeal.Hash, archived.UnicitySeal.Hash)
+	archivedUCs, err := archive.RootRoundCertificates(result.GetRootRoundNumber())
+	require.NoError(t, err)
+	require.Len(t, archivedUCs, 1)
+	require.Equal(t, partitionID, archivedUCs[0].GetPartitionID())
+	// the block which certified the IR change has been archived
+	blocks, err := blockArchive.Blocks(0, 10)
+	require.NoError(t, err)
+	require.NotEmpty(t, blocks)
+	lastBlock := blocks[len(blocks)-1]
+	require.NotNil(t, lastBlock.CommitQc)
+	require.Len(t, lastBlock.BlockData.Payload.Requests, 1)
 
 	// root will continue and next proposal is also triggered by the same QC
 	lastProposalMsg = testutils.MockAwaitMessage[*abdrc.ProposalMsg](t, mockNet, network.ProtocolRootProposal)
@@ -960,6 +1058,36 @@ func Test_ConsensusManager_messages(t *testing.T) {
 			require.EqualValues(t, 1, state.CommittedHead.Block.Round)
 			require.Empty(t, state.Pending)
 			require.Len(t, state.CommittedHead.ShardInfo, 1)
+			require.Empty(t, state.TrustBases)
+		}
+	})
+
+	t.Run("proposal of unknown root epoch triggers recovery", func(t *testing.T) {
+		cms, _ := createConsensusManagers(t, 2, shardNodeInfos)
+		cmA, cmB := cms[0], cms[1]
+
+		// cmA has missed the scheduling of the root epoch 1, it can't verify the
+		// proposal and must fetch the epoch with the state from the signers of the QC
+		prop := &abdrc.ProposalMsg{
+			Block: &drctypes.BlockData{
+				Author: cmB.id.String(),
+				Round:  10,
+				Epoch:  1,
+				Qc: &drctypes.QuorumCert{
+					VoteInfo:   &drctypes.RoundInfo{RoundNumber: 9},
+					Signatures: map[string]hex.Bytes{cmB.id.String(): {1}},
+				},
+			},
+		}
+		require.EqualError(t, cmA.onProposalMsg(context.Background(), prop),
+			"proposal triggers recovery: root epoch 1 is not scheduled")
+		require.True(t, cmA.recovery.InRecovery())
+
+		select {
+		case <-time.After(1000 * time.Millisecond):
+			t.Fatal("timeout while waiting for recovery request")
+		case msg := <-cmB.net.ReceivedChannel():
+			require.Equal(t, &abdrc.StateRequestMsg{NodeId: cmA.id.String()}, msg)
 		}
 	})
 }
@@ -1329,3 +1457,19 @@ func Test_ConsensusManager_RestoreVote(t *testing.T) {
 	require.EqualValues(t, "test", lastTimeoutMsg.Author)
 	require.EqualValues(t, 1, lastTimeoutMsg.LastTC.GetRound())
 }
+
+type mockOrchestration struct {
+	shardConfig func(partitionID types.PartitionID, shardID types.ShardID, rootRound uint64) (*types.PartitionDescriptionRecord, error)
+}
+
+func (mo mockOrchestration) NetworkID() types.NetworkID {
+	return 5
+}
+
+func (mo mockOrchestration) ShardConfigs(rootRound uint64) (map[types.PartitionShardID]*types.PartitionDescriptionRecord, error) {
+	return nil, fmt.Errorf("unexpected call")
+}
+
+func (mo mockOrchestration) ShardConfig(partitionID types.PartitionID, shardID types.ShardID, rootRound uint64) (*types.PartitionDescriptionRecord, error) {
+	return mo.shardConfig(partitionID, shardID, rootRound)
+}
@@ -1,8 +1,15 @@
 package consensus
 
 import (
+	"context"
 	"crypto"
 	"time"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill/rootchain/consensus/storage"
+	drctypes "github.com/alphabill-org/alphabill/rootchain/consensus/types"
+	"github.com/alphabill-org/alphabill/rootchain/epochstats"
+	"github.com/alphabill-org/alphabill/rootchain/evidence"
 )
 
 const (
@@ -22,7 +29,47 @@ type (
 	}
 	// Optional are common optional parameters for consensus managers
 	Optional struct {
-		Params *Parameters
+		Params       *Parameters
+		Evidence     EvidenceStore
+		UCArchive    UCArchive
+		BlockArchive BlockArchive
+		EpochStats   EpochStats
+		TrustBases   TrustBases
+	}
+
+	// EvidenceStore persists the equivocation evidence
+	EvidenceStore interface {
+		Add(ctx context.Context, e *evidence.Evidence) (bool, error)
+	}
+
+	// UCArchive persists the issued unicity certificates
+	UCArchive interface {
+		Add(ucs ...*types.UnicityCertificate) error
+	}
+
+	// BlockArchive persists the history of the root chain
+	BlockArchive interface {
+		AddBlock(block *storage.ExecutedBlock) error
+		AddTimeoutCert(tc *drctypes.TimeoutCert) error
+	}
+
+	// EpochStats persists the fee and statistics summaries of the completed shard epochs
+	EpochStats interface {
+		Add(summary *epochstats.Summary) (bool, error)
+	}
+
+	// TrustBases is the schedule of the root epochs, each epoch has its own validator set
+	TrustBases interface {
+		// Epoch returns the number of the root epoch the round belongs to
+		Epoch(round uint64) uint64
+		// TrustBase returns the trust base of the root epoch, nil when the epoch hasn't been scheduled
+		TrustBase(epoch uint64) types.RootTrustBase
+		// ForRound returns the trust base of the root epoch of the round
+		ForRound(round uint64) types.RootTrustBase
+		// Scheduled returns the trust bases of the root epochs following the genesis epoch
+		Scheduled() []*types.RootTrustBaseV1
+		// AddRecovered schedules the root epochs fetched from a peer, the epochs may have already started
+		AddRecovered(trustBases []*types.RootTrustBaseV1) error
 	}
 
 	Option func(c *Optional)
@@ -42,6 +89,45 @@ func WithConsensusParams(params Parameters) Option {
 	}
 }
 
+// WithEvidenceStore sets the store of the evidence of root validators sending conflicting votes
+func WithEvidenceStore(store EvidenceStore) Option {
+	return func(c *Optional) {
+		c.Evidence = store
+	}
+}
+
+// WithUCArchive sets the archive of the issued unicity certificates
+func WithUCArchive(archive UCArchive) Option {
+	return func(c *Optional) {
+		c.UCArchive = archive
+	}
+}
+
+// WithBlockArchive sets the archive of the committed root blocks
+func WithBlockArchive(archive BlockArchive) Option {
+	return func(c *Optional) {
+		c.BlockArchive = archive
+	}
+}
+
+// WithEpochStats sets the store of the summaries of the completed shard epochs
+func WithEpochStats(store EpochStats) Option {
+	return func(c *Optional) {
+		c.EpochStats = store
+	}
+}
+
+/*
+WithTrustBases sets the schedule of the root epochs. Without the schedule the
+validator set of the root chain is the one of the trust base given to the consensus
+manager and it doesn't change.
+*/
+func WithTrustBases(trustBases TrustBases) Option {
+	return func(c *Optional) {
+		c.TrustBases = trustBases
+	}
+}
+
 func LoadConf(opts []Option) (*Optional, error) {
 	conf := &Optional{}
 	for _, opt := range opts {
@@ -0,0 +1,143 @@
+package consensus
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill/rootchain/consensus/leader"
+	drctypes "github.com/alphabill-org/alphabill/rootchain/consensus/types"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+/*
+staticTrustBases is the schedule of the root chain whose validator set doesn't
+change, all the rounds belong to the genesis epoch.
+*/
+type staticTrustBases struct {
+	trustBase types.RootTrustBase
+}
+
+func (s staticTrustBases) Epoch(round uint64) uint64 {
+	return drctypes.GenesisRootEpoch
+}
+
+func (s staticTrustBases) TrustBase(epoch uint64) types.RootTrustBase {
+	if epoch != drctypes.GenesisRootEpoch {
+		return nil
+	}
+	return s.trustBase
+}
+
+func (s staticTrustBases) ForRound(round uint64) types.RootTrustBase {
+	return s.trustBase
+}
+
+func (s staticTrustBases) Scheduled() []*types.RootTrustBaseV1 {
+	return nil
+}
+
+func (s staticTrustBases) AddRecovered(trustBases []*types.RootTrustBaseV1) error {
+	if len(trustBases) != 0 {
+		return errors.New("root epochs can't be scheduled, the validator set is static")
+	}
+	return nil
+}
+
+/*
+epochLeader selects the leader of the round using the leader selector of the root
+epoch the round belongs to, ie the leader selection switches to the validators of
+the new epoch at the start round of the epoch.
+*/
+type epochLeader struct {
+	trustBases TrustBases
+	mu         sync.Mutex
+	selectors  map[uint64]Leader // root epoch -> leader selector of the epoch
+	round      uint64            // current round of the last Update call
+}
+
+func newEpochLeader(trustBases TrustBases) (*epochLeader, error) {
+	l := &epochLeader{
+		trustBases: trustBases,
+		selectors:  make(map[uint64]Leader),
+	}
+	if _, err := l.selector(drctypes.GenesisRootEpoch); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+/*
+selector returns the leader selector of the root epoch, it must be called while
+holding the lock.
+*/
+func (l *epochLeader) selector(epoch uint64) (Leader, error) {
+	if ls, ok := l.selectors[epoch]; ok {
+		return ls, nil
+	}
+	tb := l.trustBases.TrustBase(epoch)
+	if tb == nil {
+		return nil, fmt.Errorf("trust base of the root epoch %d not found", epoch)
+	}
+	ls, err := leaderSelector(tb)
+	if err != nil {
+		return nil, fmt.Errorf("creating leader selector of the root epoch %d: %w", epoch, err)
+	}
+	l.selectors[epoch] = ls
+	return ls, nil
+}
+
+func (l *epochLeader) GetLeaderForRound(round uint64) peer.ID {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ls, err := l.selector(l.trustBases.Epoch(round))
+	if err != nil {
+		return leader.UnknownLeader
+	}
+	return ls.GetLeaderForRound(round)
+}
+
+/*
+GetNodes returns the validators of the current root epoch and of the scheduled
+epochs, the validators of the next epoch must receive the messages of the last
+rounds of the current epoch in order to take over.
+*/
+func (l *epochLeader) GetNodes() []peer.ID {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var nodes []peer.ID
+	for epoch := l.trustBases.Epoch(l.round); ; epoch++ {
+		ls, err := l.selector(epoch)
+		if err != nil {
+			break
+		}
+		for _, id := range ls.GetNodes() {
+			if !slices.Contains(nodes, id) {
+				nodes = append(nodes, id)
+			}
+		}
+	}
+	return nodes
+}
+
+func (l *epochLeader) Update(qc *drctypes.QuorumCert, currentRound uint64, b leader.BlockLoader) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.round = currentRound
+	epoch := l.trustBases.Epoch(currentRound + 1)
+	if epoch != l.trustBases.Epoch(qc.GetRound()) {
+		// the QC has been signed by the validators of the previous epoch, the reputation
+		// can't be used - leader of the first round of the epoch is picked round-robin
+		return nil
+	}
+	ls, err := l.selector(epoch)
+	if err != nil {
+		return err
+	}
+	return ls.Update(qc, currentRound, b)
+}
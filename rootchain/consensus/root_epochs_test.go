@@ -0,0 +1,90 @@
+package consensus
+
+import (
+	"testing"
+
+	abcrypto "github.com/alphabill-org/alphabill-go-base/crypto"
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill/internal/testutils/trustbase"
+	"github.com/alphabill-org/alphabill/rootchain/consensus/leader"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+// twoEpochs is the schedule where the root epoch 1 starts from the round "start"
+type twoEpochs struct {
+	epochs []types.RootTrustBase
+	start  uint64
+}
+
+func (s twoEpochs) Epoch(round uint64) uint64 {
+	if round >= s.start {
+		return 1
+	}
+	return 0
+}
+
+func (s twoEpochs) TrustBase(epoch uint64) types.RootTrustBase {
+	if epoch >= uint64(len(s.epochs)) {
+		return nil
+	}
+	return s.epochs[epoch]
+}
+
+func (s twoEpochs) ForRound(round uint64) types.RootTrustBase {
+	return s.epochs[s.Epoch(round)]
+}
+
+func (s twoEpochs) Scheduled() []*types.RootTrustBaseV1 {
+	return nil
+}
+
+func (s twoEpochs) AddRecovered(trustBases []*types.RootTrustBaseV1) error {
+	return nil
+}
+
+func Test_epochLeader(t *testing.T) {
+	newTrustBase := func(t *testing.T) (types.RootTrustBase, peer.ID) {
+		signer, err := abcrypto.NewInMemorySecp256K1Signer()
+		require.NoError(t, err)
+		verifier, err := signer.Verifier()
+		require.NoError(t, err)
+		tb := trustbase.NewTrustBase(t, verifier)
+		id, err := peer.Decode(tb.GetRootNodes()[0].NodeID)
+		require.NoError(t, err)
+		return tb, id
+	}
+
+	t.Run("genesis trust base missing", func(t *testing.T) {
+		ls, err := newEpochLeader(twoEpochs{})
+		require.EqualError(t, err, "trust base of the root epoch 0 not found")
+		require.Nil(t, ls)
+	})
+
+	t.Run("static", func(t *testing.T) {
+		tb, id := newTrustBase(t)
+		ls, err := newEpochLeader(staticTrustBases{trustBase: tb})
+		require.NoError(t, err)
+		require.Equal(t, id, ls.GetLeaderForRound(1))
+		require.Equal(t, id, ls.GetLeaderForRound(1000))
+		require.Equal(t, []peer.ID{id}, ls.GetNodes())
+	})
+
+	t.Run("two epochs", func(t *testing.T) {
+		tb0, id0 := newTrustBase(t)
+		tb1, id1 := newTrustBase(t)
+		ls, err := newEpochLeader(twoEpochs{epochs: []types.RootTrustBase{tb0, tb1}, start: 10})
+		require.NoError(t, err)
+		require.Equal(t, id0, ls.GetLeaderForRound(9))
+		require.Equal(t, id1, ls.GetLeaderForRound(10))
+		// validators of the next epoch receive the messages before the epoch starts
+		require.ElementsMatch(t, []peer.ID{id0, id1}, ls.GetNodes())
+	})
+
+	t.Run("epoch not scheduled", func(t *testing.T) {
+		tb0, _ := newTrustBase(t)
+		ls, err := newEpochLeader(twoEpochs{epochs: []types.RootTrustBase{tb0}, start: 10})
+		require.NoError(t, err)
+		require.Equal(t, leader.UnknownLeader, ls.GetLeaderForRound(10))
+	})
+}
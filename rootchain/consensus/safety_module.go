@@ -82,12 +82,14 @@ func (s *SafetyModule) constructCommitInfo(block *drctypes.BlockData, voteInfoHa
 	if committedRound == nil {
 		return &types.UnicitySeal{Version: 1, PreviousHash: voteInfoHash}
 	}
+	// the seal is signed by the validators voting for the block, the epoch of the
+	// seal is the root epoch of the block (identifies the trust base to verify it)
 	return &types.UnicitySeal{
 		Version:              1,
 		NetworkID:            s.network,
 		PreviousHash:         voteInfoHash,
 		RootChainRoundNumber: committedRound.RoundNumber,
-		Epoch:                committedRound.Epoch,
+		Epoch:                block.Epoch,
 		Timestamp:            committedRound.Timestamp,
 		Hash:                 committedRound.CurrentRootHash,
 	}
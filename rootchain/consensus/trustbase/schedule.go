@@ -0,0 +1,232 @@
+package trustbase
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+)
+
+/*
+Schedule is the list of the root epochs: the genesis trust base (root epoch 0) and
+the trust bases of the following epochs, each of them starting from the root round
+given by the EpochStartRound of the trust base. The schedule is persisted in the
+Store, the trust base of the root epoch N is stored under the epoch number N.
+*/
+type Schedule struct {
+	store  *Store
+	mu     sync.RWMutex
+	epochs []types.RootTrustBase // index is the root epoch number
+}
+
+/*
+roundTrustBase is the trust base of the root epoch of a round, it also implements
+the EpochTrustBase interface of the consensus types so that the QCs and TCs of the
+earlier rounds are verified by the trust bases of their epochs.
+*/
+type roundTrustBase struct {
+	types.RootTrustBase
+	schedule *Schedule
+}
+
+func (tb roundTrustBase) ForRound(round uint64) types.RootTrustBase {
+	return tb.schedule.ForRound(round)
+}
+
+/*
+NewSchedule loads the trust bases of all the root epochs from the store, the store
+must contain at least the genesis trust base.
+*/
+func NewSchedule(store *Store) (*Schedule, error) {
+	if store == nil {
+		return nil, errors.New("trust base store is nil")
+	}
+	s := &Schedule{store: store}
+	for epoch := uint64(0); ; epoch++ {
+		tb, err := store.LoadTrustBase(epoch)
+		if err != nil {
+			return nil, fmt.Errorf("loading trust base of the epoch %d: %w", epoch, err)
+		}
+		if tb == nil {
+			break
+		}
+		s.epochs = append(s.epochs, tb)
+	}
+	if len(s.epochs) == 0 {
+		return nil, errors.New("genesis trust base not found")
+	}
+	return s, nil
+}
+
+/*
+Epoch returns the number of the root epoch the round belongs to.
+*/
+func (s *Schedule) Epoch(round uint64) uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.epoch(round)
+}
+
+func (s *Schedule) epoch(round uint64) uint64 {
+	for epoch := len(s.epochs) - 1; epoch > 0; epoch-- {
+		if epochStartRound(s.epochs[epoch]) <= round {
+			return uint64(epoch)
+		}
+	}
+	return 0
+}
+
+/*
+TrustBase returns the trust base of the root epoch, nil when the epoch hasn't been
+scheduled.
+*/
+func (s *Schedule) TrustBase(epoch uint64) types.RootTrustBase {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if epoch >= uint64(len(s.epochs)) {
+		return nil
+	}
+	return roundTrustBase{RootTrustBase: s.epochs[epoch], schedule: s}
+}
+
+/*
+ForRound returns the trust base of the root epoch of the round.
+*/
+func (s *Schedule) ForRound(round uint64) types.RootTrustBase {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return roundTrustBase{RootTrustBase: s.epochs[s.epoch(round)], schedule: s}
+}
+
+/*
+LastEpoch returns the number of the last scheduled root epoch.
+*/
+func (s *Schedule) LastEpoch() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return uint64(len(s.epochs) - 1)
+}
+
+/*
+LoadTrustBase returns the trust base of the root epoch, nil when the epoch hasn't
+been scheduled.
+*/
+func (s *Schedule) LoadTrustBase(epoch uint64) (types.RootTrustBase, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if epoch >= uint64(len(s.epochs)) {
+		return nil, nil
+	}
+	return s.epochs[epoch], nil
+}
+
+/*
+Add schedules the trust base of the next root epoch. The trust base must be signed
+by the quorum of the validators of the last scheduled epoch and the epoch must start
+after the current round of the root chain. Returns the number of the new epoch.
+*/
+func (s *Schedule) Add(tb *types.RootTrustBaseV1, currentRound uint64) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if tb != nil && tb.EpochStartRound <= currentRound {
+		return 0, fmt.Errorf("epoch start round %d must be greater than the current round %d", tb.EpochStartRound, currentRound)
+	}
+	return s.add(tb)
+}
+
+/*
+AddRecovered schedules the trust bases of the root epochs fetched from a peer by the
+root node which missed the scheduling of the epochs. The epochs already in the schedule
+are skipped, the others must follow the last scheduled epoch and each of them must be
+signed by the quorum of the validators of the previous epoch. Unlike with Add, the
+epochs may have already started.
+*/
+func (s *Schedule) AddRecovered(trustBases []*types.RootTrustBaseV1) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, tb := range trustBases {
+		if tb != nil && tb.Epoch < uint64(len(s.epochs)) {
+			continue
+		}
+		if _, err := s.add(tb); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+add verifies and stores the trust base of the next root epoch, it must be called
+while holding the lock.
+*/
+func (s *Schedule) add(tb *types.RootTrustBaseV1) (uint64, error) {
+	epoch := uint64(len(s.epochs))
+	if err := VerifyNext(s.epochs[epoch-1], tb); err != nil {
+		return 0, fmt.Errorf("invalid trust base of the epoch %d: %w", epoch, err)
+	}
+	if err := s.store.StoreTrustBase(epoch, tb); err != nil {
+		return 0, err
+	}
+	s.epochs = append(s.epochs, tb)
+	return epoch, nil
+}
+
+/*
+Scheduled returns the trust bases of the root epochs following the genesis epoch,
+ie the trust bases a root node which missed the scheduling needs to catch up.
+*/
+func (s *Schedule) Scheduled() []*types.RootTrustBaseV1 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var tbs []*types.RootTrustBaseV1
+	for _, tb := range s.epochs[1:] {
+		if v1, ok := tb.(*types.RootTrustBaseV1); ok {
+			tbs = append(tbs, v1)
+		}
+	}
+	return tbs
+}
+
+/*
+VerifyNext checks that "next" is a valid trust base of the root epoch following the
+epoch of "prev": it belongs to the same network, starts after "prev" and has been
+signed by the quorum of the validators of "prev".
+*/
+func VerifyNext(prev types.RootTrustBase, next *types.RootTrustBaseV1) error {
+	if next == nil {
+		return errors.New("trust base is nil")
+	}
+	if len(next.GetRootNodes()) == 0 {
+		return errors.New("trust base has no root nodes")
+	}
+	if next.GetNetworkID() != prev.GetNetworkID() {
+		return fmt.Errorf("invalid network id %d, expected %d", next.GetNetworkID(), prev.GetNetworkID())
+	}
+	if p, ok := prev.(*types.RootTrustBaseV1); ok {
+		if next.Epoch != p.Epoch+1 {
+			return fmt.Errorf("invalid epoch %d, expected %d", next.Epoch, p.Epoch+1)
+		}
+		if next.EpochStartRound <= p.EpochStartRound {
+			return fmt.Errorf("epoch start round %d must be greater than the start round %d of the previous epoch", next.EpochStartRound, p.EpochStartRound)
+		}
+	}
+	sigBytes, err := next.SigBytes()
+	if err != nil {
+		return fmt.Errorf("serializing trust base: %w", err)
+	}
+	if err := prev.VerifyQuorumSignatures(sigBytes, next.Signatures); err != nil {
+		return fmt.Errorf("trust base is not signed by the quorum of the previous epoch validators: %w", err)
+	}
+	return nil
+}
+
+func epochStartRound(tb types.RootTrustBase) uint64 {
+	if v1, ok := tb.(*types.RootTrustBaseV1); ok {
+		return v1.EpochStartRound
+	}
+	return 0
+}
@@ -0,0 +1,171 @@
+package trustbase
+
+import (
+	"testing"
+
+	"github.com/alphabill-org/alphabill-go-base/crypto"
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill/internal/testutils/trustbase"
+	"github.com/alphabill-org/alphabill/keyvaluedb/memorydb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedule(t *testing.T) {
+	genesis, genesisSigner := newTestTrustBase(t, "genesis")
+	store := newTestStore(t)
+
+	_, err := NewSchedule(nil)
+	require.EqualError(t, err, "trust base store is nil")
+
+	_, err = NewSchedule(store)
+	require.EqualError(t, err, "genesis trust base not found")
+
+	require.NoError(t, store.StoreTrustBase(0, genesis))
+	schedule, err := NewSchedule(store)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, schedule.LastEpoch())
+	require.EqualValues(t, 0, schedule.Epoch(1000))
+	require.Nil(t, schedule.TrustBase(1))
+
+	next, _ := newTestTrustBase(t, "next")
+	next.Epoch = genesis.Epoch + 1
+	next.EpochStartRound = 100
+
+	t.Run("not signed by the previous epoch", func(t *testing.T) {
+		_, err := schedule.Add(next, 10)
+		require.ErrorContains(t, err, "trust base is not signed by the quorum of the previous epoch validators")
+	})
+
+	require.NoError(t, next.Sign("genesis", genesisSigner))
+
+	t.Run("epoch starts in the past", func(t *testing.T) {
+		_, err := schedule.Add(next, 100)
+		require.EqualError(t, err, "epoch start round 100 must be greater than the current round 100")
+	})
+
+	epoch, err := schedule.Add(next, 10)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, epoch)
+	require.EqualValues(t, 1, schedule.LastEpoch())
+
+	require.EqualValues(t, 0, schedule.Epoch(99))
+	require.EqualValues(t, 1, schedule.Epoch(100))
+	require.Equal(t, genesis.GetRootNodes(), schedule.ForRound(99).GetRootNodes())
+	require.Equal(t, next.GetRootNodes(), schedule.ForRound(100).GetRootNodes())
+
+	// the trust base of the round is able to switch to the epoch of the earlier round
+	etb, ok := schedule.ForRound(100).(interface {
+		ForRound(round uint64) types.RootTrustBase
+	})
+	require.True(t, ok)
+	require.Equal(t, genesis.GetRootNodes(), etb.ForRound(99).GetRootNodes())
+
+	// the schedule is restored from the store
+	schedule, err = NewSchedule(store)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, schedule.LastEpoch())
+	tb, err := schedule.LoadTrustBase(1)
+	require.NoError(t, err)
+	require.Equal(t, next.GetRootNodes(), tb.GetRootNodes())
+}
+
+func TestSchedule_AddRecovered(t *testing.T) {
+	genesis, genesisSigner := newTestTrustBase(t, "genesis")
+	store := newTestStore(t)
+	require.NoError(t, store.StoreTrustBase(0, genesis))
+	schedule, err := NewSchedule(store)
+	require.NoError(t, err)
+	require.Empty(t, schedule.Scheduled())
+
+	epoch1, signer1 := newTestTrustBase(t, "epoch1")
+	epoch1.Epoch = 1
+	epoch1.EpochStartRound = 100
+	require.NoError(t, epoch1.Sign("genesis", genesisSigner))
+	epoch2, _ := newTestTrustBase(t, "epoch2")
+	epoch2.Epoch = 2
+	epoch2.EpochStartRound = 200
+
+	t.Run("not signed by the previous epoch", func(t *testing.T) {
+		require.ErrorContains(t, schedule.AddRecovered([]*types.RootTrustBaseV1{epoch1, epoch2}),
+			"invalid trust base of the epoch 2: trust base is not signed by the quorum of the previous epoch validators")
+		// the valid epochs before the invalid one are scheduled
+		require.EqualValues(t, 1, schedule.LastEpoch())
+	})
+
+	require.NoError(t, epoch2.Sign("epoch1", signer1))
+	// the epochs have already started and the known epochs are skipped
+	require.NoError(t, schedule.AddRecovered([]*types.RootTrustBaseV1{epoch1, epoch2}))
+	require.EqualValues(t, 2, schedule.LastEpoch())
+	require.EqualValues(t, 2, schedule.Epoch(300))
+	require.Equal(t, []*types.RootTrustBaseV1{epoch1, epoch2}, schedule.Scheduled())
+
+	// the recovered epochs are persisted
+	schedule, err = NewSchedule(store)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, schedule.LastEpoch())
+}
+
+func TestVerifyNext(t *testing.T) {
+	prev, prevSigner := newTestTrustBase(t, "prev")
+
+	newNext := func(t *testing.T) *types.RootTrustBaseV1 {
+		next, _ := newTestTrustBase(t, "next")
+		next.Epoch = prev.Epoch + 1
+		next.EpochStartRound = prev.EpochStartRound + 10
+		return next
+	}
+
+	t.Run("nil", func(t *testing.T) {
+		require.EqualError(t, VerifyNext(prev, nil), "trust base is nil")
+	})
+
+	t.Run("invalid network", func(t *testing.T) {
+		next := newNext(t)
+		next.NetworkID = prev.NetworkID + 1
+		require.ErrorContains(t, VerifyNext(prev, next), "invalid network id")
+	})
+
+	t.Run("invalid epoch", func(t *testing.T) {
+		next := newNext(t)
+		next.Epoch = prev.Epoch + 2
+		require.EqualError(t, VerifyNext(prev, next), "invalid epoch 3, expected 2")
+	})
+
+	t.Run("invalid start round", func(t *testing.T) {
+		next := newNext(t)
+		next.EpochStartRound = prev.EpochStartRound
+		require.ErrorContains(t, VerifyNext(prev, next), "must be greater than the start round")
+	})
+
+	t.Run("signature of the wrong validator", func(t *testing.T) {
+		next := newNext(t)
+		signer, err := crypto.NewInMemorySecp256K1Signer()
+		require.NoError(t, err)
+		require.NoError(t, next.Sign("prev", signer))
+		require.ErrorContains(t, VerifyNext(prev, next), "trust base is not signed by the quorum of the previous epoch validators")
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		next := newNext(t)
+		require.NoError(t, next.Sign("prev", prevSigner))
+		require.NoError(t, VerifyNext(prev, next))
+	})
+}
+
+func newTestTrustBase(t *testing.T, nodeID string) (*types.RootTrustBaseV1, crypto.Signer) {
+	signer, err := crypto.NewInMemorySecp256K1Signer()
+	require.NoError(t, err)
+	verifier, err := signer.Verifier()
+	require.NoError(t, err)
+	tb, err := types.NewTrustBaseGenesis(5, []*types.NodeInfo{trustbase.NewNodeInfoFromVerifier(t, nodeID, verifier)})
+	require.NoError(t, err)
+	return tb, signer
+}
+
+func newTestStore(t *testing.T) *Store {
+	db, err := memorydb.New()
+	require.NoError(t, err)
+	store, err := NewStore(db)
+	require.NoError(t, err)
+	return store
+}
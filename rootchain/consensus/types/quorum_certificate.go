@@ -106,7 +106,7 @@ func (x *QuorumCert) Verify(tb types.RootTrustBase) error {
 	if err != nil {
 		return fmt.Errorf("failed to marshal ledger commit info: %w", err)
 	}
-	if err := tb.VerifyQuorumSignatures(bs, x.Signatures); err != nil {
+	if err := TrustBaseForRound(tb, x.GetRound()).VerifyQuorumSignatures(bs, x.Signatures); err != nil {
 		return fmt.Errorf("failed to verify quorum signatures: %w", err)
 	}
 	return nil
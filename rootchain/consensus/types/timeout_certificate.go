@@ -141,6 +141,8 @@ func (x *TimeoutCert) Verify(tb types.RootTrustBase) error {
 		return fmt.Errorf("invalid timeout data: %w", err)
 	}
 
+	// the timeout votes are signed by the validators of the epoch of the timeout round
+	tb = TrustBaseForRound(tb, x.Timeout.Round)
 	var signedVotes uint64
 	var maxSignedRound uint64
 	highQcRound := x.Timeout.HighQc.VoteInfo.RoundNumber
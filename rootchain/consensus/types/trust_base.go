@@ -0,0 +1,29 @@
+package types
+
+import (
+	"github.com/alphabill-org/alphabill-go-base/types"
+)
+
+/*
+EpochTrustBase is the trust base of the root chain whose validator set changes
+with the root epochs. The signatures of a round must be verified by the trust base
+of the root epoch the round belongs to, ie the QC or TC of the previous round
+embedded into the message of the first round of the epoch has been signed by the
+validators of the previous epoch.
+*/
+type EpochTrustBase interface {
+	types.RootTrustBase
+	// ForRound returns the trust base of the root epoch of the round.
+	ForRound(round uint64) types.RootTrustBase
+}
+
+/*
+TrustBaseForRound returns the trust base to verify the signatures of the round.
+When "tb" is not an EpochTrustBase it is returned as is.
+*/
+func TrustBaseForRound(tb types.RootTrustBase, round uint64) types.RootTrustBase {
+	if etb, ok := tb.(EpochTrustBase); ok {
+		return etb.ForRound(round)
+	}
+	return tb
+}
@@ -29,8 +29,22 @@ type (
 		// Tracks all timeout votes for this round
 		// if 2f+1 or threshold votes, then TC is formed
 		timeoutCert *drctypes.TimeoutCert
-		// Helper, to avoid duplicate votes
-		authorToVote map[string]voteID
+		// Helper, to avoid duplicate votes and to detect equivocating votes
+		authorToVote map[string]authorVote
+	}
+
+	authorVote struct {
+		id   voteID
+		vote *abdrc.VoteMsg
+	}
+
+	// EquivocatingVoteError is returned when the author has already sent a
+	// different vote in the round
+	EquivocatingVoteError struct {
+		Previous *abdrc.VoteMsg
+		Vote     *abdrc.VoteMsg
+		prevID   voteID
+		id       voteID
 	}
 
 	// sha256 hash is used to create ID for a vote
@@ -39,11 +53,15 @@ type (
 
 var ErrVoteIsNil = errors.New("vote is nil")
 
+func (e *EquivocatingVoteError) Error() string {
+	return fmt.Sprintf("equivocating vote, previous %X, new %X", e.prevID, e.id)
+}
+
 func NewVoteRegister() *VoteRegister {
 	return &VoteRegister{
 		hashToSignatures: make(map[voteID]*ConsensusWithSignatures),
 		timeoutCert:      nil,
-		authorToVote:     make(map[string]voteID),
+		authorToVote:     make(map[string]authorVote),
 	}
 }
 
@@ -60,16 +78,16 @@ func (v *VoteRegister) InsertVote(vote *abdrc.VoteMsg, quorumInfo QuorumInfo) (*
 	commitInfoHash := sha256.Sum256(bs)
 
 	// has the author already voted in this round?
-	if prevVoteHash, voted := v.authorToVote[vote.Author]; voted {
+	if prev, voted := v.authorToVote[vote.Author]; voted {
 		// Check if vote has changed
-		if commitInfoHash != prevVoteHash {
+		if commitInfoHash != prev.id {
 			// new equivocating vote, this is a security event
-			return nil, fmt.Errorf("equivocating vote, previous %X, new %X", prevVoteHash, commitInfoHash)
+			return nil, &EquivocatingVoteError{Previous: prev.vote, Vote: vote, prevID: prev.id, id: commitInfoHash}
 		}
 		return nil, fmt.Errorf("duplicate vote")
 	}
 	// Store vote from author
-	v.authorToVote[vote.Author] = commitInfoHash
+	v.authorToVote[vote.Author] = authorVote{id: commitInfoHash, vote: vote}
 	// register commit hash
 	// Create new entry if not present
 	if _, present := v.hashToSignatures[commitInfoHash]; !present {
@@ -264,12 +264,18 @@ func TestVoteRegister_ErrDuplicateVote(t *testing.T) {
 func TestVoteRegister_ErrEquivocatingVote(t *testing.T) {
 	register := NewVoteRegister()
 	quorumInfo := NewDummyQuorum(3, 0)
-	qc, err := register.InsertVote(NewDummyVote(t, "node1", 2, []byte{1, 2, 3}), quorumInfo)
+	vote1 := NewDummyVote(t, "node1", 2, []byte{1, 2, 3})
+	qc, err := register.InsertVote(vote1, quorumInfo)
 	require.NoError(t, err)
 	require.Nil(t, qc)
-	qc, err = register.InsertVote(NewDummyVote(t, "node1", 2, []byte{1, 2, 4}), quorumInfo)
+	vote2 := NewDummyVote(t, "node1", 2, []byte{1, 2, 4})
+	qc, err = register.InsertVote(vote2, quorumInfo)
 	require.ErrorContains(t, err, "equivocating vote")
 	require.Nil(t, qc)
+	var eqErr *EquivocatingVoteError
+	require.ErrorAs(t, err, &eqErr)
+	require.Equal(t, vote1, eqErr.Previous)
+	require.Equal(t, vote2, eqErr.Vote)
 }
 
 func TestVoteRegister_Reset(t *testing.T) {
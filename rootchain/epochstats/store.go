@@ -0,0 +1,100 @@
+/*
+Package epochstats implements the store of the per epoch fee and statistics
+summaries of the shards.
+
+The root chain keeps the per validator fees and the statistical record of the
+current and the previous epoch of the shard, older epochs are available only via
+the hashes in the technical records. The store keeps the summary of every completed
+epoch so that the validator operators can query the historical epochs.
+*/
+package epochstats
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill/keyvaluedb"
+	"github.com/alphabill-org/alphabill/network/protocol/certification"
+)
+
+// summaries: prefix | partition | len(shard key) | shard key | epoch -> Summary
+var summaryPrefix = []byte("e")
+
+type (
+	Store struct {
+		db keyvaluedb.KeyValueDB
+	}
+
+	// Summary of the shard epoch.
+	Summary struct {
+		_           struct{} `cbor:",toarray"`
+		PartitionID types.PartitionID
+		ShardID     types.ShardID
+		Epoch       uint64
+		// per validator summary fees of the epoch
+		Fees map[string]uint64
+		Stat certification.StatisticalRecord
+		// certificate of the last round of the epoch, shard conf of the epoch and
+		// the fee list of the next epoch, these allow to verify the fees against
+		// the fee hash of the technical record (ie to claim the validator rewards)
+		Certificate   *certification.CertificationResponse
+		ShardConf     *types.PartitionDescriptionRecord
+		NextEpochFees map[string]uint64
+	}
+)
+
+func NewStore(db keyvaluedb.KeyValueDB) (*Store, error) {
+	if db == nil {
+		return nil, errors.New("epoch stats database is nil")
+	}
+	return &Store{db: db}, nil
+}
+
+/*
+Add stores the summary of the epoch. The summary of an epoch is final so when the
+summary of the epoch has already been stored the call is no-op and false is returned.
+*/
+func (s *Store) Add(summary *Summary) (bool, error) {
+	if summary == nil {
+		return false, errors.New("epoch summary is nil")
+	}
+	key := summaryKey(summary.PartitionID, summary.ShardID, summary.Epoch)
+	found, err := s.db.Read(key, &Summary{})
+	if err != nil {
+		return false, fmt.Errorf("reading epoch summary: %w", err)
+	}
+	if found {
+		return false, nil
+	}
+	if err := s.db.Write(key, summary); err != nil {
+		return false, fmt.Errorf("storing epoch summary: %w", err)
+	}
+	return true, nil
+}
+
+/*
+Summary returns the summary of the shard epoch, nil when the summary of the epoch
+is not in the store (the epoch hasn't been completed yet).
+*/
+func (s *Store) Summary(partition types.PartitionID, shard types.ShardID, epoch uint64) (*Summary, error) {
+	summary := &Summary{}
+	found, err := s.db.Read(summaryKey(partition, shard, epoch), summary)
+	if err != nil {
+		return nil, fmt.Errorf("reading epoch %d summary: %w", epoch, err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return summary, nil
+}
+
+func summaryKey(partition types.PartitionID, shard types.ShardID, epoch uint64) []byte {
+	key := binary.BigEndian.AppendUint32(bytes.Clone(summaryPrefix), uint32(partition))
+	sk := shard.Key()
+	key = append(key, byte(len(sk)))
+	key = append(key, sk...)
+	return binary.BigEndian.AppendUint64(key, epoch)
+}
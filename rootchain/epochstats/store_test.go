@@ -0,0 +1,58 @@
+package epochstats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill/keyvaluedb/memorydb"
+	"github.com/alphabill-org/alphabill/network/protocol/certification"
+)
+
+func TestStore(t *testing.T) {
+	db, err := memorydb.New()
+	require.NoError(t, err)
+	store, err := NewStore(db)
+	require.NoError(t, err)
+
+	shard0, shard1 := types.ShardID{}.Split()
+	newSummary := func(shard types.ShardID, epoch, fee uint64) *Summary {
+		return &Summary{
+			PartitionID: 1,
+			ShardID:     shard,
+			Epoch:       epoch,
+			Fees:        map[string]uint64{"1": fee, "2": 0},
+			Stat:        certification.StatisticalRecord{Blocks: 2, BlockFees: fee},
+		}
+	}
+	for _, s := range []*Summary{newSummary(shard0, 0, 10), newSummary(shard0, 1, 20), newSummary(shard1, 0, 30)} {
+		added, err := store.Add(s)
+		require.NoError(t, err)
+		require.True(t, added)
+	}
+
+	// the summary of an epoch is not overwritten
+	added, err := store.Add(newSummary(shard0, 1, 0))
+	require.NoError(t, err)
+	require.False(t, added)
+
+	summary, err := store.Summary(1, shard0, 1)
+	require.NoError(t, err)
+	require.Equal(t, map[string]uint64{"1": 20, "2": 0}, summary.Fees)
+	require.EqualValues(t, 20, summary.Stat.BlockFees)
+
+	summary, err = store.Summary(1, shard0, 2)
+	require.NoError(t, err)
+	require.Nil(t, summary)
+
+	summary, err = store.Summary(1, shard1, 0)
+	require.NoError(t, err)
+	require.EqualValues(t, 30, summary.Stat.BlockFees)
+
+	_, err = store.Add(nil)
+	require.EqualError(t, err, "epoch summary is nil")
+
+	_, err = NewStore(nil)
+	require.EqualError(t, err, "epoch stats database is nil")
+}
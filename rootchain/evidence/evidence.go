@@ -0,0 +1,173 @@
+package evidence
+
+import (
+	"bytes"
+	gocrypto "crypto"
+	"errors"
+	"fmt"
+
+	abcrypto "github.com/alphabill-org/alphabill-go-base/crypto"
+	abhash "github.com/alphabill-org/alphabill-go-base/hash"
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill/network/protocol/abdrc"
+	"github.com/alphabill-org/alphabill/network/protocol/certification"
+)
+
+const (
+	// shard validator signed two different block certification requests for the same round
+	KindCertificationRequest Kind = 1
+	// root validator signed two different votes for the same round
+	KindVote Kind = 2
+)
+
+type (
+	Kind uint8
+
+	// Evidence of equivocation, ie of a validator signing two conflicting messages.
+	// The evidence contains both the signed messages so it can be verified by anyone
+	// who knows the signing key of the validator.
+	Evidence struct {
+		_         struct{}          `cbor:",toarray"`
+		Kind      Kind              `json:"kind"`
+		NodeID    string            `json:"nodeId"`
+		Partition types.PartitionID `json:"partitionId,omitempty"`
+		Shard     types.ShardID     `json:"shardId"`
+		Round     uint64            `json:"round,string"`
+		// Unix time (ms) when the equivocation was detected
+		Detected uint64 `json:"detected,string"`
+		// conflicting certification requests, set when Kind is KindCertificationRequest
+		Requests []*certification.BlockCertificationRequest `json:"requests,omitempty"`
+		// conflicting votes, set when Kind is KindVote
+		Votes []*abdrc.VoteMsg `json:"votes,omitempty"`
+	}
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindCertificationRequest:
+		return "certification-request"
+	case KindVote:
+		return "vote"
+	default:
+		return fmt.Sprintf("Kind(%d)", k)
+	}
+}
+
+/*
+NewRequestEvidence returns evidence of a shard validator signing two conflicting
+block certification requests.
+*/
+func NewRequestEvidence(first, second *certification.BlockCertificationRequest) *Evidence {
+	return &Evidence{
+		Kind:      KindCertificationRequest,
+		NodeID:    first.NodeID,
+		Partition: first.PartitionID,
+		Shard:     first.ShardID,
+		Round:     first.IRRound(),
+		Detected:  types.NewTimestamp(),
+		Requests:  []*certification.BlockCertificationRequest{first, second},
+	}
+}
+
+/*
+NewVoteEvidence returns evidence of a root validator signing two conflicting votes.
+*/
+func NewVoteEvidence(first, second *abdrc.VoteMsg) *Evidence {
+	return &Evidence{
+		Kind:     KindVote,
+		NodeID:   first.Author,
+		Round:    first.VoteInfo.GetRound(),
+		Detected: types.NewTimestamp(),
+		Votes:    []*abdrc.VoteMsg{first, second},
+	}
+}
+
+/*
+IsConflictingRequest returns true when the requests are signed by the same node for the
+same round and input record timestamp but are different, ie the requests are
+equivocating. The requests for the same round with different timestamps are allowed
+as the round is re-certified after a repeat UC.
+*/
+func IsConflictingRequest(a, b *certification.BlockCertificationRequest) (bool, error) {
+	if a.PartitionID != b.PartitionID || !a.ShardID.Equal(b.ShardID) || a.NodeID != b.NodeID {
+		return false, nil
+	}
+	if a.InputRecord == nil || b.InputRecord == nil {
+		return false, nil
+	}
+	if a.InputRecord.RoundNumber != b.InputRecord.RoundNumber || a.InputRecord.Timestamp != b.InputRecord.Timestamp {
+		return false, nil
+	}
+	hashA, err := requestHash(a)
+	if err != nil {
+		return false, err
+	}
+	hashB, err := requestHash(b)
+	if err != nil {
+		return false, err
+	}
+	return !bytes.Equal(hashA, hashB), nil
+}
+
+/*
+VerifyRequests verifies the certification request evidence, "verifier" is the
+signing key of the node in the shard configuration of the round.
+*/
+func (e *Evidence) VerifyRequests(verifier abcrypto.Verifier) error {
+	if e.Kind != KindCertificationRequest {
+		return fmt.Errorf("expected %s evidence, got %s", KindCertificationRequest, e.Kind)
+	}
+	if len(e.Requests) != 2 {
+		return fmt.Errorf("expected 2 certification requests, got %d", len(e.Requests))
+	}
+	for i, req := range e.Requests {
+		if err := req.IsValid(verifier); err != nil {
+			return fmt.Errorf("invalid certification request %d: %w", i, err)
+		}
+		if req.NodeID != e.NodeID || req.PartitionID != e.Partition || !req.ShardID.Equal(e.Shard) || req.IRRound() != e.Round {
+			return fmt.Errorf("certification request %d is not for node %s round %d", i, e.NodeID, e.Round)
+		}
+	}
+	conflicting, err := IsConflictingRequest(e.Requests[0], e.Requests[1])
+	if err != nil {
+		return err
+	}
+	if !conflicting {
+		return errors.New("certification requests are not conflicting")
+	}
+	return nil
+}
+
+/*
+VerifyVotes verifies the vote evidence with the root trust base of the round.
+*/
+func (e *Evidence) VerifyVotes(tb types.RootTrustBase) error {
+	if e.Kind != KindVote {
+		return fmt.Errorf("expected %s evidence, got %s", KindVote, e.Kind)
+	}
+	if len(e.Votes) != 2 {
+		return fmt.Errorf("expected 2 votes, got %d", len(e.Votes))
+	}
+	var sigBytes [2][]byte
+	for i, vote := range e.Votes {
+		if err := vote.Verify(tb); err != nil {
+			return fmt.Errorf("invalid vote %d: %w", i, err)
+		}
+		if vote.Author != e.NodeID || vote.VoteInfo.GetRound() != e.Round {
+			return fmt.Errorf("vote %d is not for node %s round %d", i, e.NodeID, e.Round)
+		}
+		bs, err := vote.LedgerCommitInfo.SigBytes()
+		if err != nil {
+			return fmt.Errorf("marshaling commit info of vote %d: %w", i, err)
+		}
+		sigBytes[i] = bs
+	}
+	if bytes.Equal(sigBytes[0], sigBytes[1]) {
+		return errors.New("votes are not conflicting")
+	}
+	return nil
+}
+
+func requestHash(req *certification.BlockCertificationRequest) ([]byte, error) {
+	return abhash.HashValues(gocrypto.SHA256, req.InputRecord, req.BlockSize, req.StateSize)
+}
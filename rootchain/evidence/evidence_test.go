@@ -0,0 +1,155 @@
+package evidence
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+	testobservability "github.com/alphabill-org/alphabill/internal/testutils/observability"
+	testsig "github.com/alphabill-org/alphabill/internal/testutils/sig"
+	"github.com/alphabill-org/alphabill/keyvaluedb/memorydb"
+	"github.com/alphabill-org/alphabill/network/protocol/certification"
+)
+
+func TestIsConflictingRequest(t *testing.T) {
+	req := newRequest(10, 100, []byte{1})
+
+	conflicting, err := IsConflictingRequest(req, newRequest(10, 100, []byte{1}))
+	require.NoError(t, err)
+	require.False(t, conflicting, "identical requests")
+
+	conflicting, err = IsConflictingRequest(req, newRequest(10, 100, []byte{2}))
+	require.NoError(t, err)
+	require.True(t, conflicting)
+
+	other := newRequest(10, 100, []byte{1})
+	other.BlockSize++
+	conflicting, err = IsConflictingRequest(req, other)
+	require.NoError(t, err)
+	require.True(t, conflicting, "different block size")
+
+	conflicting, err = IsConflictingRequest(req, newRequest(10, 101, []byte{2}))
+	require.NoError(t, err)
+	require.False(t, conflicting, "round re-certified after repeat UC")
+
+	conflicting, err = IsConflictingRequest(req, newRequest(11, 100, []byte{2}))
+	require.NoError(t, err)
+	require.False(t, conflicting, "different round")
+
+	other = newRequest(10, 100, []byte{2})
+	other.NodeID = "2"
+	conflicting, err = IsConflictingRequest(req, other)
+	require.NoError(t, err)
+	require.False(t, conflicting, "different node")
+}
+
+func TestEvidence_VerifyRequests(t *testing.T) {
+	signer, verifier := testsig.CreateSignerAndVerifier(t)
+	first := newRequest(10, 100, []byte{1})
+	require.NoError(t, first.Sign(signer))
+	second := newRequest(10, 100, []byte{2})
+	require.NoError(t, second.Sign(signer))
+
+	ev := NewRequestEvidence(first, second)
+	require.Equal(t, KindCertificationRequest, ev.Kind)
+	require.EqualValues(t, 10, ev.Round)
+	require.Equal(t, "1", ev.NodeID)
+	require.NoError(t, ev.VerifyRequests(verifier))
+
+	_, otherVerifier := testsig.CreateSignerAndVerifier(t)
+	require.ErrorContains(t, ev.VerifyRequests(otherVerifier), "invalid certification request 0: signature verification")
+
+	ev = NewRequestEvidence(first, first)
+	require.EqualError(t, ev.VerifyRequests(verifier), "certification requests are not conflicting")
+
+	ev = NewRequestEvidence(first, second)
+	ev.Round = 11
+	require.EqualError(t, ev.VerifyRequests(verifier), "certification request 0 is not for node 1 round 11")
+
+	ev = NewRequestEvidence(first, second)
+	ev.Requests = ev.Requests[:1]
+	require.EqualError(t, ev.VerifyRequests(verifier), "expected 2 certification requests, got 1")
+
+	ev.Kind = KindVote
+	require.EqualError(t, ev.VerifyRequests(verifier), "expected certification-request evidence, got vote")
+}
+
+func TestStore(t *testing.T) {
+	db, err := memorydb.New()
+	require.NoError(t, err)
+	store, err := NewStore(db, testobservability.Default(t).Meter("evidence"))
+	require.NoError(t, err)
+
+	items, err := store.List()
+	require.NoError(t, err)
+	require.Empty(t, items)
+
+	ev1 := NewRequestEvidence(newRequest(10, 100, []byte{1}), newRequest(10, 100, []byte{2}))
+	added, err := store.Add(t.Context(), ev1)
+	require.NoError(t, err)
+	require.True(t, added)
+	// the same equivocation is stored once
+	added, err = store.Add(t.Context(), NewRequestEvidence(newRequest(10, 100, []byte{1}), newRequest(10, 100, []byte{3})))
+	require.NoError(t, err)
+	require.False(t, added)
+
+	ev2 := NewRequestEvidence(newRequest(5, 100, []byte{1}), newRequest(5, 100, []byte{2}))
+	added, err = store.Add(t.Context(), ev2)
+	require.NoError(t, err)
+	require.True(t, added)
+
+	items, err = store.List()
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	require.EqualValues(t, 5, items[0].Round)
+	require.EqualValues(t, 10, items[1].Round)
+	require.Equal(t, ev1.Requests[1].InputRecord.BlockHash, items[1].Requests[1].InputRecord.BlockHash)
+
+	// evidence of the other partition
+	req := newRequest(7, 100, []byte{1})
+	req.PartitionID = 2
+	added, err = store.Add(t.Context(), NewRequestEvidence(req, newRequest(7, 100, []byte{2})))
+	require.NoError(t, err)
+	require.True(t, added)
+
+	items, err = store.Find(Filter{Kind: KindCertificationRequest, Partition: 1})
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	items, err = store.Find(Filter{Kind: KindCertificationRequest, Partition: 1, Round: 10})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.EqualValues(t, 10, items[0].Round)
+	items, err = store.Find(Filter{Partition: 2})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.EqualValues(t, 2, items[0].Partition)
+	items, err = store.Find(Filter{Round: 5})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	items, err = store.Find(Filter{Kind: KindVote})
+	require.NoError(t, err)
+	require.Empty(t, items)
+	items, err = store.List()
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+
+	_, err = NewStore(nil, testobservability.Default(t).Meter("evidence"))
+	require.EqualError(t, err, "evidence database is nil")
+}
+
+func newRequest(round, timestamp uint64, blockHash []byte) *certification.BlockCertificationRequest {
+	return &certification.BlockCertificationRequest{
+		PartitionID: 1,
+		NodeID:      "1",
+		InputRecord: &types.InputRecord{
+			Version:      1,
+			RoundNumber:  round,
+			Timestamp:    timestamp,
+			PreviousHash: []byte{0, 0, 0, 0},
+			Hash:         []byte{0, 0, 0, 1},
+			BlockHash:    blockHash,
+			SummaryValue: []byte{0, 0, 0, 2},
+		},
+	}
+}
@@ -0,0 +1,134 @@
+package evidence
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill/keyvaluedb"
+)
+
+/*
+Store persists the detected equivocation evidence so that it is available after
+restart, ie for a slashing mechanism.
+*/
+type Store struct {
+	db keyvaluedb.KeyValueDB
+
+	equivocations metric.Int64Counter
+}
+
+func NewStore(db keyvaluedb.KeyValueDB, m metric.Meter) (*Store, error) {
+	if db == nil {
+		return nil, errors.New("evidence database is nil")
+	}
+	equivocations, err := m.Int64Counter("equivocation.count",
+		metric.WithDescription("Number of equivocations (conflicting signed messages) detected"))
+	if err != nil {
+		return nil, fmt.Errorf("creating equivocation counter: %w", err)
+	}
+	return &Store{db: db, equivocations: equivocations}, nil
+}
+
+/*
+Add stores the evidence, returns false when evidence of the same node equivocating
+in the same round has already been stored.
+*/
+func (s *Store) Add(ctx context.Context, e *Evidence) (bool, error) {
+	key := evidenceKey(e)
+	found, err := s.db.Read(key, &Evidence{})
+	if err != nil {
+		return false, fmt.Errorf("reading evidence: %w", err)
+	}
+	if found {
+		return false, nil
+	}
+	if err := s.db.Write(key, e); err != nil {
+		return false, fmt.Errorf("storing evidence: %w", err)
+	}
+	s.equivocations.Add(ctx, 1, metric.WithAttributes(attribute.String("kind", e.Kind.String())))
+	return true, nil
+}
+
+/*
+Filter selects the evidence returned by Store.Find, the fields with zero value
+match any evidence.
+*/
+type Filter struct {
+	Kind      Kind
+	Partition types.PartitionID
+	Round     uint64
+}
+
+func (f Filter) match(e *Evidence) bool {
+	return (f.Kind == 0 || f.Kind == e.Kind) &&
+		(f.Partition == 0 || f.Partition == e.Partition) &&
+		(f.Round == 0 || f.Round == e.Round)
+}
+
+// keyPrefix returns the prefix of the keys of the evidence matching the filter
+func (f Filter) keyPrefix() []byte {
+	if f.Kind == 0 {
+		return nil
+	}
+	key := []byte{byte(f.Kind)}
+	if f.Partition == 0 {
+		return key
+	}
+	key = binary.BigEndian.AppendUint32(key, uint32(f.Partition))
+	if f.Round == 0 {
+		return key
+	}
+	return binary.BigEndian.AppendUint64(key, f.Round)
+}
+
+/*
+List returns all the stored evidence, ordered by kind, partition and round.
+*/
+func (s *Store) List() ([]*Evidence, error) {
+	return s.Find(Filter{})
+}
+
+/*
+Find returns the stored evidence matching the filter, ordered by kind, partition
+and round.
+*/
+func (s *Store) Find(f Filter) (_ []*Evidence, rErr error) {
+	prefix := f.keyPrefix()
+	var it keyvaluedb.Iterator
+	if prefix == nil {
+		it = s.db.First()
+	} else {
+		it = s.db.Find(prefix)
+	}
+	defer func() { rErr = errors.Join(rErr, it.Close()) }()
+
+	var res []*Evidence
+	for ; it.Valid() && bytes.HasPrefix(it.Key(), prefix); it.Next() {
+		e := &Evidence{}
+		if err := it.Value(e); err != nil {
+			return nil, fmt.Errorf("reading evidence: %w", err)
+		}
+		if f.match(e) {
+			res = append(res, e)
+		}
+	}
+	return res, nil
+}
+
+// evidenceKey is kind | partition | round | shard | node ID
+func evidenceKey(e *Evidence) []byte {
+	key := []byte{byte(e.Kind)}
+	key = binary.BigEndian.AppendUint32(key, uint32(e.Partition))
+	key = binary.BigEndian.AppendUint64(key, e.Round)
+	shard := e.Shard.Key()
+	key = append(key, byte(len(shard)))
+	key = append(key, shard...)
+	return append(key, e.NodeID...)
+}
@@ -0,0 +1,255 @@
+/*
+Package liveness tracks the progress of the shards certified by the root chain.
+
+When a shard stops sending certification requests the root chain keeps issuing
+repeat certificates on T2 timeouts. The monitor records, per shard, when the
+input record of the shard last changed, how many certificates in a row were
+issued on T2 timeout and which validators haven't sent certification requests,
+and logs a warning when the configured thresholds are crossed.
+*/
+package liveness
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill-go-base/types/hex"
+	"github.com/alphabill-org/alphabill/logger"
+	"github.com/alphabill-org/alphabill/observability"
+)
+
+type (
+	Monitor struct {
+		mu     sync.Mutex
+		shards map[types.PartitionShardID]*shardState
+
+		// number of root rounds without IR change after which the shard is considered stalled
+		stallRounds uint64
+		// number of consecutive shard rounds the validator may miss before it is reported
+		absentRounds uint64
+		log          *slog.Logger
+	}
+
+	shardState struct {
+		partition types.PartitionID
+		shard     types.ShardID
+		attrShard metric.MeasurementOption
+
+		round             uint64    // shard round of the last IR change
+		progressRootRound uint64    // root round of the last IR change
+		progressTime      time.Time // when the last IR change was observed
+		lastRootRound     uint64    // root round of the last certificate
+		t2Timeouts        uint64    // number of consecutive certificates issued on T2 timeout
+		stalled           bool
+		// validators which haven't sent request, value is the number of consecutive rounds missed
+		absent map[string]uint64
+	}
+
+	ShardStatus struct {
+		PartitionID types.PartitionID `json:"partitionId"`
+		ShardID     types.ShardID     `json:"shardId"`
+		// shard round of the last input record change
+		RoundNumber hex.Uint64 `json:"roundNumber"`
+		// root round and time (unix ms) when the input record last changed
+		ProgressRootRound hex.Uint64 `json:"progressRootRound"`
+		ProgressTime      hex.Uint64 `json:"progressTime"`
+		// root round of the last certificate issued to the shard
+		LastRootRound hex.Uint64 `json:"lastRootRound"`
+		// number of consecutive certificates issued on T2 timeout
+		T2Timeouts hex.Uint64 `json:"t2Timeouts"`
+		// the shard hasn't made progress for the configured number of root rounds
+		Stalled bool `json:"stalled"`
+		// validators which did not send certification request, value is the
+		// number of consecutive rounds missed
+		AbsentValidators map[string]hex.Uint64 `json:"absentValidators"`
+	}
+)
+
+/*
+New returns monitor which considers shard to be stalled when it hasn't made progress
+for "stallRounds" root rounds and reports validators which haven't sent certification
+request for "absentRounds" consecutive shard rounds. Zero threshold disables the
+respective log events.
+*/
+func New(stallRounds, absentRounds uint64, m metric.Meter, log *slog.Logger) (*Monitor, error) {
+	mon := &Monitor{
+		shards:       make(map[types.PartitionShardID]*shardState),
+		stallRounds:  stallRounds,
+		absentRounds: absentRounds,
+		log:          log,
+	}
+	if err := mon.initMetrics(m); err != nil {
+		return nil, fmt.Errorf("initializing metrics: %w", err)
+	}
+	return mon, nil
+}
+
+func (m *Monitor) initMetrics(mtr metric.Meter) error {
+	if _, err := mtr.Int64ObservableGauge(
+		"shard.rounds.without.progress",
+		metric.WithDescription("Number of root rounds since the input record of the shard last changed"),
+		metric.WithUnit("{round}"),
+		metric.WithInt64Callback(m.observe(func(s *shardState) uint64 { return s.lastRootRound - s.progressRootRound })),
+	); err != nil {
+		return fmt.Errorf("creating gauge for rounds without progress: %w", err)
+	}
+	if _, err := mtr.Int64ObservableGauge(
+		"shard.t2.timeouts",
+		metric.WithDescription("Number of consecutive certificates issued to the shard on T2 timeout"),
+		metric.WithInt64Callback(m.observe(func(s *shardState) uint64 { return s.t2Timeouts })),
+	); err != nil {
+		return fmt.Errorf("creating gauge for T2 timeouts: %w", err)
+	}
+	if _, err := mtr.Int64ObservableGauge(
+		"shard.validators.absent",
+		metric.WithDescription("Number of shard validators which did not send certification request in the last round"),
+		metric.WithUnit("{validator}"),
+		metric.WithInt64Callback(m.observe(func(s *shardState) uint64 { return uint64(len(s.absent)) })),
+	); err != nil {
+		return fmt.Errorf("creating gauge for absent validators: %w", err)
+	}
+	return nil
+}
+
+func (m *Monitor) observe(value func(s *shardState) uint64) metric.Int64Callback {
+	return func(ctx context.Context, io metric.Int64Observer) error {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for _, s := range m.shards {
+			io.Observe(int64(value(s)), s.attrShard) /* #nosec G115 its unlikely that value exceeds int64 max value */
+		}
+		return nil
+	}
+}
+
+/*
+Certified records the certificate issued to the shard. "validators" is the validator
+set of the shard and "participants" are the validators which sent certification request
+for the certified round, empty list of participants means the certificate was issued
+on T2 timeout.
+*/
+func (m *Monitor) Certified(ctx context.Context, uc *types.UnicityCertificate, validators, participants []string) {
+	if uc == nil || uc.InputRecord == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := m.get(uc.GetPartitionID(), uc.GetShardID())
+	rootRound := uc.GetRootRoundNumber()
+	s.lastRootRound = rootRound
+	prevProgress := s.progressRootRound
+	if uc.GetRoundNumber() > s.round || s.progressRootRound == 0 {
+		s.round = uc.GetRoundNumber()
+		s.progressRootRound = rootRound
+		s.progressTime = time.Now()
+	}
+	if len(participants) == 0 {
+		s.t2Timeouts++
+	} else {
+		s.t2Timeouts = 0
+	}
+
+	log := m.log.With(logger.Shard(s.partition, s.shard))
+	switch stalled := m.stallRounds > 0 && rootRound-s.progressRootRound >= m.stallRounds; {
+	case stalled && !s.stalled:
+		log.WarnContext(ctx, fmt.Sprintf("shard has made no progress for %d root rounds", rootRound-s.progressRootRound),
+			slog.Uint64("round", s.round), slog.Uint64("t2_timeouts", s.t2Timeouts))
+		s.stalled = true
+	case !stalled && s.stalled:
+		log.InfoContext(ctx, fmt.Sprintf("shard made progress after %d root rounds", rootRound-prevProgress),
+			slog.Uint64("round", s.round))
+		s.stalled = false
+	}
+
+	for id := range s.absent {
+		if !slices.Contains(validators, id) {
+			delete(s.absent, id)
+		}
+	}
+	for _, id := range validators {
+		if slices.Contains(participants, id) {
+			if m.absentRounds > 0 && s.absent[id] >= m.absentRounds {
+				log.InfoContext(ctx, fmt.Sprintf("validator %s sent certification request after missing %d rounds", id, s.absent[id]))
+			}
+			delete(s.absent, id)
+			continue
+		}
+		s.absent[id]++
+		if m.absentRounds > 0 && s.absent[id] == m.absentRounds {
+			log.WarnContext(ctx, fmt.Sprintf("validator %s has not sent certification request for %d rounds", id, s.absent[id]))
+		}
+	}
+}
+
+/*
+ShardStatus returns the liveness status of the shard, nil when no certificate has
+been issued to the shard since the monitor was started.
+*/
+func (m *Monitor) ShardStatus(partition types.PartitionID, shard types.ShardID) *ShardStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.shards[types.PartitionShardID{PartitionID: partition, ShardID: shard.Key()}]
+	if !ok {
+		return nil
+	}
+	return s.status()
+}
+
+// Shards returns the liveness status of all the shards ordered by partition and shard ID.
+func (m *Monitor) Shards() []*ShardStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	res := make([]*ShardStatus, 0, len(m.shards))
+	for _, s := range m.shards {
+		res = append(res, s.status())
+	}
+	slices.SortFunc(res, func(a, b *ShardStatus) int {
+		if c := cmp.Compare(a.PartitionID, b.PartitionID); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ShardID.Key(), b.ShardID.Key())
+	})
+	return res
+}
+
+func (m *Monitor) get(partition types.PartitionID, shard types.ShardID) *shardState {
+	key := types.PartitionShardID{PartitionID: partition, ShardID: shard.Key()}
+	s, ok := m.shards[key]
+	if !ok {
+		s = &shardState{
+			partition: partition,
+			shard:     shard,
+			attrShard: observability.Shard(partition, shard),
+			absent:    make(map[string]uint64),
+		}
+		m.shards[key] = s
+	}
+	return s
+}
+
+func (s *shardState) status() *ShardStatus {
+	res := &ShardStatus{
+		PartitionID:       s.partition,
+		ShardID:           s.shard,
+		RoundNumber:       hex.Uint64(s.round),
+		ProgressRootRound: hex.Uint64(s.progressRootRound),
+		ProgressTime:      hex.Uint64(s.progressTime.UnixMilli()), /* #nosec G115 unix time is positive */
+		LastRootRound:     hex.Uint64(s.lastRootRound),
+		T2Timeouts:        hex.Uint64(s.t2Timeouts),
+		Stalled:           s.stalled,
+		AbsentValidators:  make(map[string]hex.Uint64, len(s.absent)),
+	}
+	for id, n := range s.absent {
+		res.AbsentValidators[id] = hex.Uint64(n)
+	}
+	return res
+}
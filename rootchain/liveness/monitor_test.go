@@ -0,0 +1,73 @@
+package liveness
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill-go-base/types/hex"
+	testobservability "github.com/alphabill-org/alphabill/internal/testutils/observability"
+)
+
+func TestMonitor(t *testing.T) {
+	obs := testobservability.Default(t)
+	monitor, err := New(3, 2, obs.Meter("liveness"), obs.Logger())
+	require.NoError(t, err)
+	require.Nil(t, monitor.ShardStatus(1, types.ShardID{}))
+
+	validators := []string{"1", "2", "3"}
+	// shard round 5 certified with the requests of all the validators
+	monitor.Certified(t.Context(), newUC(1, 5, 10), validators, validators)
+	status := monitor.ShardStatus(1, types.ShardID{})
+	require.EqualValues(t, 5, status.RoundNumber)
+	require.EqualValues(t, 10, status.ProgressRootRound)
+	require.EqualValues(t, 10, status.LastRootRound)
+	require.NotZero(t, status.ProgressTime)
+	require.Zero(t, status.T2Timeouts)
+	require.False(t, status.Stalled)
+	require.Empty(t, status.AbsentValidators)
+
+	// validator "3" didn't send request
+	monitor.Certified(t.Context(), newUC(1, 6, 11), validators, []string{"1", "2"})
+	status = monitor.ShardStatus(1, types.ShardID{})
+	require.EqualValues(t, 6, status.RoundNumber)
+	require.Equal(t, map[string]hex.Uint64{"3": 1}, status.AbsentValidators)
+
+	// repeat certificates on T2 timeout
+	monitor.Certified(t.Context(), newUC(1, 6, 13), validators, nil)
+	status = monitor.ShardStatus(1, types.ShardID{})
+	require.EqualValues(t, 1, status.T2Timeouts)
+	require.False(t, status.Stalled)
+	require.Equal(t, map[string]hex.Uint64{"1": 1, "2": 1, "3": 2}, status.AbsentValidators)
+
+	monitor.Certified(t.Context(), newUC(1, 6, 15), validators, nil)
+	status = monitor.ShardStatus(1, types.ShardID{})
+	require.EqualValues(t, 2, status.T2Timeouts)
+	require.EqualValues(t, 11, status.ProgressRootRound)
+	require.EqualValues(t, 15, status.LastRootRound)
+	require.True(t, status.Stalled)
+
+	// the shard recovers, validator "3" has been removed from the validator set
+	monitor.Certified(t.Context(), newUC(1, 7, 16), validators[:2], validators[:2])
+	status = monitor.ShardStatus(1, types.ShardID{})
+	require.EqualValues(t, 7, status.RoundNumber)
+	require.Zero(t, status.T2Timeouts)
+	require.False(t, status.Stalled)
+	require.Empty(t, status.AbsentValidators)
+
+	monitor.Certified(t.Context(), newUC(2, 1, 16), validators, validators)
+	shards := monitor.Shards()
+	require.Len(t, shards, 2)
+	require.EqualValues(t, 1, shards[0].PartitionID)
+	require.EqualValues(t, 2, shards[1].PartitionID)
+}
+
+func newUC(partition types.PartitionID, round, rootRound uint64) *types.UnicityCertificate {
+	return &types.UnicityCertificate{
+		Version:                1,
+		InputRecord:            &types.InputRecord{Version: 1, RoundNumber: round},
+		UnicityTreeCertificate: &types.UnicityTreeCertificate{Version: 1, Partition: partition},
+		UnicitySeal:            &types.UnicitySeal{Version: 1, RootChainRoundNumber: rootRound},
+	}
+}
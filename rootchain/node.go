@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"slices"
 
 	"github.com/libp2p/go-libp2p/core/peer"
 	"go.opentelemetry.io/otel/attribute"
@@ -20,9 +22,12 @@ import (
 	"github.com/alphabill-org/alphabill/network"
 	"github.com/alphabill-org/alphabill/network/protocol/certification"
 	"github.com/alphabill-org/alphabill/network/protocol/handshake"
+	"github.com/alphabill-org/alphabill/network/protocol/rootepoch"
+	"github.com/alphabill-org/alphabill/network/protocol/shardconf"
 	"github.com/alphabill-org/alphabill/observability"
 	"github.com/alphabill-org/alphabill/rootchain/consensus"
 	"github.com/alphabill-org/alphabill/rootchain/consensus/storage"
+	"github.com/alphabill-org/alphabill/rootchain/evidence"
 )
 
 type (
@@ -47,12 +52,38 @@ type (
 		Run(ctx context.Context) error
 	}
 
+	// EvidenceStore persists the equivocation evidence
+	EvidenceStore interface {
+		Add(ctx context.Context, e *evidence.Evidence) (bool, error)
+	}
+
+	// LivenessMonitor tracks the progress of the shards
+	LivenessMonitor interface {
+		Certified(ctx context.Context, uc *types.UnicityCertificate, validators, participants []string)
+	}
+
+	// TrustBaseLoader returns the trust bases of the root epochs
+	TrustBaseLoader interface {
+		LoadTrustBase(epoch uint64) (types.RootTrustBase, error)
+	}
+
+	// ShardConfLoader returns the shard confs of the shard epochs
+	ShardConfLoader interface {
+		ShardConfigByEpoch(partition types.PartitionID, shard types.ShardID, epoch uint64) (*types.PartitionDescriptionRecord, error)
+	}
+
+	NodeOption func(*Node)
+
 	Node struct {
 		peer             *network.Peer // p2p network host for partition
 		incomingRequests *CertRequestBuffer
 		subscription     *Subscriptions
 		net              PartitionNet
 		consensusManager ConsensusManager
+		evidence         EvidenceStore
+		liveness         LivenessMonitor
+		trustBases       TrustBaseLoader
+		shardConfs       ShardConfLoader
 
 		log    *slog.Logger
 		tracer trace.Tracer
@@ -67,6 +98,7 @@ func New(
 	pNet PartitionNet,
 	cm ConsensusManager,
 	observe Observability,
+	opts ...NodeOption,
 ) (*Node, error) {
 	if peer == nil {
 		return nil, fmt.Errorf("partition listener is nil")
@@ -93,12 +125,55 @@ func New(
 		log:              observe.Logger(),
 		tracer:           observe.Tracer("rootchain.node"),
 	}
+	for _, opt := range opts {
+		opt(node)
+	}
 	if err := node.initMetrics(meter); err != nil {
 		return nil, fmt.Errorf("initializing metrics: %w", err)
 	}
 	return node, nil
 }
 
+/*
+WithEvidenceStore sets the store of the evidence of shard validators sending
+conflicting certification requests.
+*/
+func WithEvidenceStore(store EvidenceStore) NodeOption {
+	return func(n *Node) {
+		n.evidence = store
+	}
+}
+
+/*
+WithLivenessMonitor sets the monitor which is notified about the certificates
+issued to the shards.
+*/
+func WithLivenessMonitor(monitor LivenessMonitor) NodeOption {
+	return func(n *Node) {
+		n.liveness = monitor
+	}
+}
+
+/*
+WithTrustBases sets the source of the trust bases of the root epochs the shard
+nodes may fetch.
+*/
+func WithTrustBases(trustBases TrustBaseLoader) NodeOption {
+	return func(n *Node) {
+		n.trustBases = trustBases
+	}
+}
+
+/*
+WithShardConfs sets the source of the shard confs the shard nodes may fetch when
+changing the epoch.
+*/
+func WithShardConfs(shardConfs ShardConfLoader) NodeOption {
+	return func(n *Node) {
+		n.shardConfs = shardConfs
+	}
+}
+
 func (v *Node) initMetrics(m metric.Meter) (err error) {
 	v.execMsgCnt, err = m.Int64Counter("exec.msg.count", metric.WithDescription("Number of messages processed by the node"))
 	if err != nil {
@@ -164,6 +239,12 @@ func (v *Node) handlePartitionMsg(ctx context.Context, msg any) (rErr error) {
 	case *handshake.Handshake:
 		partitionID, shardID, nodeID = mt.PartitionID, mt.ShardID, mt.NodeID
 		return v.onHandshake(ctx, mt)
+	case *rootepoch.TrustBaseRequest:
+		partitionID, shardID, nodeID = mt.PartitionID, mt.ShardID, mt.NodeID
+		return v.onTrustBaseRequest(ctx, mt)
+	case *shardconf.ShardConfRequest:
+		partitionID, shardID, nodeID = mt.PartitionID, mt.ShardID, mt.NodeID
+		return v.onShardConfRequest(ctx, mt)
 	default:
 		return fmt.Errorf("unknown message type %T", msg)
 	}
@@ -216,6 +297,65 @@ func (v *Node) onHandshake(ctx context.Context, req *handshake.Handshake) error
 	return nil
 }
 
+/*
+onTrustBaseRequest sends the trust base of the requested root epoch to the shard
+node. The shard node verifies the trust base against the trust base of the previous
+epoch so the request is not restricted to the validators of the shard.
+*/
+func (v *Node) onTrustBaseRequest(ctx context.Context, req *rootepoch.TrustBaseRequest) error {
+	ctx, span := v.tracer.Start(ctx, "node.onTrustBaseRequest")
+	defer span.End()
+
+	if err := req.IsValid(); err != nil {
+		return fmt.Errorf("invalid trust base request: %w", err)
+	}
+	if v.trustBases == nil {
+		return errors.New("trust bases are not available")
+	}
+	tb, err := v.trustBases.LoadTrustBase(req.Epoch)
+	if err != nil {
+		return fmt.Errorf("loading trust base of the root epoch %d: %w", req.Epoch, err)
+	}
+	tbV1, ok := tb.(*types.RootTrustBaseV1)
+	if !ok {
+		return fmt.Errorf("trust base of the root epoch %d not found", req.Epoch)
+	}
+	peerID, err := peer.Decode(req.NodeID)
+	if err != nil {
+		return fmt.Errorf("invalid receiver id: %w", err)
+	}
+	return v.net.Send(ctx, &rootepoch.TrustBaseResponse{Epoch: req.Epoch, TrustBase: tbV1}, peerID)
+}
+
+/*
+onShardConfRequest sends the shard conf of the requested epoch to the shard node.
+The shard node verifies the shard conf against the hash certified in the unicity
+certificate so the request is not restricted to the validators of the shard.
+*/
+func (v *Node) onShardConfRequest(ctx context.Context, req *shardconf.ShardConfRequest) error {
+	ctx, span := v.tracer.Start(ctx, "node.onShardConfRequest")
+	defer span.End()
+
+	if err := req.IsValid(); err != nil {
+		return fmt.Errorf("invalid shard conf request: %w", err)
+	}
+	if v.shardConfs == nil {
+		return errors.New("shard confs are not available")
+	}
+	shardConf, err := v.shardConfs.ShardConfigByEpoch(req.PartitionID, req.ShardID, req.Epoch)
+	if err != nil {
+		return fmt.Errorf("loading shard conf: %w", err)
+	}
+	if shardConf == nil {
+		return fmt.Errorf("shard conf of the epoch %d of the shard %s - %s not found", req.Epoch, req.PartitionID, req.ShardID)
+	}
+	peerID, err := peer.Decode(req.NodeID)
+	if err != nil {
+		return fmt.Errorf("invalid receiver id: %w", err)
+	}
+	return v.net.Send(ctx, &shardconf.ShardConfResponse{ShardConf: shardConf}, peerID)
+}
+
 /*
 onBlockCertificationRequest handles Certification Request from shard nodes.
 Shard nodes can only extend the stored/certified state.
@@ -249,6 +389,10 @@ func (v *Node) onBlockCertificationRequest(ctx context.Context, req *certificati
 	// store the new request and see if quorum is now achieved
 	res, requests, err := v.incomingRequests.Add(ctx, req, si)
 	if err != nil {
+		var eqErr *EquivocationError
+		if errors.As(err, &eqErr) {
+			err = errors.Join(err, v.recordEquivocation(ctx, eqErr))
+		}
 		return fmt.Errorf("storing request: %w", err)
 	}
 	var reason consensus.CertReqReason
@@ -277,6 +421,23 @@ func (v *Node) onBlockCertificationRequest(ctx context.Context, req *certificati
 	return nil
 }
 
+/*
+recordEquivocation stores the evidence of the shard validator sending conflicting
+certification requests. Both the requests have been verified to be signed by the node.
+*/
+func (v *Node) recordEquivocation(ctx context.Context, eqErr *EquivocationError) error {
+	ev := evidence.NewRequestEvidence(eqErr.Previous, eqErr.Request)
+	v.log.WarnContext(ctx, fmt.Sprintf("node %s sent conflicting certification requests for round %d", ev.NodeID, ev.Round),
+		logger.Shard(ev.Partition, ev.Shard), logger.Data(ev))
+	if v.evidence == nil {
+		return nil
+	}
+	if _, err := v.evidence.Add(ctx, ev); err != nil {
+		return fmt.Errorf("recording equivocation evidence: %w", err)
+	}
+	return nil
+}
+
 // handleConsensus - receives consensus results and delivers certificates to subscribers
 func (v *Node) handleConsensus(ctx context.Context) error {
 	for {
@@ -288,7 +449,26 @@ func (v *Node) handleConsensus(ctx context.Context) error {
 				return fmt.Errorf("consensus channel closed")
 			}
 			v.subscription.Send(ctx, cr)
+			v.trackLiveness(ctx, cr)
 			v.incomingRequests.Clear(ctx, cr.Partition, cr.Shard)
 		}
 	}
 }
+
+/*
+trackLiveness notifies the liveness monitor about the certificate issued to the shard.
+Must be called before the request buffer of the shard is cleared as the nodes which
+have sent request for the round are read from the buffer.
+*/
+func (v *Node) trackLiveness(ctx context.Context, cr *certification.CertificationResponse) {
+	if v.liveness == nil {
+		return
+	}
+	si, err := v.consensusManager.ShardInfo(cr.Partition, cr.Shard)
+	if err != nil {
+		v.log.WarnContext(ctx, "loading shard info for liveness monitor", logger.Error(err), logger.Shard(cr.Partition, cr.Shard))
+		return
+	}
+	validators := slices.Sorted(maps.Keys(si.Fees))
+	v.liveness.Certified(ctx, &cr.UC, validators, v.incomingRequests.Participants(cr.Partition, cr.Shard))
+}
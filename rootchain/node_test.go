@@ -18,11 +18,13 @@ import (
 	testobservability "github.com/alphabill-org/alphabill/internal/testutils/observability"
 	"github.com/alphabill-org/alphabill/internal/testutils/peer"
 	testsig "github.com/alphabill-org/alphabill/internal/testutils/sig"
+	"github.com/alphabill-org/alphabill/keyvaluedb/memorydb"
 	"github.com/alphabill-org/alphabill/network"
 	"github.com/alphabill-org/alphabill/network/protocol/certification"
 	"github.com/alphabill-org/alphabill/network/protocol/handshake"
 	"github.com/alphabill-org/alphabill/rootchain/consensus"
 	"github.com/alphabill-org/alphabill/rootchain/consensus/storage"
+	"github.com/alphabill-org/alphabill/rootchain/evidence"
 )
 
 func Test_rootNode(t *testing.T) {
@@ -601,6 +603,35 @@ func Test_onBlockCertificationRequest(t *testing.T) {
 		require.EqualError(t, err, `storing request: request of the node in this round already stored`)
 	})
 
+	t.Run("conflicting request is recorded as evidence", func(t *testing.T) {
+		cm := &mockConsensusManager{
+			shardInfo: func(partition types.PartitionID, shard types.ShardID) (*storage.ShardInfo, error) {
+				return si, nil
+			},
+		}
+		db, err := memorydb.New()
+		require.NoError(t, err)
+		obs := testobservability.Default(t)
+		evidenceStore, err := evidence.NewStore(db, obs.Meter("evidence"))
+		require.NoError(t, err)
+		node, err := New(&nwPeer, partNet, cm, obs, WithEvidenceStore(evidenceStore))
+		require.NoError(t, err)
+
+		require.NoError(t, node.onBlockCertificationRequest(t.Context(), &validCertRequest))
+		cr := validCertRequest
+		cr.BlockSize++
+		require.NoError(t, cr.Sign(signer))
+		err = node.onBlockCertificationRequest(t.Context(), &cr)
+		require.ErrorContains(t, err, "equivocating request from node")
+
+		items, err := evidenceStore.List()
+		require.NoError(t, err)
+		require.Len(t, items, 1)
+		require.Equal(t, evidence.KindCertificationRequest, items[0].Kind)
+		require.Equal(t, nodeID, items[0].NodeID)
+		require.NoError(t, items[0].VerifyRequests(verifier))
+	})
+
 	t.Run("failure to RequestCertification", func(t *testing.T) {
 		expErr := errors.New("CM out of order")
 		cm := &mockConsensusManager{
@@ -841,6 +872,53 @@ func Test_handleConsensus(t *testing.T) {
 			t.Error("msg loop didn't quit within timeout")
 		}
 	})
+
+	t.Run("liveness monitor", func(t *testing.T) {
+		nodeID := generateNodeID(t).String()
+		cr := validCertificationResponse(t)
+		cm := mockConsensusManager{
+			certificationResult: make(chan *certification.CertificationResponse),
+			shardInfo: func(partition types.PartitionID, shard types.ShardID) (*storage.ShardInfo, error) {
+				return &storage.ShardInfo{PartitionID: partition, Fees: map[string]uint64{nodeID: 0, "other": 0}}, nil
+			},
+		}
+
+		certified := make(chan []string, 1)
+		monitor := mockLivenessMonitor(func(ctx context.Context, uc *types.UnicityCertificate, validators, participants []string) {
+			require.Equal(t, &cr.UC, uc)
+			require.ElementsMatch(t, []string{nodeID, "other"}, validators)
+			certified <- participants
+		})
+		node, err := New(&nwPeer, partNet, cm, testobservability.Default(t), WithLivenessMonitor(monitor))
+		require.NoError(t, err)
+
+		go func() {
+			require.ErrorIs(t, node.handleConsensus(t.Context()), context.Canceled)
+		}()
+
+		req := certification.BlockCertificationRequest{
+			PartitionID: cr.Partition,
+			ShardID:     cr.Shard,
+			NodeID:      nodeID,
+			InputRecord: &types.InputRecord{},
+		}
+		_, _, err = node.incomingRequests.Add(t.Context(), &req, mockQuorumInfo{nodeCount: 2, quorum: 2})
+		require.NoError(t, err)
+
+		cm.certificationResult <- &cr
+		select {
+		case participants := <-certified:
+			require.Equal(t, []string{nodeID}, participants)
+		case <-time.After(1000 * time.Millisecond):
+			t.Error("liveness monitor was not called within timeout")
+		}
+	})
+}
+
+type mockLivenessMonitor func(ctx context.Context, uc *types.UnicityCertificate, validators, participants []string)
+
+func (m mockLivenessMonitor) Certified(ctx context.Context, uc *types.UnicityCertificate, validators, participants []string) {
+	m(ctx, uc, validators, participants)
 }
 
 type mockQuorumInfo struct {
@@ -0,0 +1,151 @@
+package partitions
+
+import (
+	gocrypto "crypto"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	abcrypto "github.com/alphabill-org/alphabill-go-base/crypto"
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill-go-base/types/hex"
+)
+
+// ErrNotApproved is returned when the shard conf hasn't been approved by the quorum of the approvers.
+var ErrNotApproved = errors.New("shard conf is not approved")
+
+// approvalDomainTag separates the shard conf approvals from the other messages signed
+// with the same key, the approvers sign the hash of the tag and the shard conf hash.
+var approvalDomainTag = []byte("alphabill shard conf approval")
+
+type (
+	/*
+		Governance is the m-of-n quorum of the operators who must approve the shard
+		conf changes before they are accepted by the node.
+	*/
+	Governance struct {
+		Quorum    uint64            `json:"quorum"`    // number of approvals required
+		Approvers []*types.NodeInfo `json:"approvers"` // operators allowed to approve the changes
+
+		verifiers map[string]abcrypto.Verifier
+	}
+
+	// SignedShardConf is the shard conf together with the signatures of the approvers.
+	SignedShardConf struct {
+		ShardConf  *types.PartitionDescriptionRecord `json:"shardConf"`
+		Signatures map[string]hex.Bytes              `json:"signatures"` // approver identifier to signature map
+	}
+
+	// Approval is the audit record of the approved shard conf.
+	Approval struct {
+		PartitionID   types.PartitionID `json:"partitionId"`
+		ShardID       types.ShardID     `json:"shardId"`
+		Epoch         uint64            `json:"epoch,string"`
+		ShardConfHash hex.Bytes         `json:"shardConfHash"`
+		Approvers     []string          `json:"approvers"` // identifiers of the approvers who signed the shard conf
+		Time          time.Time         `json:"time"`      // time the shard conf was accepted by the node
+	}
+)
+
+/*
+NewGovernance returns the governance requiring "quorum" approvals out of the given approvers.
+*/
+func NewGovernance(quorum uint64, approvers []*types.NodeInfo) (*Governance, error) {
+	if len(approvers) == 0 {
+		return nil, errors.New("approvers list is empty")
+	}
+	if quorum == 0 || quorum > uint64(len(approvers)) {
+		return nil, fmt.Errorf("invalid quorum %d, must be between 1 and the number of approvers %d", quorum, len(approvers))
+	}
+	g := &Governance{
+		Quorum:    quorum,
+		Approvers: approvers,
+		verifiers: make(map[string]abcrypto.Verifier, len(approvers)),
+	}
+	for _, a := range approvers {
+		if a == nil || a.NodeID == "" {
+			return nil, errors.New("approver identifier is missing")
+		}
+		if _, ok := g.verifiers[a.NodeID]; ok {
+			return nil, fmt.Errorf("duplicate approver %s", a.NodeID)
+		}
+		verifier, err := abcrypto.NewVerifierSecp256k1(a.SigKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signing key of the approver %s: %w", a.NodeID, err)
+		}
+		g.verifiers[a.NodeID] = verifier
+	}
+	return g, nil
+}
+
+/*
+Verify checks that the shard conf has been signed by the quorum of the approvers and
+returns the audit record of the approval.
+*/
+func (g *Governance) Verify(sc *SignedShardConf) (*Approval, error) {
+	if sc == nil || sc.ShardConf == nil {
+		return nil, errors.New("shard conf is nil")
+	}
+	hash, err := sc.ShardConf.Hash(gocrypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("calculating shard conf hash: %w", err)
+	}
+	sigHash := approvalHash(hash)
+	approvers := make([]string, 0, len(sc.Signatures))
+	for id, sig := range sc.Signatures {
+		verifier, ok := g.verifiers[id]
+		if !ok {
+			return nil, fmt.Errorf("unknown approver %s", id)
+		}
+		if err := verifier.VerifyHash(sig, sigHash); err != nil {
+			return nil, fmt.Errorf("invalid signature of the approver %s: %w", id, err)
+		}
+		approvers = append(approvers, id)
+	}
+	if uint64(len(approvers)) < g.Quorum {
+		return nil, fmt.Errorf("%w: got %d approvals, quorum is %d", ErrNotApproved, len(approvers), g.Quorum)
+	}
+	slices.Sort(approvers)
+	return &Approval{
+		PartitionID:   sc.ShardConf.PartitionID,
+		ShardID:       sc.ShardConf.ShardID,
+		Epoch:         sc.ShardConf.Epoch,
+		ShardConfHash: hash,
+		Approvers:     approvers,
+		Time:          time.Now().UTC(),
+	}, nil
+}
+
+/*
+Sign adds the signature of the approver to the shard conf.
+*/
+func (sc *SignedShardConf) Sign(approverID string, signer abcrypto.Signer) error {
+	if sc.ShardConf == nil {
+		return errors.New("shard conf is nil")
+	}
+	hash, err := sc.ShardConf.Hash(gocrypto.SHA256)
+	if err != nil {
+		return fmt.Errorf("calculating shard conf hash: %w", err)
+	}
+	sig, err := signer.SignHash(approvalHash(hash))
+	if err != nil {
+		return fmt.Errorf("signing shard conf: %w", err)
+	}
+	if sc.Signatures == nil {
+		sc.Signatures = make(map[string]hex.Bytes)
+	}
+	sc.Signatures[approverID] = sig
+	return nil
+}
+
+/*
+approvalHash returns the hash signed by the approvers of the shard conf with the given hash.
+*/
+func approvalHash(shardConfHash []byte) []byte {
+	h := sha256.New()
+	h.Write(approvalDomainTag)
+	h.Write(shardConfHash)
+	return h.Sum(nil)
+}
@@ -0,0 +1,145 @@
+package partitions
+
+import (
+	gocrypto "crypto"
+	"path/filepath"
+	"testing"
+
+	abcrypto "github.com/alphabill-org/alphabill-go-base/crypto"
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill/internal/testutils/logger"
+	testsig "github.com/alphabill-org/alphabill/internal/testutils/sig"
+	"github.com/alphabill-org/alphabill/internal/testutils/trustbase"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewGovernance(t *testing.T) {
+	_, verifier := testsig.CreateSignerAndVerifier(t)
+	approver := trustbase.NewNodeInfoFromVerifier(t, "op1", verifier)
+
+	_, err := NewGovernance(1, nil)
+	require.EqualError(t, err, "approvers list is empty")
+
+	_, err = NewGovernance(0, []*types.NodeInfo{approver})
+	require.EqualError(t, err, "invalid quorum 0, must be between 1 and the number of approvers 1")
+
+	_, err = NewGovernance(2, []*types.NodeInfo{approver})
+	require.EqualError(t, err, "invalid quorum 2, must be between 1 and the number of approvers 1")
+
+	_, err = NewGovernance(1, []*types.NodeInfo{approver, approver})
+	require.EqualError(t, err, "duplicate approver op1")
+
+	_, err = NewGovernance(1, []*types.NodeInfo{{NodeID: "op1", SigKey: []byte{1, 2, 3}}})
+	require.ErrorContains(t, err, "invalid signing key of the approver op1")
+
+	gov, err := NewGovernance(1, []*types.NodeInfo{approver})
+	require.NoError(t, err)
+	require.NotNil(t, gov)
+}
+
+func TestGovernance_Verify(t *testing.T) {
+	signers := make(map[string]abcrypto.Signer)
+	var approvers []*types.NodeInfo
+	for _, id := range []string{"op1", "op2", "op3"} {
+		signer, verifier := testsig.CreateSignerAndVerifier(t)
+		signers[id] = signer
+		approvers = append(approvers, trustbase.NewNodeInfoFromVerifier(t, id, verifier))
+	}
+	gov, err := NewGovernance(2, approvers)
+	require.NoError(t, err)
+
+	t.Run("shard conf missing", func(t *testing.T) {
+		_, err := gov.Verify(&SignedShardConf{})
+		require.EqualError(t, err, "shard conf is nil")
+	})
+
+	t.Run("not enough approvals", func(t *testing.T) {
+		sc := &SignedShardConf{ShardConf: createShardConf(t, 1, types.ShardID{}, 10)}
+		require.NoError(t, sc.Sign("op1", signers["op1"]))
+		_, err := gov.Verify(sc)
+		require.ErrorIs(t, err, ErrNotApproved)
+	})
+
+	t.Run("unknown approver", func(t *testing.T) {
+		sc := &SignedShardConf{ShardConf: createShardConf(t, 1, types.ShardID{}, 10)}
+		signer, _ := testsig.CreateSignerAndVerifier(t)
+		require.NoError(t, sc.Sign("op1", signers["op1"]))
+		require.NoError(t, sc.Sign("op4", signer))
+		_, err := gov.Verify(sc)
+		require.EqualError(t, err, "unknown approver op4")
+	})
+
+	t.Run("signature of another key", func(t *testing.T) {
+		sc := &SignedShardConf{ShardConf: createShardConf(t, 1, types.ShardID{}, 10)}
+		require.NoError(t, sc.Sign("op1", signers["op1"]))
+		require.NoError(t, sc.Sign("op2", signers["op3"]))
+		_, err := gov.Verify(sc)
+		require.ErrorContains(t, err, "invalid signature of the approver op2")
+	})
+
+	t.Run("shard conf modified after signing", func(t *testing.T) {
+		sc := &SignedShardConf{ShardConf: createShardConf(t, 1, types.ShardID{}, 10)}
+		require.NoError(t, sc.Sign("op1", signers["op1"]))
+		require.NoError(t, sc.Sign("op2", signers["op2"]))
+		sc.ShardConf.EpochStart = 20
+		_, err := gov.Verify(sc)
+		require.ErrorContains(t, err, "invalid signature of the approver")
+	})
+
+	t.Run("signature of the bare shard conf hash", func(t *testing.T) {
+		sc := &SignedShardConf{ShardConf: createShardConf(t, 1, types.ShardID{}, 10)}
+		require.NoError(t, sc.Sign("op1", signers["op1"]))
+		hash, err := sc.ShardConf.Hash(gocrypto.SHA256)
+		require.NoError(t, err)
+		sig, err := signers["op2"].SignHash(hash)
+		require.NoError(t, err)
+		sc.Signatures["op2"] = sig
+		_, err = gov.Verify(sc)
+		require.ErrorContains(t, err, "invalid signature of the approver op2")
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		sc := &SignedShardConf{ShardConf: createShardConf(t, 1, types.ShardID{}, 10)}
+		require.NoError(t, sc.Sign("op3", signers["op3"]))
+		require.NoError(t, sc.Sign("op1", signers["op1"]))
+		approval, err := gov.Verify(sc)
+		require.NoError(t, err)
+		require.Equal(t, []string{"op1", "op3"}, approval.Approvers)
+		require.EqualValues(t, 1, approval.PartitionID)
+		require.EqualValues(t, 0, approval.Epoch)
+		require.NotEmpty(t, approval.ShardConfHash)
+	})
+}
+
+func TestOrchestration_AddApprovedShardConfig(t *testing.T) {
+	o, err := NewOrchestration(5, filepath.Join(t.TempDir(), "orchestration.db"), logger.New(t))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = o.db.Close() })
+
+	approvals, err := o.Approvals(1, types.ShardID{})
+	require.NoError(t, err)
+	require.Empty(t, approvals)
+
+	shardConf := createShardConf(t, 1, types.ShardID{}, 10)
+	approval := &Approval{PartitionID: 1, Epoch: 0, ShardConfHash: []byte{1}, Approvers: []string{"op1", "op2"}}
+	require.NoError(t, o.AddApprovedShardConfig(shardConf, approval))
+
+	// approvals bucket must not be mistaken for a partition
+	shardConfs, err := o.ShardConfigs(10)
+	require.NoError(t, err)
+	require.Len(t, shardConfs, 1)
+
+	approvals, err = o.Approvals(1, types.ShardID{})
+	require.NoError(t, err)
+	require.Len(t, approvals, 1)
+	require.Equal(t, approval.Approvers, approvals[0].Approvers)
+	require.EqualValues(t, approval.ShardConfHash, approvals[0].ShardConfHash)
+
+	// invalid shard conf is not stored and neither is the approval
+	invalid := createShardConf(t, 1, types.ShardID{}, 20)
+	invalid.Epoch = 2
+	require.Error(t, o.AddApprovedShardConfig(invalid, &Approval{PartitionID: 1, Epoch: 2}))
+	approvals, err = o.Approvals(1, types.ShardID{})
+	require.NoError(t, err)
+	require.Len(t, approvals, 1)
+}
@@ -13,7 +13,10 @@ import (
 	bolt "go.etcd.io/bbolt"
 )
 
-var rootBucketName = []byte("root")
+var (
+	rootBucketName      = []byte("root")
+	approvalsBucketName = []byte("approvals")
+)
 
 type (
 	Orchestration struct {
@@ -34,11 +37,12 @@ func NewOrchestration(networkID types.NetworkID, dbFile string, log *slog.Logger
 		return nil, fmt.Errorf("opening bolt DB: %w", err)
 	}
 
-	// ensure root bucket exists
+	// ensure root and approvals buckets exist
 	err = db.Update(func(tx *bolt.Tx) error {
-		_, err := tx.CreateBucketIfNotExists(rootBucketName)
-		if err != nil {
-			return fmt.Errorf("creating %q bucket: %w", rootBucketName, err)
+		for _, name := range [][]byte{rootBucketName, approvalsBucketName} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return fmt.Errorf("creating %q bucket: %w", name, err)
+			}
 		}
 		return nil
 	})
@@ -80,6 +84,40 @@ func (o *Orchestration) ShardConfig(partitionID types.PartitionID, shardID types
 	return shardConf, nil
 }
 
+/*
+ShardConfigByEpoch returns the shard conf of the given epoch of the shard, nil when
+the shard conf of the epoch hasn't been added.
+*/
+func (o *Orchestration) ShardConfigByEpoch(partitionID types.PartitionID, shardID types.ShardID, epoch uint64) (*types.PartitionDescriptionRecord, error) {
+	var shardConf *types.PartitionDescriptionRecord
+	err := o.db.View(func(tx *bolt.Tx) error {
+		shardBucket := getShardBucket(tx, partitionID, shardID)
+		if shardBucket == nil {
+			return nil
+		}
+		// shard confs are keyed by the epoch start round, the epochs are in the same order
+		c := shardBucket.Cursor()
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			var sc *types.PartitionDescriptionRecord
+			if err := json.Unmarshal(v, &sc); err != nil {
+				return fmt.Errorf("failed to unmarshal shard conf: %w", err)
+			}
+			if sc.Epoch == epoch {
+				shardConf = sc
+				return nil
+			}
+			if sc.Epoch < epoch {
+				return nil
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load shard conf of epoch %d for shard %s_%s: %w", epoch, partitionID, shardID.String(), err)
+	}
+	return shardConf, nil
+}
+
 /*
 ShardConfigs returns shard confs active in the given root round.
 */
@@ -142,6 +180,15 @@ func (o *Orchestration) ShardConfigs(rootRound uint64) (map[types.PartitionShard
 //   - The activation round number must be strictly greater than the current round of the only shard in the specified partition
 //   - The node identifiers must match their authentication keys
 func (o *Orchestration) AddShardConfig(shardConf *types.PartitionDescriptionRecord) error {
+	return o.AddApprovedShardConfig(shardConf, nil)
+}
+
+/*
+AddApprovedShardConfig verifies and stores the given shard conf (see AddShardConfig)
+together with the audit record of its approval. When approval is nil the shard conf
+is stored without the audit record.
+*/
+func (o *Orchestration) AddApprovedShardConfig(shardConf *types.PartitionDescriptionRecord, approval *Approval) error {
 	if shardConf.NetworkID != o.networkID {
 		return fmt.Errorf("invalid networkID %d, expected %d", shardConf.NetworkID, o.networkID)
 	}
@@ -152,6 +199,11 @@ func (o *Orchestration) AddShardConfig(shardConf *types.PartitionDescriptionReco
 		if err := storeShardConf(tx, shardConf); err != nil {
 			return fmt.Errorf("store shard conf: %w", err)
 		}
+		if approval != nil {
+			if err := storeApproval(tx, approval); err != nil {
+				return fmt.Errorf("store approval: %w", err)
+			}
+		}
 		return nil
 	})
 	if err != nil {
@@ -159,11 +211,49 @@ func (o *Orchestration) AddShardConfig(shardConf *types.PartitionDescriptionReco
 			shardConf.PartitionID, shardConf.Epoch), logger.Error(err))
 		return err
 	}
+	if approval != nil {
+		o.log.Info(fmt.Sprintf("Added shard config for partition %d, epoch %d, epoch start %d, approved by %v",
+			shardConf.PartitionID, shardConf.Epoch, shardConf.EpochStart, approval.Approvers))
+		return nil
+	}
 	o.log.Info(fmt.Sprintf("Added shard config for partition %d, epoch %d, epoch start %d",
 		shardConf.PartitionID, shardConf.Epoch, shardConf.EpochStart), logger.Error(err))
 	return err
 }
 
+/*
+Approvals returns the audit records of the approved shard confs of the shard, ordered by epoch.
+*/
+func (o *Orchestration) Approvals(partitionID types.PartitionID, shardID types.ShardID) ([]*Approval, error) {
+	var approvals []*Approval
+	err := o.db.View(func(tx *bolt.Tx) error {
+		approvalsBucket := tx.Bucket(approvalsBucketName)
+		if approvalsBucket == nil {
+			return nil
+		}
+		partitionBucket := approvalsBucket.Bucket(partitionID.Bytes())
+		if partitionBucket == nil {
+			return nil
+		}
+		shardBucket := partitionBucket.Bucket(shardID.Bytes())
+		if shardBucket == nil {
+			return nil
+		}
+		return shardBucket.ForEach(func(k, v []byte) error {
+			var approval *Approval
+			if err := json.Unmarshal(v, &approval); err != nil {
+				return fmt.Errorf("failed to unmarshal approval: %w", err)
+			}
+			approvals = append(approvals, approval)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read approvals of shard %s_%s: %w", partitionID, shardID.String(), err)
+	}
+	return approvals, nil
+}
+
 func (o *Orchestration) Close() error {
 	return o.db.Close()
 }
@@ -208,6 +298,27 @@ func storeShardConf(tx *bolt.Tx, shardConf *types.PartitionDescriptionRecord) er
 	return nil
 }
 
+// schema: approvals bucket -> partition buckets -> shard buckets -> epoch to approval
+func storeApproval(tx *bolt.Tx, approval *Approval) error {
+	approvalBytes, err := json.Marshal(approval)
+	if err != nil {
+		return fmt.Errorf("failed to marshal approval to json: %w", err)
+	}
+	approvalsBucket := tx.Bucket(approvalsBucketName)
+	if approvalsBucket == nil {
+		return fmt.Errorf("bucket %q does not exist", approvalsBucketName)
+	}
+	partitionBucket, err := approvalsBucket.CreateBucketIfNotExists(approval.PartitionID.Bytes())
+	if err != nil {
+		return fmt.Errorf("creating partition 0x%x bucket: %w", approval.PartitionID.Bytes(), err)
+	}
+	shardBucket, err := partitionBucket.CreateBucketIfNotExists(approval.ShardID.Bytes())
+	if err != nil {
+		return fmt.Errorf("creating shard 0x%x bucket: %w", approval.ShardID.Bytes(), err)
+	}
+	return shardBucket.Put(uint64ToKey(approval.Epoch), approvalBytes)
+}
+
 func verifyShardConf(tx *bolt.Tx, shardConf *types.PartitionDescriptionRecord) error {
 	if shardConf.Epoch == 0 {
 		return shardConf.IsValid()
@@ -217,6 +217,41 @@ func TestAddShardConfig_TestAddingShardConfForPreviousEpoch(t *testing.T) {
 	require.ErrorContains(t, o.AddShardConfig(shardConf3), "invalid epoch, provided 1 previous 1")
 }
 
+func TestShardConfigByEpoch(t *testing.T) {
+	partitionID := types.PartitionID(1)
+	shardID := types.ShardID{}
+	o, err := NewOrchestration(5, filepath.Join(t.TempDir(), "orchestration.db"), logger.New(t))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = o.db.Close() })
+
+	shardConf0 := createShardConf(t, partitionID, shardID, 1)
+	require.NoError(t, o.AddShardConfig(shardConf0))
+	shardConf1 := &types.PartitionDescriptionRecord{
+		NetworkID:   5,
+		PartitionID: partitionID,
+		ShardID:     shardID,
+		Epoch:       1,
+		EpochStart:  100,
+	}
+	require.NoError(t, o.AddShardConfig(shardConf1))
+
+	shardConf, err := o.ShardConfigByEpoch(partitionID, shardID, 0)
+	require.NoError(t, err)
+	require.Equal(t, shardConf0, shardConf)
+
+	shardConf, err = o.ShardConfigByEpoch(partitionID, shardID, 1)
+	require.NoError(t, err)
+	require.Equal(t, shardConf1, shardConf)
+
+	// unknown epoch and unknown shard
+	shardConf, err = o.ShardConfigByEpoch(partitionID, shardID, 2)
+	require.NoError(t, err)
+	require.Nil(t, shardConf)
+	shardConf, err = o.ShardConfigByEpoch(2, shardID, 0)
+	require.NoError(t, err)
+	require.Nil(t, shardConf)
+}
+
 func createShardConf(t *testing.T, partitionID types.PartitionID, shardID types.ShardID, epochStart uint64) *types.PartitionDescriptionRecord {
 	validator := testutils.NewTestNode(t)
 	return &types.PartitionDescriptionRecord{
@@ -6,6 +6,8 @@ import (
 	"crypto/sha256"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"sync"
 	"time"
 
@@ -16,6 +18,7 @@ import (
 	"github.com/alphabill-org/alphabill-go-base/types"
 	"github.com/alphabill-org/alphabill/network/protocol/certification"
 	"github.com/alphabill-org/alphabill/observability"
+	"github.com/alphabill-org/alphabill/rootchain/evidence"
 )
 
 type (
@@ -44,7 +47,7 @@ type (
 	// requestBuffer keeps track of received Certification Request
 	requestBuffer struct {
 		// index of nodes which have voted (key is node identifier)
-		nodeRequest map[string]struct{}
+		nodeRequest map[string]*certification.BlockCertificationRequest
 		// index to count votes, key is IR record hash
 		requests map[sha256Hash][]*certification.BlockCertificationRequest
 		qState   QuorumStatus
@@ -52,8 +55,19 @@ type (
 		start     time.Time
 		attrShard metric.MeasurementOption
 	}
+
+	// EquivocationError is returned when a node sends a request conflicting with
+	// the request it has already sent in this round
+	EquivocationError struct {
+		Previous *certification.BlockCertificationRequest
+		Request  *certification.BlockCertificationRequest
+	}
 )
 
+func (e *EquivocationError) Error() string {
+	return fmt.Sprintf("equivocating request from node %s for round %d", e.Request.NodeID, e.Request.IRRound())
+}
+
 const (
 	QuorumUnknown QuorumStatus = iota
 	QuorumInProgress
@@ -124,6 +138,17 @@ func (c *CertRequestBuffer) IsConsensusReceived(partition types.PartitionID, sha
 	return rs.qState
 }
 
+/*
+Participants returns IDs of the nodes which have sent certification request for
+the current round of the shard, ie since the last Clear call.
+*/
+func (c *CertRequestBuffer) Participants(partition types.PartitionID, shard types.ShardID) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	rs := c.get(partition, shard)
+	return slices.Collect(maps.Keys(rs.nodeRequest))
+}
+
 /*
 Clear clears node request in one shard - this must be called when the shard's Certification Request for a
 round has been processed in order for the buffer to accept requests for the next round.
@@ -171,7 +196,7 @@ func (c *CertRequestBuffer) updQuorumStatus(ctx context.Context, rb *requestBuff
 // newRequestStore creates a new empty requestBuffer.
 func newRequestStore() *requestBuffer {
 	s := &requestBuffer{
-		nodeRequest: make(map[string]struct{}),
+		nodeRequest: make(map[string]*certification.BlockCertificationRequest),
 		requests:    make(map[sha256Hash][]*certification.BlockCertificationRequest),
 		qState:      QuorumInProgress,
 	}
@@ -180,7 +205,14 @@ func newRequestStore() *requestBuffer {
 
 // add stores a new input record received from the node.
 func (rs *requestBuffer) add(req *certification.BlockCertificationRequest, tb QuorumInfo) (QuorumStatus, []*certification.BlockCertificationRequest, error) {
-	if _, f := rs.nodeRequest[req.NodeID]; f {
+	if prev, f := rs.nodeRequest[req.NodeID]; f {
+		conflicting, err := evidence.IsConflictingRequest(prev, req)
+		if err != nil {
+			return QuorumUnknown, nil, fmt.Errorf("comparing requests: %w", err)
+		}
+		if conflicting {
+			return QuorumUnknown, nil, &EquivocationError{Previous: prev, Request: req}
+		}
 		return QuorumUnknown, nil, errors.New("request of the node in this round already stored")
 	}
 	if len(rs.nodeRequest) == 0 {
@@ -194,7 +226,7 @@ func (rs *requestBuffer) add(req *certification.BlockCertificationRequest, tb Qu
 	}
 	reqID := sha256Hash(h)
 
-	rs.nodeRequest[req.NodeID] = struct{}{}
+	rs.nodeRequest[req.NodeID] = req
 	rs.requests[reqID] = append(rs.requests[reqID], req)
 	proof, res := rs.isConsensusReceived(tb)
 	return res, proof, nil
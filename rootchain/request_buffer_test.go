@@ -63,7 +63,10 @@ func Test_requestBuffer_add(t *testing.T) {
 		bcr2 := bcr
 		bcr2.BlockSize++
 		qs, r, err = rs.add(&bcr2, tb)
-		require.EqualError(t, err, `request of the node in this round already stored`)
+		var eqErr *EquivocationError
+		require.ErrorAs(t, err, &eqErr)
+		require.Equal(t, &bcr, eqErr.Previous)
+		require.Equal(t, &bcr2, eqErr.Request)
 		assert.Nil(t, r)
 		assert.Equal(t, QuorumUnknown, qs)
 		assert.Equal(t, 1, len(rs.nodeRequest))
@@ -0,0 +1,222 @@
+/*
+Package ucarchive implements the archive of the unicity certificates issued by the
+root chain.
+
+The block store of the root chain keeps only the last committed block so once a
+round is committed the certificates issued in it are available only from the
+shards. The archive keeps every issued certificate, indexed both by the shard
+round and by the root round, for the configured number of root rounds. When a
+shard round is certified more than once (repeat UC) all the certificates of the
+round are kept.
+*/
+package ucarchive
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill/keyvaluedb"
+)
+
+var (
+	// shard index: prefix | partition | len(shard key) | shard key | shard round | root round -> UC
+	shardPrefix = []byte("s")
+	// root round index: prefix | root round | partition | shard key -> key in the shard index
+	rootPrefix = []byte("r")
+)
+
+type Archive struct {
+	db keyvaluedb.KeyValueDB
+	// number of root rounds the certificates are kept for, zero means forever
+	retention uint64
+}
+
+/*
+New returns archive backed by "db". Certificates issued more than "retention"
+root rounds ago are deleted, when "retention" is zero the certificates are kept
+forever.
+*/
+func New(db keyvaluedb.KeyValueDB, retention uint64) (*Archive, error) {
+	if db == nil {
+		return nil, errors.New("archive database is nil")
+	}
+	return &Archive{db: db, retention: retention}, nil
+}
+
+/*
+Add stores the certificates and deletes the ones which have fallen out of the
+retention window.
+*/
+func (a *Archive) Add(ucs ...*types.UnicityCertificate) (rErr error) {
+	if len(ucs) == 0 {
+		return nil
+	}
+	var lastRootRound uint64
+	tx, err := a.db.StartTx()
+	if err != nil {
+		return fmt.Errorf("starting transaction: %w", err)
+	}
+	defer func() {
+		if rErr != nil {
+			rErr = errors.Join(rErr, tx.Rollback())
+		}
+	}()
+	for _, uc := range ucs {
+		if uc == nil || uc.UnicitySeal == nil || uc.InputRecord == nil {
+			return errors.New("invalid unicity certificate")
+		}
+		key := shardKey(uc.GetPartitionID(), uc.GetShardID(), uc.GetRoundNumber(), uc.GetRootRoundNumber())
+		if err := tx.Write(key, uc); err != nil {
+			return fmt.Errorf("storing certificate: %w", err)
+		}
+		if err := tx.Write(rootKey(uc.GetRootRoundNumber(), uc.GetPartitionID(), uc.GetShardID()), key); err != nil {
+			return fmt.Errorf("storing root round index: %w", err)
+		}
+		lastRootRound = max(lastRootRound, uc.GetRootRoundNumber())
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+
+	if a.retention > 0 && lastRootRound > a.retention {
+		if err := a.prune(lastRootRound - a.retention); err != nil {
+			return fmt.Errorf("deleting expired certificates: %w", err)
+		}
+	}
+	return nil
+}
+
+/*
+ShardCertificate returns the certificate of the shard round, nil when the round is
+not in the archive. When the round has been certified more than once (repeat UC)
+the latest certificate is returned.
+*/
+func (a *Archive) ShardCertificate(partition types.PartitionID, shard types.ShardID, round uint64) (*types.UnicityCertificate, error) {
+	ucs, err := a.ShardRoundCertificates(partition, shard, round)
+	if err != nil || len(ucs) == 0 {
+		return nil, err
+	}
+	return ucs[len(ucs)-1], nil
+}
+
+/*
+ShardRoundCertificates returns all the certificates of the shard round in the order
+of the root rounds they were issued in, more than one when the round has been
+certified repeatedly.
+*/
+func (a *Archive) ShardRoundCertificates(partition types.PartitionID, shard types.ShardID, round uint64) (_ []*types.UnicityCertificate, rErr error) {
+	prefix := shardRoundPrefix(partition, shard, round)
+	it := a.db.Find(prefix)
+	defer func() { rErr = errors.Join(rErr, it.Close()) }()
+
+	var ucs []*types.UnicityCertificate
+	for ; it.Valid() && bytes.HasPrefix(it.Key(), prefix); it.Next() {
+		uc := &types.UnicityCertificate{}
+		if err := it.Value(uc); err != nil {
+			return nil, fmt.Errorf("reading certificate: %w", err)
+		}
+		ucs = append(ucs, uc)
+	}
+	return ucs, nil
+}
+
+/*
+RootRoundCertificates returns the certificates issued in the root round.
+*/
+func (a *Archive) RootRoundCertificates(rootRound uint64) ([]*types.UnicityCertificate, error) {
+	prefix := binary.BigEndian.AppendUint64(bytes.Clone(rootPrefix), rootRound)
+	keys, err := a.indexEntries(prefix, func(key []byte) bool { return bytes.HasPrefix(key, prefix) })
+	if err != nil {
+		return nil, err
+	}
+
+	var ucs []*types.UnicityCertificate
+	for _, key := range keys {
+		uc := &types.UnicityCertificate{}
+		found, err := a.db.Read(key.shardKey, uc)
+		if err != nil {
+			return nil, fmt.Errorf("reading certificate: %w", err)
+		}
+		if found {
+			ucs = append(ucs, uc)
+		}
+	}
+	return ucs, nil
+}
+
+type indexEntry struct {
+	rootKey  []byte
+	shardKey []byte
+}
+
+/*
+indexEntries returns the root round index entries starting from key "from" for
+as long as "match" returns true.
+*/
+func (a *Archive) indexEntries(from []byte, match func(key []byte) bool) (_ []indexEntry, rErr error) {
+	it := a.db.Find(from)
+	defer func() { rErr = errors.Join(rErr, it.Close()) }()
+
+	var entries []indexEntry
+	for ; it.Valid() && match(it.Key()); it.Next() {
+		var key []byte
+		if err := it.Value(&key); err != nil {
+			return nil, fmt.Errorf("reading root round index: %w", err)
+		}
+		entries = append(entries, indexEntry{rootKey: bytes.Clone(it.Key()), shardKey: key})
+	}
+	return entries, nil
+}
+
+/*
+prune deletes the certificates issued before the root round "before".
+*/
+func (a *Archive) prune(before uint64) (rErr error) {
+	limit := binary.BigEndian.AppendUint64(bytes.Clone(rootPrefix), before)
+	entries, err := a.indexEntries(rootPrefix, func(key []byte) bool {
+		return bytes.HasPrefix(key, rootPrefix) && bytes.Compare(key, limit) < 0
+	})
+	if err != nil || len(entries) == 0 {
+		return err
+	}
+
+	tx, err := a.db.StartTx()
+	if err != nil {
+		return fmt.Errorf("starting transaction: %w", err)
+	}
+	defer func() {
+		if rErr != nil {
+			rErr = errors.Join(rErr, tx.Rollback())
+		}
+	}()
+	for _, e := range entries {
+		if err := tx.Delete(e.shardKey); err != nil {
+			return fmt.Errorf("deleting certificate: %w", err)
+		}
+		if err := tx.Delete(e.rootKey); err != nil {
+			return fmt.Errorf("deleting root round index: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+func shardKey(partition types.PartitionID, shard types.ShardID, round, rootRound uint64) []byte {
+	return binary.BigEndian.AppendUint64(shardRoundPrefix(partition, shard, round), rootRound)
+}
+
+func shardRoundPrefix(partition types.PartitionID, shard types.ShardID, round uint64) []byte {
+	key := binary.BigEndian.AppendUint32(bytes.Clone(shardPrefix), uint32(partition))
+	sk := shard.Key()
+	key = append(key, byte(len(sk)))
+	key = append(key, sk...)
+	return binary.BigEndian.AppendUint64(key, round)
+}
+
+func rootKey(rootRound uint64, partition types.PartitionID, shard types.ShardID) []byte {
+	key := binary.BigEndian.AppendUint64(bytes.Clone(rootPrefix), rootRound)
+	key = binary.BigEndian.AppendUint32(key, uint32(partition))
+	return append(key, shard.Key()...)
+}
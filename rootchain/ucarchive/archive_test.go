@@ -0,0 +1,121 @@
+package ucarchive
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill/keyvaluedb/memorydb"
+)
+
+func TestArchive(t *testing.T) {
+	db, err := memorydb.New()
+	require.NoError(t, err)
+	archive, err := New(db, 0)
+	require.NoError(t, err)
+
+	shard0, shard1 := types.ShardID{}.Split()
+	require.NoError(t, archive.Add(newUC(1, shard0, 5, 10), newUC(1, shard1, 3, 10), newUC(2, types.ShardID{}, 7, 10)))
+	require.NoError(t, archive.Add(newUC(1, shard0, 6, 11)))
+
+	uc, err := archive.ShardCertificate(1, shard0, 5)
+	require.NoError(t, err)
+	require.EqualValues(t, 5, uc.GetRoundNumber())
+	require.EqualValues(t, 10, uc.GetRootRoundNumber())
+
+	uc, err = archive.ShardCertificate(1, shard1, 5)
+	require.NoError(t, err)
+	require.Nil(t, uc)
+
+	ucs, err := archive.RootRoundCertificates(10)
+	require.NoError(t, err)
+	require.Len(t, ucs, 3)
+
+	ucs, err = archive.RootRoundCertificates(11)
+	require.NoError(t, err)
+	require.Len(t, ucs, 1)
+	require.EqualValues(t, 6, ucs[0].GetRoundNumber())
+
+	ucs, err = archive.RootRoundCertificates(12)
+	require.NoError(t, err)
+	require.Empty(t, ucs)
+
+	t.Run("repeat UC", func(t *testing.T) {
+		// shard round 6 is re-certified in root round 13
+		require.NoError(t, archive.Add(newUC(1, shard0, 6, 13)))
+		uc, err := archive.ShardCertificate(1, shard0, 6)
+		require.NoError(t, err)
+		require.EqualValues(t, 13, uc.GetRootRoundNumber())
+
+		// both certificates of the shard round are kept
+		ucs, err := archive.ShardRoundCertificates(1, shard0, 6)
+		require.NoError(t, err)
+		require.Len(t, ucs, 2)
+		require.EqualValues(t, 11, ucs[0].GetRootRoundNumber())
+		require.EqualValues(t, 13, ucs[1].GetRootRoundNumber())
+
+		ucs, err = archive.RootRoundCertificates(11)
+		require.NoError(t, err)
+		require.Len(t, ucs, 1)
+		require.EqualValues(t, 6, ucs[0].GetRoundNumber())
+	})
+
+	require.EqualError(t, archive.Add(&types.UnicityCertificate{}), "invalid unicity certificate")
+
+	_, err = New(nil, 0)
+	require.EqualError(t, err, "archive database is nil")
+}
+
+func TestArchive_Retention(t *testing.T) {
+	db, err := memorydb.New()
+	require.NoError(t, err)
+	archive, err := New(db, 2)
+	require.NoError(t, err)
+
+	for rootRound := uint64(1); rootRound <= 5; rootRound++ {
+		require.NoError(t, archive.Add(newUC(1, types.ShardID{}, rootRound, rootRound)))
+	}
+	// root rounds 3, 4 and 5 are kept
+	for round := uint64(1); round <= 5; round++ {
+		uc, err := archive.ShardCertificate(1, types.ShardID{}, round)
+		require.NoError(t, err)
+		ucs, err := archive.RootRoundCertificates(round)
+		require.NoError(t, err)
+		if round < 3 {
+			require.Nil(t, uc, "round %d", round)
+			require.Empty(t, ucs, "round %d", round)
+		} else {
+			require.NotNil(t, uc, "round %d", round)
+			require.Len(t, ucs, 1, "round %d", round)
+		}
+	}
+
+	// shard round 3 re-certified in root round 6, the certificate must survive pruning of root round 3
+	require.NoError(t, archive.Add(newUC(1, types.ShardID{}, 3, 6)))
+	require.NoError(t, archive.Add(newUC(1, types.ShardID{}, 4, 7)))
+	uc, err := archive.ShardCertificate(1, types.ShardID{}, 3)
+	require.NoError(t, err)
+	require.EqualValues(t, 6, uc.GetRootRoundNumber())
+	ucs, err := archive.ShardRoundCertificates(1, types.ShardID{}, 3)
+	require.NoError(t, err)
+	require.Len(t, ucs, 1)
+}
+
+func newUC(partition types.PartitionID, shard types.ShardID, round, rootRound uint64) *types.UnicityCertificate {
+	return &types.UnicityCertificate{
+		Version:     1,
+		InputRecord: &types.InputRecord{Version: 1, RoundNumber: round},
+		UnicityTreeCertificate: &types.UnicityTreeCertificate{
+			Version:   1,
+			Partition: partition,
+		},
+		ShardTreeCertificate: types.ShardTreeCertificate{
+			Shard: shard,
+		},
+		UnicitySeal: &types.UnicitySeal{
+			Version:              1,
+			RootChainRoundNumber: rootRound,
+		},
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/alphabill-org/alphabill-go-base/types"
 	"github.com/alphabill-org/alphabill/logger"
 	"github.com/alphabill-org/alphabill/network"
+	"github.com/alphabill-org/alphabill/observability"
 )
 
 type (
@@ -47,7 +48,7 @@ func NewAdminAPI(node partitionNode, self *network.Peer, obs Observability) *Adm
 		node:       node,
 		self:       self,
 		log:        obs.Logger(),
-		updMetrics: metricsUpdater(obs.Meter(metricsScopeJRPCAPI), node, obs.Logger()),
+		updMetrics: metricsUpdater(obs.Meter(metricsScopeJRPCAPI), observability.Shard(node.PartitionID(), node.ShardID()), obs.Logger()),
 	}
 }
 
@@ -15,7 +15,7 @@ import (
 	"github.com/alphabill-org/alphabill/observability"
 )
 
-func metricsUpdater(mtr metric.Meter, node partitionNode, log *slog.Logger) func(ctx context.Context, method string, start time.Time, apiErr error) {
+func metricsUpdater(mtr metric.Meter, fixedAttr metric.MeasurementOption, log *slog.Logger) func(ctx context.Context, method string, start time.Time, apiErr error) {
 	callCnt, err := mtr.Int64Counter("calls", metric.WithDescription("How many times the endpoint has been called"))
 	if err != nil {
 		log.Error("creating calls counter", logger.Error(err))
@@ -30,7 +30,6 @@ func metricsUpdater(mtr metric.Meter, node partitionNode, log *slog.Logger) func
 		return func(context.Context, string, time.Time, error) { /* NOP */ }
 	}
 
-	fixedAttr := observability.Shard(node.PartitionID(), node.ShardID())
 	statusOK := attribute.String("status", "ok")
 	statusErr := attribute.String("status", "err")
 
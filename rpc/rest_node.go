@@ -9,16 +9,26 @@ import (
 	"github.com/alphabill-org/alphabill-go-base/cbor"
 	"github.com/alphabill-org/alphabill-go-base/types"
 	"github.com/alphabill-org/alphabill/logger"
+	"github.com/alphabill-org/alphabill/rootchain/partitions"
 	"github.com/gorilla/mux"
 )
 
-func NodeEndpoints(node partitionNode, obs Observability) RegistrarFunc {
+/*
+NodeEndpoints registers the REST endpoints of the shard node. When governance is not
+nil the shard conf changes must be signed by the quorum of the governance approvers,
+otherwise the unsigned shard conf changes are accepted only when allowUnsigned is set.
+*/
+func NodeEndpoints(node partitionNode, governance *partitions.Governance, allowUnsigned bool, obs Observability) RegistrarFunc {
 	return func(r *mux.Router) {
 		log := obs.Logger()
 
 		// get the state file
 		r.HandleFunc("/state", getState(node, log)).Methods("GET")
-		r.HandleFunc("/configurations", putShardConf(node.RegisterShardConf)).Methods("PUT")
+		if governance != nil {
+			r.HandleFunc("/configurations", putSignedShardConf(governance, node.RegisterShardConf, log)).Methods("PUT")
+		} else if allowUnsigned {
+			r.HandleFunc("/configurations", putShardConf(node.RegisterShardConf)).Methods("PUT")
+		}
 	}
 }
 
@@ -57,3 +67,36 @@ func putShardConf(registerShardConf func(shardConf *types.PartitionDescriptionRe
 		w.WriteHeader(http.StatusOK)
 	}
 }
+
+/*
+putSignedShardConf registers the shard conf approved by the quorum of the governance
+approvers. Unlike the root node, the shard node doesn't keep the audit records of the
+approvals, the approvers are only written to the log. The shard conf takes effect only
+when the root chain certifies the epoch change, the root nodes persist the approvals of
+the signed shard confs submitted to them (see root_getShardConfApprovals).
+*/
+func putSignedShardConf(governance *partitions.Governance, registerShardConf func(shardConf *types.PartitionDescriptionRecord) error, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, request *http.Request) {
+		defer request.Body.Close()
+		signedShardConf := &partitions.SignedShardConf{}
+		if err := json.NewDecoder(request.Body).Decode(signedShardConf); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "failed to parse signed shard conf: %v", err)
+			return
+		}
+		approval, err := governance.Verify(signedShardConf)
+		if err != nil {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprintf(w, "shard conf not approved: %v", err)
+			return
+		}
+		if err := registerShardConf(signedShardConf.ShardConf); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, "failed to register shard conf: %v", err)
+			return
+		}
+		log.Info(fmt.Sprintf("registered shard conf of partition %s, epoch %d, hash %X, approved by %v",
+			approval.PartitionID, approval.Epoch, []byte(approval.ShardConfHash), approval.Approvers))
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -18,7 +18,7 @@ func TestRESTServer_GetState_Ok(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/state", bytes.NewReader([]byte{}))
 	recorder := httptest.NewRecorder()
-	NewRESTServer("", 10, obs, NodeEndpoints(node, obs)).Handler.ServeHTTP(recorder, req)
+	NewRESTServer("", 10, obs, NodeEndpoints(node, nil, false, obs)).Handler.ServeHTTP(recorder, req)
 	require.Equal(t, http.StatusOK, recorder.Result().StatusCode)
 }
 
@@ -28,7 +28,23 @@ func TestRESTServer_GetState_Error(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/state", bytes.NewReader([]byte{}))
 	recorder := httptest.NewRecorder()
-	NewRESTServer("", 10, obs, NodeEndpoints(node, obs)).Handler.ServeHTTP(recorder, req)
+	NewRESTServer("", 10, obs, NodeEndpoints(node, nil, false, obs)).Handler.ServeHTTP(recorder, req)
 	require.Equal(t, http.StatusInternalServerError, recorder.Result().StatusCode)
 	require.Contains(t, recorder.Body.String(), "state error")
 }
+
+func TestRESTServer_PutShardConf_Unsigned(t *testing.T) {
+	node := &MockNode{txs: &testtxsystem.CounterTxSystem{}}
+	obs := observability.Default(t)
+
+	// unsigned shard conf changes are disabled by default
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/configurations", bytes.NewBufferString(`{}`))
+	recorder := httptest.NewRecorder()
+	NewRESTServer("", 10, obs, NodeEndpoints(node, nil, false, obs)).Handler.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusNotFound, recorder.Result().StatusCode)
+
+	req = httptest.NewRequest(http.MethodPut, "/api/v1/configurations", bytes.NewBufferString(`{}`))
+	recorder = httptest.NewRecorder()
+	NewRESTServer("", 10, obs, NodeEndpoints(node, nil, true, obs)).Handler.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Result().StatusCode)
+}
@@ -0,0 +1,527 @@
+package rpc
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/alphabill-org/alphabill-go-base/cbor"
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill-go-base/types/hex"
+	"github.com/alphabill-org/alphabill/network/protocol/abdrc"
+	"github.com/alphabill-org/alphabill/network/protocol/certification"
+	"github.com/alphabill-org/alphabill/partition"
+	"github.com/alphabill-org/alphabill/rootchain/consensus/storage"
+	rctypes "github.com/alphabill-org/alphabill/rootchain/consensus/types"
+	"github.com/alphabill-org/alphabill/rootchain/epochstats"
+	"github.com/alphabill-org/alphabill/rootchain/evidence"
+	"github.com/alphabill-org/alphabill/rootchain/liveness"
+	"github.com/alphabill-org/alphabill/rootchain/partitions"
+)
+
+type (
+	// RootAPI is the JSON-RPC API of the root node.
+	RootAPI struct {
+		node         rootNode
+		trustBases   trustBaseLoader
+		ucArchive    certificateArchive
+		blockArchive blockArchive
+		liveness     livenessMonitor
+		epochStats   epochStatsStore
+		approvals    approvalStore
+		evidence     evidenceStore
+
+		updMetrics func(ctx context.Context, method string, start time.Time, apiErr error)
+	}
+
+	rootNode interface {
+		ShardInfo(partition types.PartitionID, shard types.ShardID) (*storage.ShardInfo, error)
+		GetState() (*abdrc.StateMsg, error)
+	}
+
+	trustBaseLoader interface {
+		LoadTrustBase(epochNumber uint64) (types.RootTrustBase, error)
+	}
+
+	certificateArchive interface {
+		ShardCertificate(partition types.PartitionID, shard types.ShardID, round uint64) (*types.UnicityCertificate, error)
+		RootRoundCertificates(rootRound uint64) ([]*types.UnicityCertificate, error)
+	}
+
+	blockArchive interface {
+		Block(round uint64) (*storage.ExecutedBlock, error)
+		Blocks(from uint64, limit int) ([]*storage.ExecutedBlock, error)
+		TimeoutCert(round uint64) (*rctypes.TimeoutCert, error)
+	}
+
+	livenessMonitor interface {
+		ShardStatus(partition types.PartitionID, shard types.ShardID) *liveness.ShardStatus
+		Shards() []*liveness.ShardStatus
+	}
+
+	epochStatsStore interface {
+		Summary(partition types.PartitionID, shard types.ShardID, epoch uint64) (*epochstats.Summary, error)
+	}
+
+	approvalStore interface {
+		Approvals(partition types.PartitionID, shard types.ShardID) ([]*partitions.Approval, error)
+	}
+
+	evidenceStore interface {
+		Find(filter evidence.Filter) ([]*evidence.Evidence, error)
+	}
+
+	ShardInfoResponse struct {
+		PartitionID     types.PartitionID             `json:"partitionId"`
+		ShardID         types.ShardID                 `json:"shardId"`
+		EpochNumber     hex.Uint64                    `json:"epochNumber"`
+		InputRecord     *types.InputRecord            `json:"inputRecord"`
+		TechnicalRecord certification.TechnicalRecord `json:"technicalRecord"`
+		// per validator summary fees of the current epoch
+		Fees map[string]hex.Uint64 `json:"fees"`
+		// timestamp of the unicity seal of the last certificate sent to the shard
+		LastCertificationTime hex.Uint64 `json:"lastCertificationTime"`
+	}
+
+	ValidatorStatsResponse struct {
+		PartitionID types.PartitionID `json:"partitionId"`
+		ShardID     types.ShardID     `json:"shardId"`
+		// fees and statistics of the current and the previous epoch,
+		// previous is nil when the shard is in its first epoch
+		CurrentEpoch  *EpochStats `json:"currentEpoch"`
+		PreviousEpoch *EpochStats `json:"previousEpoch"`
+	}
+
+	EpochStats struct {
+		EpochNumber hex.Uint64 `json:"epochNumber"`
+		// per validator summary fees of the epoch
+		Fees map[string]hex.Uint64           `json:"fees"`
+		Stat certification.StatisticalRecord `json:"stat"`
+	}
+
+	// EpochRewards is the proof of the fees the validators of the shard earned during the
+	// epoch, the content of the money partition transaction distributing the rewards.
+	EpochRewards struct {
+		EpochNumber hex.Uint64 `json:"epochNumber"`
+		// certificate of the last round of the epoch
+		Certificate *certification.CertificationResponse `json:"certificate"`
+		// shard conf of the epoch
+		ShardConf *types.PartitionDescriptionRecord `json:"shardConf"`
+		// per validator summary fees of the epoch and the fee list of the next epoch
+		Fees          map[string]hex.Uint64 `json:"fees"`
+		NextEpochFees map[string]hex.Uint64 `json:"nextEpochFees"`
+	}
+
+	RootBlockInfo struct {
+		Round     hex.Uint64 `json:"round"`
+		Epoch     hex.Uint64 `json:"epoch"`
+		Timestamp hex.Uint64 `json:"timestamp"`
+		Leader    string     `json:"leader"` // author of the block
+		RootHash  hex.Bytes  `json:"rootHash"`
+		// QC of the parent block
+		ParentQc *rctypes.QuorumCert `json:"parentQc"`
+		Qc       *rctypes.QuorumCert `json:"qc"`
+		CommitQc *rctypes.QuorumCert `json:"commitQc"`
+		// TC of the previous round when the previous round timed out
+		TimeoutCert      *rctypes.TimeoutCert   `json:"timeoutCert,omitempty"`
+		IRChangeRequests []*rctypes.IRChangeReq `json:"irChangeRequests"`
+		// shards certified by committing the block
+		CertifiedShards []CertifiedShard `json:"certifiedShards"`
+	}
+
+	CertifiedShard struct {
+		PartitionID types.PartitionID `json:"partitionId"`
+		ShardID     types.ShardID     `json:"shardId"`
+		RoundNumber hex.Uint64        `json:"roundNumber"`
+	}
+)
+
+// maximum number of blocks returned by getRootBlocks
+const maxRootBlocksPageSize = 100
+
+func NewRootAPI(node rootNode, trustBases trustBaseLoader, obs Observability, opts ...RootAPIOption) *RootAPI {
+	options := &RootAPIOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return &RootAPI{
+		node:         node,
+		trustBases:   trustBases,
+		ucArchive:    options.ucArchive,
+		blockArchive: options.blockArchive,
+		liveness:     options.liveness,
+		epochStats:   options.epochStats,
+		approvals:    options.approvals,
+		evidence:     options.evidence,
+		updMetrics:   metricsUpdater(obs.Meter(metricsScopeJRPCAPI), metric.WithAttributes(), obs.Logger()),
+	}
+}
+
+// GetRootRound returns the latest committed round and epoch of the root chain.
+func (s *RootAPI) GetRootRound(ctx context.Context) (_ *partition.RoundInfo, retErr error) {
+	defer func(start time.Time) { s.updMetrics(ctx, "getRootRound", start, retErr) }(time.Now())
+	state, err := s.node.GetState()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load state: %w", err)
+	}
+	if state.CommittedHead == nil || state.CommittedHead.Block == nil {
+		return nil, errors.New("committed block is missing")
+	}
+	return &partition.RoundInfo{
+		RoundNumber: state.CommittedHead.Block.Round,
+		EpochNumber: state.CommittedHead.Block.Epoch,
+	}, nil
+}
+
+// GetUnicityCertificate returns the latest unicity certificate (hex encoded CBOR) of the shard.
+func (s *RootAPI) GetUnicityCertificate(ctx context.Context, partitionID types.PartitionID, shardID types.ShardID) (_ hex.Bytes, retErr error) {
+	defer func(start time.Time) { s.updMetrics(ctx, "getUnicityCertificate", start, retErr) }(time.Now())
+	si, err := s.node.ShardInfo(partitionID, shardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load shard info: %w", err)
+	}
+	if si.LastCR == nil {
+		return nil, nil
+	}
+	ucCbor, err := cbor.Marshal(&si.LastCR.UC)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode unicity certificate: %w", err)
+	}
+	return ucCbor, nil
+}
+
+// GetUnicityCertificateByRound returns the archived unicity certificate (hex encoded CBOR) of the shard round.
+func (s *RootAPI) GetUnicityCertificateByRound(ctx context.Context, partitionID types.PartitionID, shardID types.ShardID, roundNumber hex.Uint64) (_ hex.Bytes, retErr error) {
+	defer func(start time.Time) { s.updMetrics(ctx, "getUnicityCertificateByRound", start, retErr) }(time.Now())
+	if s.ucArchive == nil {
+		return nil, errors.New("unicity certificate archive is not enabled")
+	}
+	uc, err := s.ucArchive.ShardCertificate(partitionID, shardID, uint64(roundNumber))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load unicity certificate: %w", err)
+	}
+	if uc == nil {
+		return nil, nil
+	}
+	ucCbor, err := cbor.Marshal(uc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode unicity certificate: %w", err)
+	}
+	return ucCbor, nil
+}
+
+// GetRootRoundCertificates returns the archived unicity certificates (hex encoded CBOR) issued in the root round.
+func (s *RootAPI) GetRootRoundCertificates(ctx context.Context, rootRound hex.Uint64) (_ []hex.Bytes, retErr error) {
+	defer func(start time.Time) { s.updMetrics(ctx, "getRootRoundCertificates", start, retErr) }(time.Now())
+	if s.ucArchive == nil {
+		return nil, errors.New("unicity certificate archive is not enabled")
+	}
+	ucs, err := s.ucArchive.RootRoundCertificates(uint64(rootRound))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load unicity certificates: %w", err)
+	}
+	res := make([]hex.Bytes, 0, len(ucs))
+	for _, uc := range ucs {
+		ucCbor, err := cbor.Marshal(uc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode unicity certificate: %w", err)
+		}
+		res = append(res, ucCbor)
+	}
+	return res, nil
+}
+
+// GetShardInfo returns the certification state of the shard.
+func (s *RootAPI) GetShardInfo(ctx context.Context, partitionID types.PartitionID, shardID types.ShardID) (_ *ShardInfoResponse, retErr error) {
+	defer func(start time.Time) { s.updMetrics(ctx, "getShardInfo", start, retErr) }(time.Now())
+	si, err := s.node.ShardInfo(partitionID, shardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load shard info: %w", err)
+	}
+	rsp := &ShardInfoResponse{
+		PartitionID:     si.PartitionID,
+		ShardID:         si.ShardID,
+		EpochNumber:     hex.Uint64(si.TR.Epoch),
+		InputRecord:     si.IR,
+		TechnicalRecord: si.TR,
+		Fees:            make(map[string]hex.Uint64, len(si.Fees)),
+	}
+	for nodeID, fee := range si.Fees {
+		rsp.Fees[nodeID] = hex.Uint64(fee)
+	}
+	if si.LastCR != nil && si.LastCR.UC.UnicitySeal != nil {
+		rsp.LastCertificationTime = hex.Uint64(si.LastCR.UC.UnicitySeal.Timestamp)
+	}
+	return rsp, nil
+}
+
+/*
+GetValidatorStats returns the per validator fees and the statistical record of the
+current and the previous epoch of the shard.
+*/
+func (s *RootAPI) GetValidatorStats(ctx context.Context, partitionID types.PartitionID, shardID types.ShardID) (_ *ValidatorStatsResponse, retErr error) {
+	defer func(start time.Time) { s.updMetrics(ctx, "getValidatorStats", start, retErr) }(time.Now())
+	si, err := s.node.ShardInfo(partitionID, shardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load shard info: %w", err)
+	}
+	rsp := &ValidatorStatsResponse{
+		PartitionID:  si.PartitionID,
+		ShardID:      si.ShardID,
+		CurrentEpoch: newEpochStats(si.TR.Epoch, si.Fees, si.Stat),
+	}
+	if si.TR.Epoch > 0 {
+		var fees map[string]uint64
+		if err := cbor.Unmarshal(si.PrevEpochFees, &fees); err != nil {
+			return nil, fmt.Errorf("decoding previous epoch fees: %w", err)
+		}
+		var stat certification.StatisticalRecord
+		if err := cbor.Unmarshal(si.PrevEpochStat, &stat); err != nil {
+			return nil, fmt.Errorf("decoding previous epoch statistics: %w", err)
+		}
+		rsp.PreviousEpoch = newEpochStats(si.TR.Epoch-1, fees, stat)
+	}
+	return rsp, nil
+}
+
+/*
+GetEpochStats returns the per validator fees and the statistical record of the
+completed epoch of the shard, nil when the epoch hasn't been completed yet.
+*/
+func (s *RootAPI) GetEpochStats(ctx context.Context, partitionID types.PartitionID, shardID types.ShardID, epochNumber hex.Uint64) (_ *EpochStats, retErr error) {
+	defer func(start time.Time) { s.updMetrics(ctx, "getEpochStats", start, retErr) }(time.Now())
+	if s.epochStats == nil {
+		return nil, errors.New("epoch stats store is not enabled")
+	}
+	summary, err := s.epochStats.Summary(partitionID, shardID, uint64(epochNumber))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load epoch summary: %w", err)
+	}
+	if summary == nil {
+		return nil, nil
+	}
+	return newEpochStats(summary.Epoch, summary.Fees, summary.Stat), nil
+}
+
+/*
+GetEpochRewards returns the proof of the fees the validators earned during the completed
+epoch of the shard, nil when the epoch hasn't been completed yet.
+*/
+func (s *RootAPI) GetEpochRewards(ctx context.Context, partitionID types.PartitionID, shardID types.ShardID, epochNumber hex.Uint64) (_ *EpochRewards, retErr error) {
+	defer func(start time.Time) { s.updMetrics(ctx, "getEpochRewards", start, retErr) }(time.Now())
+	if s.epochStats == nil {
+		return nil, errors.New("epoch stats store is not enabled")
+	}
+	summary, err := s.epochStats.Summary(partitionID, shardID, uint64(epochNumber))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load epoch summary: %w", err)
+	}
+	if summary == nil {
+		return nil, nil
+	}
+	if summary.Certificate == nil || summary.ShardConf == nil {
+		return nil, fmt.Errorf("proof of the epoch %d fees is not available", epochNumber)
+	}
+	return &EpochRewards{
+		EpochNumber:   hex.Uint64(summary.Epoch),
+		Certificate:   summary.Certificate,
+		ShardConf:     summary.ShardConf,
+		Fees:          hexFees(summary.Fees),
+		NextEpochFees: hexFees(summary.NextEpochFees),
+	}, nil
+}
+
+// GetTrustBase returns the root trust base of the given epoch.
+func (s *RootAPI) GetTrustBase(ctx context.Context, epochNumber hex.Uint64) (_ types.RootTrustBase, retErr error) {
+	defer func(start time.Time) { s.updMetrics(ctx, "getTrustBase", start, retErr) }(time.Now())
+	trustBase, err := s.trustBases.LoadTrustBase(uint64(epochNumber))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load trust base: %w", err)
+	}
+	if trustBase == nil {
+		return nil, fmt.Errorf("trust base for epoch %d not found", epochNumber)
+	}
+	return trustBase, nil
+}
+
+// GetRootBlock returns the committed root block of the round, nil when the round has no committed block.
+func (s *RootAPI) GetRootBlock(ctx context.Context, roundNumber hex.Uint64) (_ *RootBlockInfo, retErr error) {
+	defer func(start time.Time) { s.updMetrics(ctx, "getRootBlock", start, retErr) }(time.Now())
+	if s.blockArchive == nil {
+		return nil, errors.New("root block archive is not enabled")
+	}
+	block, err := s.blockArchive.Block(uint64(roundNumber))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load root block: %w", err)
+	}
+	if block == nil {
+		return nil, nil
+	}
+	return s.rootBlockInfo(block)
+}
+
+// GetRootBlocks returns up to "limit" committed root blocks starting from the round "fromRound".
+func (s *RootAPI) GetRootBlocks(ctx context.Context, fromRound hex.Uint64, limit int) (_ []*RootBlockInfo, retErr error) {
+	defer func(start time.Time) { s.updMetrics(ctx, "getRootBlocks", start, retErr) }(time.Now())
+	if s.blockArchive == nil {
+		return nil, errors.New("root block archive is not enabled")
+	}
+	if limit <= 0 || limit > maxRootBlocksPageSize {
+		limit = maxRootBlocksPageSize
+	}
+	blocks, err := s.blockArchive.Blocks(uint64(fromRound), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load root blocks: %w", err)
+	}
+	res := make([]*RootBlockInfo, 0, len(blocks))
+	for _, block := range blocks {
+		info, err := s.rootBlockInfo(block)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, info)
+	}
+	return res, nil
+}
+
+// GetTimeoutCertificate returns the timeout certificate of the round, nil when the round didn't time out.
+func (s *RootAPI) GetTimeoutCertificate(ctx context.Context, roundNumber hex.Uint64) (_ *rctypes.TimeoutCert, retErr error) {
+	defer func(start time.Time) { s.updMetrics(ctx, "getTimeoutCertificate", start, retErr) }(time.Now())
+	if s.blockArchive == nil {
+		return nil, errors.New("root block archive is not enabled")
+	}
+	tc, err := s.blockArchive.TimeoutCert(uint64(roundNumber))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load timeout certificate: %w", err)
+	}
+	return tc, nil
+}
+
+/*
+GetShardLiveness returns the liveness status of the shard (progress, T2 timeouts and
+validators not sending certification requests), nil when the root node hasn't issued
+certificates to the shard since it was started.
+*/
+func (s *RootAPI) GetShardLiveness(ctx context.Context, partitionID types.PartitionID, shardID types.ShardID) (_ *liveness.ShardStatus, retErr error) {
+	defer func(start time.Time) { s.updMetrics(ctx, "getShardLiveness", start, retErr) }(time.Now())
+	if s.liveness == nil {
+		return nil, errors.New("liveness monitor is not enabled")
+	}
+	return s.liveness.ShardStatus(partitionID, shardID), nil
+}
+
+// GetLiveness returns the liveness status of all the shards.
+func (s *RootAPI) GetLiveness(ctx context.Context) (_ []*liveness.ShardStatus, retErr error) {
+	defer func(start time.Time) { s.updMetrics(ctx, "getLiveness", start, retErr) }(time.Now())
+	if s.liveness == nil {
+		return nil, errors.New("liveness monitor is not enabled")
+	}
+	return s.liveness.Shards(), nil
+}
+
+/*
+GetShardConfApprovals returns the audit records of the approved shard conf changes of
+the shard: the epoch, the hash of the shard conf and the approvers who signed it.
+*/
+func (s *RootAPI) GetShardConfApprovals(ctx context.Context, partitionID types.PartitionID, shardID types.ShardID) (_ []*partitions.Approval, retErr error) {
+	defer func(start time.Time) { s.updMetrics(ctx, "getShardConfApprovals", start, retErr) }(time.Now())
+	if s.approvals == nil {
+		return nil, errors.New("shard conf approvals are not enabled")
+	}
+	return s.approvals.Approvals(partitionID, shardID)
+}
+
+/*
+GetEvidence returns the detected equivocation evidence, ordered by kind, partition and
+round. The optional parameters filter the evidence by kind (1 - certification request,
+2 - vote), partition and round.
+*/
+func (s *RootAPI) GetEvidence(ctx context.Context, kind *evidence.Kind, partitionID *types.PartitionID, roundNumber *hex.Uint64) (_ []*evidence.Evidence, retErr error) {
+	defer func(start time.Time) { s.updMetrics(ctx, "getEvidence", start, retErr) }(time.Now())
+	if s.evidence == nil {
+		return nil, errors.New("evidence store is not enabled")
+	}
+	var filter evidence.Filter
+	if kind != nil {
+		filter.Kind = *kind
+	}
+	if partitionID != nil {
+		filter.Partition = *partitionID
+	}
+	if roundNumber != nil {
+		filter.Round = uint64(*roundNumber)
+	}
+	items, err := s.evidence.Find(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load evidence: %w", err)
+	}
+	if items == nil {
+		items = []*evidence.Evidence{}
+	}
+	return items, nil
+}
+
+func newEpochStats(epoch uint64, fees map[string]uint64, stat certification.StatisticalRecord) *EpochStats {
+	return &EpochStats{
+		EpochNumber: hex.Uint64(epoch),
+		Fees:        hexFees(fees),
+		Stat:        stat,
+	}
+}
+
+func hexFees(fees map[string]uint64) map[string]hex.Uint64 {
+	res := make(map[string]hex.Uint64, len(fees))
+	for nodeID, fee := range fees {
+		res[nodeID] = hex.Uint64(fee)
+	}
+	return res
+}
+
+func (s *RootAPI) rootBlockInfo(block *storage.ExecutedBlock) (*RootBlockInfo, error) {
+	bd := block.BlockData
+	info := &RootBlockInfo{
+		Round:           hex.Uint64(bd.Round),
+		Epoch:           hex.Uint64(bd.Epoch),
+		Timestamp:       hex.Uint64(bd.Timestamp),
+		Leader:          bd.Author,
+		RootHash:        block.RootHash,
+		ParentQc:        bd.Qc,
+		Qc:              block.Qc,
+		CommitQc:        block.CommitQc,
+		CertifiedShards: make([]CertifiedShard, 0, len(block.ShardState.Changed)),
+	}
+	if bd.Payload != nil {
+		info.IRChangeRequests = bd.Payload.Requests
+	}
+	// the previous round timed out when the parent block is not of the previous round
+	if bd.Round > 1 && bd.Qc.GetRound() != bd.Round-1 {
+		tc, err := s.blockArchive.TimeoutCert(bd.Round - 1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load timeout certificate: %w", err)
+		}
+		info.TimeoutCert = tc
+	}
+	for psID := range block.ShardState.Changed {
+		si, ok := block.ShardState.States[psID]
+		if !ok {
+			continue
+		}
+		cs := CertifiedShard{PartitionID: si.PartitionID, ShardID: si.ShardID}
+		if si.IR != nil {
+			cs.RoundNumber = hex.Uint64(si.IR.RoundNumber)
+		}
+		info.CertifiedShards = append(info.CertifiedShards, cs)
+	}
+	slices.SortFunc(info.CertifiedShards, func(a, b CertifiedShard) int {
+		if c := cmp.Compare(a.PartitionID, b.PartitionID); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ShardID.Key(), b.ShardID.Key())
+	})
+	return info, nil
+}
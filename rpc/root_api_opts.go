@@ -0,0 +1,56 @@
+package rpc
+
+type (
+	RootAPIOptions struct {
+		ucArchive    certificateArchive
+		blockArchive blockArchive
+		liveness     livenessMonitor
+		epochStats   epochStatsStore
+		approvals    approvalStore
+		evidence     evidenceStore
+	}
+
+	RootAPIOption func(*RootAPIOptions)
+)
+
+// WithUCArchive enables the methods returning the historical unicity certificates.
+func WithUCArchive(archive certificateArchive) RootAPIOption {
+	return func(c *RootAPIOptions) {
+		c.ucArchive = archive
+	}
+}
+
+// WithBlockArchive enables the methods returning the history of the root chain.
+func WithBlockArchive(archive blockArchive) RootAPIOption {
+	return func(c *RootAPIOptions) {
+		c.blockArchive = archive
+	}
+}
+
+// WithLivenessMonitor enables the methods returning the liveness status of the shards.
+func WithLivenessMonitor(monitor livenessMonitor) RootAPIOption {
+	return func(c *RootAPIOptions) {
+		c.liveness = monitor
+	}
+}
+
+// WithEpochStats enables the methods returning the fees and statistics of the completed shard epochs.
+func WithEpochStats(store epochStatsStore) RootAPIOption {
+	return func(c *RootAPIOptions) {
+		c.epochStats = store
+	}
+}
+
+// WithShardConfApprovals enables the method returning the audit records of the approved shard conf changes.
+func WithShardConfApprovals(store approvalStore) RootAPIOption {
+	return func(c *RootAPIOptions) {
+		c.approvals = store
+	}
+}
+
+// WithEvidenceStore enables the method returning the detected equivocation evidence.
+func WithEvidenceStore(store evidenceStore) RootAPIOption {
+	return func(c *RootAPIOptions) {
+		c.evidence = store
+	}
+}
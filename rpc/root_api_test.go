@@ -0,0 +1,406 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/cbor"
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill-go-base/types/hex"
+	testobservability "github.com/alphabill-org/alphabill/internal/testutils/observability"
+	testsig "github.com/alphabill-org/alphabill/internal/testutils/sig"
+	"github.com/alphabill-org/alphabill/internal/testutils/trustbase"
+	"github.com/alphabill-org/alphabill/keyvaluedb/memorydb"
+	"github.com/alphabill-org/alphabill/network/protocol/abdrc"
+	"github.com/alphabill-org/alphabill/network/protocol/certification"
+	"github.com/alphabill-org/alphabill/rootchain/blockarchive"
+	"github.com/alphabill-org/alphabill/rootchain/consensus/storage"
+	rctypes "github.com/alphabill-org/alphabill/rootchain/consensus/types"
+	"github.com/alphabill-org/alphabill/rootchain/epochstats"
+	"github.com/alphabill-org/alphabill/rootchain/evidence"
+	"github.com/alphabill-org/alphabill/rootchain/liveness"
+	"github.com/alphabill-org/alphabill/rootchain/ucarchive"
+)
+
+type mockRootNode struct {
+	shardInfo *storage.ShardInfo
+	state     *abdrc.StateMsg
+	err       error
+}
+
+func (mn *mockRootNode) ShardInfo(partition types.PartitionID, shard types.ShardID) (*storage.ShardInfo, error) {
+	if mn.err != nil {
+		return nil, mn.err
+	}
+	return mn.shardInfo, nil
+}
+
+func (mn *mockRootNode) GetState() (*abdrc.StateMsg, error) {
+	return mn.state, mn.err
+}
+
+type mockTrustBaseLoader map[uint64]types.RootTrustBase
+
+func (m mockTrustBaseLoader) LoadTrustBase(epochNumber uint64) (types.RootTrustBase, error) {
+	return m[epochNumber], nil
+}
+
+func TestRootAPI_GetRootRound(t *testing.T) {
+	node := &mockRootNode{state: &abdrc.StateMsg{CommittedHead: &abdrc.CommittedBlock{Block: &rctypes.BlockData{Round: 7, Epoch: 1}}}}
+	api := NewRootAPI(node, mockTrustBaseLoader{}, testobservability.Default(t))
+
+	ri, err := api.GetRootRound(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 7, ri.RoundNumber)
+	require.EqualValues(t, 1, ri.EpochNumber)
+
+	node.err = errors.New("some error")
+	ri, err = api.GetRootRound(context.Background())
+	require.EqualError(t, err, "failed to load state: some error")
+	require.Nil(t, ri)
+}
+
+func TestRootAPI_GetUnicityCertificate(t *testing.T) {
+	si := &storage.ShardInfo{
+		PartitionID: 1,
+		LastCR: &certification.CertificationResponse{
+			Partition: 1,
+			UC: types.UnicityCertificate{
+				Version:     1,
+				InputRecord: &types.InputRecord{Version: 1, RoundNumber: 5},
+				UnicitySeal: &types.UnicitySeal{Version: 1, RootChainRoundNumber: 10, Timestamp: 1000},
+			},
+		},
+	}
+	node := &mockRootNode{shardInfo: si}
+	api := NewRootAPI(node, mockTrustBaseLoader{}, testobservability.Default(t))
+
+	t.Run("ok", func(t *testing.T) {
+		ucCbor, err := api.GetUnicityCertificate(context.Background(), 1, types.ShardID{})
+		require.NoError(t, err)
+		var uc types.UnicityCertificate
+		require.NoError(t, cbor.Unmarshal(ucCbor, &uc))
+		require.EqualValues(t, 5, uc.GetRoundNumber())
+		require.EqualValues(t, 10, uc.GetRootRoundNumber())
+	})
+
+	t.Run("shard not certified yet", func(t *testing.T) {
+		node.shardInfo = &storage.ShardInfo{PartitionID: 1}
+		ucCbor, err := api.GetUnicityCertificate(context.Background(), 1, types.ShardID{})
+		require.NoError(t, err)
+		require.Nil(t, ucCbor)
+	})
+
+	t.Run("unknown shard", func(t *testing.T) {
+		node.err = errors.New("unknown partition 00000002 shard")
+		ucCbor, err := api.GetUnicityCertificate(context.Background(), 2, types.ShardID{})
+		require.EqualError(t, err, "failed to load shard info: unknown partition 00000002 shard")
+		require.Nil(t, ucCbor)
+	})
+}
+
+func TestRootAPI_GetShardInfo(t *testing.T) {
+	si := &storage.ShardInfo{
+		PartitionID: 1,
+		IR:          &types.InputRecord{Version: 1, RoundNumber: 5, Epoch: 2},
+		TR:          certification.TechnicalRecord{Round: 6, Epoch: 2, Leader: "1"},
+		Fees:        map[string]uint64{"1": 10, "2": 0},
+		LastCR: &certification.CertificationResponse{
+			Partition: 1,
+			UC: types.UnicityCertificate{
+				Version:     1,
+				UnicitySeal: &types.UnicitySeal{Version: 1, Timestamp: 1000},
+			},
+		},
+	}
+	api := NewRootAPI(&mockRootNode{shardInfo: si}, mockTrustBaseLoader{}, testobservability.Default(t))
+
+	rsp, err := api.GetShardInfo(context.Background(), 1, types.ShardID{})
+	require.NoError(t, err)
+	require.Equal(t, types.PartitionID(1), rsp.PartitionID)
+	require.EqualValues(t, 2, rsp.EpochNumber)
+	require.Equal(t, si.IR, rsp.InputRecord)
+	require.Equal(t, si.TR, rsp.TechnicalRecord)
+	require.Equal(t, map[string]hex.Uint64{"1": 10, "2": 0}, rsp.Fees)
+	require.EqualValues(t, 1000, rsp.LastCertificationTime)
+}
+
+func TestRootAPI_ValidatorStats(t *testing.T) {
+	prevFees, err := cbor.Marshal(map[string]uint64{"1": 7, "2": 3})
+	require.NoError(t, err)
+	prevStat, err := cbor.Marshal(certification.StatisticalRecord{Blocks: 4, BlockFees: 10})
+	require.NoError(t, err)
+	si := &storage.ShardInfo{
+		PartitionID:   1,
+		TR:            certification.TechnicalRecord{Epoch: 2},
+		Fees:          map[string]uint64{"1": 1, "2": 2},
+		Stat:          certification.StatisticalRecord{Blocks: 1, BlockFees: 3},
+		PrevEpochFees: prevFees,
+		PrevEpochStat: prevStat,
+	}
+
+	db, err := memorydb.New()
+	require.NoError(t, err)
+	store, err := epochstats.NewStore(db)
+	require.NoError(t, err)
+	_, err = store.Add(&epochstats.Summary{PartitionID: 1, Epoch: 0, Fees: map[string]uint64{"1": 5}, Stat: certification.StatisticalRecord{Blocks: 2}})
+	require.NoError(t, err)
+
+	api := NewRootAPI(&mockRootNode{shardInfo: si}, mockTrustBaseLoader{}, testobservability.Default(t), WithEpochStats(store))
+
+	rsp, err := api.GetValidatorStats(context.Background(), 1, types.ShardID{})
+	require.NoError(t, err)
+	require.Equal(t, &EpochStats{EpochNumber: 2, Fees: map[string]hex.Uint64{"1": 1, "2": 2}, Stat: si.Stat}, rsp.CurrentEpoch)
+	require.Equal(t, &EpochStats{EpochNumber: 1, Fees: map[string]hex.Uint64{"1": 7, "2": 3}, Stat: certification.StatisticalRecord{Blocks: 4, BlockFees: 10}}, rsp.PreviousEpoch)
+
+	stats, err := api.GetEpochStats(context.Background(), 1, types.ShardID{}, 0)
+	require.NoError(t, err)
+	require.Equal(t, map[string]hex.Uint64{"1": 5}, stats.Fees)
+	require.EqualValues(t, 2, stats.Stat.Blocks)
+
+	stats, err = api.GetEpochStats(context.Background(), 1, types.ShardID{}, 1)
+	require.NoError(t, err)
+	require.Nil(t, stats)
+
+	api = NewRootAPI(&mockRootNode{shardInfo: si}, mockTrustBaseLoader{}, testobservability.Default(t))
+	_, err = api.GetEpochStats(context.Background(), 1, types.ShardID{}, 0)
+	require.EqualError(t, err, "epoch stats store is not enabled")
+}
+
+func TestRootAPI_GetEpochRewards(t *testing.T) {
+	db, err := memorydb.New()
+	require.NoError(t, err)
+	store, err := epochstats.NewStore(db)
+	require.NoError(t, err)
+	summary := &epochstats.Summary{
+		PartitionID:   1,
+		Epoch:         1,
+		Fees:          map[string]uint64{"1": 5, "2": 0},
+		Certificate:   &certification.CertificationResponse{Partition: 1, Technical: certification.TechnicalRecord{Round: 10, Epoch: 2}},
+		ShardConf:     &types.PartitionDescriptionRecord{PartitionID: 1, Epoch: 1},
+		NextEpochFees: map[string]uint64{"2": 0},
+	}
+	_, err = store.Add(summary)
+	require.NoError(t, err)
+	// summary without the proof of the fees
+	_, err = store.Add(&epochstats.Summary{PartitionID: 1, Epoch: 2, Fees: map[string]uint64{"2": 1}})
+	require.NoError(t, err)
+
+	api := NewRootAPI(&mockRootNode{}, mockTrustBaseLoader{}, testobservability.Default(t), WithEpochStats(store))
+	rewards, err := api.GetEpochRewards(context.Background(), 1, types.ShardID{}, 1)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, rewards.EpochNumber)
+	require.Equal(t, summary.Certificate.Technical, rewards.Certificate.Technical)
+	require.EqualValues(t, 1, rewards.ShardConf.Epoch)
+	require.Equal(t, map[string]hex.Uint64{"1": 5, "2": 0}, rewards.Fees)
+	require.Equal(t, map[string]hex.Uint64{"2": 0}, rewards.NextEpochFees)
+
+	_, err = api.GetEpochRewards(context.Background(), 1, types.ShardID{}, 2)
+	require.EqualError(t, err, "proof of the epoch 2 fees is not available")
+
+	rewards, err = api.GetEpochRewards(context.Background(), 1, types.ShardID{}, 3)
+	require.NoError(t, err)
+	require.Nil(t, rewards)
+
+	api = NewRootAPI(&mockRootNode{}, mockTrustBaseLoader{}, testobservability.Default(t))
+	_, err = api.GetEpochRewards(context.Background(), 1, types.ShardID{}, 1)
+	require.EqualError(t, err, "epoch stats store is not enabled")
+}
+
+func TestRootAPI_GetTrustBase(t *testing.T) {
+	_, verifier := testsig.CreateSignerAndVerifier(t)
+	tb := trustbase.NewTrustBase(t, verifier)
+	api := NewRootAPI(&mockRootNode{}, mockTrustBaseLoader{0: tb}, testobservability.Default(t))
+
+	res, err := api.GetTrustBase(context.Background(), 0)
+	require.NoError(t, err)
+	require.Equal(t, tb, res)
+
+	res, err = api.GetTrustBase(context.Background(), 1)
+	require.EqualError(t, err, "trust base for epoch 1 not found")
+	require.Nil(t, res)
+}
+
+func TestRootAPI_ArchivedCertificates(t *testing.T) {
+	db, err := memorydb.New()
+	require.NoError(t, err)
+	archive, err := ucarchive.New(db, 0)
+	require.NoError(t, err)
+	uc := &types.UnicityCertificate{
+		Version:                1,
+		InputRecord:            &types.InputRecord{Version: 1, RoundNumber: 5},
+		UnicityTreeCertificate: &types.UnicityTreeCertificate{Version: 1, Partition: 1},
+		UnicitySeal:            &types.UnicitySeal{Version: 1, RootChainRoundNumber: 10},
+	}
+	require.NoError(t, archive.Add(uc))
+	api := NewRootAPI(&mockRootNode{}, mockTrustBaseLoader{}, testobservability.Default(t), WithUCArchive(archive))
+
+	ucCbor, err := api.GetUnicityCertificateByRound(context.Background(), 1, types.ShardID{}, 5)
+	require.NoError(t, err)
+	var res types.UnicityCertificate
+	require.NoError(t, cbor.Unmarshal(ucCbor, &res))
+	require.EqualValues(t, 10, res.GetRootRoundNumber())
+
+	ucCbor, err = api.GetUnicityCertificateByRound(context.Background(), 1, types.ShardID{}, 4)
+	require.NoError(t, err)
+	require.Nil(t, ucCbor)
+
+	ucs, err := api.GetRootRoundCertificates(context.Background(), 10)
+	require.NoError(t, err)
+	require.Len(t, ucs, 1)
+	ucs, err = api.GetRootRoundCertificates(context.Background(), 11)
+	require.NoError(t, err)
+	require.Empty(t, ucs)
+
+	api = NewRootAPI(&mockRootNode{}, mockTrustBaseLoader{}, testobservability.Default(t))
+	_, err = api.GetRootRoundCertificates(context.Background(), 10)
+	require.EqualError(t, err, "unicity certificate archive is not enabled")
+}
+
+func TestRootAPI_RootBlocks(t *testing.T) {
+	db, err := memorydb.New()
+	require.NoError(t, err)
+	archive, err := blockarchive.New(db, 0)
+	require.NoError(t, err)
+
+	psID := types.PartitionShardID{PartitionID: 1, ShardID: types.ShardID{}.Key()}
+	newBlock := func(round, parentRound uint64) *storage.ExecutedBlock {
+		return &storage.ExecutedBlock{
+			BlockData: &rctypes.BlockData{
+				Version: 1,
+				Author:  "leader",
+				Round:   round,
+				Payload: &rctypes.Payload{Requests: []*rctypes.IRChangeReq{{Partition: 1, CertReason: rctypes.Quorum}}},
+				Qc: &rctypes.QuorumCert{
+					VoteInfo:         &rctypes.RoundInfo{RoundNumber: parentRound},
+					LedgerCommitInfo: &types.UnicitySeal{Version: 1},
+				},
+			},
+			RootHash: []byte{1},
+			Qc:       &rctypes.QuorumCert{VoteInfo: &rctypes.RoundInfo{RoundNumber: round}},
+			CommitQc: &rctypes.QuorumCert{VoteInfo: &rctypes.RoundInfo{RoundNumber: round + 1}},
+			ShardState: storage.ShardStates{
+				States:  map[types.PartitionShardID]*storage.ShardInfo{psID: {PartitionID: 1, IR: &types.InputRecord{Version: 1, RoundNumber: 8}}},
+				Changed: storage.ShardSet{psID: struct{}{}},
+			},
+		}
+	}
+	require.NoError(t, archive.AddBlock(newBlock(2, 1)))
+	require.NoError(t, archive.AddTimeoutCert(&rctypes.TimeoutCert{Timeout: &rctypes.Timeout{Round: 3}}))
+	require.NoError(t, archive.AddBlock(newBlock(4, 2)))
+
+	api := NewRootAPI(&mockRootNode{}, mockTrustBaseLoader{}, testobservability.Default(t), WithBlockArchive(archive))
+
+	block, err := api.GetRootBlock(context.Background(), 2)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, block.Round)
+	require.Equal(t, "leader", block.Leader)
+	require.Nil(t, block.TimeoutCert)
+	require.Len(t, block.IRChangeRequests, 1)
+	require.Equal(t, []CertifiedShard{{PartitionID: 1, RoundNumber: 8}}, block.CertifiedShards)
+
+	block, err = api.GetRootBlock(context.Background(), 3)
+	require.NoError(t, err)
+	require.Nil(t, block)
+
+	block, err = api.GetRootBlock(context.Background(), 4)
+	require.NoError(t, err)
+	require.NotNil(t, block.TimeoutCert, "round 3 timed out")
+	require.EqualValues(t, 3, block.TimeoutCert.GetRound())
+
+	blocks, err := api.GetRootBlocks(context.Background(), 0, 10)
+	require.NoError(t, err)
+	require.Len(t, blocks, 2)
+	require.EqualValues(t, 2, blocks[0].Round)
+	require.EqualValues(t, 4, blocks[1].Round)
+
+	tc, err := api.GetTimeoutCertificate(context.Background(), 3)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, tc.GetRound())
+
+	api = NewRootAPI(&mockRootNode{}, mockTrustBaseLoader{}, testobservability.Default(t))
+	_, err = api.GetRootBlocks(context.Background(), 0, 10)
+	require.EqualError(t, err, "root block archive is not enabled")
+}
+
+func TestRootAPI_Liveness(t *testing.T) {
+	obs := testobservability.Default(t)
+	monitor, err := liveness.New(10, 3, obs.Meter("liveness"), obs.Logger())
+	require.NoError(t, err)
+	monitor.Certified(context.Background(), &types.UnicityCertificate{
+		Version:                1,
+		InputRecord:            &types.InputRecord{Version: 1, RoundNumber: 5},
+		UnicityTreeCertificate: &types.UnicityTreeCertificate{Version: 1, Partition: 1},
+		UnicitySeal:            &types.UnicitySeal{Version: 1, RootChainRoundNumber: 10},
+	}, []string{"1", "2"}, []string{"1"})
+
+	api := NewRootAPI(&mockRootNode{}, mockTrustBaseLoader{}, obs, WithLivenessMonitor(monitor))
+	status, err := api.GetShardLiveness(context.Background(), 1, types.ShardID{})
+	require.NoError(t, err)
+	require.EqualValues(t, 5, status.RoundNumber)
+	require.Equal(t, map[string]hex.Uint64{"2": 1}, status.AbsentValidators)
+
+	status, err = api.GetShardLiveness(context.Background(), 2, types.ShardID{})
+	require.NoError(t, err)
+	require.Nil(t, status)
+
+	shards, err := api.GetLiveness(context.Background())
+	require.NoError(t, err)
+	require.Len(t, shards, 1)
+
+	api = NewRootAPI(&mockRootNode{}, mockTrustBaseLoader{}, obs)
+	_, err = api.GetLiveness(context.Background())
+	require.EqualError(t, err, "liveness monitor is not enabled")
+}
+
+func TestRootAPI_GetEvidence(t *testing.T) {
+	obs := testobservability.Default(t)
+	db, err := memorydb.New()
+	require.NoError(t, err)
+	store, err := evidence.NewStore(db, obs.Meter("evidence"))
+	require.NoError(t, err)
+	newRequest := func(partition types.PartitionID, round uint64, blockHash []byte) *certification.BlockCertificationRequest {
+		return &certification.BlockCertificationRequest{
+			PartitionID: partition,
+			NodeID:      "1",
+			InputRecord: &types.InputRecord{Version: 1, RoundNumber: round, BlockHash: blockHash},
+		}
+	}
+	for _, partition := range []types.PartitionID{1, 2} {
+		for _, round := range []uint64{5, 6} {
+			_, err := store.Add(context.Background(), evidence.NewRequestEvidence(newRequest(partition, round, []byte{1}), newRequest(partition, round, []byte{2})))
+			require.NoError(t, err)
+		}
+	}
+
+	api := NewRootAPI(&mockRootNode{}, mockTrustBaseLoader{}, obs, WithEvidenceStore(store))
+	items, err := api.GetEvidence(context.Background(), nil, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, items, 4)
+
+	kind := evidence.KindCertificationRequest
+	partitionID := types.PartitionID(2)
+	round := hex.Uint64(6)
+	items, err = api.GetEvidence(context.Background(), &kind, &partitionID, &round)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Equal(t, partitionID, items[0].Partition)
+	require.EqualValues(t, round, items[0].Round)
+
+	items, err = api.GetEvidence(context.Background(), nil, &partitionID, nil)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+
+	kind = evidence.KindVote
+	items, err = api.GetEvidence(context.Background(), &kind, nil, nil)
+	require.NoError(t, err)
+	require.NotNil(t, items)
+	require.Empty(t, items)
+
+	api = NewRootAPI(&mockRootNode{}, mockTrustBaseLoader{}, obs)
+	_, err = api.GetEvidence(context.Background(), nil, nil, nil)
+	require.EqualError(t, err, "evidence store is not enabled")
+}
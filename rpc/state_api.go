@@ -13,6 +13,7 @@ import (
 	"github.com/alphabill-org/alphabill-go-base/cbor"
 	"github.com/alphabill-org/alphabill-go-base/types"
 	"github.com/alphabill-org/alphabill-go-base/types/hex"
+	"github.com/alphabill-org/alphabill/observability"
 	"github.com/alphabill-org/alphabill/partition"
 	"github.com/alphabill-org/alphabill/state"
 	"github.com/alphabill-org/alphabill/tree/avl"
@@ -95,7 +96,7 @@ func NewStateAPI(node partitionNode, obs Observability, opts ...StateAPIOption)
 		ownerIndex:        options.ownerIndex,
 		pdr:               options.shardConf,
 		withGetUnits:      options.withGetUnits,
-		updMetrics:        metricsUpdater(m, node, log),
+		updMetrics:        metricsUpdater(m, observability.Shard(node.PartitionID(), node.ShardID()), log),
 		updTxReceived:     metricsUpdaterTxReceived(m, node, log),
 		requestLimiter:    requestLimiter,
 		responseItemLimit: options.responseItemLimit,
@@ -34,6 +34,9 @@ type (
 		// savepoint is a special marker that allows all actions that are executed after tree was established to
 		// be rolled back, restoring the state to what it was at the time of the tree.
 		savepoints []*tree
+
+		// changes is not nil when the state is a fork of another state
+		changes *ChangeSet
 	}
 
 	Unit interface {
@@ -155,6 +158,9 @@ func (s *State) GetUnit(id types.UnitID, committed bool) (Unit, error) {
 	if committed {
 		return s.committedTree.Get(id)
 	}
+	if s.changes != nil {
+		s.changes.read(id)
+	}
 	u, err := s.latestSavepoint().Get(id)
 	if err != nil {
 		return nil, err
@@ -166,7 +172,8 @@ func (s *State) AddUnitLog(id types.UnitID, txrHash []byte) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	u, err := s.latestSavepoint().Get(id)
+	ss := s.shardState()
+	u, err := ss.Get(id)
 	if err != nil {
 		return fmt.Errorf("unable to find unit: %w", err)
 	}
@@ -177,7 +184,7 @@ func (s *State) AddUnitLog(id types.UnitID, txrHash []byte) error {
 	if err := unit.AddUnitLog(s.hashAlgorithm, txrHash); err != nil {
 		return fmt.Errorf("failed to add unit log: %w", err)
 	}
-	return s.latestSavepoint().Update(id, unit)
+	return ss.Update(id, unit)
 }
 
 // Apply applies given actions to the state. All Action functions are executed together as a single atomic operation. If
@@ -190,8 +197,9 @@ func (s *State) Apply(actions ...Action) error {
 	if err != nil {
 		return fmt.Errorf("unable to create savepoint: %w", err)
 	}
+	ss := s.shardState()
 	for _, action := range actions {
-		if err := action(s.latestSavepoint(), s.hashAlgorithm); err != nil {
+		if err := action(ss, s.hashAlgorithm); err != nil {
 			s.rollbackToSavepoint(id)
 			return err
 		}
@@ -248,6 +256,9 @@ func (s *State) Revert() {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 	s.savepoints = []*tree{s.committedTree.Clone()}
+	if s.changes != nil {
+		s.changes.reset()
+	}
 }
 
 // Savepoint creates a new savepoint and returns an id of the savepoint. Use RollbackToSavepoint to roll back all
@@ -566,6 +577,9 @@ func (s *State) createSavepoint() (int, error) {
 		return 0, fmt.Errorf("unable to mark the tree clean: %w", err)
 	}
 	s.savepoints = append(s.savepoints, clonedSavepoint)
+	if s.changes != nil {
+		s.changes.savepoint()
+	}
 	return len(s.savepoints) - 1, nil
 }
 
@@ -576,6 +590,9 @@ func (s *State) rollbackToSavepoint(id int) {
 		return
 	}
 	s.savepoints = s.savepoints[0:id]
+	if s.changes != nil {
+		s.changes.rollback(id)
+	}
 }
 
 func (s *State) releaseToSavepoint(id int) {
@@ -586,6 +603,9 @@ func (s *State) releaseToSavepoint(id int) {
 	}
 	s.savepoints[id-1] = s.latestSavepoint()
 	s.savepoints = s.savepoints[0:id]
+	if s.changes != nil {
+		s.changes.release(id)
+	}
 }
 
 func (s *State) isCommitted() (bool, error) {
@@ -607,6 +627,14 @@ func isRootClean(s *tree) (bool, error) {
 	return unit.summaryCalculated, nil
 }
 
+// shardState returns the latest savepoint, wrapped into a recorder when the state is a fork.
+func (s *State) shardState() ShardState {
+	if s.changes != nil {
+		return &recordingShardState{tree: s.latestSavepoint(), changes: s.changes}
+	}
+	return s.latestSavepoint()
+}
+
 // latestSavepoint returns the latest savepoint.
 func (s *State) latestSavepoint() *tree {
 	l := len(s.savepoints)
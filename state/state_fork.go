@@ -0,0 +1,225 @@
+package state
+
+import (
+	"crypto"
+	"fmt"
+	"sync"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill/tree/avl"
+)
+
+const (
+	opAdd changeOpKind = iota
+	opUpdate
+	opDelete
+)
+
+type (
+	// ChangeSet records the units accessed through a forked state (see
+	// State.ForkFrom) and the modifications made to the state tree, in
+	// the order they were made.
+	ChangeSet struct {
+		mu    sync.Mutex // reads are recorded under the read lock of the state
+		reads map[string]struct{}
+		ops   []changeOp
+		// marks[i] is the length of ops when the savepoint i was created
+		marks []int
+	}
+
+	changeOp struct {
+		kind  changeOpKind
+		id    types.UnitID
+		value Unit
+	}
+
+	changeOpKind uint8
+
+	// recordingShardState records the access to the units of the wrapped
+	// state tree into a ChangeSet.
+	recordingShardState struct {
+		tree    *tree
+		changes *ChangeSet
+	}
+)
+
+/*
+ForkFrom discards the content of the state "s" and replaces it with an isolated
+view of the current (uncommitted) state of "base". Changes made to the fork are
+not visible in the "base" and vice versa. The fork records the units it reads
+and the modifications made to the state tree, use Changes to retrieve them and
+State.ApplyChanges to apply them to the "base".
+
+The "base" must not be modified while forks created from it are in use. It is
+safe to fork the same "base" concurrently from multiple goroutines.
+*/
+func (s *State) ForkFrom(base *State) error {
+	if s == base {
+		return fmt.Errorf("state can't be forked from itself")
+	}
+	base.mutex.Lock()
+	sp := base.latestSavepoint()
+	// mark the base tree clean so that forks use copy-on-write instead of
+	// modifying the shared nodes (and nodes are not marked clean concurrently
+	// when savepoints are created in the forks)
+	if err := sp.Traverse(&avl.PostOrderCommitTraverser[types.UnitID, Unit]{}); err != nil {
+		base.mutex.Unlock()
+		return fmt.Errorf("unable to mark the tree clean: %w", err)
+	}
+	hashAlgorithm := base.hashAlgorithm
+	committedTree := base.committedTree.Clone()
+	committedTreeUC := base.committedTreeUC
+	fork := sp.Clone()
+	base.mutex.Unlock()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.hashAlgorithm = hashAlgorithm
+	s.committedTree = committedTree
+	s.committedTreeUC = committedTreeUC
+	s.savepoints = []*tree{fork}
+	s.changes = &ChangeSet{reads: make(map[string]struct{}), marks: []int{0}}
+	return nil
+}
+
+/*
+Changes returns the changes recorded by the forked state, nil if the state
+is not a fork. The fork stops recording, ie ForkFrom must be called before
+the state is used again.
+*/
+func (s *State) Changes() *ChangeSet {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	c := s.changes
+	s.changes = nil
+	return c
+}
+
+/*
+ApplyChanges replays the modifications recorded by a fork on the state. All
+modifications are applied as a single atomic operation. The change set must
+not be applied more than once as the units are shared with the fork.
+
+The caller must make sure that the fork didn't read any unit which has been
+modified in the state after the fork was created (see ChangeSet.DependsOn),
+otherwise the result is not the same as executing the operations on the state
+directly.
+*/
+func (s *State) ApplyChanges(c *ChangeSet) error {
+	if c == nil {
+		return nil
+	}
+	return s.Apply(func(ss ShardState, _ crypto.Hash) error {
+		for _, op := range c.ops {
+			var err error
+			switch op.kind {
+			case opAdd:
+				err = ss.Add(op.id, op.value)
+			case opUpdate:
+				err = ss.Update(op.id, op.value)
+			case opDelete:
+				err = ss.Delete(op.id)
+			default:
+				err = fmt.Errorf("unknown operation %d", op.kind)
+			}
+			if err != nil {
+				return fmt.Errorf("replaying change of unit %s: %w", op.id, err)
+			}
+		}
+		return nil
+	})
+}
+
+/*
+Modified returns IDs of the units modified (added, updated or deleted).
+*/
+func (c *ChangeSet) Modified() []types.UnitID {
+	ids := make([]types.UnitID, 0, len(c.ops))
+	seen := make(map[string]struct{}, len(c.ops))
+	for _, op := range c.ops {
+		if _, ok := seen[string(op.id)]; !ok {
+			seen[string(op.id)] = struct{}{}
+			ids = append(ids, op.id)
+		}
+	}
+	return ids
+}
+
+/*
+DependsOn returns true when any of the units in the "modified" set has been
+accessed (read or modified) by the operations recorded in the change set.
+*/
+func (c *ChangeSet) DependsOn(modified map[string]struct{}) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id := range modified {
+		if _, ok := c.reads[id]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *ChangeSet) read(id types.UnitID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reads[string(id)] = struct{}{}
+}
+
+func (c *ChangeSet) record(kind changeOpKind, id types.UnitID, value Unit) {
+	c.ops = append(c.ops, changeOp{kind: kind, id: id, value: value})
+}
+
+func (c *ChangeSet) savepoint() {
+	c.marks = append(c.marks, len(c.ops))
+}
+
+func (c *ChangeSet) rollback(id int) {
+	if id < len(c.marks) {
+		c.ops = c.ops[:c.marks[id]]
+		c.marks = c.marks[:id]
+	}
+}
+
+func (c *ChangeSet) reset() {
+	c.ops = nil
+	c.marks = []int{0}
+}
+
+func (c *ChangeSet) release(id int) {
+	if id < len(c.marks) {
+		c.marks = c.marks[:id]
+	}
+}
+
+func (r *recordingShardState) Add(id types.UnitID, u Unit) error {
+	r.changes.read(id)
+	if err := r.tree.Add(id, u); err != nil {
+		return err
+	}
+	r.changes.record(opAdd, id, u)
+	return nil
+}
+
+func (r *recordingShardState) Get(id types.UnitID) (Unit, error) {
+	r.changes.read(id)
+	return r.tree.Get(id)
+}
+
+func (r *recordingShardState) Update(id types.UnitID, u Unit) error {
+	r.changes.read(id)
+	if err := r.tree.Update(id, u); err != nil {
+		return err
+	}
+	r.changes.record(opUpdate, id, u)
+	return nil
+}
+
+func (r *recordingShardState) Delete(id types.UnitID) error {
+	r.changes.read(id)
+	if err := r.tree.Delete(id); err != nil {
+		return err
+	}
+	r.changes.record(opDelete, id, nil)
+	return nil
+}
@@ -0,0 +1,107 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestState_ForkFrom(t *testing.T) {
+	newBase := func(t *testing.T) *State {
+		s := NewEmptyState()
+		require.NoError(t, s.Apply(
+			AddUnit(unitIdentifiers[0], &TestData{Value: 1}),
+			AddUnit(unitIdentifiers[1], &TestData{Value: 2}),
+			AddUnit(unitIdentifiers[2], &TestData{Value: 3}),
+		))
+		return s
+	}
+	incValue := func(id types.UnitID) Action {
+		return UpdateUnitData(id, func(data types.UnitData) (types.UnitData, error) {
+			data.(*TestData).Value++
+			return data, nil
+		})
+	}
+
+	t.Run("fork of itself", func(t *testing.T) {
+		s := newBase(t)
+		require.EqualError(t, s.ForkFrom(s), "state can't be forked from itself")
+	})
+
+	t.Run("changes applied in order give the same state as sequential execution", func(t *testing.T) {
+		// sequential
+		seq := newBase(t)
+		require.NoError(t, seq.Apply(incValue(unitIdentifiers[0]), AddUnit(unitIdentifiers[5], &TestData{Value: 10})))
+		require.NoError(t, seq.AddUnitLog(unitIdentifiers[0], []byte{1}))
+		require.NoError(t, seq.Apply(incValue(unitIdentifiers[1]), DeleteUnit(unitIdentifiers[2])))
+		seqValue, seqHash, err := seq.CalculateRoot()
+		require.NoError(t, err)
+
+		// forked
+		base := newBase(t)
+		fork1, fork2 := NewEmptyState(), NewEmptyState()
+		require.NoError(t, fork1.ForkFrom(base))
+		require.NoError(t, fork2.ForkFrom(base))
+		require.NoError(t, fork2.Apply(incValue(unitIdentifiers[1]), DeleteUnit(unitIdentifiers[2])))
+		require.NoError(t, fork1.Apply(incValue(unitIdentifiers[0]), AddUnit(unitIdentifiers[5], &TestData{Value: 10})))
+		require.NoError(t, fork1.AddUnitLog(unitIdentifiers[0], []byte{1}))
+
+		// base is not affected by the forks
+		u, err := base.GetUnit(unitIdentifiers[0], false)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, u.Data().SummaryValueInput())
+		_, err = base.GetUnit(unitIdentifiers[5], false)
+		require.Error(t, err)
+
+		c1, c2 := fork1.Changes(), fork2.Changes()
+		require.ElementsMatch(t, []types.UnitID{unitIdentifiers[0], unitIdentifiers[5]}, c1.Modified())
+		require.False(t, c2.DependsOn(toSet(c1.Modified())))
+		require.NoError(t, base.ApplyChanges(c1))
+		require.NoError(t, base.ApplyChanges(c2))
+
+		value, hash, err := base.CalculateRoot()
+		require.NoError(t, err)
+		require.Equal(t, seqValue, value)
+		require.Equal(t, seqHash, hash)
+		require.Equal(t, seq.latestSavepoint().String(), base.latestSavepoint().String())
+	})
+
+	t.Run("read of modified unit is a dependency", func(t *testing.T) {
+		base := newBase(t)
+		fork1, fork2 := NewEmptyState(), NewEmptyState()
+		require.NoError(t, fork1.ForkFrom(base))
+		require.NoError(t, fork2.ForkFrom(base))
+		require.NoError(t, fork1.Apply(incValue(unitIdentifiers[0])))
+		_, err := fork2.GetUnit(unitIdentifiers[0], false)
+		require.NoError(t, err)
+		require.True(t, fork2.Changes().DependsOn(toSet(fork1.Changes().Modified())))
+	})
+
+	t.Run("rolled back changes are not recorded", func(t *testing.T) {
+		base := newBase(t)
+		fork := NewEmptyState()
+		require.NoError(t, fork.ForkFrom(base))
+		require.NoError(t, fork.Apply(incValue(unitIdentifiers[0])))
+		id, err := fork.Savepoint()
+		require.NoError(t, err)
+		require.NoError(t, fork.Apply(incValue(unitIdentifiers[1])))
+		fork.RollbackToSavepoint(id)
+		// failing action rolls back the changes made by the previous actions
+		require.Error(t, fork.Apply(incValue(unitIdentifiers[2]), DeleteUnit(unitIdentifiers[9])))
+
+		c := fork.Changes()
+		require.Equal(t, []types.UnitID{unitIdentifiers[0]}, c.Modified())
+		// reads of the rolled back operations are still dependencies
+		require.True(t, c.DependsOn(toSet([]types.UnitID{unitIdentifiers[2]})))
+		require.Nil(t, fork.Changes(), "fork stops recording")
+	})
+}
+
+func toSet(ids []types.UnitID) map[string]struct{} {
+	m := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		m[string(id)] = struct{}{}
+	}
+	return m
+}
@@ -39,7 +39,8 @@ type (
 		log                 *slog.Logger
 		pr                  predicates.PredicateRunner
 		unitIDValidator     func(types.UnitID) error
-		etBuffer            *ETBuffer // executed transactions buffer
+		etBuffer            *ETBuffer          // executed transactions buffer
+		workers             []*GenericTxSystem // workers for parallel execution of transactions, see ExecuteBatch
 	}
 
 	Observability interface {
@@ -97,6 +98,9 @@ func NewGenericTxSystem(shardConf types.PartitionDescriptionRecord, modules []tx
 	if err := txs.initMetrics(observe.Meter("txsystem"), shardConf.ShardID); err != nil {
 		return nil, fmt.Errorf("initializing metrics: %w", err)
 	}
+	if err := txs.initWorkers(options.parallelWorkers, options.newWorker, observe); err != nil {
+		return nil, fmt.Errorf("initializing parallel execution: %w", err)
+	}
 
 	return txs, nil
 }
@@ -19,6 +19,7 @@ type (
 	Module struct {
 		state               *state.State
 		trustBase           types.RootTrustBase
+		getTrustBase        func(epoch uint64) (types.RootTrustBase, error)
 		hashAlgorithm       crypto.Hash
 		dustCollector       *DustCollector
 		feeCreditTxRecorder *feeCreditTxRecorder
@@ -35,10 +36,16 @@ func NewMoneyModule(pdr types.PartitionDescriptionRecord, options *Options) (*Mo
 		return nil, errors.New("state is nil")
 	}
 
+	getTrustBase := options.getTrustBase
+	if getTrustBase == nil {
+		getTrustBase = func(uint64) (types.RootTrustBase, error) { return options.trustBase, nil }
+	}
+
 	m := &Module{
 		state:               options.state,
 		pdr:                 pdr,
 		trustBase:           options.trustBase,
+		getTrustBase:        getTrustBase,
 		hashAlgorithm:       options.hashAlgorithm,
 		feeCreditTxRecorder: newFeeCreditTxRecorder(options.state, pdr.PartitionID, nil),
 		dustCollector:       NewDustCollector(options.state),
@@ -50,10 +57,16 @@ func NewMoneyModule(pdr types.PartitionDescriptionRecord, options *Options) (*Mo
 func (m *Module) TxHandlers() map[uint16]txtypes.TxExecutor {
 	return map[uint16]txtypes.TxExecutor{
 		// money partition tx handlers
-		money.TransactionTypeTransfer: txtypes.NewTxHandler[money.TransferAttributes, money.TransferAuthProof](m.validateTransferTx, m.executeTransferTx),
-		money.TransactionTypeSplit:    txtypes.NewTxHandler[money.SplitAttributes, money.SplitAuthProof](m.validateSplitTx, m.executeSplitTx, txtypes.WithTargetUnitsFn(m.splitTxTargetUnits)),
-		money.TransactionTypeTransDC:  txtypes.NewTxHandler[money.TransferDCAttributes, money.TransferDCAuthProof](m.validateTransferDCTx, m.executeTransferDCTx),
-		money.TransactionTypeSwapDC:   txtypes.NewTxHandler[money.SwapDCAttributes, money.SwapDCAuthProof](m.validateSwapTx, m.executeSwapTx),
+		money.TransactionTypeTransfer: txtypes.NewTxHandler[money.TransferAttributes, money.TransferAuthProof](m.validateTransferTx, m.executeTransferTx,
+			txtypes.WithIsolatedExecution[money.TransferAttributes, money.TransferAuthProof]()),
+		money.TransactionTypeSplit: txtypes.NewTxHandler[money.SplitAttributes, money.SplitAuthProof](m.validateSplitTx, m.executeSplitTx,
+			txtypes.WithTargetUnitsFn(m.splitTxTargetUnits), txtypes.WithIsolatedExecution[money.SplitAttributes, money.SplitAuthProof]()),
+		money.TransactionTypeTransDC: txtypes.NewTxHandler[money.TransferDCAttributes, money.TransferDCAuthProof](m.validateTransferDCTx, m.executeTransferDCTx,
+			txtypes.WithIsolatedExecution[money.TransferDCAttributes, money.TransferDCAuthProof]()),
+		money.TransactionTypeSwapDC: txtypes.NewTxHandler[money.SwapDCAttributes, money.SwapDCAuthProof](m.validateSwapTx, m.executeSwapTx,
+			txtypes.WithIsolatedExecution[money.SwapDCAttributes, money.SwapDCAuthProof]()),
+		TransactionTypeDistributeRewards: txtypes.NewTxHandler[DistributeRewardsAttributes, DistributeRewardsAuthProof](m.validateDistributeRewardsTx, m.executeDistributeRewardsTx,
+			txtypes.WithTargetUnitsFn(m.distributeRewardsTxTargetUnits)),
 
 		// fee credit related transaction handlers (credit transfers and reclaims only!)
 		fcsdk.TransactionTypeTransferFeeCredit: txtypes.NewTxHandler[fcsdk.TransferFeeCreditAttributes, fcsdk.TransferFeeCreditAuthProof](m.validateTransferFCTx, m.executeTransferFCTx),
@@ -2,9 +2,11 @@ package money
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/alphabill-org/alphabill-go-base/txsystem/money"
 	basetypes "github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill/state"
 	"github.com/alphabill-org/alphabill/txsystem"
 	"github.com/alphabill-org/alphabill/txsystem/fc"
 	txtypes "github.com/alphabill-org/alphabill/txsystem/types"
@@ -30,6 +32,10 @@ func NewTxSystem(shardConf *basetypes.PartitionDescriptionRecord, observe txsyst
 	if err != nil {
 		return nil, fmt.Errorf("failed to load fee credit module: %w", err)
 	}
+	// workers are instances of the money transaction system which use their own state
+	newWorker := func(s *state.State, observe txsystem.Observability) (*txsystem.GenericTxSystem, error) {
+		return NewTxSystem(shardConf, observe, append(slices.Clone(opts), WithState(s), WithExecutedTransactions(nil), WithParallelExecution(0))...)
+	}
 	return txsystem.NewGenericTxSystem(
 		*shardConf,
 		[]txtypes.Module{moneyModule},
@@ -40,5 +46,22 @@ func NewTxSystem(shardConf *basetypes.PartitionDescriptionRecord, observe txsyst
 		txsystem.WithHashAlgorithm(options.hashAlgorithm),
 		txsystem.WithState(options.state),
 		txsystem.WithExecutedTransactions(options.executedTransactions),
+		txsystem.WithParallelExecution(options.parallelWorkers, newWorker),
 	)
 }
+
+/*
+NewTxDecoders returns the transaction handlers of the money partition which are
+not backed by state and thus may be used only to decode the attributes and auth
+proofs of the transactions (see txtypes.TxExecutor.DecodeTx).
+*/
+func NewTxDecoders() (txtypes.TxExecutors, error) {
+	handlers := make(txtypes.TxExecutors)
+	if err := handlers.Add((&Module{}).TxHandlers()); err != nil {
+		return nil, fmt.Errorf("registering money transaction handlers: %w", err)
+	}
+	if err := handlers.Add((&fc.FeeCreditModule{}).TxHandlers()); err != nil {
+		return nil, fmt.Errorf("registering fee credit transaction handlers: %w", err)
+	}
+	return handlers, nil
+}
@@ -14,11 +14,13 @@ import (
 
 type (
 	Options struct {
-		state                       *state.State
-		executedTransactions        map[string]uint64
-		hashAlgorithm               crypto.Hash
-		trustBase                   types.RootTrustBase
-		exec                        predicates.PredicateExecutor
+		state                *state.State
+		executedTransactions map[string]uint64
+		hashAlgorithm        crypto.Hash
+		trustBase            types.RootTrustBase
+		getTrustBase         func(epoch uint64) (types.RootTrustBase, error)
+		exec                 predicates.PredicateExecutor
+		parallelWorkers      int
 	}
 
 	Option func(*Options)
@@ -58,6 +60,17 @@ func WithTrustBase(trust types.RootTrustBase) Option {
 	}
 }
 
+/*
+WithTrustBases sets the lookup of the root trust base by the root epoch. It is used
+to verify the certificates which may have been issued by an earlier root epoch. When
+not set the trust base set by WithTrustBase is used for all the root epochs.
+*/
+func WithTrustBases(getTrustBase func(epoch uint64) (types.RootTrustBase, error)) Option {
+	return func(options *Options) {
+		options.getTrustBase = getTrustBase
+	}
+}
+
 func WithHashAlgorithm(hashAlgorithm crypto.Hash) Option {
 	return func(g *Options) {
 		g.hashAlgorithm = hashAlgorithm
@@ -75,3 +88,14 @@ func WithPredicateExecutor(exec predicates.PredicateExecutor) Option {
 		}
 	}
 }
+
+/*
+WithParallelExecution sets the number of workers executing non-conflicting
+transactions of a block in parallel, values less than two disable the
+parallel execution.
+*/
+func WithParallelExecution(workers int) Option {
+	return func(g *Options) {
+		g.parallelWorkers = workers
+	}
+}
@@ -179,6 +179,21 @@ func TestExecute_TransferOk(t *testing.T) {
 	require.EqualValues(t, 1, data2.Counter)
 }
 
+func TestNewTxDecoders(t *testing.T) {
+	decoders, err := NewTxDecoders()
+	require.NoError(t, err)
+
+	tx, attr, _ := createBillTransfer(t, initialBill.ID, testutils.NewFeeCreditRecordIDAlwaysTrue(t), 10, templates.AlwaysFalseBytes(), 2)
+	decodedAttr, decodedAuthProof, err := decoders.DecodeTx(tx)
+	require.NoError(t, err)
+	require.Equal(t, attr, decodedAttr)
+	require.IsType(t, &money.TransferAuthProof{}, decodedAuthProof)
+
+	tx.Type = 0xFFFF
+	_, _, err = decoders.DecodeTx(tx)
+	require.EqualError(t, err, "unknown transaction type 65535")
+}
+
 func TestExecute_Split2WayOk(t *testing.T) {
 	pdrs := createPDRs(t)
 	rmaTree, txSystem, _ := createStateAndTxSystem(t, pdrs)
@@ -0,0 +1,92 @@
+package money
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/predicates/templates"
+	moneyid "github.com/alphabill-org/alphabill-go-base/testutils/money"
+	fcsdk "github.com/alphabill-org/alphabill-go-base/txsystem/fc"
+	"github.com/alphabill-org/alphabill-go-base/txsystem/money"
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill-go-base/util"
+
+	"github.com/alphabill-org/alphabill/internal/testutils/observability"
+	testsig "github.com/alphabill-org/alphabill/internal/testutils/sig"
+	testtb "github.com/alphabill-org/alphabill/internal/testutils/trustbase"
+	"github.com/alphabill-org/alphabill/state"
+	"github.com/alphabill-org/alphabill/txsystem"
+	"github.com/alphabill-org/alphabill/txsystem/fc/unit"
+)
+
+func TestExecuteBatch_ParallelExecution(t *testing.T) {
+	const billCount = 20
+	pdrs := createPDRs(t)
+	pdr := pdrs[0]
+
+	// every bill has its own fee credit record so that transfers do not conflict
+	s := genesisState(t, initialBill, pdrs)
+	billIDs := make([]types.UnitID, billCount)
+	fcrIDs := make([]types.UnitID, billCount)
+	for i := range billCount {
+		billIDs[i] = moneyid.BillIDWithSuffix(t, uint64(100+i), nil)
+		fcrID, err := money.NewFeeCreditRecordIDFromOwnerPredicate(pdr, types.ShardID{}, templates.AlwaysTrueBytes(), uint64(1000+i))
+		require.NoError(t, err)
+		fcrIDs[i] = fcrID
+		require.NoError(t, s.Apply(
+			state.AddUnit(billIDs[i], money.NewBillData(10, templates.AlwaysTrueBytes())),
+			unit.AddCredit(fcrID, &fcsdk.FeeCreditRecord{Balance: 100, MinLifetime: 100, OwnerPredicate: templates.AlwaysTrueBytes()}),
+		))
+	}
+	summaryValue, summaryHash, err := s.CalculateRoot()
+	require.NoError(t, err)
+	require.NoError(t, s.Commit(&types.UnicityCertificate{Version: 1, InputRecord: &types.InputRecord{
+		Version:      1,
+		RoundNumber:  1,
+		Hash:         summaryHash,
+		SummaryValue: util.Uint64ToBytes(summaryValue),
+	}}))
+
+	var txs []*types.TransactionOrder
+	for i := range billCount {
+		tx, _, _ := createBillTransfer(t, billIDs[i], fcrIDs[i], 10, templates.AlwaysTrueBytes(), 0)
+		txs = append(txs, tx)
+	}
+	// conflicting transactions: bill transferred again, shared fee credit record,
+	// failing transaction (wrong counter) and a split creating a new unit
+	tx, _, _ := createBillTransfer(t, billIDs[0], fcrIDs[1], 10, templates.AlwaysFalseBytes(), 1)
+	txs = append(txs, tx)
+	tx, _, _ = createBillTransfer(t, billIDs[2], fcrIDs[2], 10, templates.AlwaysFalseBytes(), 5)
+	txs = append(txs, tx)
+	tx, _, _ = createSplit(t, billIDs[3], fcrIDs[4], []*money.TargetUnit{{Amount: 4, OwnerPredicate: templates.AlwaysTrueBytes()}}, 1)
+	txs = append(txs, tx)
+	for i := 5; i < billCount; i++ {
+		tx, _, _ := createBillTransfer(t, billIDs[i], fcrIDs[i], 10, templates.AlwaysFalseBytes(), 1)
+		txs = append(txs, tx)
+	}
+
+	_, verifier := testsig.CreateSignerAndVerifier(t)
+	trustBase := testtb.NewTrustBase(t, verifier)
+	execute := func(t *testing.T, s *state.State, workers int) ([]*types.TransactionRecord, *txsystem.StateSummary) {
+		txSystem, err := NewTxSystem(pdr, observability.Default(t),
+			WithState(s),
+			WithTrustBase(trustBase),
+			WithParallelExecution(workers),
+		)
+		require.NoError(t, err)
+		require.NoError(t, txSystem.BeginBlock(2))
+		txrs, err := txSystem.ExecuteBatch(txs)
+		require.NoError(t, err)
+		summary, err := txSystem.EndBlock()
+		require.NoError(t, err)
+		return txrs, summary
+	}
+
+	seqTxrs, seqSummary := execute(t, s.Clone(), 0)
+	parTxrs, parSummary := execute(t, s.Clone(), 4)
+	require.Len(t, parTxrs, len(txs))
+	require.Equal(t, seqTxrs, parTxrs)
+	require.Equal(t, seqSummary, parSummary)
+	require.Equal(t, types.TxStatusFailed, parTxrs[billCount+1].ServerMetadata.SuccessIndicator)
+}
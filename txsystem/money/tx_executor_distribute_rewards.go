@@ -0,0 +1,366 @@
+package money
+
+import (
+	"bytes"
+	"crypto"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/alphabill-org/alphabill-go-base/cbor"
+	abhash "github.com/alphabill-org/alphabill-go-base/hash"
+	"github.com/alphabill-org/alphabill-go-base/predicates/templates"
+	"github.com/alphabill-org/alphabill-go-base/tree/avl"
+	"github.com/alphabill-org/alphabill-go-base/txsystem/money"
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill-go-base/types/hex"
+	"github.com/alphabill-org/alphabill-go-base/util"
+	"github.com/alphabill-org/alphabill/network/protocol/certification"
+	"github.com/alphabill-org/alphabill/state"
+	txtypes "github.com/alphabill-org/alphabill/txsystem/types"
+)
+
+const (
+	// TransactionTypeDistributeRewards pays the fees earned by the validators of a shard
+	// during an epoch out of the reward pool.
+	TransactionTypeDistributeRewards uint16 = 30
+
+	// RewardOwnerParamPrefix is the prefix of the shard conf parameter "rewardOwner.<node ID>"
+	// which sets the owner predicate (hex encoded) of the reward bills of the validator.
+	RewardOwnerParamPrefix = "rewardOwner."
+)
+
+var (
+	// The ID of the reward pool money supply. The pool is created by the genesis of the
+	// money partition next to the initial bill (unit number 1) and the dust collector
+	// money supply (unit number 0), so the reward pool uses the unit number 2.
+	RewardPoolMoneySupplyID = append(append(make(types.UnitID, 31), 2), money.BillUnitType)
+
+	// Reward pool predicate
+	RewardPoolPredicate = templates.NewP2pkh256BytesFromKey([]byte("reward pool"))
+
+	ErrRewardsAlreadyDistributed = errors.New("rewards of the epoch have already been distributed")
+
+	// Owner predicate of the marker units of the distributed epochs, the marker can't be spent.
+	rewardsDistributedPredicate = templates.AlwaysFalseBytes()
+)
+
+type (
+	// DistributeRewardsAttributes is the summary of the completed shard epoch. The summary
+	// is verified against the certificate of the last round of the epoch: the technical
+	// record of the certificate commits to the fees of the epoch (and to the empty fee
+	// list of the next epoch) and the UC commits to the shard conf of the epoch.
+	DistributeRewardsAttributes struct {
+		_ struct{} `cbor:",toarray"`
+		// certificate of the last round of the rewarded epoch
+		Certificate *certification.CertificationResponse
+		// shard conf of the rewarded epoch
+		ShardConf *types.PartitionDescriptionRecord
+		// per validator summary fees of the epoch
+		Fees map[string]uint64
+		// fee list the next epoch of the shard started with
+		NextEpochFees map[string]uint64
+		// the current counter of the reward pool bill
+		Counter uint64
+	}
+
+	// DistributeRewardsAuthProof is empty, the transaction is authorized by the certificate.
+	DistributeRewardsAuthProof struct {
+		_ struct{} `cbor:",toarray"`
+	}
+
+	rewardBill struct {
+		id    types.UnitID
+		owner types.PredicateBytes
+		value uint64
+	}
+)
+
+// IsRewardOwnerParam returns true when "key" is the reward owner parameter of a validator.
+func IsRewardOwnerParam(key string) bool {
+	return strings.HasPrefix(key, RewardOwnerParamPrefix)
+}
+
+/*
+RewardOwner returns the owner predicate of the reward bills of the validator: the
+"rewardOwner.<node ID>" parameter of the shard conf or, when the parameter is not
+set, the P2PKH256 predicate of the signing key of the validator.
+*/
+func RewardOwner(shardConf *types.PartitionDescriptionRecord, nodeID string) (types.PredicateBytes, error) {
+	if value, ok := shardConf.PartitionParams[RewardOwnerParamPrefix+nodeID]; ok {
+		owner, err := hex.Decode([]byte(value))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse reward owner of the validator %s: %w", nodeID, err)
+		}
+		if len(owner) == 0 {
+			return nil, fmt.Errorf("reward owner of the validator %s is empty", nodeID)
+		}
+		return owner, nil
+	}
+	for _, v := range shardConf.Validators {
+		if v.NodeID == nodeID {
+			return templates.NewP2pkh256BytesFromKey(v.SigKey), nil
+		}
+	}
+	return nil, fmt.Errorf("validator %s is not in the shard conf", nodeID)
+}
+
+func (m *Module) executeDistributeRewardsTx(tx *types.TransactionOrder, attr *DistributeRewardsAttributes, _ *DistributeRewardsAuthProof, _ txtypes.ExecutionContext) (*types.ServerMetadata, error) {
+	unitID := tx.GetUnitID()
+	bills, err := m.rewardBills(attr)
+	if err != nil {
+		return nil, err
+	}
+	markerID, err := m.rewardsDistributedMarkerID(attr.ShardConf)
+	if err != nil {
+		return nil, err
+	}
+	targetUnitIDs := []types.UnitID{unitID}
+	var actions []state.Action
+	var sum uint64
+	for _, b := range bills {
+		targetUnitIDs = append(targetUnitIDs, b.id)
+		actions = append(actions, state.AddUnit(b.id, money.NewBillData(b.value, b.owner)))
+		sum += b.value // overflow has been checked by the validator
+	}
+	// the reward bills may be spent and deleted later, the marker unit records that
+	// the rewards of the epoch have been distributed
+	targetUnitIDs = append(targetUnitIDs, markerID)
+	actions = append(actions, state.AddUnit(markerID, money.NewBillData(0, rewardsDistributedPredicate)))
+	actions = append(actions, state.UpdateUnitData(unitID,
+		func(data types.UnitData) (types.UnitData, error) {
+			bd, ok := data.(*money.BillData)
+			if !ok {
+				return nil, fmt.Errorf("unit %v does not contain bill data", unitID)
+			}
+			bd.Value -= sum
+			bd.Counter += 1
+			return bd, nil
+		},
+	))
+	if err := m.state.Apply(actions...); err != nil {
+		return nil, fmt.Errorf("state update failed: %w", err)
+	}
+	return &types.ServerMetadata{TargetUnits: targetUnitIDs, SuccessIndicator: types.TxStatusSuccessful}, nil
+}
+
+func (m *Module) validateDistributeRewardsTx(tx *types.TransactionOrder, attr *DistributeRewardsAttributes, _ *DistributeRewardsAuthProof, _ txtypes.ExecutionContext) error {
+	if !bytes.Equal(tx.UnitID, RewardPoolMoneySupplyID) {
+		return errors.New("rewards must be paid from the reward pool")
+	}
+	unit, err := m.state.GetUnit(tx.UnitID, false)
+	if err != nil {
+		return fmt.Errorf("get unit error: %w", err)
+	}
+	bd, ok := unit.Data().(*money.BillData)
+	if !ok {
+		return errors.New("invalid unit type")
+	}
+	if bd.Counter != attr.Counter {
+		return ErrInvalidCounter
+	}
+	if err := m.verifyEpochSummary(attr); err != nil {
+		return fmt.Errorf("invalid epoch summary: %w", err)
+	}
+	markerID, err := m.rewardsDistributedMarkerID(attr.ShardConf)
+	if err != nil {
+		return err
+	}
+	if _, err := m.state.GetUnit(markerID, false); !errors.Is(err, avl.ErrNotFound) {
+		if err != nil {
+			return fmt.Errorf("loading rewards distributed marker: %w", err)
+		}
+		return ErrRewardsAlreadyDistributed
+	}
+
+	bills, err := m.rewardBills(attr)
+	if err != nil {
+		return err
+	}
+	var sum uint64
+	for _, b := range bills {
+		if _, err := m.state.GetUnit(b.id, false); !errors.Is(err, avl.ErrNotFound) {
+			if err != nil {
+				return fmt.Errorf("loading reward bill: %w", err)
+			}
+			return ErrRewardsAlreadyDistributed
+		}
+		if sum, ok = util.SafeAdd(sum, b.value); !ok {
+			return errors.New("overflow when summing rewards")
+		}
+	}
+	if sum > bd.Value {
+		return fmt.Errorf("insufficient reward pool value: rewards=%d pool=%d", sum, bd.Value)
+	}
+	return nil
+}
+
+func (m *Module) distributeRewardsTxTargetUnits(tx *types.TransactionOrder, attr *DistributeRewardsAttributes, _ *DistributeRewardsAuthProof, _ txtypes.ExecutionContext) ([]types.UnitID, error) {
+	bills, err := m.rewardBills(attr)
+	if err != nil {
+		return nil, err
+	}
+	markerID, err := m.rewardsDistributedMarkerID(attr.ShardConf)
+	if err != nil {
+		return nil, err
+	}
+	targetUnits := make([]types.UnitID, 0, len(bills)+2)
+	targetUnits = append(targetUnits, tx.UnitID)
+	for _, b := range bills {
+		targetUnits = append(targetUnits, b.id)
+	}
+	return append(targetUnits, markerID), nil
+}
+
+/*
+verifyEpochSummary checks that the certificate is issued by the root chain to the
+last round of the epoch described by the shard conf and that the fees are the ones
+committed to by the technical record of the certificate.
+*/
+func (m *Module) verifyEpochSummary(attr *DistributeRewardsAttributes) error {
+	cr, shardConf := attr.Certificate, attr.ShardConf
+	if shardConf == nil {
+		return errors.New("shard conf is missing")
+	}
+	if err := cr.IsValid(); err != nil {
+		return fmt.Errorf("invalid certificate: %w", err)
+	}
+	if shardConf.NetworkID != m.pdr.NetworkID {
+		return fmt.Errorf("invalid network id: %d (expected %d)", shardConf.NetworkID, m.pdr.NetworkID)
+	}
+	if cr.Partition != shardConf.PartitionID || cr.Shard.Key() != shardConf.ShardID.Key() {
+		return fmt.Errorf("certificate of the shard %s_%s doesn't match shard conf of the shard %s_%s",
+			cr.Partition, cr.Shard, shardConf.PartitionID, shardConf.ShardID)
+	}
+	shardConfHash, err := shardConf.Hash(m.hashAlgorithm)
+	if err != nil {
+		return fmt.Errorf("calculating shard conf hash: %w", err)
+	}
+	// the epoch may have ended before the latest root epoch change, the certificate
+	// must be verified against the trust base of the root epoch which issued it
+	trustBase, err := m.getTrustBase(cr.UC.UnicitySeal.Epoch)
+	if err != nil {
+		return fmt.Errorf("loading trust base of the root epoch %d: %w", cr.UC.UnicitySeal.Epoch, err)
+	}
+	if err := cr.UC.Verify(trustBase, m.hashAlgorithm, cr.Partition, shardConfHash); err != nil {
+		return fmt.Errorf("verifying certificate: %w", err)
+	}
+	// the epoch of the shard changes after the certified round
+	if epoch := cr.UC.InputRecord.Epoch; epoch != shardConf.Epoch || cr.Technical.Epoch != epoch+1 {
+		return fmt.Errorf("certificate is not for the last round of the epoch %d", shardConf.Epoch)
+	}
+
+	if len(attr.Fees) != len(shardConf.Validators) {
+		return fmt.Errorf("shard has %d validators but fee list contains %d validators", len(shardConf.Validators), len(attr.Fees))
+	}
+	for _, v := range shardConf.Validators {
+		if _, ok := attr.Fees[v.NodeID]; !ok {
+			return fmt.Errorf("validator %s is missing from the fee list", v.NodeID)
+		}
+	}
+	feeHash, err := epochFeeHash(attr.Fees, attr.NextEpochFees)
+	if err != nil {
+		return fmt.Errorf("calculating fee hash: %w", err)
+	}
+	if !bytes.Equal(feeHash, cr.Technical.FeeHash) {
+		return errors.New("fees do not match the fee hash of the technical record")
+	}
+	return nil
+}
+
+/*
+rewardBills returns the bills to be created for the validators with non-zero fees,
+ordered by node ID. The IDs of the bills are derived from the shard, epoch and node
+ID.
+*/
+func (m *Module) rewardBills(attr *DistributeRewardsAttributes) ([]rewardBill, error) {
+	if attr.Certificate == nil || attr.ShardConf == nil {
+		return nil, errors.New("epoch summary is incomplete")
+	}
+	var bills []rewardBill
+	for _, nodeID := range slices.Sorted(maps.Keys(attr.Fees)) {
+		if attr.Fees[nodeID] == 0 {
+			continue
+		}
+		owner, err := RewardOwner(attr.ShardConf, nodeID)
+		if err != nil {
+			return nil, err
+		}
+		id, err := m.pdr.ComposeUnitID(types.ShardID{}, money.BillUnitType, m.rewardBillIDGen(attr.ShardConf, nodeID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate reward bill id: %w", err)
+		}
+		bills = append(bills, rewardBill{id: id, owner: owner, value: attr.Fees[nodeID]})
+	}
+	return bills, nil
+}
+
+func (m *Module) rewardBillIDGen(shardConf *types.PartitionDescriptionRecord, nodeID string) func(buf []byte) error {
+	return func(buf []byte) error {
+		h := abhash.New(m.hashAlgorithm.New())
+		h.Write(shardConf.PartitionID)
+		h.Write(shardConf.ShardID.Key())
+		h.Write(shardConf.Epoch)
+		h.Write(nodeID)
+		sum, err := h.Sum()
+		if err != nil {
+			return err
+		}
+		if len(buf) > len(sum) {
+			return fmt.Errorf("unit ID length %d exceeds the hash length %d", len(buf), len(sum))
+		}
+		copy(buf, sum)
+		return nil
+	}
+}
+
+/*
+rewardsDistributedMarkerID returns the ID of the unit which is created when the rewards
+of the shard epoch are distributed. Unlike the reward bills, which can be spent and
+deleted (ie by the dust collector), the marker unit can't be spent so it prevents the
+rewards of the epoch from being paid out again.
+*/
+func (m *Module) rewardsDistributedMarkerID(shardConf *types.PartitionDescriptionRecord) (types.UnitID, error) {
+	if shardConf == nil {
+		return nil, errors.New("epoch summary is incomplete")
+	}
+	id, err := m.pdr.ComposeUnitID(types.ShardID{}, money.BillUnitType, func(buf []byte) error {
+		h := abhash.New(m.hashAlgorithm.New())
+		h.Write("rewards distributed")
+		h.Write(shardConf.PartitionID)
+		h.Write(shardConf.ShardID.Key())
+		h.Write(shardConf.Epoch)
+		sum, err := h.Sum()
+		if err != nil {
+			return err
+		}
+		if len(buf) > len(sum) {
+			return fmt.Errorf("unit ID length %d exceeds the hash length %d", len(buf), len(sum))
+		}
+		copy(buf, sum)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate rewards distributed marker id: %w", err)
+	}
+	return id, nil
+}
+
+/*
+epochFeeHash returns the fee hash of the technical record of the last round of the
+epoch, it must be calculated the same way as the root chain does it: fees of the
+completed epoch (as the CBOR encoded "previous epoch fees") and the fee list of
+the next epoch.
+*/
+func epochFeeHash(fees, nextEpochFees map[string]uint64) ([]byte, error) {
+	prevEpochFees, err := cbor.Marshal(fees)
+	if err != nil {
+		return nil, fmt.Errorf("encoding fees: %w", err)
+	}
+	h := abhash.New(crypto.SHA256.New())
+	h.WriteRaw(prevEpochFees)
+	h.Write(nextEpochFees)
+	return h.Sum()
+}
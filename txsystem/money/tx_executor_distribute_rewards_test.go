@@ -0,0 +1,240 @@
+package money
+
+import (
+	"crypto"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	abcrypto "github.com/alphabill-org/alphabill-go-base/crypto"
+	"github.com/alphabill-org/alphabill-go-base/predicates/templates"
+	moneyid "github.com/alphabill-org/alphabill-go-base/testutils/money"
+	"github.com/alphabill-org/alphabill-go-base/txsystem/money"
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill-go-base/types/hex"
+	testcertificates "github.com/alphabill-org/alphabill/internal/testutils/certificates"
+	testsig "github.com/alphabill-org/alphabill/internal/testutils/sig"
+	testtb "github.com/alphabill-org/alphabill/internal/testutils/trustbase"
+	"github.com/alphabill-org/alphabill/network/protocol/certification"
+	"github.com/alphabill-org/alphabill/state"
+	testctx "github.com/alphabill-org/alphabill/txsystem/testutils/exec_context"
+)
+
+func TestModule_DistributeRewards(t *testing.T) {
+	rootSigner, rootVerifier := testsig.CreateSignerAndVerifier(t)
+	_, v1 := testsig.CreateSignerAndVerifier(t)
+	_, v2 := testsig.CreateSignerAndVerifier(t)
+	sigKey1, err := v1.MarshalPublicKey()
+	require.NoError(t, err)
+	sigKey2, err := v2.MarshalPublicKey()
+	require.NoError(t, err)
+
+	// shard conf of the rewarded epoch, validator "2" has configured reward owner
+	shardConf := &types.PartitionDescriptionRecord{
+		Version:     1,
+		NetworkID:   moneyid.PDR().NetworkID,
+		PartitionID: 2,
+		Epoch:       1,
+		T2Timeout:   2500,
+		Validators: []*types.NodeInfo{
+			{NodeID: "1", SigKey: sigKey1, Stake: 1},
+			{NodeID: "2", SigKey: sigKey2, Stake: 1},
+		},
+		PartitionParams: map[string]string{RewardOwnerParamPrefix + "2": string(hex.Encode(templates.AlwaysTrueBytes()))},
+	}
+	fees := map[string]uint64{"1": 10, "2": 20}
+	nextEpochFees := map[string]uint64{"1": 0, "2": 0, "3": 0}
+	newAttr := func(t *testing.T, fees map[string]uint64, counter uint64) *DistributeRewardsAttributes {
+		return &DistributeRewardsAttributes{
+			Certificate:   createEpochCertificate(t, rootSigner, shardConf, fees, nextEpochFees),
+			ShardConf:     shardConf,
+			Fees:          fees,
+			NextEpochFees: nextEpochFees,
+			Counter:       counter,
+		}
+	}
+	newPool := func(value uint64) moneyModuleOption {
+		return withStateUnit(RewardPoolMoneySupplyID, &money.BillData{Value: value, Counter: 4, OwnerPredicate: RewardPoolPredicate})
+	}
+	authProof := &DistributeRewardsAuthProof{}
+
+	t.Run("ok", func(t *testing.T) {
+		module := newTestMoneyModule(t, rootVerifier, newPool(100))
+		attr := newAttr(t, fees, 4)
+		tx := createTx(RewardPoolMoneySupplyID, nil, TransactionTypeDistributeRewards)
+		require.NoError(t, tx.SetAttributes(attr))
+		exeCtx := testctx.NewMockExecutionContext()
+		require.NoError(t, module.validateDistributeRewardsTx(tx, attr, authProof, exeCtx))
+
+		sm, err := module.executeDistributeRewardsTx(tx, attr, authProof, exeCtx)
+		require.NoError(t, err)
+		require.Len(t, sm.TargetUnits, 4)
+		targetUnits, err := module.distributeRewardsTxTargetUnits(tx, attr, authProof, exeCtx)
+		require.NoError(t, err)
+		require.Equal(t, sm.TargetUnits, targetUnits)
+
+		_, pool := getBill(t, module.state, RewardPoolMoneySupplyID)
+		require.EqualValues(t, 70, pool.Value)
+		require.EqualValues(t, 5, pool.Counter)
+		_, bill := getBill(t, module.state, sm.TargetUnits[1])
+		require.EqualValues(t, 10, bill.Value)
+		require.EqualValues(t, templates.NewP2pkh256BytesFromKey(sigKey1), bill.OwnerPredicate)
+		_, bill = getBill(t, module.state, sm.TargetUnits[2])
+		require.EqualValues(t, 20, bill.Value)
+		require.EqualValues(t, templates.AlwaysTrueBytes(), bill.OwnerPredicate)
+		// the marker of the distributed epoch
+		_, marker := getBill(t, module.state, sm.TargetUnits[3])
+		require.Zero(t, marker.Value)
+		require.EqualValues(t, templates.AlwaysFalseBytes(), marker.OwnerPredicate)
+
+		// the rewards of the epoch are paid only once
+		attr.Counter = 5
+		require.ErrorIs(t, module.validateDistributeRewardsTx(tx, attr, authProof, exeCtx), ErrRewardsAlreadyDistributed)
+
+		// the rewards can't be paid again after the reward bills have been deleted (ie by the dust collector)
+		require.NoError(t, module.state.Apply(state.DeleteUnit(sm.TargetUnits[1]), state.DeleteUnit(sm.TargetUnits[2])))
+		require.ErrorIs(t, module.validateDistributeRewardsTx(tx, attr, authProof, exeCtx), ErrRewardsAlreadyDistributed)
+	})
+
+	t.Run("no rewards for validators without fees", func(t *testing.T) {
+		module := newTestMoneyModule(t, rootVerifier, newPool(100))
+		attr := newAttr(t, map[string]uint64{"1": 0, "2": 5}, 4)
+		tx := createTx(RewardPoolMoneySupplyID, nil, TransactionTypeDistributeRewards)
+		targetUnits, err := module.distributeRewardsTxTargetUnits(tx, attr, authProof, testctx.NewMockExecutionContext())
+		require.NoError(t, err)
+		require.Len(t, targetUnits, 3)
+	})
+
+	t.Run("not the reward pool", func(t *testing.T) {
+		module := newTestMoneyModule(t, rootVerifier, newPool(100))
+		attr := newAttr(t, fees, 4)
+		tx := createTx(DustCollectorMoneySupplyID, nil, TransactionTypeDistributeRewards)
+		require.EqualError(t, module.validateDistributeRewardsTx(tx, attr, authProof, testctx.NewMockExecutionContext()),
+			"rewards must be paid from the reward pool")
+	})
+
+	t.Run("invalid counter", func(t *testing.T) {
+		module := newTestMoneyModule(t, rootVerifier, newPool(100))
+		attr := newAttr(t, fees, 3)
+		tx := createTx(RewardPoolMoneySupplyID, nil, TransactionTypeDistributeRewards)
+		require.ErrorIs(t, module.validateDistributeRewardsTx(tx, attr, authProof, testctx.NewMockExecutionContext()), ErrInvalidCounter)
+	})
+
+	t.Run("fees do not match the certificate", func(t *testing.T) {
+		module := newTestMoneyModule(t, rootVerifier, newPool(100))
+		attr := newAttr(t, fees, 4)
+		attr.Fees = map[string]uint64{"1": 10, "2": 50}
+		tx := createTx(RewardPoolMoneySupplyID, nil, TransactionTypeDistributeRewards)
+		require.EqualError(t, module.validateDistributeRewardsTx(tx, attr, authProof, testctx.NewMockExecutionContext()),
+			"invalid epoch summary: fees do not match the fee hash of the technical record")
+	})
+
+	t.Run("validator missing from the fee list", func(t *testing.T) {
+		module := newTestMoneyModule(t, rootVerifier, newPool(100))
+		attr := newAttr(t, map[string]uint64{"1": 10, "3": 20}, 4)
+		tx := createTx(RewardPoolMoneySupplyID, nil, TransactionTypeDistributeRewards)
+		require.EqualError(t, module.validateDistributeRewardsTx(tx, attr, authProof, testctx.NewMockExecutionContext()),
+			"invalid epoch summary: validator 2 is missing from the fee list")
+	})
+
+	t.Run("certificate is not signed by the root chain", func(t *testing.T) {
+		_, otherVerifier := testsig.CreateSignerAndVerifier(t)
+		module := newTestMoneyModule(t, otherVerifier, newPool(100))
+		attr := newAttr(t, fees, 4)
+		tx := createTx(RewardPoolMoneySupplyID, nil, TransactionTypeDistributeRewards)
+		require.ErrorContains(t, module.validateDistributeRewardsTx(tx, attr, authProof, testctx.NewMockExecutionContext()),
+			"invalid epoch summary: verifying certificate")
+	})
+
+	t.Run("certificate is verified by the trust base of its root epoch", func(t *testing.T) {
+		// the static trust base is of another root epoch
+		_, otherVerifier := testsig.CreateSignerAndVerifier(t)
+		module := newTestMoneyModule(t, otherVerifier, newPool(100))
+		module.getTrustBase = func(epoch uint64) (types.RootTrustBase, error) {
+			if epoch != 0 {
+				return nil, fmt.Errorf("unexpected epoch %d", epoch)
+			}
+			return testtb.NewTrustBase(t, rootVerifier), nil
+		}
+		attr := newAttr(t, fees, 4)
+		tx := createTx(RewardPoolMoneySupplyID, nil, TransactionTypeDistributeRewards)
+		require.NoError(t, module.validateDistributeRewardsTx(tx, attr, authProof, testctx.NewMockExecutionContext()))
+
+		module.getTrustBase = func(epoch uint64) (types.RootTrustBase, error) {
+			return nil, fmt.Errorf("trust base of the epoch %d not found", epoch)
+		}
+		require.EqualError(t, module.validateDistributeRewardsTx(tx, attr, authProof, testctx.NewMockExecutionContext()),
+			"invalid epoch summary: loading trust base of the root epoch 0: trust base of the epoch 0 not found")
+	})
+
+	t.Run("insufficient reward pool", func(t *testing.T) {
+		module := newTestMoneyModule(t, rootVerifier, newPool(25))
+		attr := newAttr(t, fees, 4)
+		tx := createTx(RewardPoolMoneySupplyID, nil, TransactionTypeDistributeRewards)
+		require.EqualError(t, module.validateDistributeRewardsTx(tx, attr, authProof, testctx.NewMockExecutionContext()),
+			"insufficient reward pool value: rewards=30 pool=25")
+	})
+}
+
+func TestRewardOwner(t *testing.T) {
+	shardConf := &types.PartitionDescriptionRecord{
+		Validators: []*types.NodeInfo{{NodeID: "1", SigKey: []byte{1, 2, 3}, Stake: 1}},
+		PartitionParams: map[string]string{
+			RewardOwnerParamPrefix + "2": "0x83004101f6",
+			RewardOwnerParamPrefix + "3": "not hex",
+		},
+	}
+	owner, err := RewardOwner(shardConf, "1")
+	require.NoError(t, err)
+	require.EqualValues(t, templates.NewP2pkh256BytesFromKey([]byte{1, 2, 3}), owner)
+
+	owner, err = RewardOwner(shardConf, "2")
+	require.NoError(t, err)
+	require.EqualValues(t, []byte{0x83, 0x00, 0x41, 0x01, 0xf6}, owner)
+
+	_, err = RewardOwner(shardConf, "3")
+	require.ErrorContains(t, err, "failed to parse reward owner of the validator 3")
+
+	_, err = RewardOwner(shardConf, "4")
+	require.EqualError(t, err, "validator 4 is not in the shard conf")
+
+	require.True(t, IsRewardOwnerParam("rewardOwner.1"))
+	require.False(t, IsRewardOwnerParam("initialBillValue"))
+}
+
+/*
+createEpochCertificate returns certificate of the last round of the epoch described
+by the shard conf, the technical record is already in the next epoch.
+*/
+func createEpochCertificate(t *testing.T, signer abcrypto.Signer, shardConf *types.PartitionDescriptionRecord, fees, nextEpochFees map[string]uint64) *certification.CertificationResponse {
+	feeHash, err := epochFeeHash(fees, nextEpochFees)
+	require.NoError(t, err)
+	tr := certification.TechnicalRecord{
+		Round:    11,
+		Epoch:    shardConf.Epoch + 1,
+		Leader:   "1",
+		StatHash: []byte{1},
+		FeeHash:  feeHash,
+	}
+	trHash, err := tr.Hash()
+	require.NoError(t, err)
+	ir := &types.InputRecord{
+		Version:         1,
+		RoundNumber:     10,
+		Epoch:           shardConf.Epoch,
+		Hash:            []byte{2},
+		PreviousHash:    []byte{1},
+		BlockHash:       []byte{3},
+		SummaryValue:    []byte{4},
+		Timestamp:       types.NewTimestamp(),
+		SumOfEarnedFees: 1,
+	}
+	uc := testcertificates.CreateUnicityCertificate(t, signer, ir, shardConf, 5, make([]byte, crypto.SHA256.Size()), trHash)
+	return &certification.CertificationResponse{
+		Partition: shardConf.PartitionID,
+		Shard:     shardConf.ShardID,
+		Technical: tr,
+		UC:        *uc,
+	}
+}
@@ -20,6 +20,8 @@ type Options struct {
 	predicateRunner      predicates.PredicateRunner
 	feeCredit            txtypes.FeeCreditModule
 	observe              Observability
+	parallelWorkers      int
+	newWorker            TxSystemFactory
 }
 
 type Option func(*Options) error
@@ -77,6 +79,19 @@ func WithFeeCredits(f txtypes.FeeCreditModule) Option {
 	}
 }
 
+/*
+WithParallelExecution enables parallel execution of the non-conflicting transactions
+by ExecuteBatch. The "newWorker" factory is used to create "workers" instances of
+the transaction system, one per worker.
+*/
+func WithParallelExecution(workers int, newWorker TxSystemFactory) Option {
+	return func(g *Options) error {
+		g.parallelWorkers = workers
+		g.newWorker = newWorker
+		return nil
+	}
+}
+
 func (o *Options) initPredicateRunner(observe Observability) (*Options, error) {
 	templEng, err := templates.New(observe)
 	if err != nil {
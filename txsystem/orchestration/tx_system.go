@@ -33,3 +33,16 @@ func NewTxSystem(shardConf types.PartitionDescriptionRecord, observe txsystem.Ob
 func NOPFeeCreditValidator(_ txtypes.ExecutionContext, _ *types.TransactionOrder) error {
 	return nil
 }
+
+/*
+NewTxDecoders returns the transaction handlers of the orchestration partition
+which are not backed by state and thus may be used only to decode the attributes
+and auth proofs of the transactions (see txtypes.TxExecutor.DecodeTx).
+*/
+func NewTxDecoders() (txtypes.TxExecutors, error) {
+	handlers := make(txtypes.TxExecutors)
+	if err := handlers.Add((&Module{}).TxHandlers()); err != nil {
+		return nil, fmt.Errorf("registering orchestration transaction handlers: %w", err)
+	}
+	return handlers, nil
+}
@@ -0,0 +1,193 @@
+package txsystem
+
+import (
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill/state"
+	txtypes "github.com/alphabill-org/alphabill/txsystem/types"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+var _ BatchExecutor = (*GenericTxSystem)(nil)
+
+type (
+	// TxSystemFactory creates a transaction system which uses the given state,
+	// used to create the workers for the parallel execution of transactions
+	// (see WithParallelExecution).
+	TxSystemFactory func(s *state.State, observe Observability) (*GenericTxSystem, error)
+
+	waveTx struct {
+		tx   *types.TransactionOrder
+		txID string
+	}
+
+	waveResult struct {
+		txr     *types.TransactionRecord
+		err     error
+		changes *state.ChangeSet
+	}
+
+	// workerObservability disables metrics of the worker transaction systems,
+	// the worker states are not the state of the shard.
+	workerObservability struct {
+		Observability
+	}
+)
+
+func (workerObservability) Meter(name string, opts ...metric.MeterOption) metric.Meter {
+	return noop.NewMeterProvider().Meter(name, opts...)
+}
+
+func (m *GenericTxSystem) initWorkers(workers int, newWorker TxSystemFactory, observe Observability) error {
+	if workers < 2 {
+		return nil
+	}
+	if newWorker == nil {
+		return fmt.Errorf("transaction system factory is required for parallel execution")
+	}
+	for range workers {
+		s := state.NewEmptyState()
+		w, err := newWorker(s, workerObservability{Observability: observe})
+		if err != nil {
+			return fmt.Errorf("creating worker transaction system: %w", err)
+		}
+		if w.state != s {
+			return fmt.Errorf("worker transaction system must use the state provided by the factory")
+		}
+		m.workers = append(m.workers, w)
+	}
+	return nil
+}
+
+/*
+ExecuteBatch executes the transactions in the order given, the outcome is the
+same as calling Execute for each transaction. Execution stops at the first
+transaction for which Execute would return an error, ie the error returned
+is for the transaction txs[len(records)].
+
+When parallel execution is enabled (see WithParallelExecution) the transactions
+are executed in "waves". A wave is the longest run of transactions, starting
+from the next unexecuted one, which
+  - are supported by handlers marked with txtypes.WithIsolatedExecution;
+  - do not share target units (including the fee credit record).
+
+Transactions of the wave are executed in parallel by the workers, each one on an
+isolated view of the state as it was at the beginning of the wave. The changes
+are then applied to the state in the order of the transactions. When it turns out
+that the transaction accessed a unit modified by a preceding transaction of the
+wave (the target units are not a complete list of the units a transaction may
+access) the changes are discarded and the transaction is re-executed as part of
+the next wave (or sequentially).
+*/
+func (m *GenericTxSystem) ExecuteBatch(txs []*types.TransactionOrder) ([]*types.TransactionRecord, error) {
+	txrs := make([]*types.TransactionRecord, 0, len(txs))
+	for len(txrs) < len(txs) {
+		var wave []waveTx
+		if len(m.workers) > 1 {
+			wave = m.nextWave(txs[len(txrs):])
+		}
+		if len(wave) < 2 {
+			txr, err := m.Execute(txs[len(txrs)])
+			if err != nil {
+				return txrs, err
+			}
+			txrs = append(txrs, txr)
+			continue
+		}
+		waveTxrs, err := m.executeWave(wave)
+		txrs = append(txrs, waveTxrs...)
+		if err != nil {
+			return txrs, err
+		}
+	}
+	return txrs, nil
+}
+
+func (m *GenericTxSystem) nextWave(txs []*types.TransactionOrder) []waveTx {
+	units := make(map[string]struct{})
+	var wave []waveTx
+	for _, tx := range txs {
+		if m.fees.IsFeeCreditTx(tx) || !m.handlers.IsolatedExecution(tx.Type) {
+			break
+		}
+		txHash, err := tx.Hash(m.hashAlgorithm)
+		if err != nil {
+			break
+		}
+		txID := hex.EncodeToString(txHash)
+		if _, f := m.etBuffer.Get(txID); f {
+			break
+		}
+		_, _, targetUnits, err := m.handlers.UnmarshalTx(tx, txtypes.NewExecutionContext(m, m.fees, tx.MaxFee()))
+		if err != nil {
+			break
+		}
+		if fcrID := tx.FeeCreditRecordID(); fcrID != nil {
+			targetUnits = append(targetUnits, fcrID)
+		}
+		if slices.ContainsFunc(targetUnits, func(id types.UnitID) bool {
+			_, ok := units[string(id)]
+			return ok
+		}) {
+			break
+		}
+		for _, id := range targetUnits {
+			units[string(id)] = struct{}{}
+		}
+		wave = append(wave, waveTx{tx: tx, txID: txID})
+	}
+	return wave
+}
+
+func (m *GenericTxSystem) executeWave(wave []waveTx) ([]*types.TransactionRecord, error) {
+	results := make([]waveResult, len(wave))
+	var wg sync.WaitGroup
+	for i, w := range m.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := i; idx < len(wave); idx += len(m.workers) {
+				results[idx] = w.executeIsolated(m, wave[idx].tx)
+			}
+		}()
+	}
+	wg.Wait()
+
+	modified := make(map[string]struct{})
+	txrs := make([]*types.TransactionRecord, 0, len(wave))
+	for i, r := range results {
+		if r.changes == nil || r.changes.DependsOn(modified) {
+			// the transaction must see the changes made by the preceding transactions,
+			// it will be executed again on the current state
+			return txrs, nil
+		}
+		if r.err != nil {
+			return txrs, r.err
+		}
+		if err := m.state.ApplyChanges(r.changes); err != nil {
+			return txrs, fmt.Errorf("applying state changes of the transaction: %w", err)
+		}
+		m.etBuffer.Add(wave[i].txID, wave[i].tx.Timeout())
+		for _, id := range r.changes.Modified() {
+			modified[string(id)] = struct{}{}
+		}
+		txrs = append(txrs, r.txr)
+	}
+	return txrs, nil
+}
+
+// executeIsolated executes the transaction on the isolated view of the state of the "base".
+func (m *GenericTxSystem) executeIsolated(base *GenericTxSystem, tx *types.TransactionOrder) waveResult {
+	if err := m.state.ForkFrom(base.state); err != nil {
+		return waveResult{err: fmt.Errorf("forking state: %w", err)}
+	}
+	m.currentRoundNumber = base.currentRoundNumber
+	m.etBuffer = NewETBuffer()
+	txr, err := m.Execute(tx)
+	return waveResult{txr: txr, err: err, changes: m.state.Changes()}
+}
@@ -83,3 +83,25 @@ func NewTxSystem(shardConf basetypes.PartitionDescriptionRecord, observe txsyste
 		txsystem.WithExecutedTransactions(options.executedTransactions),
 	)
 }
+
+/*
+NewTxDecoders returns the transaction handlers of the tokens partition which are
+not backed by state and thus may be used only to decode the attributes and auth
+proofs of the transactions (see txtypes.TxExecutor.DecodeTx). The "permissionedMode"
+flag selects the fee credit handlers, see WithAdminOwnerPredicate.
+*/
+func NewTxDecoders(permissionedMode bool) (txtypes.TxExecutors, error) {
+	handlers := make(txtypes.TxExecutors)
+	modules := []txtypes.Module{&NonFungibleTokensModule{}, &FungibleTokensModule{}, &NopModule{}}
+	if permissionedMode {
+		modules = append(modules, &permissioned.FeeCreditModule{})
+	} else {
+		modules = append(modules, &fc.FeeCreditModule{})
+	}
+	for _, m := range modules {
+		if err := handlers.Add(m.TxHandlers()); err != nil {
+			return nil, fmt.Errorf("registering transaction handlers: %w", err)
+		}
+	}
+	return handlers, nil
+}
@@ -82,6 +82,15 @@ type (
 		Execute(order *types.TransactionOrder) (*types.TransactionRecord, error)
 	}
 
+	// BatchExecutor is implemented by the transaction systems which are able to
+	// execute a batch of transactions more efficiently than one by one.
+	BatchExecutor interface {
+		// ExecuteBatch executes the transactions in the given order, the outcome must be
+		// the same as calling Execute for each transaction. Execution stops at the first
+		// failing transaction, ie the error returned is for the transaction txs[len(records)].
+		ExecuteBatch(txs []*types.TransactionOrder) ([]*types.TransactionRecord, error)
+	}
+
 	// StateSummary represents aggregate state hashes of the transaction system.
 	StateSummary struct {
 		rootHash []byte
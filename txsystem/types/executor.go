@@ -16,10 +16,15 @@ type (
 		Execute     func(tx *types.TransactionOrder, attributes *A, authProof *P, exeCtx ExecutionContext) (*types.ServerMetadata, error)
 		Validate    func(tx *types.TransactionOrder, attributes *A, authProof *P, exeCtx ExecutionContext) error
 		TargetUnits func(tx *types.TransactionOrder, attributes *A, authProof *P, exeCtx ExecutionContext) ([]types.UnitID, error)
+		// isolated is true when the handler has no side effects besides the changes
+		// made to the state, ie the transaction can be executed on an isolated view
+		// of the state (in parallel with other transactions)
+		isolated bool
 	}
 
 	TxExecutor interface {
 		UnmarshalTx(txo *types.TransactionOrder, ctx ExecutionContext) (any, any, []types.UnitID, error)
+		DecodeTx(txo *types.TransactionOrder) (any, any, error)
 		ValidateTx(tx *types.TransactionOrder, attributes any, authProof any, exeCtx ExecutionContext) error
 		ExecuteTxWithAttr(tx *types.TransactionOrder, attributes any, authProof any, exeCtx ExecutionContext) (*types.ServerMetadata, error)
 		ExecuteTx(tx *types.TransactionOrder, exeCtx ExecutionContext) (*types.ServerMetadata, error)
@@ -71,14 +76,25 @@ func WithTargetUnitsFn[A, P any](u GenericTargetUnitsFunc[A, P]) TxHandlerOption
 	}
 }
 
-func (t *TxHandler[A, P]) UnmarshalTx(txo *types.TransactionOrder, exeCtx ExecutionContext) (any, any, []types.UnitID, error) {
-	attr := new(A)
-	if err := txo.UnmarshalAttributes(attr); err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to unmarshal payload: %w", err)
+/*
+WithIsolatedExecution marks the transaction handler as safe to be executed on
+an isolated view of the state, ie handler must not keep any data of its own
+and all the changes made by the transaction must be applied to the state.
+*/
+func WithIsolatedExecution[A, P any]() TxHandlerOption[A, P] {
+	return func(handler *TxHandler[A, P]) {
+		handler.isolated = true
 	}
-	authProof := new(P)
-	if err := txo.UnmarshalAuthProof(authProof); err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to unmarshal auth proof: %w", err)
+}
+
+func (t *TxHandler[A, P]) IsolatedExecution() bool {
+	return t.isolated
+}
+
+func (t *TxHandler[A, P]) UnmarshalTx(txo *types.TransactionOrder, exeCtx ExecutionContext) (any, any, []types.UnitID, error) {
+	attr, authProof, err := t.decodeTx(txo)
+	if err != nil {
+		return nil, nil, nil, err
 	}
 	targetUnits, err := t.TargetUnits(txo, attr, authProof, exeCtx)
 	if err != nil {
@@ -87,6 +103,28 @@ func (t *TxHandler[A, P]) UnmarshalTx(txo *types.TransactionOrder, exeCtx Execut
 	return attr, authProof, targetUnits, nil
 }
 
+/*
+DecodeTx unmarshals the attributes and the auth proof of the transaction order
+into the types used by the handler. Unlike UnmarshalTx it doesn't need the
+execution context, so it can be used to inspect transactions outside of the
+transaction system.
+*/
+func (t *TxHandler[A, P]) DecodeTx(txo *types.TransactionOrder) (any, any, error) {
+	return t.decodeTx(txo)
+}
+
+func (t *TxHandler[A, P]) decodeTx(txo *types.TransactionOrder) (*A, *P, error) {
+	attr := new(A)
+	if err := txo.UnmarshalAttributes(attr); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+	authProof := new(P)
+	if err := txo.UnmarshalAuthProof(authProof); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal auth proof: %w", err)
+	}
+	return attr, authProof, nil
+}
+
 func (t *TxHandler[A, P]) ValidateTx(txo *types.TransactionOrder, attr any, authProof any, exeCtx ExecutionContext) error {
 	// cannot directly accept generic params in order to satisfy types.TxExecutor interface
 	txAttr, ok := attr.(*A)
@@ -151,6 +189,14 @@ func (h TxExecutors) UnmarshalTx(tx *types.TransactionOrder, exeCtx ExecutionCon
 	return handler.UnmarshalTx(tx, exeCtx)
 }
 
+func (h TxExecutors) DecodeTx(tx *types.TransactionOrder) (any, any, error) {
+	handler, found := h[tx.Type]
+	if !found {
+		return nil, nil, fmt.Errorf("unknown transaction type %d", tx.Type)
+	}
+	return handler.DecodeTx(tx)
+}
+
 func (h TxExecutors) Validate(txo *types.TransactionOrder, attr any, authProof any, exeCtx ExecutionContext) error {
 	handler, found := h[txo.Type]
 	if !found {
@@ -180,6 +226,15 @@ func (h TxExecutors) Execute(txo *types.TransactionOrder, exeCtx ExecutionContex
 	return sm, nil
 }
 
+/*
+IsolatedExecution returns true when the handler of the transaction type
+supports execution on an isolated view of the state (see WithIsolatedExecution).
+*/
+func (h TxExecutors) IsolatedExecution(txType uint16) bool {
+	handler, ok := h[txType].(interface{ IsolatedExecution() bool })
+	return ok && handler.IsolatedExecution()
+}
+
 func (h TxExecutors) Add(src TxExecutors) error {
 	for txType, handler := range src {
 		if txType == 0 {
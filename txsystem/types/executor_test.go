@@ -360,6 +360,41 @@ func Test_TxExecutors_Execute(t *testing.T) {
 	})
 }
 
+func Test_TxExecutors_DecodeTx(t *testing.T) {
+	exec := make(TxExecutors)
+	require.NoError(t, exec.Add(NewMockTxModule(nil).TxHandlers()))
+
+	t.Run("unknown transaction type", func(t *testing.T) {
+		txo := &types.TransactionOrder{Version: 1, Payload: types.Payload{Type: 23}}
+		attr, authProof, err := exec.DecodeTx(txo)
+		require.EqualError(t, err, `unknown transaction type 23`)
+		require.Nil(t, attr)
+		require.Nil(t, authProof)
+	})
+
+	t.Run("invalid attributes", func(t *testing.T) {
+		txo := transaction.NewTransactionOrder(t,
+			transaction.WithTransactionType(mockTx),
+			transaction.WithAttributes(MockTxAuthProof{OwnerProof: []byte{1}}),
+			transaction.WithAuthProof(MockTxAuthProof{}),
+		)
+		_, _, err := exec.DecodeTx(txo)
+		require.ErrorContains(t, err, "failed to unmarshal payload")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		txo := transaction.NewTransactionOrder(t,
+			transaction.WithTransactionType(mockTx),
+			transaction.WithAttributes(MockTxAttributes{Value: 5}),
+			transaction.WithAuthProof(MockTxAuthProof{OwnerProof: []byte{1, 2}}),
+		)
+		attr, authProof, err := exec.DecodeTx(txo)
+		require.NoError(t, err)
+		require.Equal(t, &MockTxAttributes{Value: 5}, attr)
+		require.Equal(t, &MockTxAuthProof{OwnerProof: []byte{1, 2}}, authProof)
+	})
+}
+
 func Test_TxExecutors_Add(t *testing.T) {
 	t.Run("empty inputs", func(t *testing.T) {
 		dst := make(TxExecutors)